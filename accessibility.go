@@ -0,0 +1,40 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import . "github.com/lxn/go-winapi"
+
+// WinEvents, that tell accessibility clients like screen readers about
+// changes, which they can't detect on their own. Windows translates them to
+// UI Automation events.
+//
+// Walk has no UI Automation providers. Widgets wrapping system controls rely
+// on the proxies Windows provides for them, see TableView.
+const (
+	eventObjectReorder     = 0x8004
+	eventObjectStateChange = 0x800A
+	eventObjectNameChange  = 0x800C
+	eventObjectValueChange = 0x800E
+)
+
+const (
+	objIdClient = -4
+	childIdSelf = 0
+)
+
+var procNotifyWinEvent = libuser32.NewProc("NotifyWinEvent")
+
+// notifyWinEvent reports event for the child idChild of the client area of
+// the window hwnd. Children are numbered from 1, 0 means the window itself.
+func notifyWinEvent(event uint32, hwnd HWND, idChild int32) {
+	if hwnd == 0 {
+		return
+	}
+
+	// The object id is a LONG.
+	objId := int32(objIdClient)
+
+	procNotifyWinEvent.Call(uintptr(event), uintptr(hwnd), uintptr(objId), uintptr(idChild))
+}
@@ -4,6 +4,10 @@
 
 package walk
 
+import (
+	"time"
+)
+
 type actionChangedHandler interface {
 	onActionChanged(action *Action) error
 	onActionVisibleChanged(action *Action) error
@@ -17,13 +21,53 @@ var (
 	actionsById  map[uint16]*Action = make(map[uint16]*Action)
 )
 
+// MenuItemState describes the state of an owner-drawn menu item at the time
+// it is drawn.
+type MenuItemState byte
+
+const (
+	MenuItemSelected MenuItemState = 1 << iota
+	MenuItemDisabled
+	MenuItemChecked
+)
+
+// MenuBreak specifies if a menu item starts a new column.
+type MenuBreak byte
+
+const (
+	// MenuBreakNone places the menu item below the previous one.
+	MenuBreakNone MenuBreak = iota
+
+	// MenuBreakColumn places the menu item at the top of a new column.
+	MenuBreakColumn
+
+	// MenuBreakColumnWithBar places the menu item at the top of a new column,
+	// separated from the previous one by a vertical line.
+	MenuBreakColumnWithBar
+)
+
+// MenuItemMeasureFunc returns the Size of an owner-drawn menu item.
+//
+// The canvas may be used for text measurement only.
+type MenuItemMeasureFunc func(action *Action, canvas *Canvas) (Size, error)
+
+// MenuItemDrawFunc draws an owner-drawn menu item into bounds.
+type MenuItemDrawFunc func(action *Action, canvas *Canvas, bounds Rectangle, state MenuItemState) error
+
 type Action struct {
 	menu                          *Menu
 	triggeredPublisher            EventPublisher
 	changedHandlers               []actionChangedHandler
+	name                          string
 	text                          string
 	toolTip                       string
 	image                         *Bitmap
+	font                          *Font
+	textColor                     Color
+	hasTextColor                  bool
+	measureFunc                   MenuItemMeasureFunc
+	drawFunc                      MenuItemDrawFunc
+	menuBreak                     MenuBreak
 	enabledCondition              Condition
 	enabledConditionChangedHandle int
 	visibleCondition              Condition
@@ -33,6 +77,7 @@ type Action struct {
 	visible                       bool
 	checkable                     bool
 	checked                       bool
+	defawlt                       bool
 	exclusive                     bool
 	id                            uint16
 }
@@ -51,6 +96,15 @@ func NewAction() *Action {
 	return a
 }
 
+// NewMenuAction returns a new *Action, that shows menu as sub menu in a *Menu
+// and as drop-down menu in a *ToolBar.
+func NewMenuAction(menu *Menu) *Action {
+	a := NewAction()
+	a.menu = menu
+
+	return a
+}
+
 func (a *Action) addRef() {
 	a.refCount++
 }
@@ -59,6 +113,7 @@ func (a *Action) release() {
 	a.refCount--
 
 	if a.refCount == 0 {
+		Untranslate(a)
 		a.SetEnabledCondition(nil)
 		a.SetVisibleCondition(nil)
 
@@ -106,6 +161,30 @@ func (a *Action) SetChecked(value bool) (err error) {
 	return
 }
 
+// Default returns if the Action is the default item of its Menu.
+func (a *Action) Default() bool {
+	return a.defawlt
+}
+
+// SetDefault sets if the Action is the default item of its Menu.
+//
+// The default item is rendered in bold. It usually represents the command that
+// is executed when double clicking e.g. a NotifyIcon.
+func (a *Action) SetDefault(value bool) (err error) {
+	if value != a.defawlt {
+		old := a.defawlt
+
+		a.defawlt = value
+
+		if err = a.raiseChanged(); err != nil {
+			a.defawlt = old
+			a.raiseChanged()
+		}
+	}
+
+	return
+}
+
 func (a *Action) Enabled() bool {
 	if a.enabledCondition != nil {
 		return a.enabledCondition.Satisfied()
@@ -195,6 +274,139 @@ func (a *Action) SetImage(value *Bitmap) (err error) {
 	return
 }
 
+// MenuBreak returns if the Action starts a new column, when shown in a Menu.
+func (a *Action) MenuBreak() MenuBreak {
+	return a.menuBreak
+}
+
+// SetMenuBreak sets if the Action starts a new column, when shown in a Menu.
+//
+// This allows very long menus to flow into multiple columns, instead of
+// requiring the user to scroll.
+func (a *Action) SetMenuBreak(value MenuBreak) (err error) {
+	if value != a.menuBreak {
+		old := a.menuBreak
+
+		a.menuBreak = value
+
+		if err = a.raiseChanged(); err != nil {
+			a.menuBreak = old
+			a.raiseChanged()
+		}
+	}
+
+	return
+}
+
+// Font returns the *Font used to show the Action in a Menu.
+//
+// By default this is nil, i.e. the menu font of the system is used.
+func (a *Action) Font() *Font {
+	return a.font
+}
+
+// SetFont sets the *Font used to show the Action in a Menu.
+//
+// Menu items with a custom font are drawn by walk. ToolBars ignore the font.
+func (a *Action) SetFont(value *Font) (err error) {
+	if value != a.font {
+		old := a.font
+
+		a.font = value
+
+		if err = a.raiseChanged(); err != nil {
+			a.font = old
+			a.raiseChanged()
+		}
+	}
+
+	return
+}
+
+// TextColor returns the Color of the text of the Action in a Menu.
+//
+// If none was set, the menu text color of the system is used and TextColor
+// returns 0. Use HasTextColor to tell this apart from black.
+func (a *Action) TextColor() Color {
+	return a.textColor
+}
+
+// HasTextColor returns if a Color was set using SetTextColor.
+func (a *Action) HasTextColor() bool {
+	return a.hasTextColor
+}
+
+// SetTextColor sets the Color of the text of the Action in a Menu.
+//
+// Menu items with a custom text color are drawn by walk, disabled ones in
+// gray regardless of the text color. ToolBars ignore the text color. Use
+// ResetTextColor to return to the menu text color of the system.
+func (a *Action) SetTextColor(value Color) error {
+	return a.setTextColor(value, true)
+}
+
+// ResetTextColor makes the Action use the menu text color of the system
+// again.
+func (a *Action) ResetTextColor() error {
+	return a.setTextColor(0, false)
+}
+
+func (a *Action) setTextColor(value Color, has bool) (err error) {
+	if value != a.textColor || has != a.hasTextColor {
+		oldValue, oldHas := a.textColor, a.hasTextColor
+
+		a.textColor, a.hasTextColor = value, has
+
+		if err = a.raiseChanged(); err != nil {
+			a.textColor, a.hasTextColor = oldValue, oldHas
+			a.raiseChanged()
+		}
+	}
+
+	return
+}
+
+// Name returns the name of the Action.
+func (a *Action) Name() string {
+	return a.name
+}
+
+// SetName sets the name of the Action.
+//
+// Unlike the text, the name is never shown to the user. It identifies the
+// Action for Menu.FindAction and in telemetry records.
+func (a *Action) SetName(value string) {
+	a.name = value
+}
+
+// OwnerDrawn returns if the Action is rendered by custom callbacks when shown
+// in a Menu.
+func (a *Action) OwnerDrawn() bool {
+	return a.measureFunc != nil && a.drawFunc != nil
+}
+
+// SetOwnerDraw sets the callbacks that measure and draw the Action when it is
+// shown in a Menu.
+//
+// Both callbacks must be non-nil to enable owner drawing. Pass nil for both to
+// restore default rendering. ToolBars ignore these callbacks.
+func (a *Action) SetOwnerDraw(measure MenuItemMeasureFunc, draw MenuItemDrawFunc) (err error) {
+	if (measure == nil) != (draw == nil) {
+		return newError("measure and draw must both be nil or non-nil")
+	}
+
+	oldMeasure, oldDraw := a.measureFunc, a.drawFunc
+
+	a.measureFunc, a.drawFunc = measure, draw
+
+	if err = a.raiseChanged(); err != nil {
+		a.measureFunc, a.drawFunc = oldMeasure, oldDraw
+		a.raiseChanged()
+	}
+
+	return
+}
+
 func (a *Action) Text() string {
 	return a.text
 }
@@ -288,8 +500,24 @@ func (a *Action) Triggered() *Event {
 	return a.triggeredPublisher.Event()
 }
 
+// hasTriggeredHandlers returns if any handlers are attached to the Triggered
+// event.
+func (a *Action) hasTriggeredHandlers() bool {
+	for _, handler := range a.triggeredPublisher.event.handlers {
+		if handler != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (a *Action) raiseTriggered() {
+	start := time.Now()
+
 	a.triggeredPublisher.Publish()
+
+	recordTelemetry(TelemetryActionTriggered, actionTelemetryName(a), start, 0)
 }
 
 func (a *Action) addChangedHandler(handler actionChangedHandler) {
@@ -8,6 +8,7 @@ type actionListObserver interface {
 	onInsertedAction(action *Action) error
 	onRemovingAction(action *Action) error
 	onClearingActions() error
+	actionVisible(action *Action) bool
 }
 
 type ActionList struct {
@@ -70,7 +71,7 @@ func (l *ActionList) indexInObserver(action *Action) int {
 		if a == action {
 			return idx
 		}
-		if a.Visible() {
+		if l.observer.actionVisible(a) {
 			idx++
 		}
 	}
@@ -95,8 +96,7 @@ func (l *ActionList) Insert(index int, action *Action) error {
 }
 
 func (l *ActionList) InsertMenu(index int, menu *Menu) (*Action, error) {
-	action := NewAction()
-	action.menu = menu
+	action := NewMenuAction(menu)
 
 	if err := l.Insert(index, action); err != nil {
 		return nil, err
@@ -120,7 +120,7 @@ func (l *ActionList) Remove(action *Action) error {
 
 func (l *ActionList) RemoveAt(index int) error {
 	action := l.actions[index]
-	if action.Visible() {
+	if l.observer.actionVisible(action) {
 		if err := l.observer.onRemovingAction(action); err != nil {
 			return err
 		}
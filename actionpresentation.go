@@ -0,0 +1,83 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+// ActionPresentation overrides how an *Action appears in a particular *Menu or
+// *ToolBar.
+//
+// This allows the same *Action, with its Triggered handlers, enabled state
+// and so on, to appear differently in the menu bar and a tool bar.
+type ActionPresentation struct {
+	// Text replaces the text of the *Action, if not empty.
+	Text string
+
+	// Image replaces the image of the *Action, if not nil, e.g. to use a
+	// larger icon in a tool bar.
+	Image *Bitmap
+
+	// Hidden hides the *Action in the *Menu or *ToolBar, regardless of its
+	// Visible property.
+	Hidden bool
+}
+
+// actionPresentations holds the ActionPresentation overrides of a *Menu or
+// *ToolBar.
+type actionPresentations struct {
+	byAction map[*Action]*ActionPresentation
+}
+
+// ActionPresentation returns a copy of the ActionPresentation override for
+// action, or nil if there is none.
+func (ap *actionPresentations) ActionPresentation(action *Action) *ActionPresentation {
+	p, ok := ap.byAction[action]
+	if !ok {
+		return nil
+	}
+
+	c := *p
+	return &c
+}
+
+func (ap *actionPresentations) setActionPresentation(action *Action, presentation *ActionPresentation) {
+	if presentation == nil {
+		delete(ap.byAction, action)
+		return
+	}
+
+	if ap.byAction == nil {
+		ap.byAction = make(map[*Action]*ActionPresentation)
+	}
+
+	p := *presentation
+	ap.byAction[action] = &p
+}
+
+// actionHidden returns if the presentation of action hides it, so its
+// Visible property does not matter.
+func (ap *actionPresentations) actionHidden(action *Action) bool {
+	p := ap.byAction[action]
+
+	return p != nil && p.Hidden
+}
+
+func (ap *actionPresentations) actionVisible(action *Action) bool {
+	return !ap.actionHidden(action) && action.Visible()
+}
+
+func (ap *actionPresentations) actionText(action *Action) string {
+	if p := ap.byAction[action]; p != nil && p.Text != "" {
+		return p.Text
+	}
+
+	return action.text
+}
+
+func (ap *actionPresentations) actionImage(action *Action) *Bitmap {
+	if p := ap.byAction[action]; p != nil && p.Image != nil {
+		return p.Image
+	}
+
+	return action.image
+}
@@ -0,0 +1,156 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ActivationKind tells how the application was activated.
+type ActivationKind byte
+
+const (
+	// ActivationLaunch is a plain launch, without a document or URI.
+	ActivationLaunch ActivationKind = iota
+
+	// ActivationFile is a launch to open a document, e.g. using a file
+	// association.
+	ActivationFile
+
+	// ActivationProtocol is a launch for a URI with one of the schemes set
+	// using SetProtocolSchemes.
+	ActivationProtocol
+)
+
+// Activation describes, why the application was launched.
+type Activation struct {
+	Kind ActivationKind
+
+	// Target is the path of the document or the URI.
+	Target string
+
+	// Args are all command line arguments, without the program name.
+	Args *Arguments
+
+	// Forwarded is true, if another instance forwarded its arguments to this
+	// one, see SetSingleInstance.
+	Forwarded bool
+}
+
+var (
+	protocolSchemes     []string
+	activationPublished bool
+)
+
+// SetProtocolSchemes sets the URI schemes, e.g. "myapp", the application is
+// registered for as protocol handler. Arguments with one of them are
+// reported as ActivationProtocol.
+func (app *Application) SetProtocolSchemes(schemes ...string) {
+	protocolSchemes = append([]string(nil), schemes...)
+}
+
+// Activation returns the Activation of this instance of the application.
+func (app *Application) Activation() *Activation {
+	return activationFromArgs(os.Args[1:], false)
+}
+
+// Activated returns the *ActivationEvent, that is published when the
+// application was launched for a document or URI.
+//
+// For this instance, it is published once, when the message loop starts.
+// If SetSingleInstance is used, it is also published, when another instance
+// forwarded its arguments for a document or URI, with Forwarded set. Plain
+// launches are not published, see ActivatedWithArgs instead.
+func (app *Application) Activated() *ActivationEvent {
+	return app.activatedPublisher.Event()
+}
+
+// activationFromArgs determines the Activation from the first positional
+// argument in args.
+func activationFromArgs(args []string, forwarded bool) *Activation {
+	arguments := ParseArguments(args)
+
+	activation := &Activation{Args: arguments, Forwarded: forwarded}
+
+	positional := arguments.Positional()
+	if len(positional) == 0 {
+		return activation
+	}
+
+	target := positional[0]
+
+	if isProtocolURI(target) {
+		activation.Kind = ActivationProtocol
+		activation.Target = target
+	} else if _, err := os.Stat(target); err == nil {
+		activation.Kind = ActivationFile
+		activation.Target = target
+	}
+
+	return activation
+}
+
+func isProtocolURI(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+
+	for _, scheme := range protocolSchemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// publishActivation publishes the Activated event for the Activation of
+// this instance, the first time it is called.
+func publishActivation() {
+	if activationPublished {
+		return
+	}
+
+	activationPublished = true
+
+	if activation := appSingleton.Activation(); activation.Kind != ActivationLaunch {
+		appSingleton.activatedPublisher.Publish(activation)
+	}
+}
+
+// publishForwardedActivation publishes the Activated event for args
+// forwarded by another instance.
+func publishForwardedActivation(args []string) {
+	if activation := activationFromArgs(args, true); activation.Kind != ActivationLaunch {
+		appSingleton.activatedPublisher.Publish(activation)
+	}
+}
+
+// absolutePathArgs returns args with relative paths of existing files made
+// absolute, so they stay valid when forwarded to an instance running in
+// another working directory.
+func absolutePathArgs(args []string) []string {
+	abs := make([]string, len(args))
+
+	for i, arg := range args {
+		abs[i] = arg
+
+		if isOption(arg) || filepath.IsAbs(arg) {
+			continue
+		}
+
+		if _, err := os.Stat(arg); err == nil {
+			if path, err := filepath.Abs(arg); err == nil {
+				abs[i] = path
+			}
+		}
+	}
+
+	return abs
+}
@@ -0,0 +1,43 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+type ActivationEventHandler func(activation *Activation)
+
+type ActivationEvent struct {
+	handlers []ActivationEventHandler
+}
+
+func (e *ActivationEvent) Attach(handler ActivationEventHandler) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	return len(e.handlers) - 1
+}
+
+func (e *ActivationEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type ActivationEventPublisher struct {
+	event ActivationEvent
+}
+
+func (p *ActivationEventPublisher) Event() *ActivationEvent {
+	return &p.event
+}
+
+func (p *ActivationEventPublisher) Publish(activation *Activation) {
+	for _, handler := range p.event.handlers {
+		if handler != nil {
+			handler(activation)
+		}
+	}
+}
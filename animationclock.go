@@ -0,0 +1,127 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"time"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// animationFrameInterval results in roughly 60 frames per second.
+const animationFrameInterval = time.Second / 60
+
+// AnimationTickHandler is called for each frame of an *Animation, with the
+// time elapsed since the *Animation was started.
+type AnimationTickHandler func(elapsed time.Duration)
+
+// Animation is a periodic callback driven by the *AnimationClock.
+type Animation struct {
+	clock   *AnimationClock
+	widget  Widget
+	handler AnimationTickHandler
+	start   time.Time
+}
+
+// Stop stops the *Animation. The handler will not be called anymore.
+func (a *Animation) Stop() {
+	a.clock.remove(a)
+}
+
+// AnimationClock drives all animations of the application from a single
+// timer, at roughly 60 frames per second.
+//
+// Animations of widgets, whose root window is minimized or which are not
+// visible, are paused. The timer runs only while there are animations and
+// the application is visible.
+type AnimationClock struct {
+	animations []*Animation
+	timerId    uintptr
+}
+
+var animationClockSingleton = new(AnimationClock)
+
+// AnimationClock returns the *AnimationClock of the application.
+func (app *Application) AnimationClock() *AnimationClock {
+	return animationClockSingleton
+}
+
+// Start starts a new *Animation for widget that calls handler for each frame.
+//
+// widget may be nil for animations not tied to a widget, which are never
+// paused. Animations of a widget are stopped when it is disposed of.
+func (ac *AnimationClock) Start(widget Widget, handler AnimationTickHandler) *Animation {
+	a := &Animation{
+		clock:   ac,
+		widget:  widget,
+		handler: handler,
+		start:   time.Now(),
+	}
+
+	ac.animations = append(ac.animations, a)
+
+	if widget != nil {
+		widget.BaseWidget().Disposing().Attach(a.Stop)
+	}
+
+	if !appSingleton.hidden {
+		ac.resume()
+	}
+
+	return a
+}
+
+func (ac *AnimationClock) remove(a *Animation) {
+	for i, anim := range ac.animations {
+		if anim == a {
+			ac.animations = append(ac.animations[:i], ac.animations[i+1:]...)
+			break
+		}
+	}
+
+	if len(ac.animations) == 0 {
+		ac.suspend()
+	}
+}
+
+func (ac *AnimationClock) suspend() {
+	stopTimer(ac.timerId)
+	ac.timerId = 0
+}
+
+func (ac *AnimationClock) resume() {
+	if ac.timerId == 0 && len(ac.animations) > 0 {
+		ac.timerId = startTimer(animationFrameInterval, ac.tick)
+	}
+}
+
+func (ac *AnimationClock) tick() {
+	now := time.Now()
+
+	// Handlers may start or stop animations, so we iterate over a copy.
+	animations := append([]*Animation(nil), ac.animations...)
+
+	for _, a := range animations {
+		if a.paused() {
+			continue
+		}
+
+		a.handler(now.Sub(a.start))
+	}
+}
+
+// paused returns if the widget of the *Animation can't be seen currently.
+func (a *Animation) paused() bool {
+	if a.widget == nil {
+		return false
+	}
+
+	hwnd := a.widget.Handle()
+	if hwnd == 0 || !IsWindowVisible(hwnd) {
+		return true
+	}
+
+	return IsIconic(GetAncestor(hwnd, GA_ROOT))
+}
@@ -4,6 +4,11 @@
 
 package walk
 
+import (
+	"context"
+	"time"
+)
+
 import . "github.com/lxn/go-winapi"
 
 type Settings interface {
@@ -24,9 +29,49 @@ type Application struct {
 	organizationName   string
 	productName        string
 	settings           Settings
+	unscopedWindows    bool
 	exiting            bool
 	exitCode           int
 	panickingPublisher ErrorEventPublisher
+	exitingPublisher   CancelEventPublisher
+	telemetryHandler   TelemetryHandler
+	crashHandler       *CrashHandlerOptions
+	theme              Theme
+	colorScheme        ColorScheme
+	colorManaged       bool
+	formMemory         bool
+	rightToLeft        bool
+	hidden             bool
+
+	singleInstanceMutex   HANDLE
+	settingsChangedHandle int
+
+	autoSavesSettings        bool
+	settingsSavedOnExit      bool
+	settingsAutoSaveInterval time.Duration
+	settingsAutoSaveTimerId  uintptr
+
+	ctx       context.Context
+	cancelCtx context.CancelFunc
+
+	visibilityChangedPublisher     EventPublisher
+	activatedWithArgsPublisher     ArgsEventPublisher
+	activatedPublisher             ActivationEventPublisher
+	systemColorsChangedPublisher   EventPublisher
+	themeChangedPublisher          EventPublisher
+	colorSchemeChangedPublisher    EventPublisher
+	highContrastChangedPublisher   EventPublisher
+	systemSettingsChangedPublisher StringEventPublisher
+	dpiChangedPublisher            DPIChangedEventPublisher
+	restartedPublisher             StringEventPublisher
+	languageChangedPublisher       EventPublisher
+	idlePublisher                  EventPublisher
+	sessionEndingPublisher         SessionEndEventPublisher
+	sessionEndedPublisher          EventPublisher
+	suspendingPublisher            EventPublisher
+	resumedPublisher               EventPublisher
+	powerSourceChangedPublisher    EventPublisher
+	displayStateChangedPublisher   EventPublisher
 }
 
 var appSingleton *Application = &Application{}
@@ -56,12 +101,43 @@ func (app *Application) Settings() Settings {
 }
 
 func (app *Application) SetSettings(value Settings) {
+	app.detachSettingsChanged()
+
 	app.settings = value
+
+	app.attachSettingsChanged()
+}
+
+// RightToLeft returns if all menus are laid out from right to left.
+func (app *Application) RightToLeft() bool {
+	return app.rightToLeft
+}
+
+// SetRightToLeft sets if all menus are laid out from right to left, as needed
+// for languages like Arabic and Hebrew.
+//
+// To lay out the menus of a single window from right to left, use
+// Menu.SetRightToLeft instead.
+func (app *Application) SetRightToLeft(value bool) error {
+	if value == app.rightToLeft {
+		return nil
+	}
+
+	app.rightToLeft = value
+
+	for _, m := range menusByHandle {
+		if err := m.updateItems(); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (app *Application) Exit(exitCode int) {
 	app.exiting = true
 	app.exitCode = exitCode
+	app.cancelContext()
 	PostQuitMessage(int32(exitCode))
 }
 
@@ -0,0 +1,43 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+type ArgsEventHandler func(args []string)
+
+type ArgsEvent struct {
+	handlers []ArgsEventHandler
+}
+
+func (e *ArgsEvent) Attach(handler ArgsEventHandler) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	return len(e.handlers) - 1
+}
+
+func (e *ArgsEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type ArgsEventPublisher struct {
+	event ArgsEvent
+}
+
+func (p *ArgsEventPublisher) Event() *ArgsEvent {
+	return &p.event
+}
+
+func (p *ArgsEventPublisher) Publish(args []string) {
+	for _, handler := range p.event.handlers {
+		if handler != nil {
+			handler(args)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"os"
+	"strings"
+)
+
+// Arguments provides access to command line arguments.
+//
+// Options may be given as -name, --name or /name, with a value as
+// --name=value or /name:value. Arguments that are no options are positional,
+// so a value must not be separated from its option by a space. After "--",
+// all arguments are positional.
+type Arguments struct {
+	args []string
+}
+
+// ParseArguments returns *Arguments for args, which don't include the program
+// name.
+func ParseArguments(args []string) *Arguments {
+	return &Arguments{append([]string(nil), args...)}
+}
+
+// Arguments returns the command line arguments of the application, without
+// the program name.
+func (app *Application) Arguments() *Arguments {
+	return ParseArguments(os.Args[1:])
+}
+
+// All returns all arguments.
+func (a *Arguments) All() []string {
+	return append([]string(nil), a.args...)
+}
+
+// Positional returns the arguments that are no options.
+func (a *Arguments) Positional() []string {
+	var positional []string
+
+	for i := 0; i < len(a.args); i++ {
+		arg := a.args[i]
+
+		if arg == "--" {
+			return append(positional, a.args[i+1:]...)
+		}
+
+		if !isOption(arg) {
+			positional = append(positional, arg)
+		}
+	}
+
+	return positional
+}
+
+// Flag returns if the option name is present.
+func (a *Arguments) Flag(name string) bool {
+	_, _, ok := a.find(name)
+	return ok
+}
+
+// Value returns the value of the option name, given as --name=value or
+// /name:value.
+func (a *Arguments) Value(name string) (value string, ok bool) {
+	_, value, ok = a.find(name)
+	return
+}
+
+// find returns the index and the inline value of the option name.
+func (a *Arguments) find(name string) (index int, value string, ok bool) {
+	for i, arg := range a.args {
+		if arg == "--" {
+			break
+		}
+
+		if !isOption(arg) {
+			continue
+		}
+
+		n := strings.TrimLeft(arg, "-/")
+		if j := strings.IndexAny(n, "=:"); j > -1 {
+			n, value = n[:j], n[j+1:]
+		}
+
+		if strings.EqualFold(n, name) {
+			return i, value, true
+		}
+
+		value = ""
+	}
+
+	return -1, "", false
+}
+
+// isOption returns if arg is an option, as opposed to a positional argument.
+// A single "-" is positional, as it often stands for stdin.
+func isOption(arg string) bool {
+	return len(arg) > 1 && (arg[0] == '-' || arg[0] == '/') && arg != "--"
+}
@@ -0,0 +1,127 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// BitmapLoadedHandler is called on the UI thread, when loading a *Bitmap
+// asynchronously finished.
+type BitmapLoadedHandler func(bmp *Bitmap, err error)
+
+// IconLoadedHandler is called on the UI thread, when loading an *Icon
+// asynchronously finished.
+type IconLoadedHandler func(icon *Icon, err error)
+
+// asyncLoad tracks the cancellation of an asynchronous image load.
+type asyncLoad struct {
+	mutex    sync.Mutex
+	canceled bool
+}
+
+func (al *asyncLoad) cancel() {
+	al.mutex.Lock()
+	defer al.mutex.Unlock()
+
+	al.canceled = true
+}
+
+func (al *asyncLoad) isCanceled() bool {
+	al.mutex.Lock()
+	defer al.mutex.Unlock()
+
+	return al.canceled
+}
+
+// run calls decode on a new goroutine and then finish with its results on the
+// UI thread, unless the load was canceled in the meantime.
+func (al *asyncLoad) run(decode func() (image.Image, error), finish func(im image.Image, err error)) {
+	go func() {
+		im, err := decode()
+
+		postSynchronized(func() {
+			if al.isCanceled() {
+				return
+			}
+
+			finish(im, err)
+		})
+	}()
+}
+
+// NewBitmapFromFileAsync loads a *Bitmap from an image file without blocking
+// the UI thread and passes it to handler on the UI thread.
+//
+// PNG, JPEG and GIF images are decoded on a separate goroutine, other formats
+// are loaded as by NewBitmapFromFile. Call the returned function to cancel the
+// load, in which case handler will not be called.
+func NewBitmapFromFileAsync(filePath string, handler BitmapLoadedHandler) (cancel func()) {
+	al := new(asyncLoad)
+
+	al.run(func() (image.Image, error) {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		im, _, err := image.Decode(file)
+		return im, err
+	}, func(im image.Image, err error) {
+		if err == image.ErrFormat {
+			handler(NewBitmapFromFile(filePath))
+			return
+		}
+		if err != nil {
+			handler(nil, wrapError(err))
+			return
+		}
+
+		handler(NewBitmapFromImage(im))
+	})
+
+	return al.cancel
+}
+
+// NewIconFromURLAsync downloads an *Icon from a PNG, JPEG or GIF image at url
+// without blocking the UI thread and passes it to handler on the UI thread.
+//
+// Call the returned function to cancel the load, in which case handler will
+// not be called.
+func NewIconFromURLAsync(url string, handler IconLoadedHandler) (cancel func()) {
+	al := new(asyncLoad)
+
+	al.run(func() (image.Image, error) {
+		resp, err := http.Get(url)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.New("unexpected HTTP status: " + resp.Status)
+		}
+
+		im, _, err := image.Decode(resp.Body)
+		return im, err
+	}, func(im image.Image, err error) {
+		if err != nil {
+			handler(nil, wrapError(err))
+			return
+		}
+
+		handler(NewIconFromImage(im))
+	})
+
+	return al.cancel
+}
@@ -0,0 +1,143 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	flashwStop      = 0
+	flashwTray      = 0x00000002
+	flashwTimerNoFG = 0x0000000C
+
+	sndAsync     = 0x0001
+	sndFilename  = 0x00020000
+	sndNoDefault = 0x0002
+)
+
+var (
+	procFlashWindowEx = libuser32.NewProc("FlashWindowEx")
+	procMessageBeep   = libuser32.NewProc("MessageBeep")
+	libwinmm          = syscall.NewLazyDLL("winmm.dll")
+	procPlaySound     = libwinmm.NewProc("PlaySoundW")
+)
+
+type flashWInfo struct {
+	cbSize    uint32
+	hwnd      HWND
+	dwFlags   uint32
+	uCount    uint32
+	dwTimeout uint32
+}
+
+// AttentionSound is a system sound, that can be played by
+// TopLevelWindow.RequestAttention.
+type AttentionSound int
+
+const (
+	AttentionSoundNone AttentionSound = iota
+	AttentionSoundDefault
+	AttentionSoundInformation
+	AttentionSoundWarning
+	AttentionSoundError
+	AttentionSoundQuestion
+)
+
+// messageBeepType returns the MessageBeep type of the AttentionSound.
+func (as AttentionSound) messageBeepType() uintptr {
+	switch as {
+	case AttentionSoundInformation:
+		return MB_ICONINFORMATION
+
+	case AttentionSoundWarning:
+		return MB_ICONWARNING
+
+	case AttentionSoundError:
+		return MB_ICONERROR
+
+	case AttentionSoundQuestion:
+		return MB_ICONQUESTION
+	}
+
+	return MB_OK
+}
+
+// AttentionOptions specify how TopLevelWindow.RequestAttention gets the
+// attention of the user.
+type AttentionOptions struct {
+	// FlashCount is the number of times the taskbar button is flashed. If 0,
+	// it flashes until the window is activated.
+	FlashCount int
+
+	// Sound is the system sound to play, none by default.
+	Sound AttentionSound
+
+	// SoundFile is the path of a .wav file to play instead of Sound.
+	SoundFile string
+
+	// PlaySound, if not nil, is called instead of playing Sound or SoundFile,
+	// e.g. to use a custom audio library.
+	PlaySound func()
+}
+
+// RequestAttention flashes the taskbar button of the *TopLevelWindow and
+// plays a sound, as specified by options, e.g. to notify the user that a
+// background operation completed.
+//
+// If the *TopLevelWindow is the foreground window already, nothing happens.
+// Flashing stops automatically when it is activated.
+func (tlw *TopLevelWindow) RequestAttention(options AttentionOptions) error {
+	if GetForegroundWindow() == tlw.hWnd {
+		return nil
+	}
+
+	fwi := flashWInfo{
+		hwnd:    tlw.hWnd,
+		dwFlags: flashwTray | flashwTimerNoFG,
+		uCount:  uint32(options.FlashCount),
+	}
+	fwi.cbSize = uint32(unsafe.Sizeof(fwi))
+
+	if options.FlashCount > 0 {
+		fwi.dwFlags = flashwTray
+	}
+
+	procFlashWindowEx.Call(uintptr(unsafe.Pointer(&fwi)))
+
+	switch {
+	case options.PlaySound != nil:
+		options.PlaySound()
+
+	case options.SoundFile != "":
+		if ret, _, _ := procPlaySound.Call(
+			uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(options.SoundFile))),
+			0,
+			sndFilename|sndAsync|sndNoDefault); ret == 0 {
+
+			return newAPIError("PlaySound")
+		}
+
+	case options.Sound != AttentionSoundNone:
+		procMessageBeep.Call(options.Sound.messageBeepType())
+	}
+
+	return nil
+}
+
+// CancelAttention stops flashing the taskbar button of the *TopLevelWindow,
+// started by RequestAttention.
+func (tlw *TopLevelWindow) CancelAttention() {
+	fwi := flashWInfo{
+		hwnd:    tlw.hWnd,
+		dwFlags: flashwStop,
+	}
+	fwi.cbSize = uint32(unsafe.Sizeof(fwi))
+
+	procFlashWindowEx.Call(uintptr(unsafe.Pointer(&fwi)))
+}
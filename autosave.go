@@ -0,0 +1,74 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"time"
+)
+
+// AutoSavesSettings returns if the Settings of the application are saved
+// automatically, when it exits normally or the user session ends.
+func (app *Application) AutoSavesSettings() bool {
+	return app.autoSavesSettings
+}
+
+// SetAutoSavesSettings sets if the Settings of the application are saved
+// automatically, when it exits normally or the user session ends.
+//
+// The application exits normally, when Exit is called or its last top-level
+// window is closed. When the user logs off or the machine shuts down, there
+// is no normal exit, so the Settings are saved on WM_ENDSESSION.
+func (app *Application) SetAutoSavesSettings(value bool) {
+	app.autoSavesSettings = value
+}
+
+// SettingsAutoSaveInterval returns the interval, at which the Settings of the
+// application are saved periodically. 0 means no periodic saving.
+func (app *Application) SettingsAutoSaveInterval() time.Duration {
+	return app.settingsAutoSaveInterval
+}
+
+// SetSettingsAutoSaveInterval sets the interval, at which the Settings of the
+// application are saved periodically, so that changed preferences are not
+// lost if the process is killed. Pass 0 to disable periodic saving.
+func (app *Application) SetSettingsAutoSaveInterval(value time.Duration) {
+	if value == app.settingsAutoSaveInterval {
+		return
+	}
+
+	stopTimer(app.settingsAutoSaveTimerId)
+	app.settingsAutoSaveTimerId = 0
+
+	app.settingsAutoSaveInterval = value
+
+	if value > 0 {
+		app.settingsAutoSaveTimerId = startTimer(value, app.saveSettings)
+	}
+}
+
+// saveSettings saves the Settings of the application, if any. Errors have
+// been logged by the Settings already and there is nobody to return them to.
+func (app *Application) saveSettings() {
+	if app.settings != nil {
+		app.settings.Save()
+	}
+}
+
+// saveSettingsOnExit saves the Settings of the application, if automatic
+// saving is enabled and it is exiting normally.
+func (app *Application) saveSettingsOnExit() {
+	if !app.autoSavesSettings || app.settingsSavedOnExit {
+		return
+	}
+
+	if !app.exiting && len(topLevelWindows) > 0 {
+		// Only a modal dialog has been closed.
+		return
+	}
+
+	app.settingsSavedOnExit = true
+
+	app.saveSettings()
+}
@@ -0,0 +1,239 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"context"
+	"sync"
+)
+
+// BackgroundWorkFunc does the work of a BackgroundWorker on its goroutine.
+//
+// It should call worker.ReportProgress from time to time and return early,
+// when worker.Context() is done.
+type BackgroundWorkFunc func(worker *BackgroundWorker) (result interface{}, err error)
+
+// BackgroundWorker runs a BackgroundWorkFunc on a goroutine and publishes its
+// progress and completion on the UI thread.
+type BackgroundWorker struct {
+	work                     BackgroundWorkFunc
+	ctx                      context.Context
+	cancel                   context.CancelFunc
+	running                  bool
+	result                   interface{}
+	err                      error
+	progressBar              *ProgressBar
+	progressChangedPublisher ProgressEventPublisher
+	completedPublisher       ErrorEventPublisher
+	mutex                    sync.Mutex
+	percent                  int
+	status                   string
+	progressPosted           bool
+	powerRequestKind         PowerRequestKind
+	powerRequestReason       string
+	powerRequest             *PowerRequest
+}
+
+// NewBackgroundWorker returns a new *BackgroundWorker, that runs work.
+func NewBackgroundWorker(work BackgroundWorkFunc) *BackgroundWorker {
+	return &BackgroundWorker{work: work}
+}
+
+// Start runs the work on a new goroutine. The work is canceled when the
+// application exits.
+//
+// Start must be called on the UI thread.
+func (bw *BackgroundWorker) Start() error {
+	return bw.StartContext(appSingleton.Context())
+}
+
+// StartContext is like Start, but the work is canceled when ctx is done as
+// well, e.g. when passing the Context of a *TopLevelWindow.
+func (bw *BackgroundWorker) StartContext(ctx context.Context) error {
+	if bw.running {
+		return newError("background worker already running")
+	}
+
+	if bw.powerRequestReason != "" {
+		pr, err := NewPowerRequest(bw.powerRequestKind, bw.powerRequestReason)
+		if err != nil {
+			return err
+		}
+
+		bw.powerRequest = pr
+	}
+
+	bw.running = true
+	bw.result = nil
+	bw.err = nil
+	bw.percent = 0
+	bw.status = ""
+	bw.ctx, bw.cancel = context.WithCancel(ctx)
+
+	if bw.progressBar != nil {
+		bw.progressBar.SetValue(0)
+	}
+
+	go bw.run(bw.ctx)
+
+	return nil
+}
+
+func (bw *BackgroundWorker) run(ctx context.Context) {
+	var result interface{}
+	var err error
+
+	func() {
+		defer func() {
+			if x := recover(); x != nil {
+				err = toErrorNoPanic(x)
+			}
+		}()
+
+		result, err = bw.work(bw)
+	}()
+
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	postSynchronized(func() {
+		bw.complete(result, err)
+	})
+}
+
+func (bw *BackgroundWorker) complete(result interface{}, err error) {
+	bw.cancel()
+	bw.releasePowerRequest()
+
+	bw.running = false
+	bw.result = result
+	bw.err = err
+
+	if bw.progressBar != nil && err == nil {
+		bw.progressBar.SetValue(bw.progressBar.MaxValue())
+	}
+
+	bw.completedPublisher.Publish(err)
+}
+
+// Context returns the context.Context of the running work, that is done when
+// the work was canceled.
+func (bw *BackgroundWorker) Context() context.Context {
+	return bw.ctx
+}
+
+// Cancel requests the work to stop, by canceling its Context.
+func (bw *BackgroundWorker) Cancel() {
+	if bw.cancel != nil {
+		bw.cancel()
+	}
+
+	bw.releasePowerRequest()
+}
+
+// PowerRequest returns the kind and reason of the *PowerRequest, that is
+// active while the work runs. The reason is empty, if there is none.
+func (bw *BackgroundWorker) PowerRequest() (kind PowerRequestKind, reason string) {
+	return bw.powerRequestKind, bw.powerRequestReason
+}
+
+// SetPowerRequest makes the *BackgroundWorker keep the system awake, while the
+// work runs, like a *PowerRequest of kind created with reason. The request is
+// released, when the work completed or was canceled. An empty reason turns
+// this off for the next start.
+func (bw *BackgroundWorker) SetPowerRequest(kind PowerRequestKind, reason string) {
+	bw.powerRequestKind = kind
+	bw.powerRequestReason = reason
+}
+
+func (bw *BackgroundWorker) releasePowerRequest() {
+	if bw.powerRequest != nil {
+		bw.powerRequest.Release()
+		bw.powerRequest = nil
+	}
+}
+
+// Canceled returns if cancellation of the work was requested.
+//
+// Unlike most methods of *BackgroundWorker, it may be called by the work
+// itself.
+func (bw *BackgroundWorker) Canceled() bool {
+	return bw.ctx != nil && bw.ctx.Err() != nil
+}
+
+// Running returns if the work was started and has not completed yet.
+func (bw *BackgroundWorker) Running() bool {
+	return bw.running
+}
+
+// Result returns the result of the last completed work.
+func (bw *BackgroundWorker) Result() interface{} {
+	return bw.result
+}
+
+// Err returns the error of the last completed work. If the work was canceled
+// without returning an error, it is context.Canceled.
+func (bw *BackgroundWorker) Err() error {
+	return bw.err
+}
+
+// ReportProgress publishes the ProgressChanged event with the percentage done
+// and a status text on the UI thread. It is meant to be called by the work.
+//
+// Calls are coalesced, so reporting progress very often does not flood the
+// UI thread; handlers then only see the latest progress.
+func (bw *BackgroundWorker) ReportProgress(percent int, status string) {
+	bw.mutex.Lock()
+	bw.percent = percent
+	bw.status = status
+	posted := bw.progressPosted
+	bw.progressPosted = true
+	bw.mutex.Unlock()
+
+	if !posted {
+		postSynchronized(bw.publishProgress)
+	}
+}
+
+func (bw *BackgroundWorker) publishProgress() {
+	bw.mutex.Lock()
+	percent, status := bw.percent, bw.status
+	bw.progressPosted = false
+	bw.mutex.Unlock()
+
+	if bw.progressBar != nil {
+		min, max := bw.progressBar.MinValue(), bw.progressBar.MaxValue()
+		bw.progressBar.SetValue(min + (max-min)*percent/100)
+	}
+
+	bw.progressChangedPublisher.Publish(percent, status)
+}
+
+// ProgressBar returns the *ProgressBar, that shows the progress of the work.
+func (bw *BackgroundWorker) ProgressBar() *ProgressBar {
+	return bw.progressBar
+}
+
+// SetProgressBar sets a *ProgressBar, that shows the progress of the work.
+//
+// The percentages reported are mapped to its range. When the work completed
+// successfully, the *ProgressBar is set to its maximum.
+func (bw *BackgroundWorker) SetProgressBar(pb *ProgressBar) {
+	bw.progressBar = pb
+}
+
+// ProgressChanged returns the *ProgressEvent, that is published on the UI
+// thread, when the work reported progress.
+func (bw *BackgroundWorker) ProgressChanged() *ProgressEvent {
+	return bw.progressChangedPublisher.Event()
+}
+
+// Completed returns the *ErrorEvent, that is published on the UI thread, when
+// the work completed, passing the error returned by it, context.Canceled if
+// it was canceled, or nil. Result returns the result of the work.
+func (bw *BackgroundWorker) Completed() *ErrorEvent {
+	return bw.completedPublisher.Event()
+}
@@ -0,0 +1,104 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import . "github.com/lxn/go-winapi"
+
+var changeBatch struct {
+	level       int
+	layouts     []Layout
+	resets      map[Layout]bool
+	invalidated []*WidgetBase
+}
+
+// BatchChanges calls f and defers layout updates and repaints caused by the
+// changes it makes until f returns.
+//
+// Each affected Layout is then updated only once and each affected Widget is
+// repainted only once, which avoids flicker and wasted work during bulk
+// updates. Calls to BatchChanges may be nested; the deferred work is done when
+// the outermost call returns. Functions passed to Synchronize and calls to
+// DataBinder.Reset and DataBinder.Submit are batched automatically.
+func BatchChanges(f func() error) error {
+	beginChangeBatch()
+	defer endChangeBatch()
+
+	return f()
+}
+
+func beginChangeBatch() {
+	if changeBatch.level == 0 {
+		changeBatch.resets = make(map[Layout]bool)
+	}
+
+	changeBatch.level++
+}
+
+func endChangeBatch() {
+	changeBatch.level--
+
+	if changeBatch.level > 0 {
+		return
+	}
+
+	layouts, resets, invalidated := changeBatch.layouts, changeBatch.resets, changeBatch.invalidated
+	changeBatch.layouts, changeBatch.resets, changeBatch.invalidated = nil, nil, nil
+
+	for _, layout := range layouts {
+		layout.Update(resets[layout])
+	}
+
+	for _, wb := range invalidated {
+		if wb.hWnd != 0 {
+			wb.invalidateNow()
+		}
+	}
+}
+
+// updateLayout updates layout, or defers the update if changes are batched.
+func updateLayout(layout Layout, reset bool) error {
+	if layout == nil {
+		return nil
+	}
+
+	if changeBatch.level == 0 {
+		return layout.Update(reset)
+	}
+
+	if r, ok := changeBatch.resets[layout]; !ok {
+		changeBatch.layouts = append(changeBatch.layouts, layout)
+		changeBatch.resets[layout] = reset
+	} else if reset && !r {
+		changeBatch.resets[layout] = true
+	}
+
+	return nil
+}
+
+// invalidateBatched records wb for repainting at the end of the current batch
+// and returns true, or returns false if changes are not batched.
+func invalidateBatched(wb *WidgetBase) bool {
+	if changeBatch.level == 0 {
+		return false
+	}
+
+	for _, w := range changeBatch.invalidated {
+		if w == wb {
+			return true
+		}
+	}
+
+	changeBatch.invalidated = append(changeBatch.invalidated, wb)
+
+	return true
+}
+
+func (wb *WidgetBase) invalidateNow() error {
+	if !InvalidateRect(wb.hWnd, nil, true) {
+		return newAPIError("InvalidateRect")
+	}
+
+	return nil
+}
@@ -16,7 +16,7 @@ import . "github.com/lxn/go-winapi"
 func withCompatibleDC(f func(hdc HDC) error) error {
 	hdc := CreateCompatibleDC(0)
 	if hdc == 0 {
-		return newError("CreateCompatibleDC failed")
+		return newAPIError("CreateCompatibleDC")
 	}
 	defer DeleteDC(hdc)
 
@@ -26,7 +26,7 @@ func withCompatibleDC(f func(hdc HDC) error) error {
 func hPackedDIBFromHBITMAP(hBmp HBITMAP) (HGLOBAL, error) {
 	var dib DIBSECTION
 	if GetObject(HGDIOBJ(hBmp), unsafe.Sizeof(dib), unsafe.Pointer(&dib)) == 0 {
-		return 0, newError("GetObject failed")
+		return 0, newAPIError("GetObject")
 	}
 
 	bmihSize := uintptr(unsafe.Sizeof(dib.DsBmih))
@@ -65,6 +65,8 @@ func hBitmapFromImage(im image.Image) (HBITMAP, error) {
 	bi.BV4GreenMask = 0x0000FF00
 	bi.BV4BlueMask = 0x000000FF
 	bi.BV4AlphaMask = 0xFF000000
+	bi.BV4CSType = lcsSRGB
+	bi.BV5Intent = lcsGMImages
 
 	hdc := GetDC(0)
 	defer ReleaseDC(0, hdc)
@@ -75,7 +77,7 @@ func hBitmapFromImage(im image.Image) (HBITMAP, error) {
 	hBitmap := CreateDIBSection(hdc, &bi.BITMAPINFOHEADER, DIB_RGB_COLORS, &lpBits, 0, 0)
 	switch hBitmap {
 	case 0, ERROR_INVALID_PARAMETER:
-		return 0, newError("CreateDIBSection failed")
+		return 0, newAPIError("CreateDIBSection")
 	}
 
 	// Fill the image
@@ -104,7 +106,7 @@ type Bitmap struct {
 func newBitmapFromHBITMAP(hBmp HBITMAP) (bmp *Bitmap, err error) {
 	var dib DIBSECTION
 	if GetObject(HGDIOBJ(hBmp), unsafe.Sizeof(dib), unsafe.Pointer(&dib)) == 0 {
-		return nil, newError("GetObject failed")
+		return nil, newAPIError("GetObject")
 	}
 
 	bmih := &dib.DsBmih
@@ -150,7 +152,7 @@ func NewBitmap(size Size) (bmp *Bitmap, err error) {
 		hBmp := CreateDIBSection(hdc, &hdr, DIB_RGB_COLORS, nil, 0, 0)
 		switch hBmp {
 		case 0, ERROR_INVALID_PARAMETER:
-			return newError("CreateDIBSection failed")
+			return newAPIError("CreateDIBSection")
 		}
 
 		bmp, err = newBitmapFromHBITMAP(hBmp)
@@ -194,7 +196,7 @@ func (bmp *Bitmap) withSelectedIntoMemDC(f func(hdcMem HDC) error) error {
 	return withCompatibleDC(func(hdcMem HDC) error {
 		hBmpOld := SelectObject(hdcMem, HGDIOBJ(bmp.hBmp))
 		if hBmpOld == 0 {
-			return newError("SelectObject failed")
+			return newAPIError("SelectObject")
 		}
 		defer SelectObject(hdcMem, hBmpOld)
 
@@ -203,6 +205,10 @@ func (bmp *Bitmap) withSelectedIntoMemDC(f func(hdcMem HDC) error) error {
 }
 
 func (bmp *Bitmap) draw(hdc HDC, location Point) error {
+	if colorManagedDC(hdc) && bmp.drawColorManaged(hdc, Rectangle{location.X, location.Y, bmp.size.Width, bmp.size.Height}) {
+		return nil
+	}
+
 	return bmp.withSelectedIntoMemDC(func(hdcMem HDC) error {
 		size := bmp.Size()
 
@@ -225,6 +231,10 @@ func (bmp *Bitmap) draw(hdc HDC, location Point) error {
 }
 
 func (bmp *Bitmap) drawStretched(hdc HDC, bounds Rectangle) error {
+	if colorManagedDC(hdc) && bmp.drawColorManaged(hdc, bounds) {
+		return nil
+	}
+
 	return bmp.withSelectedIntoMemDC(func(hdcMem HDC) error {
 		size := bmp.Size()
 
@@ -241,7 +251,7 @@ func (bmp *Bitmap) drawStretched(hdc HDC, bounds Rectangle) error {
 			int32(size.Height),
 			SRCCOPY) {
 
-			return newError("StretchBlt failed")
+			return newAPIError("StretchBlt")
 		}
 
 		return nil
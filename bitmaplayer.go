@@ -0,0 +1,301 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+var (
+	libmsimg32     = syscall.NewLazyDLL("msimg32.dll")
+	procAlphaBlend = libmsimg32.NewProc("AlphaBlend")
+)
+
+// BitmapLayer is a retained part of the content of a *CustomWidget.
+//
+// The content of a BitmapLayer is painted once into an off-screen *Bitmap,
+// which is then copied to the screen whenever the *CustomWidget is repainted,
+// until the BitmapLayer is invalidated. Moving a BitmapLayer, e.g. for
+// scrolling, does not repaint its content. BitmapLayers are composited on top
+// of what the PaintFunc of the *CustomWidget painted, in the order they were
+// added. Their content is opaque, but the whole BitmapLayer may be blended
+// with what is below it, see SetOpacity.
+//
+// Where Direct2D is available, a *CustomWidget with layers is presented by
+// Direct2D: The content of the layers is cached in video memory and blended
+// by the GPU. Otherwise, or if Direct2D fails, the layers are composited with
+// GDI from the off-screen bitmaps.
+type BitmapLayer struct {
+	widget    *CustomWidget
+	paint     PaintFunc
+	bounds    Rectangle
+	bitmap    *Bitmap
+	d2dBitmap unsafe.Pointer
+	opacity   float64
+	valid     bool
+	visible   bool
+}
+
+// AddBitmapLayer adds a new *BitmapLayer to the *CustomWidget, that covers
+// bounds and whose content is painted by paint.
+//
+// The Canvas passed to paint has the size of bounds and its origin at the top
+// left corner of the *BitmapLayer.
+func (cw *CustomWidget) AddBitmapLayer(bounds Rectangle, paint PaintFunc) (*BitmapLayer, error) {
+	if paint == nil {
+		return nil, newError("paint must not be nil")
+	}
+
+	l := &BitmapLayer{
+		widget:  cw,
+		paint:   paint,
+		bounds:  bounds,
+		opacity: 1,
+		visible: true,
+	}
+
+	cw.layers = append(cw.layers, l)
+
+	cw.invalidateRectangle(bounds)
+
+	return l, nil
+}
+
+// BitmapLayers returns the layers of the *CustomWidget, bottom first.
+func (cw *CustomWidget) BitmapLayers() []*BitmapLayer {
+	return append([]*BitmapLayer(nil), cw.layers...)
+}
+
+// Bounds returns the bounds of the *BitmapLayer, relative to the client area
+// of its *CustomWidget.
+func (l *BitmapLayer) Bounds() Rectangle {
+	return l.bounds
+}
+
+// SetBounds sets the bounds of the *BitmapLayer, relative to the client area
+// of its *CustomWidget.
+//
+// The content of the *BitmapLayer is repainted only, if its size changed.
+func (l *BitmapLayer) SetBounds(value Rectangle) {
+	if value == l.bounds {
+		return
+	}
+
+	l.widget.invalidateRectangle(l.bounds)
+
+	if value.Size() != l.bounds.Size() {
+		l.disposeBitmap()
+	}
+
+	l.bounds = value
+
+	l.widget.invalidateRectangle(l.bounds)
+}
+
+// Visible returns if the *BitmapLayer is shown.
+func (l *BitmapLayer) Visible() bool {
+	return l.visible
+}
+
+// SetVisible sets if the *BitmapLayer is shown.
+func (l *BitmapLayer) SetVisible(value bool) {
+	if value == l.visible {
+		return
+	}
+
+	l.visible = value
+
+	l.widget.invalidateRectangle(l.bounds)
+}
+
+// Opacity returns the opacity of the *BitmapLayer, from 0 for invisible to 1
+// for opaque.
+func (l *BitmapLayer) Opacity() float64 {
+	return l.opacity
+}
+
+// SetOpacity sets the opacity of the *BitmapLayer, from 0 for invisible to 1
+// for opaque. Changing it does not repaint the content of the *BitmapLayer.
+func (l *BitmapLayer) SetOpacity(value float64) error {
+	if value < 0 || value > 1 {
+		return newError("value out of range")
+	}
+
+	if value == l.opacity {
+		return nil
+	}
+
+	l.opacity = value
+
+	l.widget.invalidateRectangle(l.bounds)
+
+	return nil
+}
+
+// Invalidate schedules a repaint of the content of the *BitmapLayer.
+func (l *BitmapLayer) Invalidate() {
+	l.valid = false
+	l.releaseD2DBitmap()
+
+	l.widget.invalidateRectangle(l.bounds)
+}
+
+// Dispose removes the *BitmapLayer from its *CustomWidget and releases its
+// resources.
+func (l *BitmapLayer) Dispose() {
+	cw := l.widget
+
+	for i, layer := range cw.layers {
+		if layer == l {
+			cw.layers = append(cw.layers[:i], cw.layers[i+1:]...)
+			break
+		}
+	}
+
+	l.disposeBitmap()
+
+	if len(cw.layers) == 0 {
+		cw.disposeD2D()
+	}
+
+	cw.invalidateRectangle(l.bounds)
+}
+
+// disposeBitmap releases the cached content of the *BitmapLayer, which is
+// painted again when needed.
+func (l *BitmapLayer) disposeBitmap() {
+	if l.bitmap != nil {
+		l.bitmap.Dispose()
+		l.bitmap = nil
+	}
+
+	l.releaseD2DBitmap()
+
+	l.valid = false
+}
+
+// ensureContent paints the content of the *BitmapLayer into its *Bitmap,
+// unless it is still valid.
+func (l *BitmapLayer) ensureContent() error {
+	if l.valid {
+		return nil
+	}
+
+	l.releaseD2DBitmap()
+
+	if l.bitmap == nil {
+		bmp, err := NewBitmap(l.bounds.Size())
+		if err != nil {
+			return err
+		}
+
+		l.bitmap = bmp
+	}
+
+	canvas, err := NewCanvasFromImage(l.bitmap)
+	if err != nil {
+		return err
+	}
+	defer canvas.Dispose()
+
+	size := l.bounds.Size()
+	if err := l.paint(canvas, Rectangle{0, 0, size.Width, size.Height}); err != nil {
+		return err
+	}
+
+	l.valid = true
+
+	return nil
+}
+
+// compositeLayers copies the content of the visible layers of the
+// *CustomWidget, that intersect updateBounds, to canvas.
+func (cw *CustomWidget) compositeLayers(canvas *Canvas, updateBounds Rectangle) error {
+	for _, l := range cw.layers {
+		if !l.visible || l.bounds.Width <= 0 || l.bounds.Height <= 0 || !rectanglesIntersect(l.bounds, updateBounds) {
+			continue
+		}
+
+		if err := l.ensureContent(); err != nil {
+			return err
+		}
+
+		if l.opacity <= 0 {
+			continue
+		}
+
+		if l.opacity < 1 {
+			if err := l.alphaBlend(canvas.hdc); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := canvas.DrawImage(l.bitmap, l.bounds.Location()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// alphaBlend blends the content of the *BitmapLayer with its opacity into
+// hdc.
+func (l *BitmapLayer) alphaBlend(hdc HDC) error {
+	return l.bitmap.withSelectedIntoMemDC(func(hdcMem HDC) error {
+		size := l.bitmap.Size()
+
+		// BLENDFUNCTION with AC_SRC_OVER and a constant alpha.
+		blend := uintptr(uint8(l.opacity*255+0.5)) << 16
+
+		if ret, _, _ := procAlphaBlend.Call(
+			uintptr(hdc),
+			uintptr(l.bounds.X),
+			uintptr(l.bounds.Y),
+			uintptr(size.Width),
+			uintptr(size.Height),
+			uintptr(hdcMem),
+			0,
+			0,
+			uintptr(size.Width),
+			uintptr(size.Height),
+			blend); ret == 0 {
+
+			return newAPIError("AlphaBlend")
+		}
+
+		return nil
+	})
+}
+
+// releaseOutsideLayers releases the cached content of the layers, that are
+// outside the client area after a resize. It is painted again, once they
+// become visible.
+func (cw *CustomWidget) releaseOutsideLayers() {
+	client := cw.ClientBounds()
+
+	for _, l := range cw.layers {
+		if !rectanglesIntersect(l.bounds, client) {
+			l.disposeBitmap()
+		}
+	}
+}
+
+func (cw *CustomWidget) disposeLayers() {
+	for _, l := range cw.layers {
+		l.disposeBitmap()
+	}
+
+	cw.layers = nil
+
+	cw.disposeD2D()
+}
+
+func rectanglesIntersect(a, b Rectangle) bool {
+	return a.X < b.X+b.Width && b.X < a.X+a.Width && a.Y < b.Y+b.Height && b.Y < a.Y+a.Height
+}
@@ -0,0 +1,422 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"math"
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// Vtable indexes of the Direct2D methods, that the compositor of
+// BitmapLayers calls.
+const (
+	iD2D1FactoryCreateHwndRenderTarget = 14
+
+	iD2D1RenderTargetCreateBitmap        = 4
+	iD2D1RenderTargetDrawBitmap          = 26
+	iD2D1RenderTargetPushAxisAlignedClip = 45
+	iD2D1RenderTargetPopAxisAlignedClip  = 46
+	iD2D1RenderTargetBeginDraw           = 48
+	iD2D1RenderTargetEndDraw             = 49
+	iD2D1HwndRenderTargetResize          = 58
+
+	iD2D1GdiInteropRenderTargetGetDC     = 3
+	iD2D1GdiInteropRenderTargetReleaseDC = 4
+)
+
+const (
+	d2d1FactoryTypeSingleThreaded      = 0
+	d2d1RenderTargetUsageGDICompatible = 0x2
+	d2d1PresentOptionsRetainContents   = 0x1
+	d2d1AlphaModePremultiplied         = 1
+	d2d1AlphaModeIgnore                = 3
+	d2d1DCInitializeModeCopy           = 0
+	d2d1AntialiasModeAliased           = 1
+	d2d1InterpolationNearestNeighbor   = 0
+
+	dxgiFormatB8G8R8A8UNorm = 87
+
+	d2dErrRecreateTarget = 0x8899000C
+)
+
+var (
+	iidID2D1Factory                = IID{0x06152247, 0x6F50, 0x465A, [8]byte{0x92, 0x45, 0x11, 0x8B, 0xFD, 0x3B, 0x60, 0x07}}
+	iidID2D1GdiInteropRenderTarget = IID{0xE0DB51C3, 0x6F77, 0x4BAE, [8]byte{0xB3, 0xD5, 0xE4, 0x75, 0x09, 0xB3, 0x58, 0x38}}
+
+	libd2d1               = syscall.NewLazyDLL("d2d1.dll")
+	procD2D1CreateFactory = libd2d1.NewProc("D2D1CreateFactory")
+	procGetDIBits         = libgdi32.NewProc("GetDIBits")
+
+	// d2dFactory is shared by all compositors. It is created on first use.
+	d2dFactory     unsafe.Pointer
+	d2dUnavailable bool
+)
+
+type d2d1PixelFormat struct {
+	format    uint32
+	alphaMode uint32
+}
+
+type d2d1RenderTargetProperties struct {
+	typ         uint32
+	pixelFormat d2d1PixelFormat
+	dpiX, dpiY  float32
+	usage       uint32
+	minLevel    uint32
+}
+
+type d2d1HwndRenderTargetProperties struct {
+	hwnd           HWND
+	pixelSize      d2d1SizeU
+	presentOptions uint32
+}
+
+type d2d1BitmapProperties struct {
+	pixelFormat d2d1PixelFormat
+	dpiX, dpiY  float32
+}
+
+type d2d1SizeU struct {
+	width, height uint32
+}
+
+type d2d1RectF struct {
+	left, top, right, bottom float32
+}
+
+func d2d1RectFFromRectangle(r Rectangle) d2d1RectF {
+	return d2d1RectF{float32(r.X), float32(r.Y), float32(r.X + r.Width), float32(r.Y + r.Height)}
+}
+
+// d2d1SizeUArgs returns the arguments for a D2D1_SIZE_U passed by value. It
+// takes one register on 64 bit and two stack slots on 32 bit Windows.
+func d2d1SizeUArgs(size Size) []uintptr {
+	if unsafe.Sizeof(uintptr(0)) == 8 {
+		return []uintptr{uintptr(uint64(uint32(size.Width)) | uint64(uint32(size.Height))<<32)}
+	}
+
+	return []uintptr{uintptr(size.Width), uintptr(size.Height)}
+}
+
+// d2dFactoryInstance returns the shared ID2D1Factory, or nil if Direct2D is
+// not available, e.g. before Windows 7 or in some remote sessions.
+func d2dFactoryInstance() unsafe.Pointer {
+	if d2dFactory != nil || d2dUnavailable {
+		return d2dFactory
+	}
+
+	if err := procD2D1CreateFactory.Find(); err != nil {
+		d2dUnavailable = true
+		return nil
+	}
+
+	if hr, _, _ := procD2D1CreateFactory.Call(
+		d2d1FactoryTypeSingleThreaded,
+		uintptr(unsafe.Pointer(&iidID2D1Factory)),
+		0,
+		uintptr(unsafe.Pointer(&d2dFactory))); FAILED(HRESULT(int32(hr))) {
+
+		d2dFactory = nil
+		d2dUnavailable = true
+	}
+
+	return d2dFactory
+}
+
+// d2dCompositor presents a *CustomWidget with Direct2D. The PaintFunc of the
+// widget paints with GDI into the render target, then the layers are drawn
+// from bitmaps cached in video memory.
+type d2dCompositor struct {
+	cw           *CustomWidget
+	renderTarget unsafe.Pointer
+	interop      unsafe.Pointer
+}
+
+func newD2DCompositor(cw *CustomWidget) (*d2dCompositor, error) {
+	factory := d2dFactoryInstance()
+	if factory == nil {
+		return nil, newErr("Direct2D not available")
+	}
+
+	size := cw.ClientBounds().Size()
+
+	rtProps := d2d1RenderTargetProperties{
+		pixelFormat: d2d1PixelFormat{dxgiFormatB8G8R8A8UNorm, d2d1AlphaModeIgnore},
+		// Device independent pixels are pixels.
+		dpiX:  96,
+		dpiY:  96,
+		usage: d2d1RenderTargetUsageGDICompatible,
+	}
+	hwndProps := d2d1HwndRenderTargetProperties{
+		hwnd:           cw.hWnd,
+		pixelSize:      d2d1SizeU{uint32(size.Width), uint32(size.Height)},
+		presentOptions: d2d1PresentOptionsRetainContents,
+	}
+
+	c := &d2dCompositor{cw: cw}
+
+	if hr := comCall(
+		factory,
+		iD2D1FactoryCreateHwndRenderTarget,
+		uintptr(unsafe.Pointer(&rtProps)),
+		uintptr(unsafe.Pointer(&hwndProps)),
+		uintptr(unsafe.Pointer(&c.renderTarget))); FAILED(hr) {
+
+		return nil, errorFromHRESULT("ID2D1Factory.CreateHwndRenderTarget", hr)
+	}
+
+	if hr := comCall(
+		c.renderTarget,
+		iUnknownQueryInterface,
+		uintptr(unsafe.Pointer(&iidID2D1GdiInteropRenderTarget)),
+		uintptr(unsafe.Pointer(&c.interop))); FAILED(hr) {
+
+		comRelease(c.renderTarget)
+		return nil, errorFromHRESULT("ID2D1HwndRenderTarget.QueryInterface", hr)
+	}
+
+	return c, nil
+}
+
+// dispose releases the render target and the bitmaps created for it.
+func (c *d2dCompositor) dispose() {
+	for _, l := range c.cw.layers {
+		l.releaseD2DBitmap()
+	}
+
+	comRelease(c.interop)
+	comRelease(c.renderTarget)
+}
+
+// resize adapts the render target to the new size of the client area. The
+// retained content is lost, so the whole widget is repainted.
+func (c *d2dCompositor) resize(size Size) {
+	pixelSize := d2d1SizeU{uint32(size.Width), uint32(size.Height)}
+
+	comCall(c.renderTarget, iD2D1HwndRenderTargetResize, uintptr(unsafe.Pointer(&pixelSize)))
+
+	c.cw.Invalidate()
+}
+
+// paint presents updateBounds of the widget. If recreate is true, the render
+// target was lost, e.g. because the display driver was updated, and must be
+// created again.
+func (c *d2dCompositor) paint(updateBounds Rectangle) (recreate bool, err error) {
+	comCall(c.renderTarget, iD2D1RenderTargetBeginDraw)
+
+	var hdc HDC
+	if hr := comCall(c.interop, iD2D1GdiInteropRenderTargetGetDC, d2d1DCInitializeModeCopy, uintptr(unsafe.Pointer(&hdc))); FAILED(hr) {
+		comCall(c.renderTarget, iD2D1RenderTargetEndDraw, 0, 0)
+		return false, errorFromHRESULT("ID2D1GdiInteropRenderTarget.GetDC", hr)
+	}
+
+	err = c.paintGDI(hdc, updateBounds)
+
+	update := updateBounds.toRECT()
+	comCall(c.interop, iD2D1GdiInteropRenderTargetReleaseDC, uintptr(unsafe.Pointer(&update)))
+
+	if err == nil {
+		err = c.compositeLayers(updateBounds)
+	}
+
+	hr := comCall(c.renderTarget, iD2D1RenderTargetEndDraw, 0, 0)
+
+	if err != nil {
+		return false, err
+	}
+	if uint32(hr) == d2dErrRecreateTarget {
+		return true, newErr("Direct2D render target lost")
+	}
+	if FAILED(hr) {
+		return false, errorFromHRESULT("ID2D1RenderTarget.EndDraw", hr)
+	}
+
+	return false, nil
+}
+
+// paintGDI runs the PaintFunc of the widget on hdc, clipped to updateBounds,
+// as the render target keeps the content outside of it.
+func (c *d2dCompositor) paintGDI(hdc HDC, updateBounds Rectangle) error {
+	if c.cw.paint == nil {
+		return nil
+	}
+
+	procSaveDC.Call(uintptr(hdc))
+	defer procRestoreDC.Call(uintptr(hdc), ^uintptr(0))
+
+	procIntersectClipRect.Call(
+		uintptr(hdc),
+		uintptr(updateBounds.X),
+		uintptr(updateBounds.Y),
+		uintptr(updateBounds.X+updateBounds.Width),
+		uintptr(updateBounds.Y+updateBounds.Height))
+
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return err
+	}
+	defer canvas.Dispose()
+
+	return c.cw.paint(canvas, updateBounds)
+}
+
+// compositeLayers draws the visible layers, that intersect updateBounds.
+func (c *d2dCompositor) compositeLayers(updateBounds Rectangle) error {
+	// Without the clip, translucent layers would be blended again over their
+	// retained pixels outside updateBounds.
+	clip := d2d1RectFFromRectangle(updateBounds)
+	comCall(c.renderTarget, iD2D1RenderTargetPushAxisAlignedClip, uintptr(unsafe.Pointer(&clip)), d2d1AntialiasModeAliased)
+	defer comCall(c.renderTarget, iD2D1RenderTargetPopAxisAlignedClip)
+
+	for _, l := range c.cw.layers {
+		if !l.visible || l.opacity <= 0 || l.bounds.Width <= 0 || l.bounds.Height <= 0 || !rectanglesIntersect(l.bounds, updateBounds) {
+			continue
+		}
+
+		if err := l.ensureContent(); err != nil {
+			return err
+		}
+
+		if l.d2dBitmap == nil {
+			if err := c.upload(l); err != nil {
+				return err
+			}
+		}
+
+		dest := d2d1RectFFromRectangle(l.bounds)
+
+		// The opacity is a FLOAT, which the syscall passes in the
+		// corresponding XMM register on 64 bit Windows, too.
+		comCall(
+			c.renderTarget,
+			iD2D1RenderTargetDrawBitmap,
+			uintptr(l.d2dBitmap),
+			uintptr(unsafe.Pointer(&dest)),
+			uintptr(math.Float32bits(float32(l.opacity))),
+			d2d1InterpolationNearestNeighbor,
+			0)
+	}
+
+	return nil
+}
+
+// upload copies the content of l to a new bitmap in video memory.
+func (c *d2dCompositor) upload(l *BitmapLayer) error {
+	size := l.bitmap.Size()
+
+	pixels, err := l.bitmap.opaqueBGRAPixels()
+	if err != nil {
+		return err
+	}
+
+	props := d2d1BitmapProperties{
+		pixelFormat: d2d1PixelFormat{dxgiFormatB8G8R8A8UNorm, d2d1AlphaModePremultiplied},
+		dpiX:        96,
+		dpiY:        96,
+	}
+
+	args := append(
+		d2d1SizeUArgs(size),
+		uintptr(unsafe.Pointer(&pixels[0])),
+		uintptr(4*size.Width),
+		uintptr(unsafe.Pointer(&props)),
+		uintptr(unsafe.Pointer(&l.d2dBitmap)))
+
+	if hr := comCall(c.renderTarget, iD2D1RenderTargetCreateBitmap, args...); FAILED(hr) {
+		l.d2dBitmap = nil
+		return errorFromHRESULT("ID2D1RenderTarget.CreateBitmap", hr)
+	}
+
+	return nil
+}
+
+// releaseD2DBitmap releases the copy of the content of the *BitmapLayer in
+// video memory, if there is one.
+func (l *BitmapLayer) releaseD2DBitmap() {
+	if l.d2dBitmap != nil {
+		comRelease(l.d2dBitmap)
+		l.d2dBitmap = nil
+	}
+}
+
+// opaqueBGRAPixels returns the pixels of the *Bitmap top-down, with 4 bytes
+// per pixel in the order blue, green, red and an alpha of 255.
+func (bmp *Bitmap) opaqueBGRAPixels() ([]byte, error) {
+	var hdr BITMAPINFOHEADER
+	hdr.BiSize = uint32(unsafe.Sizeof(hdr))
+	hdr.BiBitCount = 32
+	hdr.BiCompression = BI_RGB
+	hdr.BiPlanes = 1
+	hdr.BiWidth = int32(bmp.size.Width)
+	hdr.BiHeight = -int32(bmp.size.Height)
+
+	pixels := make([]byte, 4*bmp.size.Width*bmp.size.Height)
+
+	err := withCompatibleDC(func(hdc HDC) error {
+		if ret, _, _ := procGetDIBits.Call(
+			uintptr(hdc),
+			uintptr(bmp.hBmp),
+			0,
+			uintptr(bmp.size.Height),
+			uintptr(unsafe.Pointer(&pixels[0])),
+			uintptr(unsafe.Pointer(&hdr)),
+			DIB_RGB_COLORS); ret == 0 {
+
+			return newAPIError("GetDIBits")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 3; i < len(pixels); i += 4 {
+		pixels[i] = 0xFF
+	}
+
+	return pixels, nil
+}
+
+// paintD2D presents updateBounds of the *CustomWidget with Direct2D. It
+// returns false, if the caller must paint with GDI instead.
+func (cw *CustomWidget) paintD2D(updateBounds Rectangle) bool {
+	if cw.d2dFailed {
+		return false
+	}
+
+	if cw.d2d == nil {
+		c, err := newD2DCompositor(cw)
+		if err != nil {
+			cw.d2dFailed = true
+			return false
+		}
+
+		cw.d2d = c
+	}
+
+	recreate, err := cw.d2d.paint(updateBounds)
+	if err != nil {
+		cw.disposeD2D()
+
+		// A lost render target is created again on the next paint, other
+		// failures make the widget stay with GDI.
+		cw.d2dFailed = !recreate
+
+		return false
+	}
+
+	return true
+}
+
+func (cw *CustomWidget) disposeD2D() {
+	if cw.d2d != nil {
+		cw.d2d.dispose()
+		cw.d2d = nil
+	}
+}
@@ -68,7 +68,7 @@ type SystemColorBrush struct {
 func NewSystemColorBrush(colorIndex int) (*SystemColorBrush, error) {
 	hBrush := GetSysColorBrush(colorIndex)
 	if hBrush == 0 {
-		return nil, newError("GetSysColorBrush failed")
+		return nil, newAPIError("GetSysColorBrush")
 	}
 
 	return &SystemColorBrush{hBrush, colorIndex}, nil
@@ -103,7 +103,7 @@ func NewSolidColorBrush(color Color) (*SolidColorBrush, error) {
 
 	hBrush := CreateBrushIndirect(lb)
 	if hBrush == 0 {
-		return nil, newError("CreateBrushIndirect failed")
+		return nil, newAPIError("CreateBrushIndirect")
 	}
 
 	return &SolidColorBrush{hBrush: hBrush, color: color}, nil
@@ -140,7 +140,7 @@ func NewHatchBrush(color Color, style HatchStyle) (*HatchBrush, error) {
 
 	hBrush := CreateBrushIndirect(lb)
 	if hBrush == 0 {
-		return nil, newError("CreateBrushIndirect failed")
+		return nil, newAPIError("CreateBrushIndirect")
 	}
 
 	return &HatchBrush{hBrush: hBrush, color: color, style: style}, nil
@@ -184,7 +184,7 @@ func NewBitmapBrush(bitmap *Bitmap) (*BitmapBrush, error) {
 
 	hBrush := CreateBrushIndirect(lb)
 	if hBrush == 0 {
-		return nil, newError("CreateBrushIndirect failed")
+		return nil, newAPIError("CreateBrushIndirect")
 	}
 
 	return &BitmapBrush{hBrush: hBrush, bitmap: bitmap}, nil
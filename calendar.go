@@ -0,0 +1,339 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"time"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	mcsDayState    = 0x0001
+	mcsMultiSelect = 0x0002
+
+	mcmGetCurSel         = 0x1001
+	mcmSetCurSel         = 0x1002
+	mcmGetMaxSelCount    = 0x1003
+	mcmSetMaxSelCount    = 0x1004
+	mcmGetSelRange       = 0x1005
+	mcmSetSelRange       = 0x1006
+	mcmGetMonthRange     = 0x1007
+	mcmSetDayState       = 0x1008
+	mcmGetMinReqRect     = 0x1009
+	mcmSetFirstDayOfWeek = 0x100F
+	mcmGetFirstDayOfWeek = 0x1010
+	mcmGetRange          = 0x1011
+	mcmSetRange          = 0x1012
+
+	mcnSelChange   = 0xFFFFFD13 // -749
+	mcnGetDayState = 0xFFFFFD15 // -747
+
+	gmrDayState = 1
+)
+
+type nmDayState struct {
+	nmhdr       NMHDR
+	stStart     SYSTEMTIME
+	cDayState   int32
+	prgDayState *uint32
+}
+
+// Calendar wraps the month calendar control, which shows one or more months
+// to select a date or, if created by NewCalendarWithRangeSelection, a range
+// of dates.
+type Calendar struct {
+	WidgetBase
+	boldDaysFunc              func(date time.Time) bool
+	selectionChangedPublisher EventPublisher
+}
+
+func newCalendar(parent Container, style uint32) (*Calendar, error) {
+	c := new(Calendar)
+
+	if err := InitChildWidget(
+		c,
+		parent,
+		"SysMonthCal32",
+		WS_TABSTOP|WS_VISIBLE|mcsDayState|style,
+		0); err != nil {
+		return nil, err
+	}
+
+	c.MustRegisterProperty("Date", NewProperty(
+		func() interface{} {
+			return c.Date()
+		},
+		func(v interface{}) error {
+			return c.SetDate(v.(time.Time))
+		},
+		c.selectionChangedPublisher.Event()))
+
+	return c, nil
+}
+
+// NewCalendar returns a new *Calendar, that selects a single date.
+func NewCalendar(parent Container) (*Calendar, error) {
+	return newCalendar(parent, 0)
+}
+
+// NewCalendarWithRangeSelection returns a new *Calendar, that selects a range
+// of dates.
+func NewCalendarWithRangeSelection(parent Container) (*Calendar, error) {
+	return newCalendar(parent, mcsMultiSelect)
+}
+
+func (*Calendar) LayoutFlags() LayoutFlags {
+	return 0
+}
+
+func (c *Calendar) MinSizeHint() Size {
+	var r RECT
+	if 0 == c.SendMessage(mcmGetMinReqRect, 0, uintptr(unsafe.Pointer(&r))) {
+		return c.dialogBaseUnitsToPixels(Size{120, 100})
+	}
+
+	return Size{int(r.Right - r.Left), int(r.Bottom - r.Top)}
+}
+
+func (c *Calendar) SizeHint() Size {
+	return c.MinSizeHint()
+}
+
+// RangeSelection returns if the *Calendar selects a range of dates.
+func (c *Calendar) RangeSelection() bool {
+	return c.hasStyleBits(mcsMultiSelect)
+}
+
+func calendarSystemTimeToTime(st *SYSTEMTIME) time.Time {
+	return time.Date(int(st.WYear), time.Month(st.WMonth), int(st.WDay), 0, 0, 0, 0, time.Local)
+}
+
+func calendarTimeToSystemTime(t time.Time) SYSTEMTIME {
+	return SYSTEMTIME{
+		WYear:      uint16(t.Year()),
+		WMonth:     uint16(t.Month()),
+		WDay:       uint16(t.Day()),
+		WDayOfWeek: uint16(t.Weekday()),
+	}
+}
+
+// Date returns the selected date or, with range selection, the start of the
+// selected range.
+func (c *Calendar) Date() time.Time {
+	start, _ := c.Selection()
+
+	return start
+}
+
+// SetDate selects date.
+func (c *Calendar) SetDate(date time.Time) error {
+	return c.SetSelection(date, date)
+}
+
+// Selection returns the selected range. Without range selection, start and
+// end are the selected date.
+func (c *Calendar) Selection() (start, end time.Time) {
+	if !c.RangeSelection() {
+		var st SYSTEMTIME
+		if 0 == c.SendMessage(mcmGetCurSel, 0, uintptr(unsafe.Pointer(&st))) {
+			return
+		}
+
+		start = calendarSystemTimeToTime(&st)
+		return start, start
+	}
+
+	var st [2]SYSTEMTIME
+	if 0 == c.SendMessage(mcmGetSelRange, 0, uintptr(unsafe.Pointer(&st[0]))) {
+		return
+	}
+
+	return calendarSystemTimeToTime(&st[0]), calendarSystemTimeToTime(&st[1])
+}
+
+// SetSelection selects the range from start to end. Without range selection,
+// start and end must be the same date.
+func (c *Calendar) SetSelection(start, end time.Time) error {
+	if end.Before(start) {
+		return newError("start after end")
+	}
+
+	if !c.RangeSelection() {
+		if !start.Equal(end) {
+			return newError("range selection not enabled")
+		}
+
+		st := calendarTimeToSystemTime(start)
+		if 0 == c.SendMessage(mcmSetCurSel, 0, uintptr(unsafe.Pointer(&st))) {
+			return newAPIError("SendMessage(MCM_SETCURSEL)")
+		}
+	} else {
+		st := [2]SYSTEMTIME{calendarTimeToSystemTime(start), calendarTimeToSystemTime(end)}
+		if 0 == c.SendMessage(mcmSetSelRange, 0, uintptr(unsafe.Pointer(&st[0]))) {
+			return newAPIError("SendMessage(MCM_SETSELRANGE)")
+		}
+	}
+
+	c.selectionChangedPublisher.Publish()
+
+	return nil
+}
+
+// MaxSelectionCount returns the maximum number of days, that can be selected
+// with range selection.
+func (c *Calendar) MaxSelectionCount() int {
+	return int(c.SendMessage(mcmGetMaxSelCount, 0, 0))
+}
+
+// SetMaxSelectionCount sets the maximum number of days, that can be selected
+// with range selection. The default is 7.
+func (c *Calendar) SetMaxSelectionCount(count int) error {
+	if 0 == c.SendMessage(mcmSetMaxSelCount, uintptr(count), 0) {
+		return newAPIError("SendMessage(MCM_SETMAXSELCOUNT)")
+	}
+
+	return nil
+}
+
+// Range returns the dates that can be selected. Zero values mean there is no
+// limit.
+func (c *Calendar) Range() (min, max time.Time) {
+	var st [2]SYSTEMTIME
+
+	ret := c.SendMessage(mcmGetRange, 0, uintptr(unsafe.Pointer(&st[0])))
+
+	if ret&GDTR_MIN > 0 {
+		min = calendarSystemTimeToTime(&st[0])
+	}
+
+	if ret&GDTR_MAX > 0 {
+		max = calendarSystemTimeToTime(&st[1])
+	}
+
+	return
+}
+
+// SetRange sets the dates that can be selected. Zero values mean there is no
+// limit.
+func (c *Calendar) SetRange(min, max time.Time) error {
+	if !min.IsZero() && !max.IsZero() && max.Before(min) {
+		return newError("invalid range")
+	}
+
+	var st [2]SYSTEMTIME
+	var wParam uintptr
+
+	if !min.IsZero() {
+		wParam |= GDTR_MIN
+		st[0] = calendarTimeToSystemTime(min)
+	}
+
+	if !max.IsZero() {
+		wParam |= GDTR_MAX
+		st[1] = calendarTimeToSystemTime(max)
+	}
+
+	if 0 == c.SendMessage(mcmSetRange, wParam, uintptr(unsafe.Pointer(&st[0]))) {
+		return newAPIError("SendMessage(MCM_SETRANGE)")
+	}
+
+	return nil
+}
+
+// FirstDayOfWeek returns the day the weeks start with.
+func (c *Calendar) FirstDayOfWeek() time.Weekday {
+	// The control counts from Monday.
+	day := LOWORD(uint32(c.SendMessage(mcmGetFirstDayOfWeek, 0, 0)))
+
+	return time.Weekday((day + 1) % 7)
+}
+
+// SetFirstDayOfWeek sets the day the weeks start with. By default, it is
+// the one of the locale of the user.
+func (c *Calendar) SetFirstDayOfWeek(day time.Weekday) {
+	c.SendMessage(mcmSetFirstDayOfWeek, 0, uintptr((day+6)%7))
+
+	c.updateParentLayout()
+}
+
+// BoldDaysFunc returns the func, that decides which days are shown bold.
+func (c *Calendar) BoldDaysFunc() func(date time.Time) bool {
+	return c.boldDaysFunc
+}
+
+// SetBoldDaysFunc sets the func, that decides which days are shown bold, e.g.
+// days with appointments. It is called for the days of the visible months,
+// whenever they change.
+func (c *Calendar) SetBoldDaysFunc(f func(date time.Time) bool) {
+	c.boldDaysFunc = f
+
+	c.UpdateBoldDays()
+}
+
+// UpdateBoldDays asks the bold days func again for the visible months, e.g.
+// after appointments were added.
+func (c *Calendar) UpdateBoldDays() {
+	var st [2]SYSTEMTIME
+	count := int(c.SendMessage(mcmGetMonthRange, gmrDayState, uintptr(unsafe.Pointer(&st[0]))))
+	if count <= 0 {
+		return
+	}
+
+	states := c.dayStates(calendarSystemTimeToTime(&st[0]), count)
+
+	c.SendMessage(mcmSetDayState, uintptr(count), uintptr(unsafe.Pointer(&states[0])))
+}
+
+// dayStates returns the bold days of count months from the one of start, as
+// bit masks with bit n for day n+1.
+func (c *Calendar) dayStates(start time.Time, count int) []uint32 {
+	states := make([]uint32, count)
+
+	if c.boldDaysFunc == nil {
+		return states
+	}
+
+	month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.Local)
+
+	for i := range states {
+		for date := month; date.Month() == month.Month(); date = date.AddDate(0, 0, 1) {
+			if c.boldDaysFunc(date) {
+				states[i] |= 1 << uint(date.Day()-1)
+			}
+		}
+
+		month = month.AddDate(0, 1, 0)
+	}
+
+	return states
+}
+
+// SelectionChanged returns the event that is published, when the selected
+// date or range changed.
+func (c *Calendar) SelectionChanged() *Event {
+	return c.selectionChangedPublisher.Event()
+}
+
+func (c *Calendar) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_NOTIFY:
+		switch uint32(((*NMHDR)(unsafe.Pointer(lParam))).Code) {
+		case mcnSelChange:
+			c.selectionChangedPublisher.Publish()
+
+		case mcnGetDayState:
+			nmds := (*nmDayState)(unsafe.Pointer(lParam))
+
+			states := c.dayStates(calendarSystemTimeToTime(&nmds.stStart), int(nmds.cDayState))
+			copy((*[1 << 10]uint32)(unsafe.Pointer(nmds.prgDayState))[:len(states)], states)
+
+			return 1
+		}
+	}
+
+	return c.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
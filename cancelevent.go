@@ -4,21 +4,37 @@
 
 package walk
 
+import (
+	"sort"
+)
+
 type CancelEventHandler func(canceled *bool)
 
 type CancelEvent struct {
-	handlers []CancelEventHandler
+	handlers   []CancelEventHandler
+	priorities []int
 }
 
 func (e *CancelEvent) Attach(handler CancelEventHandler) int {
+	return e.AttachWithPriority(handler, 0)
+}
+
+// AttachWithPriority attaches handler, so that it is called before all
+// handlers with a lower priority.
+//
+// Handlers of equal priority are called in the same order as with Attach. As
+// soon as a handler cancels, the remaining handlers are not called.
+func (e *CancelEvent) AttachWithPriority(handler CancelEventHandler, priority int) int {
 	for i, h := range e.handlers {
 		if h == nil {
 			e.handlers[i] = handler
+			e.priorities[i] = priority
 			return i
 		}
 	}
 
 	e.handlers = append(e.handlers, handler)
+	e.priorities = append(e.priorities, priority)
 	return len(e.handlers) - 1
 }
 
@@ -35,9 +51,47 @@ func (p *CancelEventPublisher) Event() *CancelEvent {
 }
 
 func (p *CancelEventPublisher) Publish(canceled *bool) {
-	for _, handler := range p.event.handlers {
-		if handler != nil {
+	for _, i := range handlerIndicesByPriority(p.event.priorities) {
+		if handler := p.event.handlers[i]; handler != nil {
 			handler(canceled)
+
+			if *canceled {
+				return
+			}
 		}
 	}
 }
+
+type handlerIndicesSorter struct {
+	indices    []int
+	priorities []int
+}
+
+func (s *handlerIndicesSorter) Len() int {
+	return len(s.indices)
+}
+
+func (s *handlerIndicesSorter) Less(i, j int) bool {
+	return s.priorities[s.indices[i]] > s.priorities[s.indices[j]]
+}
+
+func (s *handlerIndicesSorter) Swap(i, j int) {
+	s.indices[i], s.indices[j] = s.indices[j], s.indices[i]
+}
+
+// handlerIndicesByPriority returns the indices of the handlers of an event in
+// the order they should be called.
+func handlerIndicesByPriority(priorities []int) []int {
+	sorter := &handlerIndicesSorter{
+		indices:    make([]int, len(priorities)),
+		priorities: priorities,
+	}
+
+	for i := range sorter.indices {
+		sorter.indices[i] = i
+	}
+
+	sort.Stable(sorter)
+
+	return sorter.indices
+}
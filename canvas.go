@@ -58,7 +58,7 @@ func NewCanvasFromImage(image Image) (*Canvas, error) {
 	case *Bitmap:
 		hdc := CreateCompatibleDC(0)
 		if hdc == 0 {
-			return nil, newError("CreateCompatibleDC failed")
+			return nil, newAPIError("CreateCompatibleDC")
 		}
 		succeeded := false
 
@@ -69,7 +69,7 @@ func NewCanvasFromImage(image Image) (*Canvas, error) {
 		}()
 
 		if SelectObject(hdc, HGDIOBJ(img.hBmp)) == 0 {
-			return nil, newError("SelectObject failed")
+			return nil, newAPIError("SelectObject")
 		}
 
 		succeeded = true
@@ -93,7 +93,7 @@ func NewCanvasFromImage(image Image) (*Canvas, error) {
 func newCanvasFromHWND(hwnd HWND) (*Canvas, error) {
 	hdc := GetDC(hwnd)
 	if hdc == 0 {
-		return nil, newError("GetDC failed")
+		return nil, newAPIError("GetDC")
 	}
 
 	return (&Canvas{hdc: hdc, hwnd: hwnd}).init()
@@ -112,16 +112,21 @@ func (c *Canvas) init() (*Canvas, error) {
 	c.dpiy = int(GetDeviceCaps(c.hdc, LOGPIXELSY))
 
 	if SetBkMode(c.hdc, TRANSPARENT) == 0 {
-		return nil, newError("SetBkMode failed")
+		return nil, newAPIError("SetBkMode")
 	}
 
 	switch SetStretchBltMode(c.hdc, HALFTONE) {
 	case 0, ERROR_INVALID_PARAMETER:
-		return nil, newError("SetStretchBltMode failed")
+		return nil, newAPIError("SetStretchBltMode")
 	}
 
 	if !SetBrushOrgEx(c.hdc, 0, 0, nil) {
-		return nil, newError("SetBrushOrgEx failed")
+		return nil, newAPIError("SetBrushOrgEx")
+	}
+
+	if appSingleton.colorManaged {
+		// Not all devices support color management, so we ignore failure.
+		c.SetColorManaged(true)
 	}
 
 	return c, nil
@@ -152,7 +157,7 @@ func (c *Canvas) Dispose() {
 func (c *Canvas) withGdiObj(handle HGDIOBJ, f func() error) error {
 	oldHandle := SelectObject(c.hdc, handle)
 	if oldHandle == 0 {
-		return newError("SelectObject failed")
+		return newAPIError("SelectObject")
 	}
 	defer SelectObject(c.hdc, oldHandle)
 
@@ -167,7 +172,7 @@ func (c *Canvas) withFontAndTextColor(font *Font, color Color, f func() error) e
 	return c.withGdiObj(HGDIOBJ(font.handleForDPI(c.dpiy)), func() error {
 		oldColor := SetTextColor(c.hdc, COLORREF(color))
 		if oldColor == CLR_INVALID {
-			return newError("SetTextColor failed")
+			return newAPIError("SetTextColor")
 		}
 		defer func() {
 			SetTextColor(c.hdc, oldColor)
@@ -203,7 +208,7 @@ func (c *Canvas) ellipse(brush Brush, pen Pen, bounds Rectangle, sizeCorrection
 			int32(bounds.X+bounds.Width+sizeCorrection),
 			int32(bounds.Y+bounds.Height+sizeCorrection)) {
 
-			return newError("Ellipse failed")
+			return newAPIError("Ellipse")
 		}
 
 		return nil
@@ -236,12 +241,12 @@ func (c *Canvas) DrawImageStretched(image Image, bounds Rectangle) error {
 
 func (c *Canvas) DrawLine(pen Pen, from, to Point) error {
 	if !MoveToEx(c.hdc, from.X, from.Y, nil) {
-		return newError("MoveToEx failed")
+		return newAPIError("MoveToEx")
 	}
 
 	return c.withPen(pen, func() error {
 		if !LineTo(c.hdc, int32(to.X), int32(to.Y)) {
-			return newError("LineTo failed")
+			return newAPIError("LineTo")
 		}
 
 		return nil
@@ -257,7 +262,7 @@ func (c *Canvas) rectangle(brush Brush, pen Pen, bounds Rectangle, sizeCorrectio
 			int32(bounds.X+bounds.Width+sizeCorrection),
 			int32(bounds.Y+bounds.Height+sizeCorrection)) {
 
-			return newError("Rectangle_ failed")
+			return newAPIError("Rectangle_")
 		}
 
 		return nil
@@ -283,7 +288,7 @@ func (c *Canvas) DrawText(text string, font *Font, color Color, bounds Rectangle
 			uint32(format)|DT_EDITCONTROL,
 			nil)
 		if ret == 0 {
-			return newError("DrawTextEx failed")
+			return newAPIError("DrawTextEx")
 		}
 
 		return nil
@@ -294,7 +299,7 @@ func (c *Canvas) fontHeight(font *Font) (height int, err error) {
 	err = c.withFontAndTextColor(font, 0, func() error {
 		var size SIZE
 		if !GetTextExtentPoint32(c.hdc, gM, 2, &size) {
-			return newError("GetTextExtentPoint32 failed")
+			return newAPIError("GetTextExtentPoint32")
 		}
 
 		height = int(size.CY)
@@ -323,7 +328,7 @@ func (c *Canvas) MeasureText(text string, font *Font, bounds Rectangle, format D
 	hFont := HGDIOBJ(font.handleForDPI(c.dpiy))
 	oldHandle := SelectObject(c.measureTextMetafile.hdc, hFont)
 	if oldHandle == 0 {
-		err = newError("SelectObject failed")
+		err = newAPIError("SelectObject")
 		return
 	}
 	defer SelectObject(c.measureTextMetafile.hdc, oldHandle)
@@ -343,7 +348,7 @@ func (c *Canvas) MeasureText(text string, font *Font, bounds Rectangle, format D
 	height := DrawTextEx(
 		c.measureTextMetafile.hdc, strPtr, -1, rect, dtfmt, &params)
 	if height == 0 {
-		err = newError("DrawTextEx failed")
+		err = newAPIError("DrawTextEx")
 		return
 	}
 
@@ -0,0 +1,1031 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"math"
+	"strconv"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const chartWindowClass = `\o/ Walk_Chart_Class \o/`
+
+const (
+	chartMargin      = 8
+	chartSpacing     = 4
+	chartTickLength  = 4
+	chartMarkerSize  = 6
+	chartSwatchSize  = 10
+	chartMaxTicks    = 8
+	chartMinTickGap  = 40
+	chartBarGapRatio = 0.2
+)
+
+const (
+	tmeLeave     = 0x00000002
+	wmMouseLeave = 0x02A3
+)
+
+var (
+	procTrackMouseEvent = libuser32.NewProc("TrackMouseEvent")
+	procPie             = libgdi32.NewProc("Pie")
+)
+
+type trackMouseEvent struct {
+	CbSize      uint32
+	DwFlags     uint32
+	HwndTrack   HWND
+	DwHoverTime uint32
+}
+
+func init() {
+	MustRegisterWindowClass(chartWindowClass)
+}
+
+// chartDefaultColors are used for series and pie slices, that have no color
+// of their own.
+var chartDefaultColors = []Color{
+	RGB(31, 119, 180),
+	RGB(255, 127, 14),
+	RGB(44, 160, 44),
+	RGB(214, 39, 40),
+	RGB(148, 103, 189),
+	RGB(140, 86, 75),
+	RGB(227, 119, 194),
+	RGB(127, 127, 127),
+}
+
+type ChartKind int
+
+const (
+	// ChartLine connects the points of a series by lines.
+	ChartLine ChartKind = iota
+
+	// ChartBar draws a bar for each point of a series. The points are
+	// shown as categories in the order of the series, their X is ignored.
+	ChartBar
+
+	// ChartPie draws a slice for each point of a series, sized by its Y.
+	ChartPie
+)
+
+// ChartPoint is a data point of a ChartSeries.
+type ChartPoint struct {
+	X, Y float64
+
+	// Label names the point on the category axis of bar charts, in the
+	// legend of pie charts and in tool tips.
+	Label string
+}
+
+// finite returns if X and Y are neither NaN nor infinite. Other points are
+// not drawn.
+func (p ChartPoint) finite() bool {
+	return !math.IsNaN(p.X) && !math.IsInf(p.X, 0) && !math.IsNaN(p.Y) && !math.IsInf(p.Y, 0)
+}
+
+// ChartSeries is a named sequence of points, that a *Chart shows.
+//
+// After changing the points of a series that is shown, call Update on the
+// *Chart.
+type ChartSeries struct {
+	Name   string
+	Kind   ChartKind
+	Points []ChartPoint
+
+	// Color is the color of lines and bars. The zero value, which is black,
+	// picks one of the default colors instead.
+	Color Color
+}
+
+// chartHit is an area of the chart, that shows a point.
+type chartHit struct {
+	series, point int
+	bounds        Rectangle
+
+	// Pie slices are hit by angle within the circle of bounds.
+	pie                  bool
+	startAngle, endAngle float64
+}
+
+// Chart draws line, bar and pie charts of a few series of points, with axes
+// and a legend. Hovering over a point shows its value as tool tip.
+//
+// A series of kind ChartPie takes the whole chart, only the first one is
+// shown. Line and bar series can be combined, then the points of line series
+// are placed on the bar categories.
+type Chart struct {
+	WidgetBase
+	series        []*ChartSeries
+	title         string
+	xAxisTitle    string
+	yAxisTitle    string
+	legendVisible bool
+	toolTipText   string
+	hits          []chartHit
+	hover         int // index into hits, -1 if none
+	tracking      bool
+}
+
+// NewChart returns a new, empty *Chart, that shows a legend.
+func NewChart(parent Container) (*Chart, error) {
+	c := &Chart{legendVisible: true, hover: -1}
+
+	if err := InitChildWidget(
+		c,
+		parent,
+		chartWindowClass,
+		WS_VISIBLE,
+		0); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (*Chart) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | ShrinkableVert | GrowableHorz | GrowableVert | GreedyHorz | GreedyVert
+}
+
+func (c *Chart) SizeHint() Size {
+	return Size{c.IntFromDIP(300), c.IntFromDIP(200)}
+}
+
+// Series returns the series shown by the *Chart.
+func (c *Chart) Series() []*ChartSeries {
+	return append([]*ChartSeries(nil), c.series...)
+}
+
+// SetSeries replaces the series shown by the *Chart.
+func (c *Chart) SetSeries(series ...*ChartSeries) {
+	c.series = append([]*ChartSeries(nil), series...)
+
+	c.Update()
+}
+
+// AddSeries adds series to the *Chart.
+func (c *Chart) AddSeries(series *ChartSeries) {
+	c.series = append(c.series, series)
+
+	c.Update()
+}
+
+// ClearSeries removes all series from the *Chart.
+func (c *Chart) ClearSeries() {
+	c.series = nil
+
+	c.Update()
+}
+
+// Update redraws the *Chart, after the points of its series changed.
+func (c *Chart) Update() {
+	c.hover = -1
+	c.hits = nil
+	c.SetToolTipText(c.toolTipText)
+
+	c.Invalidate()
+}
+
+// Title returns the text shown above the chart.
+func (c *Chart) Title() string {
+	return c.title
+}
+
+// SetTitle sets the text shown above the chart.
+func (c *Chart) SetTitle(value string) {
+	c.title = value
+
+	c.Invalidate()
+}
+
+// XAxisTitle returns the text shown below the x axis.
+func (c *Chart) XAxisTitle() string {
+	return c.xAxisTitle
+}
+
+// SetXAxisTitle sets the text shown below the x axis.
+func (c *Chart) SetXAxisTitle(value string) {
+	c.xAxisTitle = value
+
+	c.Invalidate()
+}
+
+// YAxisTitle returns the text shown above the y axis.
+func (c *Chart) YAxisTitle() string {
+	return c.yAxisTitle
+}
+
+// SetYAxisTitle sets the text shown above the y axis.
+func (c *Chart) SetYAxisTitle(value string) {
+	c.yAxisTitle = value
+
+	c.Invalidate()
+}
+
+// LegendVisible returns if the names of the series, or of the slices of a
+// pie, are shown next to the chart.
+func (c *Chart) LegendVisible() bool {
+	return c.legendVisible
+}
+
+// SetLegendVisible sets if the names of the series, or of the slices of a
+// pie, are shown next to the chart.
+func (c *Chart) SetLegendVisible(value bool) {
+	c.legendVisible = value
+
+	c.Invalidate()
+}
+
+// SetToolTipText sets the tool tip text, that is shown while the mouse is not
+// over a point.
+func (c *Chart) SetToolTipText(s string) error {
+	c.toolTipText = s
+
+	if c.hover > -1 {
+		return nil
+	}
+
+	return c.WidgetBase.SetToolTipText(s)
+}
+
+// ToBitmap draws the chart into a new *Bitmap of size, e.g. to save or print
+// it.
+func (c *Chart) ToBitmap(size Size) (*Bitmap, error) {
+	bmp, err := NewBitmap(size)
+	if err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			bmp.Dispose()
+		}
+	}()
+
+	canvas, err := NewCanvasFromImage(bmp)
+	if err != nil {
+		return nil, err
+	}
+	defer canvas.Dispose()
+
+	if _, err := c.paint(canvas, Rectangle{0, 0, size.Width, size.Height}, -1); err != nil {
+		return nil, err
+	}
+
+	succeeded = true
+
+	return bmp, nil
+}
+
+func (c *Chart) seriesColor(index int) Color {
+	if color := c.series[index].Color; color != 0 {
+		return color
+	}
+
+	return chartDefaultColors[index%len(chartDefaultColors)]
+}
+
+// pieSeries returns the index of the first pie series, or -1.
+func (c *Chart) pieSeries() int {
+	for i, s := range c.series {
+		if s.Kind == ChartPie {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// categorical returns if the x axis shows the points as categories, which is
+// the case with bars.
+func (c *Chart) categorical() bool {
+	for _, s := range c.series {
+		if s.Kind == ChartBar {
+			return true
+		}
+	}
+
+	return false
+}
+
+// paint draws the chart into bounds of canvas, with the point of hits at
+// hover highlighted, and returns the areas of the points.
+func (c *Chart) paint(canvas *Canvas, bounds Rectangle, hover int) ([]chartHit, error) {
+	palette := appSingleton.Palette()
+	font := c.Font()
+
+	background, err := NewSolidColorBrush(palette.Background)
+	if err != nil {
+		return nil, err
+	}
+	defer background.Dispose()
+
+	if err := canvas.FillRectangle(background, bounds); err != nil {
+		return nil, err
+	}
+
+	lineHeight, err := canvas.fontHeight(font)
+	if err != nil {
+		return nil, err
+	}
+
+	margin := c.IntFromDIP(chartMargin)
+	spacing := c.IntFromDIP(chartSpacing)
+
+	bounds = Rectangle{bounds.X + margin, bounds.Y + margin, bounds.Width - 2*margin, bounds.Height - 2*margin}
+
+	if c.title != "" {
+		titleFont, err := NewFont(font.Family(), font.PointSize(), font.Style()|FontBold)
+		if err != nil {
+			return nil, err
+		}
+		defer titleFont.Dispose()
+
+		if err := canvas.DrawText(c.title, titleFont, palette.Text, Rectangle{bounds.X, bounds.Y, bounds.Width, lineHeight}, TextCenter|TextSingleLine|TextEndEllipsis); err != nil {
+			return nil, err
+		}
+
+		bounds.Y += lineHeight + spacing
+		bounds.Height -= lineHeight + spacing
+	}
+
+	pie := c.pieSeries()
+
+	if c.legendVisible {
+		if bounds, err = c.paintLegend(canvas, bounds, pie, lineHeight); err != nil {
+			return nil, err
+		}
+	}
+
+	if bounds.Width <= 0 || bounds.Height <= 0 {
+		return nil, nil
+	}
+
+	if pie > -1 {
+		return c.paintPie(canvas, bounds, pie, hover)
+	}
+
+	return c.paintAxes(canvas, bounds, hover, lineHeight)
+}
+
+// paintLegend draws the legend at the right of bounds and returns the space
+// left for the chart.
+func (c *Chart) paintLegend(canvas *Canvas, bounds Rectangle, pie, lineHeight int) (Rectangle, error) {
+	type entry struct {
+		text  string
+		color Color
+	}
+
+	var entries []entry
+	if pie > -1 {
+		for i, p := range c.series[pie].Points {
+			entries = append(entries, entry{p.Label, chartDefaultColors[i%len(chartDefaultColors)]})
+		}
+	} else {
+		for i, s := range c.series {
+			if s.Name != "" {
+				entries = append(entries, entry{s.Name, c.seriesColor(i)})
+			}
+		}
+	}
+
+	if len(entries) == 0 {
+		return bounds, nil
+	}
+
+	font := c.Font()
+	spacing := c.IntFromDIP(chartSpacing)
+	swatch := c.IntFromDIP(chartSwatchSize)
+
+	var textWidth int
+	for _, e := range entries {
+		r, _, err := canvas.MeasureText(e.text, font, Rectangle{0, 0, bounds.Width, lineHeight}, TextSingleLine)
+		if err != nil {
+			return bounds, err
+		}
+
+		textWidth = maxi(textWidth, r.Width)
+	}
+
+	// The legend takes at most a third of the width.
+	width := mini(swatch+spacing+textWidth, bounds.Width/3)
+	x := bounds.X + bounds.Width - width
+	y := bounds.Y + (bounds.Height-len(entries)*(lineHeight+spacing))/2
+
+	for _, e := range entries {
+		brush, err := NewSolidColorBrush(e.color)
+		if err != nil {
+			return bounds, err
+		}
+
+		err = canvas.FillRectangle(brush, Rectangle{x, y + (lineHeight-swatch)/2, swatch, swatch})
+		brush.Dispose()
+		if err != nil {
+			return bounds, err
+		}
+
+		textBounds := Rectangle{x + swatch + spacing, y, width - swatch - spacing, lineHeight}
+		if err := canvas.DrawText(e.text, font, appSingleton.Palette().Text, textBounds, TextLeft|TextSingleLine|TextEndEllipsis); err != nil {
+			return bounds, err
+		}
+
+		y += lineHeight + spacing
+	}
+
+	bounds.Width -= width + c.IntFromDIP(chartMargin)
+
+	return bounds, nil
+}
+
+func (c *Chart) paintPie(canvas *Canvas, bounds Rectangle, series, hover int) ([]chartHit, error) {
+	points := c.series[series].Points
+
+	var total float64
+	for _, p := range points {
+		if p.finite() && p.Y > 0 {
+			total += p.Y
+		}
+	}
+
+	if total == 0 {
+		return nil, nil
+	}
+
+	d := mini(bounds.Width, bounds.Height)
+	circle := Rectangle{bounds.X + (bounds.Width-d)/2, bounds.Y + (bounds.Height-d)/2, d, d}
+
+	pen, err := NewCosmeticPen(PenSolid, appSingleton.Palette().Background)
+	if err != nil {
+		return nil, err
+	}
+	defer pen.Dispose()
+
+	var hits []chartHit
+
+	// The slices go clockwise from the top.
+	angle := math.Pi / 2
+	for i, p := range points {
+		if !p.finite() || p.Y <= 0 {
+			continue
+		}
+
+		sweep := p.Y / total * 2 * math.Pi
+		hit := chartHit{series: series, point: i, bounds: circle, pie: true, startAngle: angle - sweep, endAngle: angle}
+
+		r := circle
+		if len(hits) == hover {
+			// The hovered slice moves out a bit.
+			mid := angle - sweep/2
+			offset := float64(c.IntFromDIP(chartSpacing))
+			r.X += int(offset * math.Cos(mid))
+			r.Y -= int(offset * math.Sin(mid))
+		}
+
+		if err := c.drawPieSlice(canvas, pen, chartDefaultColors[i%len(chartDefaultColors)], r, hit.startAngle, hit.endAngle); err != nil {
+			return nil, err
+		}
+
+		hits = append(hits, hit)
+
+		angle -= sweep
+	}
+
+	return hits, nil
+}
+
+// drawPieSlice draws the slice of the circle r from angle start to end, in
+// radians counterclockwise from 3 o'clock.
+func (c *Chart) drawPieSlice(canvas *Canvas, pen Pen, color Color, r Rectangle, start, end float64) error {
+	brush, err := NewSolidColorBrush(color)
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	if end-start >= 2*math.Pi-1e-9 {
+		return canvas.FillEllipse(brush, r)
+	}
+
+	cx := float64(r.X) + float64(r.Width)/2
+	cy := float64(r.Y) + float64(r.Height)/2
+	radius := float64(r.Width)
+
+	return canvas.withBrushAndPen(brush, pen, func() error {
+		// Pie draws counterclockwise from the first radial to the second.
+		if ret, _, _ := procPie.Call(
+			uintptr(canvas.hdc),
+			uintptr(r.X),
+			uintptr(r.Y),
+			uintptr(r.X+r.Width),
+			uintptr(r.Y+r.Height),
+			uintptr(int(cx+radius*math.Cos(start))),
+			uintptr(int(cy-radius*math.Sin(start))),
+			uintptr(int(cx+radius*math.Cos(end))),
+			uintptr(int(cy-radius*math.Sin(end)))); ret == 0 {
+
+			return newAPIError("Pie")
+		}
+
+		return nil
+	})
+}
+
+// paintAxes draws the line and bar series with their axes into bounds.
+func (c *Chart) paintAxes(canvas *Canvas, bounds Rectangle, hover, lineHeight int) ([]chartHit, error) {
+	palette := appSingleton.Palette()
+	font := c.Font()
+	spacing := c.IntFromDIP(chartSpacing)
+	tickLength := c.IntFromDIP(chartTickLength)
+
+	var barSeries []int
+	var categories int
+	for i, s := range c.series {
+		if s.Kind == ChartBar {
+			barSeries = append(barSeries, i)
+		}
+		categories = maxi(categories, len(s.Points))
+	}
+	categorical := c.categorical()
+
+	xMin, xMax := math.Inf(1), math.Inf(-1)
+	yMin, yMax := math.Inf(1), math.Inf(-1)
+	for _, s := range c.series {
+		for _, p := range s.Points {
+			if !p.finite() {
+				continue
+			}
+
+			xMin, xMax = math.Min(xMin, p.X), math.Max(xMax, p.X)
+			yMin, yMax = math.Min(yMin, p.Y), math.Max(yMax, p.Y)
+		}
+	}
+
+	if categories == 0 || yMin > yMax {
+		// There are no finite points.
+		return nil, nil
+	}
+
+	if categorical {
+		// Bars grow from zero.
+		yMin, yMax = math.Min(yMin, 0), math.Max(yMax, 0)
+	}
+
+	if c.yAxisTitle != "" {
+		if err := canvas.DrawText(c.yAxisTitle, font, palette.Text, Rectangle{bounds.X, bounds.Y, bounds.Width, lineHeight}, TextLeft|TextSingleLine|TextEndEllipsis); err != nil {
+			return nil, err
+		}
+
+		bounds.Y += lineHeight + spacing
+		bounds.Height -= lineHeight + spacing
+	}
+
+	if c.xAxisTitle != "" {
+		bounds.Height -= lineHeight + spacing
+
+		if err := canvas.DrawText(c.xAxisTitle, font, palette.Text, Rectangle{bounds.X, bounds.Y + bounds.Height + spacing, bounds.Width, lineHeight}, TextCenter|TextSingleLine|TextEndEllipsis); err != nil {
+			return nil, err
+		}
+	}
+
+	// Room for the labels of the x axis.
+	bounds.Height -= lineHeight + tickLength + spacing
+
+	yTicks, yDecimals := chartTicks(yMin, yMax, mini(chartMaxTicks, maxi(2, bounds.Height/(2*lineHeight))))
+	if len(yTicks) == 0 {
+		return nil, nil
+	}
+
+	var labelWidth int
+	for _, v := range yTicks {
+		r, _, err := canvas.MeasureText(strconv.FormatFloat(v, 'f', yDecimals, 64), font, Rectangle{0, 0, bounds.Width, lineHeight}, TextSingleLine)
+		if err != nil {
+			return nil, err
+		}
+
+		labelWidth = maxi(labelWidth, r.Width)
+	}
+
+	plot := Rectangle{bounds.X + labelWidth + spacing + tickLength, bounds.Y, bounds.Width - labelWidth - spacing - tickLength, bounds.Height}
+	if plot.Width <= 0 || plot.Height <= 0 {
+		return nil, nil
+	}
+
+	yMin, yMax = yTicks[0], yTicks[len(yTicks)-1]
+	yToPixel := func(y float64) int {
+		return plot.Y + plot.Height - int(float64(plot.Height)*(y-yMin)/(yMax-yMin))
+	}
+
+	var xToPixel func(i int, x float64) int
+	slot := float64(plot.Width) / float64(categories)
+	if categorical {
+		xToPixel = func(i int, x float64) int {
+			return plot.X + int(slot*(float64(i)+0.5))
+		}
+	} else {
+		if xMin == xMax {
+			xMin, xMax = xMin-1, xMax+1
+		}
+
+		xToPixel = func(i int, x float64) int {
+			return plot.X + int(float64(plot.Width)*(x-xMin)/(xMax-xMin))
+		}
+	}
+
+	gridPen, err := NewCosmeticPen(PenDot, palette.Border)
+	if err != nil {
+		return nil, err
+	}
+	defer gridPen.Dispose()
+
+	axisPen, err := NewCosmeticPen(PenSolid, palette.DisabledText)
+	if err != nil {
+		return nil, err
+	}
+	defer axisPen.Dispose()
+
+	for _, v := range yTicks {
+		y := yToPixel(v)
+
+		if err := canvas.DrawLine(gridPen, Point{plot.X, y}, Point{plot.X + plot.Width, y}); err != nil {
+			return nil, err
+		}
+		if err := canvas.DrawLine(axisPen, Point{plot.X - tickLength, y}, Point{plot.X, y}); err != nil {
+			return nil, err
+		}
+
+		labelBounds := Rectangle{bounds.X, y - lineHeight/2, labelWidth, lineHeight}
+		if err := canvas.DrawText(strconv.FormatFloat(v, 'f', yDecimals, 64), font, palette.Text, labelBounds, TextRight|TextSingleLine); err != nil {
+			return nil, err
+		}
+	}
+
+	// The labels of the x axis are the categories or numeric ticks.
+	type xLabel struct {
+		x    int
+		text string
+	}
+	var xLabels []xLabel
+	if categorical {
+		// Skip categories, if their labels would overlap.
+		step := 1
+		if minGap := c.IntFromDIP(chartMinTickGap); slot < float64(minGap) {
+			step = int(math.Ceil(float64(minGap) / slot))
+		}
+
+		for i := 0; i < categories; i += step {
+			xLabels = append(xLabels, xLabel{xToPixel(i, 0), c.categoryLabel(i)})
+		}
+	} else {
+		xTicks, xDecimals := chartTicks(xMin, xMax, mini(chartMaxTicks, maxi(2, plot.Width/c.IntFromDIP(2*chartMinTickGap))))
+		for _, v := range xTicks {
+			if v < xMin || v > xMax {
+				continue
+			}
+
+			xLabels = append(xLabels, xLabel{xToPixel(0, v), strconv.FormatFloat(v, 'f', xDecimals, 64)})
+		}
+	}
+
+	axisY := yToPixel(math.Max(yMin, math.Min(0, yMax)))
+	bottom := plot.Y + plot.Height
+
+	for _, l := range xLabels {
+		if err := canvas.DrawLine(axisPen, Point{l.x, bottom}, Point{l.x, bottom + tickLength}); err != nil {
+			return nil, err
+		}
+
+		width := int(slot)
+		if !categorical {
+			width = c.IntFromDIP(2 * chartMinTickGap)
+		}
+
+		labelBounds := Rectangle{l.x - width/2, bottom + tickLength + spacing, width, lineHeight}
+		if err := canvas.DrawText(l.text, font, palette.Text, labelBounds, TextCenter|TextSingleLine|TextEndEllipsis); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := canvas.DrawLine(axisPen, Point{plot.X, plot.Y}, Point{plot.X, bottom}); err != nil {
+		return nil, err
+	}
+	if err := canvas.DrawLine(axisPen, Point{plot.X, axisY}, Point{plot.X + plot.Width, axisY}); err != nil {
+		return nil, err
+	}
+
+	var hits []chartHit
+
+	// Bars of the same category are drawn side by side.
+	if categorical {
+		barWidth := slot * (1 - chartBarGapRatio) / float64(len(barSeries))
+
+		for j, si := range barSeries {
+			brush, err := NewSolidColorBrush(c.seriesColor(si))
+			if err != nil {
+				return nil, err
+			}
+
+			for i, p := range c.series[si].Points {
+				if !p.finite() {
+					continue
+				}
+
+				x := plot.X + int(slot*(float64(i)+chartBarGapRatio/2)+barWidth*float64(j))
+				y := yToPixel(p.Y)
+				top, height := mini(y, axisY), absi(y-axisY)
+
+				r := Rectangle{x, top, maxi(1, int(barWidth)), maxi(1, height)}
+
+				err := canvas.FillRectangle(brush, r)
+				if err == nil && len(hits) == hover {
+					err = canvas.DrawRectangle(axisPen, r)
+				}
+				if err != nil {
+					brush.Dispose()
+					return nil, err
+				}
+
+				hits = append(hits, chartHit{series: si, point: i, bounds: r})
+			}
+
+			brush.Dispose()
+		}
+	}
+
+	markerSize := c.IntFromDIP(chartMarkerSize)
+
+	for si, s := range c.series {
+		if s.Kind != ChartLine || len(s.Points) == 0 {
+			continue
+		}
+
+		brush, err := NewSolidColorBrush(c.seriesColor(si))
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.paintLine(canvas, brush, s, xToPixel, yToPixel)
+		if err != nil {
+			brush.Dispose()
+			return nil, err
+		}
+
+		for i, p := range s.Points {
+			if !p.finite() {
+				continue
+			}
+
+			pt := Point{xToPixel(i, p.X), yToPixel(p.Y)}
+
+			size := markerSize
+			if len(hits) == hover {
+				size *= 2
+			}
+
+			r := Rectangle{pt.X - size/2, pt.Y - size/2, size, size}
+			if err := canvas.FillEllipse(brush, r); err != nil {
+				brush.Dispose()
+				return nil, err
+			}
+
+			// Points are easier to hit than to see.
+			hitSize := 2 * markerSize
+			hits = append(hits, chartHit{series: si, point: i, bounds: Rectangle{pt.X - hitSize/2, pt.Y - hitSize/2, hitSize, hitSize}})
+		}
+
+		brush.Dispose()
+	}
+
+	return hits, nil
+}
+
+func (c *Chart) paintLine(canvas *Canvas, brush Brush, s *ChartSeries, xToPixel func(int, float64) int, yToPixel func(float64) int) error {
+	pen, err := NewGeometricPen(PenSolid|PenCapRound, c.IntFromDIP(2), brush)
+	if err != nil {
+		return err
+	}
+	defer pen.Dispose()
+
+	// Non-finite points leave gaps in the line.
+	for i := 1; i < len(s.Points); i++ {
+		if !s.Points[i-1].finite() || !s.Points[i].finite() {
+			continue
+		}
+
+		from := Point{xToPixel(i-1, s.Points[i-1].X), yToPixel(s.Points[i-1].Y)}
+		to := Point{xToPixel(i, s.Points[i].X), yToPixel(s.Points[i].Y)}
+
+		if err := canvas.DrawLine(pen, from, to); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// categoryLabel returns the label of the first point at index, that has one,
+// or its number.
+func (c *Chart) categoryLabel(index int) string {
+	for _, s := range c.series {
+		if index < len(s.Points) && s.Points[index].Label != "" {
+			return s.Points[index].Label
+		}
+	}
+
+	return strconv.Itoa(index + 1)
+}
+
+// chartTicks returns evenly spaced, round values covering min to max, at most
+// maxCount of them, and the decimals needed to format them. It returns no
+// ticks, if they can't be computed, e.g. for infinite values.
+func chartTicks(min, max float64, maxCount int) (ticks []float64, decimals int) {
+	if min == max {
+		min, max = min-1, max+1
+	}
+
+	step := chartNiceNumber((max - min) / float64(maxCount-1))
+
+	first := math.Floor(min/step) * step
+	last := math.Ceil(max/step) * step
+
+	if math.IsNaN(first) || math.IsInf(first, 0) || math.IsInf(last, 0) || first+step == first {
+		return nil, 0
+	}
+
+	for v := first; v <= last+step/2; v += step {
+		ticks = append(ticks, v)
+	}
+
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step)))
+	}
+
+	return
+}
+
+// chartNiceNumber returns the smallest of 1, 2 or 5 times a power of ten,
+// that is at least value.
+func chartNiceNumber(value float64) float64 {
+	exp := math.Floor(math.Log10(value))
+	fraction := value / math.Pow(10, exp)
+
+	var nice float64
+	switch {
+	case fraction <= 1:
+		nice = 1
+
+	case fraction <= 2:
+		nice = 2
+
+	case fraction <= 5:
+		nice = 5
+
+	default:
+		nice = 10
+	}
+
+	return nice * math.Pow(10, exp)
+}
+
+// hitAt returns the index into hits of the point at x, y, or -1.
+func (c *Chart) hitAt(x, y int) int {
+	// Later hits are drawn on top.
+	for i := len(c.hits) - 1; i >= 0; i-- {
+		h := c.hits[i]
+		r := h.bounds
+
+		if !h.pie {
+			if x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height {
+				return i
+			}
+
+			continue
+		}
+
+		dx := float64(x) - (float64(r.X) + float64(r.Width)/2)
+		dy := (float64(r.Y) + float64(r.Height)/2) - float64(y)
+		if math.Hypot(dx, dy) > float64(r.Width)/2 {
+			continue
+		}
+
+		// Bring the angle into the range of the slice.
+		angle := math.Atan2(dy, dx)
+		for angle > h.endAngle {
+			angle -= 2 * math.Pi
+		}
+		for angle < h.startAngle {
+			angle += 2 * math.Pi
+		}
+
+		if angle <= h.endAngle {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (c *Chart) hitText(hit chartHit) string {
+	s := c.series[hit.series]
+	p := s.Points[hit.point]
+
+	value := strconv.FormatFloat(p.Y, 'g', -1, 64)
+
+	var name string
+	switch {
+	case s.Kind == ChartPie:
+		name = p.Label
+
+	case s.Kind == ChartLine && p.Label == "" && !c.categorical():
+		name = s.Name
+		value = strconv.FormatFloat(p.X, 'g', -1, 64) + ", " + value
+
+	default:
+		name = s.Name
+		if p.Label != "" {
+			if name != "" {
+				name += " – "
+			}
+			name += p.Label
+		}
+	}
+
+	if name == "" {
+		return value
+	}
+
+	return name + ": " + value
+}
+
+func (c *Chart) setHover(hover int) {
+	if hover == c.hover {
+		return
+	}
+
+	c.hover = hover
+
+	if hover > -1 {
+		c.WidgetBase.SetToolTipText(c.hitText(c.hits[hover]))
+	} else {
+		c.WidgetBase.SetToolTipText(c.toolTipText)
+	}
+
+	c.Invalidate()
+}
+
+func (c *Chart) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_PAINT:
+		var ps PAINTSTRUCT
+
+		hdc := BeginPaint(hwnd, &ps)
+		if hdc == 0 {
+			newAPIError("BeginPaint")
+			break
+		}
+		defer EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			break
+		}
+		defer canvas.Dispose()
+
+		hits, err := c.paint(canvas, c.ClientBounds(), c.hover)
+		if err == nil {
+			c.hits = hits
+		}
+
+		return 0
+
+	case WM_ERASEBKGND:
+		return 1
+
+	case WM_SIZE, WM_SIZING:
+		c.hover = -1
+		c.Invalidate()
+
+	case WM_MOUSEMOVE:
+		if !c.tracking {
+			tme := trackMouseEvent{DwFlags: tmeLeave, HwndTrack: hwnd}
+			tme.CbSize = uint32(unsafe.Sizeof(tme))
+
+			if ret, _, _ := procTrackMouseEvent.Call(uintptr(unsafe.Pointer(&tme))); ret != 0 {
+				c.tracking = true
+			}
+		}
+
+		c.setHover(c.hitAt(int(GET_X_LPARAM(lParam)), int(GET_Y_LPARAM(lParam))))
+
+	case wmMouseLeave:
+		c.tracking = false
+
+		c.setHover(-1)
+	}
+
+	return c.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
@@ -0,0 +1,495 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	cbsOwnerDrawFixed = 0x0010
+	cbsHasStrings     = 0x0200
+
+	cbSetItemHeight    = 0x0153
+	cbGetComboBoxInfo  = 0x0164
+	lbItemFromPoint    = 0x01A9
+	odsComboBoxEdit    = 0x1000
+	dfcButton          = 4
+	dfcsButtonCheck    = 0x0000
+	dfcsChecked        = 0x0400
+	dfcsInactive       = 0x0100
+	dfcsFlat           = 0x4000
+	checkableItemSpace = 4
+)
+
+// comboBoxInfo is COMBOBOXINFO.
+type comboBoxInfo struct {
+	cbSize      uint32
+	rcItem      RECT
+	rcButton    RECT
+	stateButton uint32
+	hwndCombo   HWND
+	hwndItem    HWND
+	hwndList    HWND
+}
+
+var (
+	procDrawFrameControl = libuser32.NewProc("DrawFrameControl")
+
+	checkableComboBoxListWndProcPtr = syscall.NewCallback(checkableComboBoxListWndProc)
+	checkableComboBoxesByListHWnd   = make(map[HWND]*CheckableComboBox)
+)
+
+// CheckableComboBox is a drop-down list, whose items can be checked
+// independently, e.g. to pick the values of a filter.
+//
+// When closed, it shows the texts of the checked items, or their number, if
+// the texts don't fit.
+type CheckableComboBox struct {
+	WidgetBase
+	model                          ListModel
+	providedModel                  interface{}
+	displayMember                  string
+	checked                        []bool
+	hwndList                       HWND
+	origListWndProcPtr             uintptr
+	itemsResetHandlerHandle        int
+	itemChangedHandlerHandle       int
+	checkedIndexesChangedPublisher EventPublisher
+}
+
+func NewCheckableComboBox(parent Container) (*CheckableComboBox, error) {
+	ccb := &CheckableComboBox{}
+
+	if err := InitChildWidget(
+		ccb,
+		parent,
+		"COMBOBOX",
+		WS_TABSTOP|WS_VISIBLE|WS_VSCROLL|CBS_DROPDOWNLIST|cbsOwnerDrawFixed|cbsHasStrings,
+		0); err != nil {
+		return nil, err
+	}
+
+	height := ccb.dialogBaseUnitsToPixels(Size{0, 10}).Height
+	ccb.SendMessage(cbSetItemHeight, ^uintptr(0), uintptr(height))
+	ccb.SendMessage(cbSetItemHeight, 0, uintptr(height))
+
+	cbi := comboBoxInfo{cbSize: uint32(unsafe.Sizeof(comboBoxInfo{}))}
+	if ccb.SendMessage(cbGetComboBoxInfo, 0, uintptr(unsafe.Pointer(&cbi))) != 0 && cbi.hwndList != 0 {
+		// Clicks in the list toggle items, instead of closing it.
+		ccb.hwndList = cbi.hwndList
+		ccb.origListWndProcPtr = SetWindowLongPtr(ccb.hwndList, GWLP_WNDPROC, checkableComboBoxListWndProcPtr)
+		checkableComboBoxesByListHWnd[ccb.hwndList] = ccb
+	}
+
+	ccb.MustRegisterProperty("CheckedIndexes", NewProperty(
+		func() interface{} {
+			return ccb.CheckedIndexes()
+		},
+		func(v interface{}) error {
+			indexes, _ := v.([]int)
+			return ccb.SetCheckedIndexes(indexes)
+		},
+		ccb.CheckedIndexesChanged()))
+
+	ccb.MustRegisterProperty("CheckedTexts", NewProperty(
+		func() interface{} {
+			return ccb.CheckedTexts()
+		},
+		func(v interface{}) error {
+			texts, _ := v.([]string)
+			return ccb.SetCheckedTexts(texts)
+		},
+		ccb.CheckedIndexesChanged()))
+
+	return ccb, nil
+}
+
+func (*CheckableComboBox) LayoutFlags() LayoutFlags {
+	return GrowableHorz
+}
+
+func (ccb *CheckableComboBox) MinSizeHint() Size {
+	size := ccb.dialogBaseUnitsToPixels(Size{50, 12})
+	size.Height++
+
+	return size
+}
+
+func (ccb *CheckableComboBox) SizeHint() Size {
+	return ccb.MinSizeHint()
+}
+
+// Model returns the model of the *CheckableComboBox.
+func (ccb *CheckableComboBox) Model() interface{} {
+	return ccb.providedModel
+}
+
+// SetModel sets the model of the *CheckableComboBox.
+//
+// Like for ComboBox, mdl either implements walk.ListModel or
+// walk.ReflectListModel or is a slice of pointers to struct. All items are
+// unchecked afterwards.
+func (ccb *CheckableComboBox) SetModel(mdl interface{}) error {
+	model, ok := mdl.(ListModel)
+	if !ok && mdl != nil {
+		var err error
+		if model, err = newReflectListModel(mdl); err != nil {
+			return err
+		}
+
+		if badms, ok := model.(bindingAndDisplayMemberSetter); ok {
+			badms.setDisplayMember(ccb.displayMember)
+		}
+	}
+	ccb.providedModel = mdl
+
+	if ccb.model != nil {
+		ccb.model.ItemsReset().Detach(ccb.itemsResetHandlerHandle)
+		ccb.model.ItemChanged().Detach(ccb.itemChangedHandlerHandle)
+	}
+
+	ccb.model = model
+
+	if model != nil {
+		ccb.itemsResetHandlerHandle = model.ItemsReset().Attach(func() {
+			ccb.resetItems()
+		})
+		ccb.itemChangedHandlerHandle = model.ItemChanged().Attach(func(index int) {
+			ccb.SendMessage(CB_DELETESTRING, uintptr(index), 0)
+			ccb.insertItemAt(index)
+			ccb.Invalidate()
+		})
+	}
+
+	return ccb.resetItems()
+}
+
+// DisplayMember returns the member from the model of the *CheckableComboBox
+// that is displayed.
+//
+// This is only applicable to walk.ReflectListModel models and simple slices of
+// pointers to struct.
+func (ccb *CheckableComboBox) DisplayMember() string {
+	return ccb.displayMember
+}
+
+// SetDisplayMember sets the member from the model of the *CheckableComboBox
+// that is displayed, like ComboBox.SetDisplayMember.
+func (ccb *CheckableComboBox) SetDisplayMember(displayMember string) {
+	ccb.displayMember = displayMember
+
+	if badms, ok := ccb.model.(bindingAndDisplayMemberSetter); ok {
+		badms.setDisplayMember(displayMember)
+	}
+}
+
+func (ccb *CheckableComboBox) itemString(index int) string {
+	switch val := ccb.model.Value(index).(type) {
+	case string:
+		return val
+
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func (ccb *CheckableComboBox) insertItemAt(index int) error {
+	lp := uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(ccb.itemString(index))))
+
+	if CB_ERR == ccb.SendMessage(CB_INSERTSTRING, uintptr(index), lp) {
+		return newAPIError("SendMessage(CB_INSERTSTRING)")
+	}
+
+	return nil
+}
+
+func (ccb *CheckableComboBox) resetItems() error {
+	ccb.SetSuspended(true)
+	defer ccb.SetSuspended(false)
+
+	ccb.SendMessage(CB_RESETCONTENT, 0, 0)
+
+	hadChecked := len(ccb.CheckedIndexes()) > 0
+	ccb.checked = nil
+
+	if ccb.model != nil {
+		count := ccb.model.ItemCount()
+		ccb.checked = make([]bool, count)
+
+		for i := 0; i < count; i++ {
+			if err := ccb.insertItemAt(i); err != nil {
+				return err
+			}
+		}
+	}
+
+	if hadChecked {
+		ccb.checkedIndexesChangedPublisher.Publish()
+	}
+
+	return nil
+}
+
+// Checked returns if the item at index is checked.
+func (ccb *CheckableComboBox) Checked(index int) bool {
+	return index >= 0 && index < len(ccb.checked) && ccb.checked[index]
+}
+
+// SetChecked checks or unchecks the item at index.
+func (ccb *CheckableComboBox) SetChecked(index int, checked bool) error {
+	if index < 0 || index >= len(ccb.checked) {
+		return newError("invalid index")
+	}
+
+	if ccb.checked[index] == checked {
+		return nil
+	}
+
+	ccb.checked[index] = checked
+
+	ccb.updateChecked()
+
+	return nil
+}
+
+// CheckedIndexes returns the indexes of the checked items in ascending order.
+func (ccb *CheckableComboBox) CheckedIndexes() []int {
+	var indexes []int
+
+	for i, checked := range ccb.checked {
+		if checked {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes
+}
+
+// SetCheckedIndexes checks the items at indexes and unchecks all others.
+func (ccb *CheckableComboBox) SetCheckedIndexes(indexes []int) error {
+	checked := make([]bool, len(ccb.checked))
+
+	for _, index := range indexes {
+		if index < 0 || index >= len(checked) {
+			return newError("invalid index")
+		}
+
+		checked[index] = true
+	}
+
+	return ccb.setChecked(checked)
+}
+
+// CheckedTexts returns the texts of the checked items.
+func (ccb *CheckableComboBox) CheckedTexts() []string {
+	var texts []string
+
+	for _, index := range ccb.CheckedIndexes() {
+		texts = append(texts, ccb.itemString(index))
+	}
+
+	return texts
+}
+
+// SetCheckedTexts checks the items with one of texts and unchecks all others.
+func (ccb *CheckableComboBox) SetCheckedTexts(texts []string) error {
+	set := make(map[string]bool, len(texts))
+	for _, text := range texts {
+		set[text] = true
+	}
+
+	checked := make([]bool, len(ccb.checked))
+	for i := range checked {
+		checked[i] = set[ccb.itemString(i)]
+	}
+
+	return ccb.setChecked(checked)
+}
+
+func (ccb *CheckableComboBox) setChecked(checked []bool) error {
+	changed := false
+	for i := range checked {
+		if checked[i] != ccb.checked[i] {
+			changed = true
+			break
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	ccb.checked = checked
+
+	ccb.updateChecked()
+
+	return nil
+}
+
+func (ccb *CheckableComboBox) toggle(index int) {
+	if index >= 0 && index < len(ccb.checked) {
+		ccb.SetChecked(index, !ccb.checked[index])
+	}
+}
+
+func (ccb *CheckableComboBox) updateChecked() {
+	if ccb.hwndList != 0 {
+		InvalidateRect(ccb.hwndList, nil, false)
+	}
+	ccb.Invalidate()
+
+	ccb.checkedIndexesChangedPublisher.Publish()
+}
+
+// CheckedIndexesChanged returns the *Event that is published, when items were
+// checked or unchecked.
+func (ccb *CheckableComboBox) CheckedIndexesChanged() *Event {
+	return ccb.checkedIndexesChangedPublisher.Event()
+}
+
+// summaryText returns the text shown, while the *CheckableComboBox is closed.
+func (ccb *CheckableComboBox) summaryText(canvas *Canvas, font *Font, bounds Rectangle) string {
+	texts := ccb.CheckedTexts()
+	if len(texts) < 2 {
+		return strings.Join(texts, "")
+	}
+
+	joined := strings.Join(texts, ", ")
+
+	measured, _, err := canvas.MeasureText(joined, font, Rectangle{0, 0, 1 << 16, bounds.Height}, TextSingleLine)
+	if err == nil && measured.Width <= bounds.Width {
+		return joined
+	}
+
+	return fmt.Sprintf(tr("%d selected", "walk"), len(texts))
+}
+
+func (ccb *CheckableComboBox) drawItem(dis *DRAWITEMSTRUCT) {
+	canvas, err := newCanvasFromHDC(dis.HDC)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	palette := appSingleton.Palette()
+
+	background, color := palette.ControlBackground, palette.ControlText
+	if dis.ItemState&ODS_SELECTED != 0 {
+		background, color = palette.Highlight, palette.HighlightText
+	}
+	if dis.ItemState&ODS_DISABLED != 0 {
+		background, color = palette.ControlBackground, palette.DisabledText
+	}
+
+	brush, err := NewSolidColorBrush(background)
+	if err != nil {
+		return
+	}
+	defer brush.Dispose()
+
+	bounds := rectangleFromRECT(dis.RcItem)
+	canvas.FillRectangle(brush, bounds)
+
+	font := ccb.Font()
+	format := TextSingleLine | TextVCenter | TextEndEllipsis | TextNoPrefix
+
+	bounds.X += checkableItemSpace
+	bounds.Width -= 2 * checkableItemSpace
+
+	if dis.ItemState&odsComboBoxEdit != 0 {
+		canvas.DrawText(ccb.summaryText(canvas, font, bounds), font, color, bounds, format)
+		return
+	}
+
+	index := int(int32(dis.ItemID))
+	if index < 0 {
+		return
+	}
+
+	boxSize := dis.RcItem.Bottom - dis.RcItem.Top - 2
+	box := RECT{
+		int32(bounds.X),
+		dis.RcItem.Top + 1,
+		int32(bounds.X) + boxSize,
+		dis.RcItem.Top + 1 + boxSize,
+	}
+
+	state := uintptr(dfcsButtonCheck | dfcsFlat)
+	if ccb.Checked(index) {
+		state |= dfcsChecked
+	}
+	if dis.ItemState&ODS_DISABLED != 0 {
+		state |= dfcsInactive
+	}
+
+	procDrawFrameControl.Call(uintptr(dis.HDC), uintptr(unsafe.Pointer(&box)), dfcButton, state)
+
+	bounds.X += int(boxSize) + checkableItemSpace
+	bounds.Width -= int(boxSize) + checkableItemSpace
+
+	canvas.DrawText(ccb.itemString(index), font, color, bounds, format)
+}
+
+func (ccb *CheckableComboBox) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_DRAWITEM:
+		ccb.drawItem((*DRAWITEMSTRUCT)(unsafe.Pointer(lParam)))
+		return 1
+
+	case WM_CHAR:
+		if wParam == ' ' && ccb.SendMessage(CB_GETDROPPEDSTATE, 0, 0) != 0 {
+			ccb.toggle(int(int32(ccb.SendMessage(CB_GETCURSEL, 0, 0))))
+			return 0
+		}
+	}
+
+	return ccb.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+// checkableComboBoxListWndProc handles clicks in the drop-down list of a
+// *CheckableComboBox, so they toggle items and the list stays open.
+func checkableComboBoxListWndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	ccb := checkableComboBoxesByListHWnd[hwnd]
+	if ccb == nil {
+		return DefWindowProc(hwnd, msg, wParam, lParam)
+	}
+
+	switch msg {
+	case WM_LBUTTONDOWN, WM_LBUTTONDBLCLK, WM_LBUTTONUP:
+		var cr RECT
+		GetClientRect(hwnd, &cr)
+
+		x := int32(int16(LOWORD(uint32(lParam))))
+		y := int32(int16(HIWORD(uint32(lParam))))
+
+		if x < cr.Left || x >= cr.Right || y < cr.Top || y >= cr.Bottom {
+			// Clicks outside close the list as usual.
+			break
+		}
+
+		if msg == WM_LBUTTONUP {
+			ret := CallWindowProc(ccb.origListWndProcPtr, hwnd, lbItemFromPoint, 0, lParam)
+			if HIWORD(uint32(ret)) == 0 {
+				ccb.toggle(int(LOWORD(uint32(ret))))
+			}
+		}
+
+		return 0
+
+	case WM_NCDESTROY:
+		origWndProcPtr := ccb.origListWndProcPtr
+		delete(checkableComboBoxesByListHWnd, hwnd)
+
+		return CallWindowProc(origWndProcPtr, hwnd, msg, wParam, lParam)
+	}
+
+	return CallWindowProc(ccb.origListWndProcPtr, hwnd, msg, wParam, lParam)
+}
@@ -0,0 +1,50 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+const cfUnicodeText = 13
+
+var (
+	procOpenClipboard              = libuser32.NewProc("OpenClipboard")
+	procCloseClipboard             = libuser32.NewProc("CloseClipboard")
+	procGetClipboardData           = libuser32.NewProc("GetClipboardData")
+	procIsClipboardFormatAvailable = libuser32.NewProc("IsClipboardFormatAvailable")
+	procGlobalLock                 = libkernel32.NewProc("GlobalLock")
+	procGlobalUnlock               = libkernel32.NewProc("GlobalUnlock")
+)
+
+// clipboardHasText returns if the clipboard contains text.
+func clipboardHasText() bool {
+	ret, _, _ := procIsClipboardFormatAvailable.Call(cfUnicodeText)
+
+	return ret != 0
+}
+
+// clipboardText returns the text on the clipboard, or an empty string if it
+// contains no text.
+func clipboardText() (string, error) {
+	if ret, _, err := procOpenClipboard.Call(0); ret == 0 {
+		return "", callError("OpenClipboard", err)
+	}
+	defer procCloseClipboard.Call()
+
+	hMem, _, _ := procGetClipboardData.Call(cfUnicodeText)
+	if hMem == 0 {
+		return "", nil
+	}
+
+	p, _, err := procGlobalLock.Call(hMem)
+	if p == 0 {
+		return "", callError("GlobalLock", err)
+	}
+	defer procGlobalUnlock.Call(hMem)
+
+	return syscall.UTF16ToString((*[1 << 29]uint16)(unsafe.Pointer(p))[:]), nil
+}
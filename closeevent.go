@@ -7,18 +7,30 @@ package walk
 type CloseEventHandler func(canceled *bool, reason CloseReason)
 
 type CloseEvent struct {
-	handlers []CloseEventHandler
+	handlers   []CloseEventHandler
+	priorities []int
 }
 
 func (e *CloseEvent) Attach(handler CloseEventHandler) int {
+	return e.AttachWithPriority(handler, 0)
+}
+
+// AttachWithPriority attaches handler, so that it is called before all
+// handlers with a lower priority.
+//
+// Handlers of equal priority are called in the same order as with Attach. As
+// soon as a handler cancels, the remaining handlers are not called.
+func (e *CloseEvent) AttachWithPriority(handler CloseEventHandler, priority int) int {
 	for i, h := range e.handlers {
 		if h == nil {
 			e.handlers[i] = handler
+			e.priorities[i] = priority
 			return i
 		}
 	}
 
 	e.handlers = append(e.handlers, handler)
+	e.priorities = append(e.priorities, priority)
 	return len(e.handlers) - 1
 }
 
@@ -35,9 +47,13 @@ func (p *CloseEventPublisher) Event() *CloseEvent {
 }
 
 func (p *CloseEventPublisher) Publish(canceled *bool, reason CloseReason) {
-	for _, handler := range p.event.handlers {
-		if handler != nil {
+	for _, i := range handlerIndicesByPriority(p.event.priorities) {
+		if handler := p.event.handlers[i]; handler != nil {
 			handler(canceled, reason)
+
+			if *canceled {
+				return
+			}
 		}
 	}
 }
@@ -0,0 +1,115 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"time"
+)
+
+import . "github.com/lxn/go-winapi"
+
+var (
+	timerProcPtr   = syscall.NewCallback(timerProc)
+	timerFuncsById = make(map[uintptr]func())
+)
+
+func timerProc(hwnd HWND, msg uint32, id uintptr, dwTime uint32) uintptr {
+	if f, ok := timerFuncsById[id]; ok {
+		f()
+	}
+
+	return 0
+}
+
+// startTimer calls f on the UI thread every interval, until stopTimer is
+// called with the returned id.
+func startTimer(interval time.Duration, f func()) uintptr {
+	ms := uint32(interval / time.Millisecond)
+	if ms == 0 {
+		ms = 1
+	}
+
+	id := SetTimer(0, 0, ms, timerProcPtr)
+	if id == 0 {
+		lastError("SetTimer")
+		return 0
+	}
+
+	timerFuncsById[id] = f
+
+	return id
+}
+
+func stopTimer(id uintptr) {
+	if id == 0 {
+		return
+	}
+
+	delete(timerFuncsById, id)
+
+	KillTimer(0, id)
+}
+
+// Debounce returns an *Event that is published once, after source has not been
+// published for the duration of delay.
+//
+// This is useful e.g. to start a search only after the user stopped typing.
+// source must be published on the UI thread. The returned *Event is published
+// on the UI thread as well.
+func Debounce(source *Event, delay time.Duration) *Event {
+	publisher := new(EventPublisher)
+
+	var timerId uintptr
+
+	source.Attach(func() {
+		stopTimer(timerId)
+
+		timerId = startTimer(delay, func() {
+			stopTimer(timerId)
+			timerId = 0
+
+			publisher.Publish()
+		})
+	})
+
+	return publisher.Event()
+}
+
+// Throttle returns an *Event that is published at most once per interval while
+// source is being published.
+//
+// The first publication of source is passed on immediately. Further
+// publications within interval are coalesced into a single one at the end of
+// the interval, so the last state is never missed. source must be published on
+// the UI thread. The returned *Event is published on the UI thread as well.
+func Throttle(source *Event, interval time.Duration) *Event {
+	publisher := new(EventPublisher)
+
+	var timerId uintptr
+	var pending bool
+
+	source.Attach(func() {
+		if timerId != 0 {
+			pending = true
+			return
+		}
+
+		publisher.Publish()
+
+		timerId = startTimer(interval, func() {
+			if pending {
+				pending = false
+				publisher.Publish()
+				return
+			}
+
+			stopTimer(timerId)
+			timerId = 0
+		})
+	})
+
+	return publisher.Event()
+}
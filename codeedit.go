@@ -0,0 +1,613 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	emGetLineCount     = 0x00BA
+	emExLineFromChar   = WM_USER + 54
+	emGetOleInterface  = WM_USER + 60
+	emSetTargetDevice  = WM_USER + 72
+	emGetTextLengthEx  = WM_USER + 95
+	emGetScrollPos     = WM_USER + 221
+	emSetScrollPos     = WM_USER + 222
+	enmScroll          = 0x00000004
+	enmSelChange       = 0x00080000
+	enSelChange        = 0x0702
+	enHScroll          = 0x0601
+	enVScroll          = 0x0602
+	cfeAutoColor       = cfmColor
+	cfeAutoBackColor   = cfmBackColor
+	gtlPrecise         = 0x0002
+	gtlNumChars        = 0x0008
+	cpUnicode          = 1200
+	tomSuspend         = -9999995
+	tomResume          = -9999994
+	iTextDocumentUndo  = 22
+	codeEditGutterPad  = 4
+	codeEditColorTimer = 1
+	codeEditColorDelay = 200
+	codeEditBrackets   = "()[]{}"
+)
+
+var iidITextDocument = IID{0x8CC497C0, 0xA1DF, 0x11CE, [8]byte{0x80, 0x98, 0x00, 0xAA, 0x00, 0x47, 0xBE, 0x5D}}
+
+type getTextLengthEx struct {
+	flags    uint32
+	codepage uint32
+}
+
+// codeEditLightColors and codeEditDarkColors are the default colors of the
+// token kinds.
+var (
+	codeEditLightColors = map[CodeTokenKind]Color{
+		CodeTokenKeyword:  RGB(0, 0, 192),
+		CodeTokenString:   RGB(163, 21, 21),
+		CodeTokenNumber:   RGB(9, 134, 88),
+		CodeTokenComment:  RGB(0, 128, 0),
+		CodeTokenOperator: RGB(96, 96, 96),
+	}
+	codeEditDarkColors = map[CodeTokenKind]Color{
+		CodeTokenKeyword:  RGB(86, 156, 214),
+		CodeTokenString:   RGB(206, 145, 120),
+		CodeTokenNumber:   RGB(181, 206, 168),
+		CodeTokenComment:  RGB(106, 153, 85),
+		CodeTokenOperator: RGB(180, 180, 180),
+	}
+)
+
+// CodeEdit is a multi-line edit for source code, configuration files and
+// scripts. It uses a monospaced font, shows line numbers and highlights the
+// line with the caret and the bracket matching the one at the caret.
+//
+// Syntax coloring is done by a CodeLexer, e.g. a *SimpleCodeLexer. The text is
+// colored again shortly after the user stopped typing.
+type CodeEdit struct {
+	RichTextEdit
+	lexer          CodeLexer
+	tokenColors    map[CodeTokenKind]Color
+	ignored        []bool // per UTF-16 code unit, if it is in a string or comment
+	textDocument   unsafe.Pointer
+	gutterWidth    int
+	currentLine    int
+	bracketMatch   [2]int // -1, if no brackets match
+	lineNumbersOff bool
+	coloring       bool
+}
+
+// NewCodeEdit returns a new, empty *CodeEdit without a lexer.
+func NewCodeEdit(parent Container) (*CodeEdit, error) {
+	if err := libmsftedit.Load(); err != nil {
+		return nil, wrapError(err)
+	}
+
+	ce := &CodeEdit{tokenColors: make(map[CodeTokenKind]Color), bracketMatch: [2]int{-1, -1}}
+
+	if err := InitChildWidget(
+		ce,
+		parent,
+		"RICHEDIT50W",
+		WS_TABSTOP|WS_VISIBLE|WS_VSCROLL|WS_HSCROLL|ES_MULTILINE|ES_WANTRETURN|ES_AUTOVSCROLL|ES_AUTOHSCROLL|ES_NOHIDESEL,
+		WS_EX_CLIENTEDGE); err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			ce.Dispose()
+		}
+	}()
+
+	ce.SendMessage(emExLimitText, 0, richTextMaxChars)
+	ce.SendMessage(emSetEventMask, 0, enmChange|enmSelChange|enmScroll)
+
+	// Lines are not wrapped.
+	ce.SendMessage(emSetTargetDevice, 0, 1)
+
+	// The text document allows to color without filling the undo buffer.
+	var richEditOle unsafe.Pointer
+	if ce.SendMessage(emGetOleInterface, 0, uintptr(unsafe.Pointer(&richEditOle))) != 0 && richEditOle != nil {
+		comCall(richEditOle, iUnknownQueryInterface, uintptr(unsafe.Pointer(&iidITextDocument)), uintptr(unsafe.Pointer(&ce.textDocument)))
+		comRelease(richEditOle)
+	}
+
+	font, err := NewFont("Consolas", 10, 0)
+	if err != nil {
+		return nil, err
+	}
+	ce.SetFont(font)
+
+	for kind, color := range codeEditLightColors {
+		if appSingleton.DarkColors() {
+			color = codeEditDarkColors[kind]
+		}
+
+		ce.tokenColors[kind] = color
+	}
+
+	ce.MustRegisterProperty("ReadOnly", NewProperty(
+		func() interface{} {
+			return ce.ReadOnly()
+		},
+		func(v interface{}) error {
+			return ce.SetReadOnly(v.(bool))
+		},
+		ce.readOnlyChangedPublisher.Event()))
+
+	ce.MustRegisterProperty("Text", NewProperty(
+		func() interface{} {
+			return ce.Text()
+		},
+		func(v interface{}) error {
+			return ce.SetText(v.(string))
+		},
+		ce.textChangedPublisher.Event()))
+
+	ce.updateGutter()
+
+	succeeded = true
+
+	return ce, nil
+}
+
+// Dispose releases the operating system resources, associated with the
+// *CodeEdit.
+func (ce *CodeEdit) Dispose() {
+	if ce.textDocument != nil {
+		comRelease(ce.textDocument)
+		ce.textDocument = nil
+	}
+
+	ce.RichTextEdit.Dispose()
+}
+
+// SetText replaces the text and colors it right away.
+func (ce *CodeEdit) SetText(value string) error {
+	if err := ce.RichTextEdit.SetText(value); err != nil {
+		return err
+	}
+
+	ce.colorize()
+
+	return nil
+}
+
+// Lexer returns the CodeLexer, that splits the text into tokens for syntax
+// coloring.
+func (ce *CodeEdit) Lexer() CodeLexer {
+	return ce.lexer
+}
+
+// SetLexer sets the CodeLexer, that splits the text into tokens for syntax
+// coloring. With a nil lexer, the text is not colored.
+func (ce *CodeEdit) SetLexer(lexer CodeLexer) {
+	ce.lexer = lexer
+
+	ce.colorize()
+}
+
+// TokenColor returns the color of tokens of kind.
+func (ce *CodeEdit) TokenColor(kind CodeTokenKind) Color {
+	return ce.tokenColors[kind]
+}
+
+// SetTokenColor sets the color of tokens of kind. The zero value, which is
+// black, uses the text color.
+func (ce *CodeEdit) SetTokenColor(kind CodeTokenKind, color Color) {
+	ce.tokenColors[kind] = color
+
+	ce.colorize()
+}
+
+// LineNumbersVisible returns if line numbers are shown left of the text.
+func (ce *CodeEdit) LineNumbersVisible() bool {
+	return !ce.lineNumbersOff
+}
+
+// SetLineNumbersVisible sets if line numbers are shown left of the text.
+func (ce *CodeEdit) SetLineNumbersVisible(value bool) {
+	ce.lineNumbersOff = !value
+
+	ce.gutterWidth = -1
+	ce.updateGutter()
+}
+
+// Line returns the zero-based number of the line with the caret.
+func (ce *CodeEdit) Line() int {
+	_, end := ce.TextSelection()
+
+	return int(ce.SendMessage(emExLineFromChar, 0, uintptr(end)))
+}
+
+// textLength returns the length of the text in UTF-16 code units, with line
+// breaks counting as one.
+func (ce *CodeEdit) textLength() int {
+	gtl := getTextLengthEx{gtlPrecise | gtlNumChars, cpUnicode}
+
+	return int(ce.SendMessage(emGetTextLengthEx, uintptr(unsafe.Pointer(&gtl)), 0))
+}
+
+// rawText returns the text, with line breaks as "\n". Other than with Text,
+// its positions are those of the control.
+func (ce *CodeEdit) rawText() string {
+	n := ce.textLength()
+	if n == 0 {
+		return ""
+	}
+
+	return strings.Replace(ce.textRange(0, n), "\r", "\n", -1)
+}
+
+// withoutUndo calls f, without recording the changes it makes for undo and
+// without redrawing. The selection and scroll position are kept.
+func (ce *CodeEdit) withoutUndo(f func()) {
+	ce.coloring = true
+	defer func() {
+		ce.coloring = false
+	}()
+
+	if ce.textDocument != nil {
+		suspend, resume := int32(tomSuspend), int32(tomResume)
+
+		comCall(ce.textDocument, iTextDocumentUndo, uintptr(suspend), 0)
+		defer comCall(ce.textDocument, iTextDocumentUndo, uintptr(resume), 0)
+	}
+
+	var cr charRange
+	ce.SendMessage(emExGetSel, 0, uintptr(unsafe.Pointer(&cr)))
+
+	var scrollPos POINT
+	ce.SendMessage(emGetScrollPos, 0, uintptr(unsafe.Pointer(&scrollPos)))
+
+	mask := ce.SendMessage(emSetEventMask, 0, 0)
+
+	suspended := ce.Suspended()
+	ce.SetSuspended(true)
+
+	f()
+
+	ce.SendMessage(emExSetSel, 0, uintptr(unsafe.Pointer(&cr)))
+	ce.SendMessage(emSetScrollPos, 0, uintptr(unsafe.Pointer(&scrollPos)))
+	ce.SendMessage(emSetEventMask, 0, mask)
+
+	if !suspended {
+		ce.SetSuspended(false)
+		ce.Invalidate()
+	}
+}
+
+func (ce *CodeEdit) setRangeCharFormat(start, end int, cf *charFormat2) {
+	cr := charRange{int32(start), int32(end)}
+	ce.SendMessage(emExSetSel, 0, uintptr(unsafe.Pointer(&cr)))
+
+	ce.setSelectionCharFormat(cf)
+}
+
+// colorize colors the text by the tokens of the lexer and highlights the
+// matching brackets again.
+func (ce *CodeEdit) colorize() {
+	KillTimer(ce.hWnd, codeEditColorTimer)
+
+	text := ce.rawText()
+
+	var tokens []CodeToken
+	if ce.lexer != nil {
+		tokens = ce.lexer.Tokens(text)
+	}
+
+	// The lexer works on bytes, the control on UTF-16 code units.
+	offsets := make([]int, len(text)+1)
+	var offset int
+	for i, r := range text {
+		for j := i; j < len(text) && (j == i || !utf8.RuneStart(text[j])); j++ {
+			offsets[j] = offset
+		}
+
+		offset += len(utf16.Encode([]rune{r}))
+	}
+	offsets[len(text)] = offset
+
+	ce.ignored = make([]bool, offset)
+
+	ce.withoutUndo(func() {
+		ce.setRangeCharFormat(0, -1, &charFormat2{dwMask: cfmColor | cfmBackColor, dwEffects: cfeAutoColor | cfeAutoBackColor})
+
+		for _, t := range tokens {
+			if t.Start < 0 || t.End > len(text) || t.Start >= t.End {
+				continue
+			}
+
+			start, end := offsets[t.Start], offsets[t.End]
+
+			if t.Kind == CodeTokenString || t.Kind == CodeTokenComment {
+				for i := start; i < end; i++ {
+					ce.ignored[i] = true
+				}
+			}
+
+			if color := ce.tokenColors[t.Kind]; color != 0 {
+				ce.setRangeCharFormat(start, end, &charFormat2{dwMask: cfmColor, crTextColor: uint32(color)})
+			}
+		}
+	})
+
+	ce.bracketMatch = [2]int{-1, -1}
+	ce.updateBracketMatch()
+}
+
+// inStringOrComment returns if the character at pos was part of a string or
+// comment token, when the text was colored last.
+func (ce *CodeEdit) inStringOrComment(pos int) bool {
+	return pos < len(ce.ignored) && ce.ignored[pos]
+}
+
+// findBracketMatch returns the positions of the bracket next to the caret and
+// the one matching it, or -1, -1.
+func (ce *CodeEdit) findBracketMatch() (int, int) {
+	start, end := ce.TextSelection()
+	if start != end {
+		return -1, -1
+	}
+
+	// Positions in the control are those of UTF-16 code units.
+	text := utf16.Encode([]rune(ce.rawText()))
+
+	// The caret is between characters, the one after it takes precedence.
+	for _, pos := range []int{end, end - 1} {
+		if pos < 0 || pos >= len(text) || text[pos] >= utf8.RuneSelf {
+			continue
+		}
+
+		i := strings.IndexByte(codeEditBrackets, byte(text[pos]))
+		if i == -1 || ce.inStringOrComment(pos) {
+			continue
+		}
+
+		openBracket, closeBracket := uint16(codeEditBrackets[i&^1]), uint16(codeEditBrackets[i|1])
+		step := 1
+		if i&1 == 1 {
+			step = -1
+		}
+
+		depth := 0
+		for j := pos; j >= 0 && j < len(text); j += step {
+			if text[j] != openBracket && text[j] != closeBracket || ce.inStringOrComment(j) {
+				continue
+			}
+
+			if (text[j] == openBracket) == (step == 1) {
+				depth++
+			} else {
+				depth--
+			}
+
+			if depth == 0 {
+				return pos, j
+			}
+		}
+	}
+
+	return -1, -1
+}
+
+// updateBracketMatch highlights the bracket next to the caret and the one
+// matching it.
+func (ce *CodeEdit) updateBracketMatch() {
+	a, b := ce.findBracketMatch()
+	if [2]int{a, b} == ce.bracketMatch {
+		return
+	}
+
+	old := ce.bracketMatch
+	ce.bracketMatch = [2]int{a, b}
+
+	ce.withoutUndo(func() {
+		for _, pos := range old {
+			if pos > -1 {
+				ce.setRangeCharFormat(pos, pos+1, &charFormat2{dwMask: cfmBackColor, dwEffects: cfeAutoBackColor})
+			}
+		}
+
+		color := appSingleton.Palette().Border
+		for _, pos := range ce.bracketMatch {
+			if pos > -1 {
+				ce.setRangeCharFormat(pos, pos+1, &charFormat2{dwMask: cfmBackColor, crBackColor: uint32(color)})
+			}
+		}
+	})
+}
+
+func (ce *CodeEdit) lineHeight() int {
+	canvas, err := ce.CreateCanvas()
+	if err != nil {
+		return 0
+	}
+	defer canvas.Dispose()
+
+	height, _ := canvas.fontHeight(ce.Font())
+
+	return height
+}
+
+// updateGutter makes room for the line numbers, when their number of digits
+// changed.
+func (ce *CodeEdit) updateGutter() {
+	var width int
+	if !ce.lineNumbersOff {
+		digits := len(strconv.Itoa(int(ce.SendMessage(emGetLineCount, 0, 0))))
+		width = maxi(digits, 2)*ce.lineHeight()*5/8 + 2*ce.IntFromDIP(codeEditGutterPad)
+	}
+
+	if width == ce.gutterWidth {
+		return
+	}
+
+	ce.gutterWidth = width
+
+	margin := width + ce.IntFromDIP(codeEditGutterPad)
+	ce.SendMessage(emSetMargins, ecLeftMargin, uintptr(MAKELONG(uint16(margin), 0)))
+
+	ce.Invalidate()
+}
+
+// paintDecorations draws the line numbers and the frame of the current line
+// over the text painted by the control.
+func (ce *CodeEdit) paintDecorations() error {
+	canvas, err := ce.CreateCanvas()
+	if err != nil {
+		return err
+	}
+	defer canvas.Dispose()
+
+	palette := appSingleton.Palette()
+	cb := ce.ClientBounds()
+	lineHeight := ce.lineHeight()
+	currentLine := ce.Line()
+
+	// lineTop returns the y of line, or false if it is not visible.
+	lineTop := func(line int) (int, bool) {
+		index := int32(ce.SendMessage(EM_LINEINDEX, uintptr(line), 0))
+		if index < 0 {
+			return 0, false
+		}
+
+		var pt POINT
+		ce.SendMessage(EM_POSFROMCHAR, uintptr(unsafe.Pointer(&pt)), uintptr(index))
+
+		return int(pt.Y), int(pt.Y) < cb.Height
+	}
+
+	if y, ok := lineTop(currentLine); ok {
+		pen, err := NewCosmeticPen(PenSolid, palette.Border)
+		if err != nil {
+			return err
+		}
+		defer pen.Dispose()
+
+		if err := canvas.DrawRectangle(pen, Rectangle{ce.gutterWidth, y, cb.Width - ce.gutterWidth, lineHeight}); err != nil {
+			return err
+		}
+	}
+
+	if ce.lineNumbersOff {
+		return nil
+	}
+
+	gutter, err := NewSolidColorBrush(palette.ControlBackground)
+	if err != nil {
+		return err
+	}
+	defer gutter.Dispose()
+
+	if err := canvas.FillRectangle(gutter, Rectangle{0, 0, ce.gutterWidth, cb.Height}); err != nil {
+		return err
+	}
+
+	pad := ce.IntFromDIP(codeEditGutterPad)
+	first := int(ce.SendMessage(EM_GETFIRSTVISIBLELINE, 0, 0))
+
+	for line := first; ; line++ {
+		y, ok := lineTop(line)
+		if !ok {
+			break
+		}
+
+		color := palette.DisabledText
+		if line == currentLine {
+			color = palette.Text
+		}
+
+		bounds := Rectangle{0, y, ce.gutterWidth - pad, lineHeight}
+		if err := canvas.DrawText(strconv.Itoa(line+1), ce.Font(), color, bounds, TextRight|TextSingleLine); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ce *CodeEdit) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_PAINT:
+		result := ce.RichTextEdit.WndProc(hwnd, msg, wParam, lParam)
+
+		if !ce.coloring {
+			ce.paintDecorations()
+		}
+
+		return result
+
+	case WM_COMMAND:
+		switch HIWORD(uint32(wParam)) {
+		case EN_CHANGE:
+			ce.updateGutter()
+
+			if 0 == SetTimer(hwnd, codeEditColorTimer, codeEditColorDelay, 0) {
+				lastError("SetTimer")
+				ce.colorize()
+			}
+
+		case enVScroll, enHScroll:
+			ce.Invalidate()
+		}
+
+	case WM_NOTIFY:
+		if ((*NMHDR)(unsafe.Pointer(lParam))).Code == enSelChange {
+			if line := ce.Line(); line != ce.currentLine {
+				ce.currentLine = line
+				ce.Invalidate()
+			}
+
+			ce.updateBracketMatch()
+
+			return 0
+		}
+
+	case WM_TIMER:
+		if wParam == codeEditColorTimer {
+			ce.colorize()
+			return 0
+		}
+
+	case WM_MOUSEWHEEL:
+		result := ce.RichTextEdit.WndProc(hwnd, msg, wParam, lParam)
+
+		ce.Invalidate()
+
+		return result
+
+	case WM_SETFONT:
+		result := ce.RichTextEdit.WndProc(hwnd, msg, wParam, lParam)
+
+		ce.gutterWidth = -1
+		ce.updateGutter()
+
+		return result
+
+	case WM_GETDLGCODE:
+		code := ce.RichTextEdit.WndProc(hwnd, msg, wParam, lParam)
+
+		if !ce.ReadOnly() {
+			code |= DLGC_WANTTAB
+		}
+
+		return code
+
+	case WM_DESTROY:
+		KillTimer(hwnd, codeEditColorTimer)
+	}
+
+	return ce.RichTextEdit.WndProc(hwnd, msg, wParam, lParam)
+}
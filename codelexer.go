@@ -0,0 +1,212 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CodeTokenKind classifies a CodeToken for syntax coloring.
+type CodeTokenKind int
+
+const (
+	CodeTokenText CodeTokenKind = iota
+	CodeTokenKeyword
+	CodeTokenString
+	CodeTokenNumber
+	CodeTokenComment
+	CodeTokenOperator
+)
+
+// CodeToken is a piece of source code, from byte offset Start up to End.
+type CodeToken struct {
+	Kind       CodeTokenKind
+	Start, End int
+}
+
+// CodeLexer splits source code into tokens for a *CodeEdit.
+type CodeLexer interface {
+	// Tokens returns the tokens of text in ascending order. Text, that is not
+	// covered by a token, is shown as CodeTokenText. Lines of text end with
+	// "\n".
+	Tokens(text string) []CodeToken
+}
+
+// SimpleCodeLexer is a CodeLexer for languages, that consist of keywords,
+// identifiers, numbers, quoted strings and comments, like most programming
+// and configuration languages.
+type SimpleCodeLexer struct {
+	// Keywords are the identifiers, that are shown as CodeTokenKeyword. They
+	// must not change after the first call of Tokens.
+	Keywords []string
+
+	// CaseInsensitive makes Keywords match regardless of case.
+	CaseInsensitive bool
+
+	// LineComment starts a comment up to the end of the line, e.g. "//" or
+	// "#".
+	LineComment string
+
+	// BlockCommentStart and BlockCommentEnd enclose a comment, that may span
+	// several lines, e.g. "/*" and "*/".
+	BlockCommentStart string
+	BlockCommentEnd   string
+
+	// StringDelimiters are the characters that enclose strings, e.g. `"'`.
+	// Within strings a backslash escapes the next character.
+	StringDelimiters string
+
+	// Operators are the characters, that are shown as CodeTokenOperator.
+	Operators string
+
+	keywords map[string]bool
+}
+
+// NewGoCodeLexer returns a *SimpleCodeLexer for the Go programming language.
+func NewGoCodeLexer() *SimpleCodeLexer {
+	return &SimpleCodeLexer{
+		Keywords: []string{
+			"break", "case", "chan", "const", "continue", "default", "defer",
+			"else", "fallthrough", "for", "func", "go", "goto", "if", "import",
+			"interface", "map", "package", "range", "return", "select",
+			"struct", "switch", "type", "var", "nil", "true", "false",
+		},
+		LineComment:       "//",
+		BlockCommentStart: "/*",
+		BlockCommentEnd:   "*/",
+		StringDelimiters:  "\"'`",
+		Operators:         "+-*/%&|^<>=!:.,;",
+	}
+}
+
+// NewINICodeLexer returns a *SimpleCodeLexer for INI files.
+func NewINICodeLexer() *SimpleCodeLexer {
+	return &SimpleCodeLexer{
+		CaseInsensitive:  true,
+		Keywords:         []string{"true", "false", "yes", "no", "on", "off"},
+		LineComment:      ";",
+		StringDelimiters: `"`,
+		Operators:        "=[]",
+	}
+}
+
+func (l *SimpleCodeLexer) isKeyword(word string) bool {
+	if l.keywords == nil {
+		l.keywords = make(map[string]bool, len(l.Keywords))
+
+		for _, k := range l.Keywords {
+			if l.CaseInsensitive {
+				k = strings.ToLower(k)
+			}
+
+			l.keywords[k] = true
+		}
+	}
+
+	if l.CaseInsensitive {
+		word = strings.ToLower(word)
+	}
+
+	return l.keywords[word]
+}
+
+func (l *SimpleCodeLexer) Tokens(text string) []CodeToken {
+	var tokens []CodeToken
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		rest := text[i:]
+
+		switch {
+		case l.LineComment != "" && strings.HasPrefix(rest, l.LineComment):
+			end := strings.IndexByte(rest, '\n')
+			if end == -1 {
+				end = len(rest)
+			}
+
+			tokens = append(tokens, CodeToken{CodeTokenComment, i, i + end})
+			i += end
+
+		case l.BlockCommentStart != "" && strings.HasPrefix(rest, l.BlockCommentStart):
+			end := len(rest)
+			if j := strings.Index(rest[len(l.BlockCommentStart):], l.BlockCommentEnd); j > -1 {
+				end = len(l.BlockCommentStart) + j + len(l.BlockCommentEnd)
+			}
+
+			tokens = append(tokens, CodeToken{CodeTokenComment, i, i + end})
+			i += end
+
+		case strings.ContainsRune(l.StringDelimiters, r):
+			end := l.stringEnd(rest, r, size)
+
+			tokens = append(tokens, CodeToken{CodeTokenString, i, i + end})
+			i += end
+
+		case unicode.IsDigit(r):
+			end := strings.IndexFunc(rest, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '_'
+			})
+			if end == -1 {
+				end = len(rest)
+			}
+
+			tokens = append(tokens, CodeToken{CodeTokenNumber, i, i + end})
+			i += end
+
+		case unicode.IsLetter(r) || r == '_':
+			end := strings.IndexFunc(rest, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+			})
+			if end == -1 {
+				end = len(rest)
+			}
+
+			if l.isKeyword(rest[:end]) {
+				tokens = append(tokens, CodeToken{CodeTokenKeyword, i, i + end})
+			}
+			i += end
+
+		case strings.ContainsRune(l.Operators, r):
+			tokens = append(tokens, CodeToken{CodeTokenOperator, i, i + size})
+			i += size
+
+		default:
+			i += size
+		}
+	}
+
+	return tokens
+}
+
+// stringEnd returns the length of the string at the start of text, which is
+// enclosed by delimiter. Unterminated strings end with the line, except for
+// raw strings in backquotes.
+func (l *SimpleCodeLexer) stringEnd(text string, delimiter rune, size int) int {
+	for i := size; i < len(text); {
+		r, n := utf8.DecodeRuneInString(text[i:])
+
+		switch {
+		case r == delimiter:
+			return i + n
+
+		case r == '\\' && delimiter != '`':
+			i += n
+			if i < len(text) {
+				_, n = utf8.DecodeRuneInString(text[i:])
+			} else {
+				n = 0
+			}
+
+		case r == '\n' && delimiter != '`':
+			return i
+		}
+
+		i += n
+	}
+
+	return len(text)
+}
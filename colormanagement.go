@@ -0,0 +1,121 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	icmOff   = 1
+	icmOn    = 2
+	icmQuery = 3
+
+	lcsSRGB     = 0x73524742 // 'sRGB'
+	lcsGMImages = 4
+)
+
+var (
+	libgdi32          = syscall.NewLazyDLL("gdi32.dll")
+	procSetICMMode    = libgdi32.NewProc("SetICMMode")
+	procStretchDIBits = libgdi32.NewProc("StretchDIBits")
+)
+
+// ColorManaged returns if canvases are color managed by default.
+func (app *Application) ColorManaged() bool {
+	return app.colorManaged
+}
+
+// SetColorManaged sets if canvases are color managed by default.
+//
+// See Canvas.SetColorManaged for details. This affects canvases created after
+// the call only.
+func (app *Application) SetColorManaged(value bool) {
+	app.colorManaged = value
+}
+
+// ColorManaged returns if colors drawn on the *Canvas are converted to the
+// color profile of the device.
+func (c *Canvas) ColorManaged() bool {
+	return colorManagedDC(c.hdc)
+}
+
+// SetColorManaged sets if colors drawn on the *Canvas are converted to the
+// color profile of the device.
+//
+// For a *Canvas of a Widget, this is the profile of the monitor, so a color
+// managed *Canvas renders bitmaps faithfully on wide gamut displays. Bitmaps
+// created from Go images are treated as sRGB. By default, canvases are not
+// color managed, unless App().ColorManaged() returns true.
+func (c *Canvas) SetColorManaged(value bool) error {
+	mode := uintptr(icmOff)
+	if value {
+		mode = icmOn
+	}
+
+	if ret, _, _ := procSetICMMode.Call(uintptr(c.hdc), mode); ret == 0 {
+		return newAPIError("SetICMMode")
+	}
+
+	return nil
+}
+
+// colorManagedDC returns if hdc has color management enabled.
+func colorManagedDC(hdc HDC) bool {
+	ret, _, _ := procSetICMMode.Call(uintptr(hdc), icmQuery)
+
+	return ret == icmOn
+}
+
+// drawColorManaged draws bmp, tagged as sRGB, into bounds of hdc, so GDI
+// converts the colors to the color profile of hdc. It returns false, if bmp
+// could not be drawn this way.
+func (bmp *Bitmap) drawColorManaged(hdc HDC, bounds Rectangle) bool {
+	var dib DIBSECTION
+	if GetObject(HGDIOBJ(bmp.hBmp), unsafe.Sizeof(dib), unsafe.Pointer(&dib)) == 0 {
+		return false
+	}
+
+	if dib.DsBmih.BiBitCount < 16 || dib.DsBm.BmBits == nil {
+		// Palette based bitmaps are left to BitBlt.
+		return false
+	}
+
+	var bi BITMAPV5HEADER
+	bi.BITMAPINFOHEADER = dib.DsBmih
+	bi.BiSize = uint32(unsafe.Sizeof(bi))
+	if bi.BiCompression == BI_BITFIELDS {
+		bi.BV4RedMask = dib.DsBitfields[0]
+		bi.BV4GreenMask = dib.DsBitfields[1]
+		bi.BV4BlueMask = dib.DsBitfields[2]
+		if bi.BiBitCount == 32 {
+			bi.BV4AlphaMask = 0xFF000000
+		}
+	}
+	bi.BV4CSType = lcsSRGB
+	bi.BV5Intent = lcsGMImages
+
+	size := bmp.Size()
+
+	ret, _, _ := procStretchDIBits.Call(
+		uintptr(hdc),
+		uintptr(bounds.X),
+		uintptr(bounds.Y),
+		uintptr(bounds.Width),
+		uintptr(bounds.Height),
+		0,
+		0,
+		uintptr(size.Width),
+		uintptr(size.Height),
+		uintptr(dib.DsBm.BmBits),
+		uintptr(unsafe.Pointer(&bi)),
+		DIB_RGB_COLORS,
+		SRCCOPY)
+
+	return ret != 0
+}
@@ -0,0 +1,279 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// ColorScheme specifies whether the application uses light or dark colors.
+type ColorScheme byte
+
+const (
+	ColorSchemeLight ColorScheme = iota
+	ColorSchemeDark
+
+	// ColorSchemeSystem follows the choice of the user for apps in the
+	// Windows settings.
+	ColorSchemeSystem
+)
+
+const (
+	dwmwaUseImmersiveDarkMode       = 20
+	dwmwaUseImmersiveDarkModeBefore = 19
+
+	wmCtlColorMsgBox  = 0x0132
+	wmCtlColorEdit    = 0x0133
+	wmCtlColorListBox = 0x0134
+	wmCtlColorBtn     = 0x0135
+	wmCtlColorDlg     = 0x0136
+	wmCtlColorStatic  = 0x0138
+)
+
+var (
+	procDwmSetWindowAttribute = libdwmapi.NewProc("DwmSetWindowAttribute")
+	procSetBkColor            = libgdi32.NewProc("SetBkColor")
+)
+
+// Palette holds the colors of the effective ColorScheme, so custom painting
+// can follow it.
+type Palette struct {
+	Background        Color
+	Text              Color
+	ControlBackground Color
+	ControlText       Color
+	Highlight         Color
+	HighlightText     Color
+	DisabledText      Color
+	Border            Color
+}
+
+var darkPalette = Palette{
+	Background:        RGB(32, 32, 32),
+	Text:              RGB(255, 255, 255),
+	ControlBackground: RGB(43, 43, 43),
+	ControlText:       RGB(255, 255, 255),
+	Highlight:         RGB(0, 120, 215),
+	HighlightText:     RGB(255, 255, 255),
+	DisabledText:      RGB(109, 109, 109),
+	Border:            RGB(85, 85, 85),
+}
+
+var (
+	darkBackgroundBrush        Brush
+	darkControlBackgroundBrush Brush
+)
+
+// ColorScheme returns the ColorScheme of the application.
+func (app *Application) ColorScheme() ColorScheme {
+	return app.colorScheme
+}
+
+// SetColorScheme sets the ColorScheme of the application.
+//
+// With dark colors, title bars use the immersive dark mode, common controls
+// the dark visual styles and menus ThemeDark. Windows and widgets created
+// later follow the ColorScheme as well. With ColorSchemeSystem, the
+// application switches, whenever the user does.
+func (app *Application) SetColorScheme(value ColorScheme) error {
+	if value == app.colorScheme {
+		return nil
+	}
+
+	app.colorScheme = value
+
+	return app.applyColorScheme()
+}
+
+// DarkColors returns if the effective ColorScheme is dark. It returns false,
+// while HighContrast is on.
+func (app *Application) DarkColors() bool {
+	if app.HighContrast() {
+		return false
+	}
+
+	switch app.colorScheme {
+	case ColorSchemeDark:
+		return true
+
+	case ColorSchemeSystem:
+		return app.DarkMode()
+	}
+
+	return false
+}
+
+// Palette returns the colors of the effective ColorScheme.
+func (app *Application) Palette() Palette {
+	if app.DarkColors() {
+		return darkPalette
+	}
+
+	return Palette{
+		Background:        Color(GetSysColor(COLOR_BTNFACE)),
+		Text:              Color(GetSysColor(COLOR_BTNTEXT)),
+		ControlBackground: Color(GetSysColor(COLOR_WINDOW)),
+		ControlText:       Color(GetSysColor(COLOR_WINDOWTEXT)),
+		Highlight:         Color(GetSysColor(COLOR_HIGHLIGHT)),
+		HighlightText:     Color(GetSysColor(COLOR_HIGHLIGHTTEXT)),
+		DisabledText:      Color(GetSysColor(COLOR_GRAYTEXT)),
+		Border:            Color(GetSysColor(COLOR_BTNSHADOW)),
+	}
+}
+
+// applyColorScheme applies the effective ColorScheme to all top-level windows
+// and menus.
+func (app *Application) applyColorScheme() error {
+	theme := ThemeDefault
+	if app.DarkColors() {
+		theme = ThemeDark
+	}
+
+	if err := app.SetTheme(theme); err != nil {
+		return err
+	}
+
+	for tlw := range topLevelWindows {
+		applyColorSchemeToWidget(tlw.widget)
+		refreshWidget(tlw.widget, false)
+	}
+
+	return nil
+}
+
+// applyColorSchemeToWidget applies the effective ColorScheme to widget and its
+// descendants.
+func applyColorSchemeToWidget(widget Widget) {
+	if widget.BaseWidget().hWnd == 0 {
+		return
+	}
+
+	applyWidgetColorScheme(widget)
+
+	switch w := widget.(type) {
+	case *TabWidget:
+		pages := w.Pages()
+		for i := 0; i < pages.Len(); i++ {
+			applyColorSchemeToWidget(pages.At(i))
+		}
+
+	case Container:
+		children := w.Children()
+		for i := 0; i < children.Len(); i++ {
+			applyColorSchemeToWidget(children.At(i))
+		}
+	}
+}
+
+// applyWidgetColorScheme applies the effective ColorScheme to widget only.
+func applyWidgetColorScheme(widget Widget) {
+	wb := widget.BaseWidget()
+
+	if _, ok := widget.(RootWidget); ok {
+		setDarkTitleBar(wb.hWnd, appSingleton.DarkColors())
+	} else {
+		wb.applyWindowTheme()
+	}
+}
+
+// setDarkTitleBar switches the title bar of the top-level window hwnd between
+// light and dark.
+func setDarkTitleBar(hwnd HWND, dark bool) {
+	if procDwmSetWindowAttribute.Find() != nil {
+		return
+	}
+
+	var value int32
+	if dark {
+		value = 1
+	}
+
+	// The attribute was renumbered in Windows 10 20H1.
+	for _, attr := range []uintptr{dwmwaUseImmersiveDarkMode, dwmwaUseImmersiveDarkModeBefore} {
+		if hr, _, _ := procDwmSetWindowAttribute.Call(
+			uintptr(hwnd),
+			attr,
+			uintptr(unsafe.Pointer(&value)),
+			unsafe.Sizeof(value)); hr == 0 {
+
+			return
+		}
+	}
+}
+
+// applyWindowTheme sets the visual style of the *WidgetBase, according to the
+// application name passed to setTheme and the effective ColorScheme.
+func (wb *WidgetBase) applyWindowTheme() error {
+	appName := wb.themeAppName
+	if appSingleton.DarkColors() {
+		if appName == "" {
+			appName = "Explorer"
+		}
+
+		appName = "DarkMode_" + appName
+	}
+
+	// Passing nil restores the default visual style.
+	var appNamePtr *uint16
+	if appName != "" {
+		appNamePtr = syscall.StringToUTF16Ptr(appName)
+	}
+
+	if hr := SetWindowTheme(wb.hWnd, appNamePtr, nil); FAILED(hr) {
+		return errorFromHRESULT("SetWindowTheme", hr)
+	}
+
+	return nil
+}
+
+// darkBrushes lazily creates the brushes for the dark Palette.
+func darkBrushes() (background, control Brush, err error) {
+	if darkBackgroundBrush == nil {
+		if darkBackgroundBrush, err = NewSolidColorBrush(darkPalette.Background); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if darkControlBackgroundBrush == nil {
+		if darkControlBackgroundBrush, err = NewSolidColorBrush(darkPalette.ControlBackground); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return darkBackgroundBrush, darkControlBackgroundBrush, nil
+}
+
+// handleCtlColor colors the child controls of a container with the dark
+// Palette. It returns the brush to paint the background of the control with,
+// or 0 if the default colors are used.
+func handleCtlColor(msg uint32, hdc HDC) uintptr {
+	if !appSingleton.DarkColors() {
+		return 0
+	}
+
+	background, control, err := darkBrushes()
+	if err != nil {
+		return 0
+	}
+
+	switch msg {
+	case wmCtlColorEdit, wmCtlColorListBox:
+		SetTextColor(hdc, COLORREF(darkPalette.ControlText))
+		procSetBkColor.Call(uintptr(hdc), uintptr(darkPalette.ControlBackground))
+
+		return uintptr(control.handle())
+
+	case wmCtlColorStatic, wmCtlColorBtn, wmCtlColorDlg, wmCtlColorMsgBox:
+		SetTextColor(hdc, COLORREF(darkPalette.Text))
+		procSetBkColor.Call(uintptr(hdc), uintptr(darkPalette.Background))
+
+		return uintptr(background.handle())
+	}
+
+	return 0
+}
@@ -0,0 +1,38 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// Vtable indexes of the IUnknown methods, which all COM and WinRT interfaces
+// start with.
+const (
+	iUnknownQueryInterface = 0
+	iUnknownRelease        = 2
+)
+
+// comCall calls the method at index of the vtable of the COM or WinRT object
+// obj, for interfaces that winapi does not declare.
+func comCall(obj unsafe.Pointer, index int, args ...uintptr) HRESULT {
+	vtbl := *(*uintptr)(obj)
+	method := *(*uintptr)(unsafe.Pointer(vtbl + uintptr(index)*unsafe.Sizeof(vtbl)))
+
+	var a [9]uintptr
+	a[0] = uintptr(obj)
+	copy(a[1:], args)
+
+	ret, _, _ := syscall.Syscall9(method, uintptr(len(args)+1), a[0], a[1], a[2], a[3], a[4], a[5], a[6], a[7], a[8])
+
+	return HRESULT(int32(ret))
+}
+
+func comRelease(obj unsafe.Pointer) {
+	comCall(obj, iUnknownRelease)
+}
@@ -129,7 +129,7 @@ func (cb *ComboBox) insertItemAt(index int) error {
 	lp := uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(str)))
 
 	if CB_ERR == cb.SendMessage(CB_INSERTSTRING, uintptr(index), lp) {
-		return newError("SendMessage(CB_INSERTSTRING)")
+		return newAPIError("SendMessage(CB_INSERTSTRING)")
 	}
 
 	return nil
@@ -140,7 +140,7 @@ func (cb *ComboBox) resetItems() error {
 	defer cb.SetSuspended(false)
 
 	if FALSE == cb.SendMessage(CB_RESETCONTENT, 0, 0) {
-		return newError("SendMessage(CB_RESETCONTENT)")
+		return newAPIError("SendMessage(CB_RESETCONTENT)")
 	}
 
 	cb.maxItemTextWidth = 0
@@ -170,7 +170,7 @@ func (cb *ComboBox) attachModel() {
 
 	itemChangedHandler := func(index int) {
 		if CB_ERR == cb.SendMessage(CB_DELETESTRING, uintptr(index), 0) {
-			newError("SendMessage(CB_DELETESTRING)")
+			newAPIError("SendMessage(CB_DELETESTRING)")
 		}
 
 		cb.insertItemAt(index)
@@ -316,7 +316,7 @@ func (cb *ComboBox) SetPrecision(value int) {
 func (cb *ComboBox) calculateMaxItemTextWidth() int {
 	hdc := GetDC(cb.hWnd)
 	if hdc == 0 {
-		newError("GetDC failed")
+		newAPIError("GetDC")
 		return -1
 	}
 	defer ReleaseDC(cb.hWnd, hdc)
@@ -332,7 +332,7 @@ func (cb *ComboBox) calculateMaxItemTextWidth() int {
 		str := syscall.StringToUTF16(cb.itemString(i))
 
 		if !GetTextExtentPoint32(hdc, &str[0], int32(len(str)-1), &s) {
-			newError("GetTextExtentPoint32 failed")
+			newAPIError("GetTextExtentPoint32")
 			return -1
 		}
 
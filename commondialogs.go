@@ -81,7 +81,7 @@ func (dlg *FileDialog) ShowBrowseFolder(owner RootWidget) (accepted bool, err er
 	pathFromPIDL := func(pidl uintptr) (string, error) {
 		var path [MAX_PATH]uint16
 		if !SHGetPathFromIDList(pidl, &path[0]) {
-			return "", newError("SHGetPathFromIDList failed")
+			return "", newAPIError("SHGetPathFromIDList")
 		}
 
 		return syscall.UTF16ToString(path[:]), nil
@@ -136,3 +136,66 @@ func (dlg *FileDialog) ShowBrowseFolder(owner RootWidget) (accepted bool, err er
 	accepted = dlg.FilePath != ""
 	return
 }
+
+const (
+	ccRGBInit  = 0x00000001
+	ccFullOpen = 0x00000002
+)
+
+type chooseColor struct {
+	LStructSize    uint32
+	HwndOwner      HWND
+	HInstance      HWND
+	RgbResult      uint32
+	LpCustColors   *[16]uint32
+	Flags          uint32
+	LCustData      uintptr
+	LpfnHook       uintptr
+	LpTemplateName *uint16
+}
+
+var procChooseColor = syscall.NewLazyDLL("comdlg32.dll").NewProc("ChooseColorW")
+
+// ColorDialog lets the user pick a color.
+type ColorDialog struct {
+	// Color is the initially selected color and, after the dialog was
+	// accepted, the picked one.
+	Color Color
+
+	// CustomColors are the custom colors the dialog offers. The user may
+	// change them.
+	CustomColors [16]Color
+}
+
+// Show runs the dialog and returns if the user accepted it.
+func (dlg *ColorDialog) Show(owner RootWidget) (accepted bool, err error) {
+	var custColors [16]uint32
+	for i, c := range dlg.CustomColors {
+		custColors[i] = uint32(c)
+	}
+
+	cc := chooseColor{
+		RgbResult:    uint32(dlg.Color),
+		LpCustColors: &custColors,
+		Flags:        ccRGBInit | ccFullOpen,
+	}
+	cc.LStructSize = uint32(unsafe.Sizeof(cc))
+	if owner != nil {
+		cc.HwndOwner = owner.Handle()
+	}
+
+	if ret, _, _ := procChooseColor.Call(uintptr(unsafe.Pointer(&cc))); ret == 0 {
+		if errno := CommDlgExtendedError(); errno != 0 {
+			return false, newError(fmt.Sprintf("Error %d", errno))
+		}
+
+		return false, nil
+	}
+
+	dlg.Color = Color(cc.RgbResult)
+	for i, c := range custColors {
+		dlg.CustomColors[i] = Color(c)
+	}
+
+	return true, nil
+}
@@ -14,6 +14,12 @@ func init() {
 
 type Composite struct {
 	ContainerBase
+	focusScope    bool
+	defaultButton *PushButton
+	cancelButton  *PushButton
+
+	navigationGroup      bool
+	navigationGroupFocus HWND
 }
 
 func newCompositeWithStyle(parent Widget, style uint32) (*Composite, error) {
@@ -50,10 +50,13 @@ type RootWidget interface {
 
 type ContainerBase struct {
 	WidgetBase
-	layout     Layout
-	children   *WidgetList
-	dataBinder *DataBinder
-	persistent bool
+	layout                                 Layout
+	children                               *WidgetList
+	dataBinder                             *DataBinder
+	persistent                             bool
+	designGridSize                         int
+	invalidDescendantCount                 int
+	invalidDescendantCountChangedPublisher EventPublisher
 }
 
 func (cb *ContainerBase) LayoutFlags() LayoutFlags {
@@ -153,6 +156,27 @@ func (cb *ContainerBase) SetDataBinder(db *DataBinder) {
 	}
 }
 
+// InvalidDescendantCount returns the number of descendants of the
+// *ContainerBase, whose bound properties currently fail validation by their
+// DataBinder.
+//
+// This can be used e.g. to show an error badge for a section of a form.
+func (cb *ContainerBase) InvalidDescendantCount() int {
+	return cb.invalidDescendantCount
+}
+
+// InvalidDescendantCountChanged returns an *Event that is published when the
+// InvalidDescendantCount of the *ContainerBase changed.
+func (cb *ContainerBase) InvalidDescendantCountChanged() *Event {
+	return cb.invalidDescendantCountChangedPublisher.Event()
+}
+
+func (cb *ContainerBase) adjustInvalidDescendantCount(delta int) {
+	cb.invalidDescendantCount += delta
+
+	cb.invalidDescendantCountChangedPublisher.Publish()
+}
+
 func (cb *ContainerBase) forEachPersistableChild(f func(p Persistable) error) error {
 	if cb.children == nil {
 		return nil
@@ -208,21 +232,16 @@ func (cb *ContainerBase) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr)
 				cmdId := LOWORD(uint32(wParam))
 				switch cmdId {
 				case IDOK, IDCANCEL:
-					root := rootWidget(cb)
-					if root == nil {
-						break
-					}
-
-					dlg, ok := root.(dialogish)
-					if !ok {
-						break
+					widget := focusedWidget()
+					if widget == nil {
+						widget = cb.widget
 					}
 
 					var button *PushButton
 					if cmdId == IDOK {
-						button = dlg.DefaultButton()
+						button = defaultButtonFor(widget)
 					} else {
-						button = dlg.CancelButton()
+						button = cancelButtonFor(widget)
 					}
 
 					if button != nil && button.Visible() && button.Enabled() {
@@ -251,6 +270,66 @@ func (cb *ContainerBase) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr)
 			}
 		}
 
+	case WM_INITMENUPOPUP:
+		if menu, ok := menusByHandle[HMENU(wParam)]; ok {
+			menu.beforePopupPublisher.Publish()
+		}
+
+	case WM_PAINT:
+		if !cb.designMode || cb.origWndProcPtr != 0 {
+			break
+		}
+
+		var ps PAINTSTRUCT
+
+		hdc := BeginPaint(hwnd, &ps)
+		if hdc == 0 {
+			newAPIError("BeginPaint")
+			break
+		}
+		defer EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			break
+		}
+		defer canvas.Dispose()
+
+		cb.paintDesignAdorners(canvas)
+
+		return 0
+
+	case wmCtlColorEdit, wmCtlColorListBox, wmCtlColorStatic, wmCtlColorBtn, wmCtlColorDlg, wmCtlColorMsgBox:
+		if brush := handleCtlColor(msg, HDC(wParam)); brush != 0 {
+			return brush
+		}
+
+	case WM_MEASUREITEM:
+		if measureMenuItem(hwnd, (*MEASUREITEMSTRUCT)(unsafe.Pointer(lParam))) {
+			return 1
+		}
+
+	case WM_DRAWITEM:
+		dis := (*DRAWITEMSTRUCT)(unsafe.Pointer(lParam))
+		if drawMenuItem(dis) {
+			return 1
+		}
+
+		if dis.CtlType != ODT_MENU {
+			if widget := widgetFromHWND(dis.HwndItem); widget != nil {
+				// The owner-drawn widget shall draw itself.
+				return widget.WndProc(hwnd, msg, wParam, lParam)
+			}
+		}
+
+	case WM_HSCROLL, WM_VSCROLL:
+		if lParam != 0 {
+			// A scroll bar or trackbar control shall handle it itself.
+			if widget := widgetFromHWND(HWND(lParam)); widget != nil {
+				return widget.WndProc(hwnd, msg, wParam, lParam)
+			}
+		}
+
 	case WM_NOTIFY:
 		nmh := (*NMHDR)(unsafe.Pointer(lParam))
 		if widget := widgetFromHWND(nmh.HwndFrom); widget != nil {
@@ -279,9 +358,7 @@ func (cb *ContainerBase) onInsertedWidget(index int, widget Widget) (err error)
 		}
 	}
 
-	if cb.layout != nil {
-		cb.layout.Update(true)
-	}
+	updateLayout(cb.layout, true)
 
 	return
 }
@@ -299,9 +376,7 @@ func (cb *ContainerBase) onRemovingWidget(index int, widget Widget) (err error)
 }
 
 func (cb *ContainerBase) onRemovedWidget(index int, widget Widget) (err error) {
-	if cb.layout != nil {
-		cb.layout.Update(true)
-	}
+	updateLayout(cb.layout, true)
 
 	return
 }
@@ -319,9 +394,7 @@ func (cb *ContainerBase) onClearingWidgets() (err error) {
 }
 
 func (cb *ContainerBase) onClearedWidgets() (err error) {
-	if cb.layout != nil {
-		cb.layout.Update(true)
-	}
+	updateLayout(cb.layout, true)
 
 	return
 }
@@ -0,0 +1,100 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"context"
+	"sync"
+)
+
+var contextMutex sync.Mutex
+
+// Context returns a context.Context, that is canceled when the application
+// exits, i.e. Exit was called or the message loop received WM_QUIT.
+//
+// Goroutines doing background work should stop when it is done. It is the
+// default for BackgroundWorker.Start. Dialogs and BackgroundWorkers can be
+// bound to other contexts using Dialog.RunContext and
+// BackgroundWorker.StartContext, which are the only modal loops and tasks of
+// walk, that observe contexts.
+func (app *Application) Context() context.Context {
+	contextMutex.Lock()
+	defer contextMutex.Unlock()
+
+	if app.ctx == nil {
+		app.ctx, app.cancelCtx = context.WithCancel(context.Background())
+	}
+
+	return app.ctx
+}
+
+func (app *Application) cancelContext() {
+	app.Context()
+
+	contextMutex.Lock()
+	cancel := app.cancelCtx
+	contextMutex.Unlock()
+
+	cancel()
+}
+
+// Context returns a context.Context, that is canceled when the
+// *TopLevelWindow is destroyed, its owner is destroyed or the application
+// exits.
+//
+// It is meant for work started on behalf of the *TopLevelWindow, that is
+// pointless once it is gone, e.g. by passing it to
+// BackgroundWorker.StartContext or to Dialog.RunContext of a child dialog.
+func (tlw *TopLevelWindow) Context() context.Context {
+	if tlw.ctx != nil {
+		return tlw.ctx
+	}
+
+	parent := appSingleton.Context()
+	if owner, ok := tlw.owner.(interface {
+		Context() context.Context
+	}); ok {
+		parent = owner.Context()
+	}
+
+	tlw.ctx, tlw.cancelCtx = context.WithCancel(parent)
+
+	if tlw.hWnd == 0 {
+		tlw.cancelCtx()
+	}
+
+	return tlw.ctx
+}
+
+func (tlw *TopLevelWindow) cancelContext() {
+	if tlw.cancelCtx != nil {
+		tlw.cancelCtx()
+	}
+}
+
+// RunContext is like Run, but the *Dialog is canceled, as if the user clicked
+// its cancel button, when ctx is done.
+//
+// Pass the Context of the owner or of the application to close the *Dialog
+// consistently, when the owner is closed or the application exits.
+func (dlg *Dialog) RunContext(ctx context.Context) int {
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			postSynchronized(func() {
+				if dlg.hWnd != 0 {
+					dlg.Cancel()
+				}
+			})
+
+		case <-done:
+		}
+	}()
+
+	return dlg.Run()
+}
@@ -0,0 +1,93 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+// ContextMenuEventArgs carries information about a context menu request.
+type ContextMenuEventArgs struct {
+	widget     Widget
+	source     Widget
+	location   Point
+	byKeyboard bool
+	menu       *Menu
+}
+
+// Widget returns the Widget the context menu is requested for.
+func (a *ContextMenuEventArgs) Widget() Widget {
+	return a.widget
+}
+
+// Source returns the innermost Widget the user clicked on or that had the
+// keyboard focus. This may be a descendant of Widget.
+func (a *ContextMenuEventArgs) Source() Widget {
+	return a.source
+}
+
+// Location returns the location, relative to the client area of Widget, where
+// the context menu will be shown.
+//
+// If the request was made using the keyboard, this is the center of the client
+// area.
+func (a *ContextMenuEventArgs) Location() Point {
+	return a.location
+}
+
+// ByKeyboard returns if the request was made using the keyboard, i.e. the Menu
+// key or Shift+F10.
+func (a *ContextMenuEventArgs) ByKeyboard() bool {
+	return a.byKeyboard
+}
+
+// Menu returns the *Menu that will be shown.
+//
+// Initially this is the ContextMenu of Widget.
+func (a *ContextMenuEventArgs) Menu() *Menu {
+	return a.menu
+}
+
+// SetMenu sets the *Menu that will be shown.
+//
+// Set it to nil to show no context menu for Widget, in which case the request
+// is passed on to the parent of Widget.
+func (a *ContextMenuEventArgs) SetMenu(menu *Menu) {
+	a.menu = menu
+}
+
+type ContextMenuEventHandler func(args *ContextMenuEventArgs)
+
+type ContextMenuEvent struct {
+	handlers []ContextMenuEventHandler
+}
+
+func (e *ContextMenuEvent) Attach(handler ContextMenuEventHandler) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	return len(e.handlers) - 1
+}
+
+func (e *ContextMenuEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type ContextMenuEventPublisher struct {
+	event ContextMenuEvent
+}
+
+func (p *ContextMenuEventPublisher) Event() *ContextMenuEvent {
+	return &p.event
+}
+
+func (p *ContextMenuEventPublisher) Publish(args *ContextMenuEventArgs) {
+	for _, handler := range p.event.handlers {
+		if handler != nil {
+			handler(args)
+		}
+	}
+}
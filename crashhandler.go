@@ -0,0 +1,255 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	miniDumpNormal = 0x00000000
+
+	// crashDialogStackLines is the number of lines of the stack trace shown
+	// in the crash dialog. The report contains all of them.
+	crashDialogStackLines = 16
+)
+
+var procMiniDumpWriteDump = syscall.NewLazyDLL("dbghelp.dll").NewProc("MiniDumpWriteDump")
+
+// CrashHandlerOptions configure the crash handler installed by
+// Application.SetCrashHandler.
+type CrashHandlerOptions struct {
+	// ReportDir is the directory crash reports are written to. It defaults to
+	// %LOCALAPPDATA%\<OrganizationName>\<ProductName>\CrashReports.
+	ReportDir string
+
+	// WriteMinidump makes the crash handler write a minidump next to the
+	// report, that can be opened in a debugger.
+	WriteMinidump bool
+
+	// OfferRestart makes the crash dialog ask the user, if the application
+	// should be started again.
+	OfferRestart bool
+
+	// Report, if not nil, is called with the paths of the files written,
+	// before the crash dialog is shown, e.g. to upload them.
+	Report func(reportPath, minidumpPath string)
+}
+
+var crashing bool
+
+// SetCrashHandler installs a crash handler, that catches panics on the UI
+// thread, or removes it if options is nil.
+//
+// When a panic is caught, the Panicking event is published first. Then the
+// crash handler writes a report with the stacks of all goroutines and
+// optionally a minidump, shows a dialog with the stack trace and exits the
+// process, after restarting it if the user chose so.
+//
+// Panics in other goroutines can't be caught.
+func (app *Application) SetCrashHandler(options *CrashHandlerOptions) {
+	if options != nil {
+		o := *options
+		options = &o
+	}
+
+	app.crashHandler = options
+}
+
+// recoverCrash handles a panic of the UI thread, if a crash handler is
+// installed. It must be called deferred.
+func recoverCrash() {
+	if appSingleton.crashHandler == nil {
+		return
+	}
+
+	if x := recover(); x != nil {
+		err := toErrorNoPanic(x)
+
+		appSingleton.panickingPublisher.Publish(err)
+
+		handleCrash(err)
+	}
+}
+
+// handleCrash reports err and exits the process.
+func handleCrash(err error) {
+	options := appSingleton.crashHandler
+
+	if crashing {
+		// A handler of Panicking or Report panicked as well.
+		os.Exit(2)
+	}
+	crashing = true
+
+	var stack []byte
+	if walkErr, ok := err.(*Error); ok {
+		stack = walkErr.Stack()
+	}
+
+	message := err.Error()
+	if walkErr, ok := err.(*Error); ok {
+		message = walkErr.Message()
+	}
+
+	reportPath, minidumpPath := writeCrashReport(options, message, stack)
+
+	if options.Report != nil {
+		options.Report(reportPath, minidumpPath)
+	}
+
+	restart := showCrashDialog(options, message, stack, reportPath)
+
+	if restart {
+		appSingleton.releaseSingleInstance()
+
+		if exe, err := os.Executable(); err == nil {
+			os.StartProcess(exe, append([]string{exe}, os.Args[1:]...), &os.ProcAttr{
+				Files: []*os.File{os.Stdin, os.Stdout, os.Stderr},
+			})
+		}
+	}
+
+	os.Exit(2)
+}
+
+func crashReportDir(options *CrashHandlerOptions) (string, error) {
+	if options.ReportDir != "" {
+		return options.ReportDir, nil
+	}
+
+	localAppDataPath, err := LocalAppDataPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(
+		localAppDataPath,
+		appSingleton.OrganizationName(),
+		appSingleton.ProductName(),
+		"CrashReports"), nil
+}
+
+// writeCrashReport writes the report and the minidump, if requested, and
+// returns their paths. Paths of files that could not be written are empty.
+func writeCrashReport(options *CrashHandlerOptions, message string, stack []byte) (reportPath, minidumpPath string) {
+	dir, err := crashReportDir(options)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return
+	}
+
+	now := time.Now()
+	baseName := filepath.Join(dir, "crash-"+now.Format("20060102-150405"))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Product: %s\r\n", appSingleton.ProductName())
+	fmt.Fprintf(&buf, "Time: %s\r\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&buf, "Go: %s %s/%s\r\n\r\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&buf, "%s\r\n\r\nStack:\r\n%s\r\n", message, stack)
+	fmt.Fprintf(&buf, "All goroutines:\r\n%s", allGoroutineStacks())
+
+	if f, err := os.Create(baseName + ".txt"); err == nil {
+		_, err = f.Write(buf.Bytes())
+		if f.Close() == nil && err == nil {
+			reportPath = f.Name()
+		}
+	}
+
+	if options.WriteMinidump && writeMinidump(baseName+".dmp") {
+		minidumpPath = baseName + ".dmp"
+	}
+
+	return
+}
+
+func allGoroutineStacks() []byte {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+func writeMinidump(filePath string) bool {
+	if procMiniDumpWriteDump.Find() != nil {
+		return false
+	}
+
+	f, err := os.Create(filePath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	process, _ := syscall.GetCurrentProcess()
+
+	ret, _, _ := procMiniDumpWriteDump.Call(
+		uintptr(process),
+		uintptr(os.Getpid()),
+		f.Fd(),
+		miniDumpNormal,
+		0,
+		0,
+		0)
+
+	return ret != 0
+}
+
+// showCrashDialog tells the user about the crash and returns if the
+// application should be restarted.
+//
+// A message box is used, because the state of the widgets is unknown.
+func showCrashDialog(options *CrashHandlerOptions, message string, stack []byte, reportPath string) bool {
+	title := appSingleton.ProductName()
+	if title == "" {
+		title = filepath.Base(os.Args[0])
+	}
+
+	lines := strings.Split(string(stack), "\n")
+	if len(lines) > crashDialogStackLines {
+		lines = append(lines[:crashDialogStackLines], "…")
+	}
+
+	text := fmt.Sprintf("%s has stopped working because of an internal error:\n\n%s\n\n%s",
+		title, message, strings.Join(lines, "\n"))
+
+	if reportPath != "" {
+		text += "\n\nA report was written to:\n" + reportPath
+	}
+
+	style := uint32(MB_ICONERROR | MB_TASKMODAL | MB_SETFOREGROUND)
+
+	if options.OfferRestart {
+		text += "\n\nDo you want to restart " + title + "?"
+		style |= MB_YESNO
+	} else {
+		style |= MB_OK
+	}
+
+	ret := MessageBox(
+		0,
+		syscall.StringToUTF16Ptr(text),
+		syscall.StringToUTF16Ptr(title),
+		style)
+
+	return options.OfferRestart && ret == IDYES
+}
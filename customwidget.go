@@ -19,6 +19,9 @@ type CustomWidget struct {
 	paint               PaintFunc
 	clearsBackground    bool
 	invalidatesOnResize bool
+	layers              []*BitmapLayer
+	d2d                 *d2dCompositor
+	d2dFailed           bool
 }
 
 func NewCustomWidget(parent Container, style uint, paint PaintFunc) (*CustomWidget, error) {
@@ -60,10 +63,28 @@ func (cw *CustomWidget) SetInvalidatesOnResize(value bool) {
 	cw.invalidatesOnResize = value
 }
 
+// Dispose releases the operating system resources, associated with the
+// *CustomWidget, including those of its layers.
+func (cw *CustomWidget) Dispose() {
+	cw.disposeLayers()
+
+	cw.WidgetBase.Dispose()
+}
+
+func (cw *CustomWidget) invalidateRectangle(bounds Rectangle) {
+	if cw.hWnd == 0 {
+		return
+	}
+
+	rect := bounds.toRECT()
+
+	InvalidateRect(cw.hWnd, &rect, false)
+}
+
 func (cw *CustomWidget) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	switch msg {
 	case WM_PAINT:
-		if cw.paint == nil {
+		if cw.paint == nil && len(cw.layers) == 0 {
 			newError("paint func is nil")
 			break
 		}
@@ -72,11 +93,23 @@ func (cw *CustomWidget) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) u
 
 		hdc := BeginPaint(cw.hWnd, &ps)
 		if hdc == 0 {
-			newError("BeginPaint failed")
+			newAPIError("BeginPaint")
 			break
 		}
 		defer EndPaint(cw.hWnd, &ps)
 
+		r := &ps.RcPaint
+		updateBounds := Rectangle{
+			int(r.Left),
+			int(r.Top),
+			int(r.Right - r.Left),
+			int(r.Bottom - r.Top),
+		}
+
+		if len(cw.layers) > 0 && cw.paintD2D(updateBounds) {
+			return 0
+		}
+
 		canvas, err := newCanvasFromHDC(hdc)
 		if err != nil {
 			newError("newCanvasFromHDC failed")
@@ -84,17 +117,15 @@ func (cw *CustomWidget) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) u
 		}
 		defer canvas.Dispose()
 
-		r := &ps.RcPaint
-		err = cw.paint(
-			canvas,
-			Rectangle{
-				int(r.Left),
-				int(r.Top),
-				int(r.Right - r.Left),
-				int(r.Bottom - r.Top),
-			})
-		if err != nil {
-			newError("paint failed")
+		if cw.paint != nil {
+			if err := cw.paint(canvas, updateBounds); err != nil {
+				newError("paint failed")
+				break
+			}
+		}
+
+		if err := cw.compositeLayers(canvas, updateBounds); err != nil {
+			newError("compositing layers failed")
 			break
 		}
 
@@ -106,6 +137,14 @@ func (cw *CustomWidget) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) u
 		}
 
 	case WM_SIZE, WM_SIZING:
+		if msg == WM_SIZE {
+			cw.releaseOutsideLayers()
+
+			if cw.d2d != nil {
+				cw.d2d.resize(cw.ClientBounds().Size())
+			}
+		}
+
 		if cw.invalidatesOnResize {
 			cw.Invalidate()
 		}
@@ -7,8 +7,10 @@ package walk
 import (
 	"errors"
 	"fmt"
+	"net"
 	"reflect"
 	"strings"
+	"time"
 )
 
 var (
@@ -51,6 +53,10 @@ func (db *DataBinder) SetBoundWidgets(boundWidgets []Widget) {
 		prop.Changed().Detach(handle)
 	}
 
+	for widget := range db.widget2Property2Error {
+		adjustInvalidDescendantCounts(widget, -1)
+	}
+
 	db.boundWidgets = boundWidgets
 
 	db.property2Widget = make(map[Property]Widget)
@@ -92,6 +98,8 @@ func (db *DataBinder) validateProperty(prop Property, widget Widget) {
 		if prop2Err == nil {
 			prop2Err = make(map[Property]error)
 			db.widget2Property2Error[widget] = prop2Err
+
+			adjustInvalidDescendantCounts(widget, 1)
 		}
 		prop2Err[prop] = err
 	} else {
@@ -104,6 +112,8 @@ func (db *DataBinder) validateProperty(prop Property, widget Widget) {
 		if len(prop2Err) == 0 {
 			delete(db.widget2Property2Error, widget)
 
+			adjustInvalidDescendantCounts(widget, -1)
+
 			changed = len(db.widget2Property2Error) == 0
 		}
 	}
@@ -117,6 +127,20 @@ func (db *DataBinder) validateProperty(prop Property, widget Widget) {
 	}
 }
 
+type invalidDescendantCounter interface {
+	adjustInvalidDescendantCount(delta int)
+}
+
+// adjustInvalidDescendantCounts adds delta to the InvalidDescendantCount of all
+// containers hosting widget.
+func adjustInvalidDescendantCounts(widget Widget, delta int) {
+	for w := hostWidget(widget); w != nil; w = hostWidget(w) {
+		if c, ok := w.(invalidDescendantCounter); ok {
+			c.adjustInvalidDescendantCount(delta)
+		}
+	}
+}
+
 func (db *DataBinder) ErrorPresenter() ErrorPresenter {
 	return db.errorPresenter
 }
@@ -134,6 +158,9 @@ func (db *DataBinder) CanSubmitChanged() *Event {
 }
 
 func (db *DataBinder) Reset() error {
+	beginChangeBatch()
+	defer endChangeBatch()
+
 	return db.forEach(func(prop Property, field reflect.Value) error {
 		if f64, ok := prop.Get().(float64); ok {
 			switch v := field.Interface().(type) {
@@ -199,6 +226,9 @@ func (db *DataBinder) Submit() error {
 		return errValidationFailed
 	}
 
+	beginChangeBatch()
+	defer endChangeBatch()
+
 	return db.forEach(func(prop Property, field reflect.Value) error {
 		value := prop.Get()
 		if value == nil {
@@ -228,6 +258,28 @@ func (db *DataBinder) Submit() error {
 			return nil
 		}
 
+		if t, ok := value.(time.Time); ok && field.Type() == reflect.TypeOf((*time.Time)(nil)) {
+			// Fields of type *time.Time are nil without a value.
+			if t.IsZero() {
+				field.Set(reflect.Zero(field.Type()))
+			} else {
+				field.Set(reflect.ValueOf(&t))
+			}
+
+			return nil
+		}
+
+		if ip, ok := value.(net.IP); ok && field.Kind() == reflect.String {
+			// String fields are empty without an address.
+			if ip == nil {
+				field.SetString("")
+			} else {
+				field.SetString(ip.String())
+			}
+
+			return nil
+		}
+
 		field.Set(reflect.ValueOf(value))
 
 		return nil
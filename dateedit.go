@@ -5,14 +5,24 @@
 package walk
 
 import (
+	"strings"
+	"syscall"
 	"time"
 	"unsafe"
 )
 
 import . "github.com/lxn/go-winapi"
 
+const dtmSetFormat = 0x1032
+
+// DateEdit wraps the date and time picker control.
+//
+// By default, it edits dates in the short date format of the user. With a
+// format that contains time fields, see SetFormat, it edits the time as well.
 type DateEdit struct {
 	WidgetBase
+	format                string
+	timeFields            bool
 	valueChangedPublisher EventPublisher
 }
 
@@ -33,6 +43,15 @@ func newDateEdit(parent Container, style uint32) (*DateEdit, error) {
 			return de.Value()
 		},
 		func(v interface{}) error {
+			// Fields of type *time.Time are nil without a value.
+			if t, ok := v.(*time.Time); ok {
+				if t == nil {
+					return de.SetValue(time.Time{})
+				}
+
+				return de.SetValue(*t)
+			}
+
 			return de.SetValue(v.(time.Time))
 		},
 		de.valueChangedPublisher.Event()))
@@ -65,6 +84,10 @@ func (de *DateEdit) systemTimeToTime(st *SYSTEMTIME) time.Time {
 		return time.Time{}
 	}
 
+	if de.timeFields {
+		return time.Date(int(st.WYear), time.Month(st.WMonth), int(st.WDay), int(st.WHour), int(st.WMinute), int(st.WSecond), 0, time.Local)
+	}
+
 	return time.Date(int(st.WYear), time.Month(st.WMonth), int(st.WDay), 0, 0, 0, 0, time.Local)
 }
 
@@ -81,11 +104,19 @@ func (de *DateEdit) timeToSystemTime(t time.Time) *SYSTEMTIME {
 		}
 	}
 
-	return &SYSTEMTIME{
+	st := &SYSTEMTIME{
 		WYear:  uint16(t.Year()),
 		WMonth: uint16(t.Month()),
 		WDay:   uint16(t.Day()),
 	}
+
+	if de.timeFields {
+		st.WHour = uint16(t.Hour())
+		st.WMinute = uint16(t.Minute())
+		st.WSecond = uint16(t.Second())
+	}
+
+	return st
 }
 
 func (de *DateEdit) systemTime() (*SYSTEMTIME, error) {
@@ -99,7 +130,7 @@ func (de *DateEdit) systemTime() (*SYSTEMTIME, error) {
 		return nil, nil
 	}
 
-	return nil, newError("SendMessage(DTM_GETSYSTEMTIME)")
+	return nil, newAPIError("SendMessage(DTM_GETSYSTEMTIME)")
 }
 
 func (de *DateEdit) setSystemTime(st *SYSTEMTIME) error {
@@ -112,7 +143,7 @@ func (de *DateEdit) setSystemTime(st *SYSTEMTIME) error {
 	}
 
 	if 0 == de.SendMessage(DTM_SETSYSTEMTIME, wParam, uintptr(unsafe.Pointer(st))) {
-		return newError("SendMessage(DTM_SETSYSTEMTIME)")
+		return newAPIError("SendMessage(DTM_SETSYSTEMTIME)")
 	}
 
 	de.valueChangedPublisher.Publish()
@@ -159,7 +190,7 @@ func (de *DateEdit) SetRange(min, max time.Time) error {
 	}
 
 	if 0 == de.SendMessage(DTM_SETRANGE, wParam, uintptr(unsafe.Pointer(&st[0]))) {
-		return newError("SendMessage(DTM_SETRANGE)")
+		return newAPIError("SendMessage(DTM_SETRANGE)")
 	}
 
 	return nil
@@ -186,6 +217,81 @@ func (de *DateEdit) ValueChanged() *Event {
 	return de.valueChangedPublisher.Event()
 }
 
+// Date returns the value of the *DateEdit, which is the zero time without
+// one. It is the same as Value.
+func (de *DateEdit) Date() time.Time {
+	return de.Value()
+}
+
+// SetDate sets the value of the *DateEdit. The zero time clears it, if the
+// *DateEdit has the none option. It is the same as SetValue.
+func (de *DateEdit) SetDate(value time.Time) error {
+	return de.SetValue(value)
+}
+
+// DateChanged returns the event that is published, when the value changed. It
+// is the same as ValueChanged.
+func (de *DateEdit) DateChanged() *Event {
+	return de.ValueChanged()
+}
+
+// HasNoneOption returns if the *DateEdit shows a check box to have no value.
+func (de *DateEdit) HasNoneOption() bool {
+	return de.hasStyleBits(DTS_SHOWNONE)
+}
+
+// Format returns the format string of the *DateEdit, or an empty string for
+// the short date format of the user.
+func (de *DateEdit) Format() string {
+	return de.format
+}
+
+// SetFormat sets the format string of the *DateEdit, e.g. "dd.MM.yyyy HH:mm".
+//
+// The format uses the Windows date and time pictures: d, dd, ddd and dddd for
+// the day, M, MM, MMM and MMMM for the month, y, yy and yyyy for the year, h,
+// hh, H and HH for the hour, mm for minutes, ss for seconds and t or tt for
+// AM/PM. Literal text is enclosed in single quotes. If the format contains
+// time fields, Value keeps the time of day. An empty format restores the
+// short date format of the user.
+func (de *DateEdit) SetFormat(format string) error {
+	value := de.Value()
+
+	var formatPtr *uint16
+	if format != "" {
+		formatPtr = syscall.StringToUTF16Ptr(format)
+	}
+
+	if 0 == de.SendMessage(dtmSetFormat, 0, uintptr(unsafe.Pointer(formatPtr))) {
+		return newAPIError("SendMessage(DTM_SETFORMAT)")
+	}
+
+	de.format = format
+	de.timeFields = formatHasTimeFields(format)
+
+	// Changing timeFields changes how the value is mapped.
+	if !value.IsZero() {
+		return de.SetValue(value)
+	}
+
+	return nil
+}
+
+// formatHasTimeFields returns if a date and time picker format contains
+// fields for the time of day.
+func formatHasTimeFields(format string) bool {
+	parts := strings.Split(format, "'")
+
+	// Even parts are outside of quotes.
+	for i := 0; i < len(parts); i += 2 {
+		if strings.ContainsAny(parts[i], "hHmst") {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (de *DateEdit) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	switch msg {
 	case WM_NOTIFY:
@@ -0,0 +1,279 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"time"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const dateRangePickerWindowClass = `\o/ Walk_DateRangePicker_Class \o/`
+
+func init() {
+	MustRegisterWindowClass(dateRangePickerWindowClass)
+}
+
+// DateRangePreset is a commonly used date range, that a DateRangePicker
+// offers as button.
+type DateRangePreset struct {
+	Text string
+
+	// Range returns the range relative to today, which is at midnight local
+	// time.
+	Range func(today time.Time) (start, end time.Time)
+}
+
+// DefaultDateRangePresets returns presets for today, the last 7 and 30 days
+// and the current month.
+func DefaultDateRangePresets() []DateRangePreset {
+	return []DateRangePreset{
+		{tr("Today", "walk"), func(today time.Time) (time.Time, time.Time) {
+			return today, today
+		}},
+		{tr("Last 7 days", "walk"), func(today time.Time) (time.Time, time.Time) {
+			return today.AddDate(0, 0, -6), today
+		}},
+		{tr("Last 30 days", "walk"), func(today time.Time) (time.Time, time.Time) {
+			return today.AddDate(0, 0, -29), today
+		}},
+		{tr("This month", "walk"), func(today time.Time) (time.Time, time.Time) {
+			return today.AddDate(0, 0, 1-today.Day()), today
+		}},
+	}
+}
+
+// DateRangePicker consists of two DateEdits for the start and the end of a
+// range of dates, e.g. for filtering, and buttons for presets.
+//
+// The start can't be set after the end and vice versa.
+type DateRangePicker struct {
+	WidgetBase
+	composite             *Composite
+	startEdit             *DateEdit
+	endEdit               *DateEdit
+	presetButtons         []*PushButton
+	updating              bool
+	rangeChangedPublisher EventPublisher
+}
+
+func NewDateRangePicker(parent Container) (*DateRangePicker, error) {
+	drp := new(DateRangePicker)
+
+	if err := InitChildWidget(
+		drp,
+		parent,
+		dateRangePickerWindowClass,
+		WS_VISIBLE,
+		WS_EX_CONTROLPARENT); err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			drp.Dispose()
+		}
+	}()
+
+	var err error
+
+	if drp.composite, err = newCompositeWithStyle(drp, 0); err != nil {
+		return nil, err
+	}
+
+	l := NewHBoxLayout()
+	l.SetMargins(Margins{})
+
+	if err = drp.composite.SetLayout(l); err != nil {
+		return nil, err
+	}
+
+	if drp.startEdit, err = NewDateEdit(drp.composite); err != nil {
+		return nil, err
+	}
+
+	separator, err := NewLabel(drp.composite)
+	if err != nil {
+		return nil, err
+	}
+	separator.SetText("–")
+
+	if drp.endEdit, err = NewDateEdit(drp.composite); err != nil {
+		return nil, err
+	}
+
+	today := dateRangeToday()
+	drp.startEdit.SetValue(today)
+	drp.endEdit.SetValue(today)
+
+	drp.startEdit.ValueChanged().Attach(drp.onEditValueChanged)
+	drp.endEdit.ValueChanged().Attach(drp.onEditValueChanged)
+
+	if err = drp.SetPresets(DefaultDateRangePresets()); err != nil {
+		return nil, err
+	}
+
+	drp.MustRegisterProperty("Start", NewProperty(
+		func() interface{} {
+			return drp.Start()
+		},
+		func(v interface{}) error {
+			return drp.SetRange(v.(time.Time), drp.End())
+		},
+		drp.rangeChangedPublisher.Event()))
+
+	drp.MustRegisterProperty("End", NewProperty(
+		func() interface{} {
+			return drp.End()
+		},
+		func(v interface{}) error {
+			return drp.SetRange(drp.Start(), v.(time.Time))
+		},
+		drp.rangeChangedPublisher.Event()))
+
+	succeeded = true
+
+	return drp, nil
+}
+
+func dateRangeToday() time.Time {
+	now := time.Now()
+
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func (*DateRangePicker) LayoutFlags() LayoutFlags {
+	return GrowableHorz
+}
+
+func (drp *DateRangePicker) MinSizeHint() Size {
+	if drp.composite == nil {
+		return Size{}
+	}
+
+	return drp.composite.Layout().MinSize()
+}
+
+func (drp *DateRangePicker) SizeHint() Size {
+	return drp.MinSizeHint()
+}
+
+// Start returns the first day of the range.
+func (drp *DateRangePicker) Start() time.Time {
+	return drp.startEdit.Value()
+}
+
+// End returns the last day of the range.
+func (drp *DateRangePicker) End() time.Time {
+	return drp.endEdit.Value()
+}
+
+// SetRange sets the first and the last day of the range.
+func (drp *DateRangePicker) SetRange(start, end time.Time) error {
+	if end.Before(start) {
+		return newError("start after end")
+	}
+
+	if start.Equal(drp.Start()) && end.Equal(drp.End()) {
+		return nil
+	}
+
+	err := func() error {
+		drp.updating = true
+		defer func() {
+			drp.updating = false
+		}()
+
+		// Lift the limits first, so the new values are accepted.
+		drp.startEdit.SetRange(time.Time{}, time.Time{})
+		drp.endEdit.SetRange(time.Time{}, time.Time{})
+
+		if err := drp.startEdit.SetValue(start); err != nil {
+			return err
+		}
+
+		return drp.endEdit.SetValue(end)
+	}()
+
+	drp.onEditValueChanged()
+
+	return err
+}
+
+func (drp *DateRangePicker) onEditValueChanged() {
+	if drp.updating {
+		return
+	}
+
+	drp.updating = true
+	defer func() {
+		drp.updating = false
+	}()
+
+	start, end := drp.Start(), drp.End()
+
+	drp.startEdit.SetRange(time.Time{}, end)
+	drp.endEdit.SetRange(start, time.Time{})
+
+	drp.rangeChangedPublisher.Publish()
+}
+
+// SetPresets replaces the preset buttons with buttons for presets.
+func (drp *DateRangePicker) SetPresets(presets []DateRangePreset) error {
+	for _, pb := range drp.presetButtons {
+		pb.Dispose()
+	}
+	drp.presetButtons = nil
+
+	for _, preset := range presets {
+		pb, err := NewPushButton(drp.composite)
+		if err != nil {
+			return err
+		}
+
+		if err := pb.SetText(preset.Text); err != nil {
+			return err
+		}
+
+		rangeFunc := preset.Range
+		pb.Clicked().Attach(func() {
+			drp.SetRange(rangeFunc(dateRangeToday()))
+		})
+
+		drp.presetButtons = append(drp.presetButtons, pb)
+	}
+
+	drp.updateParentLayout()
+
+	return nil
+}
+
+// StartEdit returns the *DateEdit for the start of the range.
+func (drp *DateRangePicker) StartEdit() *DateEdit {
+	return drp.startEdit
+}
+
+// EndEdit returns the *DateEdit for the end of the range.
+func (drp *DateRangePicker) EndEdit() *DateEdit {
+	return drp.endEdit
+}
+
+// RangeChanged returns the *Event that is published, when the start or the
+// end of the range changed.
+func (drp *DateRangePicker) RangeChanged() *Event {
+	return drp.rangeChangedPublisher.Event()
+}
+
+func (drp *DateRangePicker) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_SIZE, WM_SIZING:
+		if drp.composite != nil {
+			drp.composite.SetBounds(drp.ClientBounds())
+		}
+	}
+
+	return drp.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
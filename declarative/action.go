@@ -15,16 +15,25 @@ import (
 
 type Action struct {
 	AssignTo    **walk.Action
+	Name        string
 	Text        string
 	Image       interface{}
+	Font        Font
+	TextColor   walk.Color
 	Enabled     Property
 	Visible     Property
+	Default     bool
+	MenuBreak   walk.MenuBreak
 	OnTriggered walk.EventHandler
+	OnMeasure   walk.MenuItemMeasureFunc
+	OnDraw      walk.MenuItemDrawFunc
 }
 
 func (a Action) createAction(builder *Builder, menu *walk.Menu) (*walk.Action, error) {
 	action := walk.NewAction()
 
+	action.SetName(a.Name)
+
 	if err := action.SetText(a.Text); err != nil {
 		return nil, err
 	}
@@ -59,10 +68,42 @@ func (a Action) createAction(builder *Builder, menu *walk.Menu) (*walk.Action, e
 		}
 	}
 
+	if f, err := a.Font.Create(); err != nil {
+		return nil, err
+	} else if f != nil {
+		if err := action.SetFont(f); err != nil {
+			return nil, err
+		}
+	}
+
+	if a.TextColor != 0 {
+		if err := action.SetTextColor(a.TextColor); err != nil {
+			return nil, err
+		}
+	}
+
+	if a.MenuBreak != walk.MenuBreakNone {
+		if err := action.SetMenuBreak(a.MenuBreak); err != nil {
+			return nil, err
+		}
+	}
+
+	if a.Default {
+		if err := action.SetDefault(true); err != nil {
+			return nil, err
+		}
+	}
+
 	if a.OnTriggered != nil {
 		action.Triggered().Attach(a.OnTriggered)
 	}
 
+	if a.OnMeasure != nil || a.OnDraw != nil {
+		if err := action.SetOwnerDraw(a.OnMeasure, a.OnDraw); err != nil {
+			return nil, err
+		}
+	}
+
 	if menu != nil {
 		if err := menu.Actions().Add(action); err != nil {
 			return nil, err
@@ -93,9 +134,12 @@ func (ar ActionRef) createAction(builder *Builder, menu *walk.Menu) (*walk.Actio
 type Menu struct {
 	AssignTo       **walk.Menu
 	AssignActionTo **walk.Action
+	Name           string
 	Text           string
 	Image          interface{}
 	Items          []MenuItem
+	OnBeforePopup  walk.EventHandler
+	OnTriggered    walk.EventHandler
 }
 
 func (m Menu) createAction(builder *Builder, menu *walk.Menu) (*walk.Action, error) {
@@ -104,10 +148,9 @@ func (m Menu) createAction(builder *Builder, menu *walk.Menu) (*walk.Action, err
 		return nil, err
 	}
 
-	action, err := menu.Actions().AddMenu(subMenu)
-	if err != nil {
-		return nil, err
-	}
+	action := walk.NewMenuAction(subMenu)
+
+	action.SetName(m.Name)
 
 	if err := action.SetText(m.Text); err != nil {
 		return nil, err
@@ -122,6 +165,21 @@ func (m Menu) createAction(builder *Builder, menu *walk.Menu) (*walk.Action, err
 		}
 	}
 
+	if m.OnBeforePopup != nil {
+		subMenu.BeforePopup().Attach(m.OnBeforePopup)
+	}
+
+	// In a ToolBar, this splits the drop-down button.
+	if m.OnTriggered != nil {
+		action.Triggered().Attach(m.OnTriggered)
+	}
+
+	if menu != nil {
+		if err := menu.Actions().Add(action); err != nil {
+			return nil, err
+		}
+	}
+
 	if m.AssignActionTo != nil {
 		*m.AssignActionTo = action
 	}
@@ -0,0 +1,91 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"time"
+)
+
+import (
+	"github.com/lxn/walk"
+)
+
+type Calendar struct {
+	AssignTo           **walk.Calendar
+	Name               string
+	Enabled            Property
+	Visible            Property
+	Font               Font
+	ToolTipText        Property
+	MinSize            Size
+	MaxSize            Size
+	StretchFactor      int
+	Row                int
+	RowSpan            int
+	Column             int
+	ColumnSpan         int
+	ContextMenuItems   []MenuItem
+	OnKeyDown          walk.KeyEventHandler
+	OnMouseDown        walk.MouseEventHandler
+	OnMouseMove        walk.MouseEventHandler
+	OnMouseUp          walk.MouseEventHandler
+	OnSizeChanged      walk.EventHandler
+	RangeSelection     bool
+	MaxSelectionCount  int
+	MinDate            time.Time
+	MaxDate            time.Time
+	FirstDayOfWeek     *time.Weekday
+	BoldDaysFunc       func(date time.Time) bool
+	Date               Property
+	OnSelectionChanged walk.EventHandler
+}
+
+func (c Calendar) Create(builder *Builder) error {
+	var w *walk.Calendar
+	var err error
+
+	if c.RangeSelection {
+		w, err = walk.NewCalendarWithRangeSelection(builder.Parent())
+	} else {
+		w, err = walk.NewCalendar(builder.Parent())
+	}
+	if err != nil {
+		return err
+	}
+
+	return builder.InitWidget(c, w, func() error {
+		if c.MaxSelectionCount > 0 {
+			if err := w.SetMaxSelectionCount(c.MaxSelectionCount); err != nil {
+				return err
+			}
+		}
+
+		if err := w.SetRange(c.MinDate, c.MaxDate); err != nil {
+			return err
+		}
+
+		if c.FirstDayOfWeek != nil {
+			w.SetFirstDayOfWeek(*c.FirstDayOfWeek)
+		}
+
+		if c.BoldDaysFunc != nil {
+			w.SetBoldDaysFunc(c.BoldDaysFunc)
+		}
+
+		if c.OnSelectionChanged != nil {
+			w.SelectionChanged().Attach(c.OnSelectionChanged)
+		}
+
+		if c.AssignTo != nil {
+			*c.AssignTo = w
+		}
+
+		return nil
+	})
+}
+
+func (w Calendar) WidgetInfo() (name string, disabled, hidden bool, font *Font, toolTipText string, minSize, maxSize Size, stretchFactor, row, rowSpan, column, columnSpan int, contextMenuItems []MenuItem, OnKeyDown walk.KeyEventHandler, OnMouseDown walk.MouseEventHandler, OnMouseMove walk.MouseEventHandler, OnMouseUp walk.MouseEventHandler, OnSizeChanged walk.EventHandler) {
+	return w.Name, false, false, &w.Font, "", w.MinSize, w.MaxSize, w.StretchFactor, w.Row, w.RowSpan, w.Column, w.ColumnSpan, w.ContextMenuItems, w.OnKeyDown, w.OnMouseDown, w.OnMouseMove, w.OnMouseUp, w.OnSizeChanged
+}
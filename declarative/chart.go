@@ -0,0 +1,61 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type Chart struct {
+	AssignTo         **walk.Chart
+	Name             string
+	Enabled          Property
+	Visible          Property
+	Font             Font
+	ToolTipText      Property
+	MinSize          Size
+	MaxSize          Size
+	StretchFactor    int
+	Row              int
+	RowSpan          int
+	Column           int
+	ColumnSpan       int
+	ContextMenuItems []MenuItem
+	OnKeyDown        walk.KeyEventHandler
+	OnMouseDown      walk.MouseEventHandler
+	OnMouseMove      walk.MouseEventHandler
+	OnMouseUp        walk.MouseEventHandler
+	OnSizeChanged    walk.EventHandler
+	Title            string
+	XAxisTitle       string
+	YAxisTitle       string
+	LegendHidden     bool
+	Series           []*walk.ChartSeries
+}
+
+func (c Chart) Create(builder *Builder) error {
+	w, err := walk.NewChart(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	return builder.InitWidget(c, w, func() error {
+		w.SetTitle(c.Title)
+		w.SetXAxisTitle(c.XAxisTitle)
+		w.SetYAxisTitle(c.YAxisTitle)
+		w.SetLegendVisible(!c.LegendHidden)
+		w.SetSeries(c.Series...)
+
+		if c.AssignTo != nil {
+			*c.AssignTo = w
+		}
+
+		return nil
+	})
+}
+
+func (w Chart) WidgetInfo() (name string, disabled, hidden bool, font *Font, toolTipText string, minSize, maxSize Size, stretchFactor, row, rowSpan, column, columnSpan int, contextMenuItems []MenuItem, OnKeyDown walk.KeyEventHandler, OnMouseDown walk.MouseEventHandler, OnMouseMove walk.MouseEventHandler, OnMouseUp walk.MouseEventHandler, OnSizeChanged walk.EventHandler) {
+	return w.Name, false, false, &w.Font, "", w.MinSize, w.MaxSize, w.StretchFactor, w.Row, w.RowSpan, w.Column, w.ColumnSpan, w.ContextMenuItems, w.OnKeyDown, w.OnMouseDown, w.OnMouseMove, w.OnMouseUp, w.OnSizeChanged
+}
@@ -0,0 +1,65 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type CheckableComboBox struct {
+	AssignTo                **walk.CheckableComboBox
+	Name                    string
+	Enabled                 Property
+	Visible                 Property
+	Font                    Font
+	ToolTipText             Property
+	MinSize                 Size
+	MaxSize                 Size
+	StretchFactor           int
+	Row                     int
+	RowSpan                 int
+	Column                  int
+	ColumnSpan              int
+	ContextMenuItems        []MenuItem
+	OnKeyDown               walk.KeyEventHandler
+	OnMouseDown             walk.MouseEventHandler
+	OnMouseMove             walk.MouseEventHandler
+	OnMouseUp               walk.MouseEventHandler
+	OnSizeChanged           walk.EventHandler
+	DisplayMember           string
+	Model                   interface{}
+	CheckedIndexes          Property
+	CheckedTexts            Property
+	OnCheckedIndexesChanged walk.EventHandler
+}
+
+func (ccb CheckableComboBox) Create(builder *Builder) error {
+	w, err := walk.NewCheckableComboBox(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	return builder.InitWidget(ccb, w, func() error {
+		w.SetDisplayMember(ccb.DisplayMember)
+
+		if err := w.SetModel(ccb.Model); err != nil {
+			return err
+		}
+
+		if ccb.OnCheckedIndexesChanged != nil {
+			w.CheckedIndexesChanged().Attach(ccb.OnCheckedIndexesChanged)
+		}
+
+		if ccb.AssignTo != nil {
+			*ccb.AssignTo = w
+		}
+
+		return nil
+	})
+}
+
+func (w CheckableComboBox) WidgetInfo() (name string, disabled, hidden bool, font *Font, toolTipText string, minSize, maxSize Size, stretchFactor, row, rowSpan, column, columnSpan int, contextMenuItems []MenuItem, OnKeyDown walk.KeyEventHandler, OnMouseDown walk.MouseEventHandler, OnMouseMove walk.MouseEventHandler, OnMouseUp walk.MouseEventHandler, OnSizeChanged walk.EventHandler) {
+	return w.Name, false, false, &w.Font, "", w.MinSize, w.MaxSize, w.StretchFactor, w.Row, w.RowSpan, w.Column, w.ColumnSpan, w.ContextMenuItems, w.OnKeyDown, w.OnMouseDown, w.OnMouseMove, w.OnMouseUp, w.OnSizeChanged
+}
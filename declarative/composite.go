@@ -31,6 +31,10 @@ type Composite struct {
 	DataBinder       DataBinder
 	Layout           Layout
 	Children         []Widget
+	FocusScope       bool
+	NavigationGroup  bool
+	DefaultButton    **walk.PushButton
+	CancelButton     **walk.PushButton
 }
 
 func (c Composite) Create(builder *Builder) error {
@@ -46,6 +50,20 @@ func (c Composite) Create(builder *Builder) error {
 	})
 
 	return builder.InitWidget(c, w, func() error {
+		w.SetFocusScope(c.FocusScope)
+		w.SetNavigationGroup(c.NavigationGroup)
+
+		if c.DefaultButton != nil {
+			if err := w.SetDefaultButton(*c.DefaultButton); err != nil {
+				return err
+			}
+		}
+		if c.CancelButton != nil {
+			if err := w.SetCancelButton(*c.CancelButton); err != nil {
+				return err
+			}
+		}
+
 		if c.AssignTo != nil {
 			*c.AssignTo = w
 		}
@@ -33,6 +33,7 @@ type DateEdit struct {
 	OnMouseUp        walk.MouseEventHandler
 	OnSizeChanged    walk.EventHandler
 	NoneOption       bool
+	Format           string
 	MinDate          time.Time
 	MaxDate          time.Time
 	Date             Property
@@ -53,6 +54,12 @@ func (de DateEdit) Create(builder *Builder) error {
 	}
 
 	return builder.InitWidget(de, w, func() error {
+		if de.Format != "" {
+			if err := w.SetFormat(de.Format); err != nil {
+				return err
+			}
+		}
+
 		if err := w.SetRange(de.MinDate, de.MaxDate); err != nil {
 			return err
 		}
@@ -0,0 +1,64 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type DateRangePicker struct {
+	AssignTo         **walk.DateRangePicker
+	Name             string
+	Enabled          Property
+	Visible          Property
+	Font             Font
+	ToolTipText      Property
+	MinSize          Size
+	MaxSize          Size
+	StretchFactor    int
+	Row              int
+	RowSpan          int
+	Column           int
+	ColumnSpan       int
+	ContextMenuItems []MenuItem
+	OnKeyDown        walk.KeyEventHandler
+	OnMouseDown      walk.MouseEventHandler
+	OnMouseMove      walk.MouseEventHandler
+	OnMouseUp        walk.MouseEventHandler
+	OnSizeChanged    walk.EventHandler
+	Presets          []walk.DateRangePreset
+	Start            Property
+	End              Property
+	OnRangeChanged   walk.EventHandler
+}
+
+func (drp DateRangePicker) Create(builder *Builder) error {
+	w, err := walk.NewDateRangePicker(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	return builder.InitWidget(drp, w, func() error {
+		if drp.Presets != nil {
+			if err := w.SetPresets(drp.Presets); err != nil {
+				return err
+			}
+		}
+
+		if drp.OnRangeChanged != nil {
+			w.RangeChanged().Attach(drp.OnRangeChanged)
+		}
+
+		if drp.AssignTo != nil {
+			*drp.AssignTo = w
+		}
+
+		return nil
+	})
+}
+
+func (w DateRangePicker) WidgetInfo() (name string, disabled, hidden bool, font *Font, toolTipText string, minSize, maxSize Size, stretchFactor, row, rowSpan, column, columnSpan int, contextMenuItems []MenuItem, OnKeyDown walk.KeyEventHandler, OnMouseDown walk.MouseEventHandler, OnMouseMove walk.MouseEventHandler, OnMouseUp walk.MouseEventHandler, OnSizeChanged walk.EventHandler) {
+	return w.Name, false, false, &w.Font, "", w.MinSize, w.MaxSize, w.StretchFactor, w.Row, w.RowSpan, w.Column, w.ColumnSpan, w.ContextMenuItems, w.OnKeyDown, w.OnMouseDown, w.OnMouseMove, w.OnMouseUp, w.OnSizeChanged
+}
@@ -29,6 +29,9 @@ type GroupBox struct {
 	OnMouseUp        walk.MouseEventHandler
 	OnSizeChanged    walk.EventHandler
 	Title            string
+	Checkable        bool
+	Checked          Property
+	OnCheckedChanged walk.EventHandler
 	DataBinder       DataBinder
 	Layout           Layout
 	Children         []Widget
@@ -51,6 +54,21 @@ func (gb GroupBox) Create(builder *Builder) error {
 			return err
 		}
 
+		if err := w.SetCheckable(gb.Checkable); err != nil {
+			return err
+		}
+		if gb.Checkable {
+			// Children are created afterwards and must follow the check box.
+			builder.Defer(func() error {
+				w.SetChecked(w.Checked())
+				return nil
+			})
+		}
+
+		if gb.OnCheckedChanged != nil {
+			w.CheckedChanged().Attach(gb.OnCheckedChanged)
+		}
+
 		if gb.AssignTo != nil {
 			*gb.AssignTo = w
 		}
@@ -0,0 +1,63 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type HotkeyEdit struct {
+	AssignTo          **walk.HotkeyEdit
+	Name              string
+	Enabled           Property
+	Visible           Property
+	Font              Font
+	ToolTipText       Property
+	MinSize           Size
+	MaxSize           Size
+	StretchFactor     int
+	Row               int
+	RowSpan           int
+	Column            int
+	ColumnSpan        int
+	ContextMenuItems  []MenuItem
+	OnKeyDown         walk.KeyEventHandler
+	OnMouseDown       walk.MouseEventHandler
+	OnMouseMove       walk.MouseEventHandler
+	OnMouseUp         walk.MouseEventHandler
+	OnSizeChanged     walk.EventHandler
+	UnmodifiedAllowed bool
+	Hotkey            Property
+	OnHotkeyChanged   walk.EventHandler
+	OnHotkeyRejected  walk.ErrorEventHandler
+}
+
+func (he HotkeyEdit) Create(builder *Builder) error {
+	w, err := walk.NewHotkeyEdit(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	return builder.InitWidget(he, w, func() error {
+		w.SetUnmodifiedAllowed(he.UnmodifiedAllowed)
+
+		if he.OnHotkeyChanged != nil {
+			w.HotkeyChanged().Attach(he.OnHotkeyChanged)
+		}
+		if he.OnHotkeyRejected != nil {
+			w.HotkeyRejected().Attach(he.OnHotkeyRejected)
+		}
+
+		if he.AssignTo != nil {
+			*he.AssignTo = w
+		}
+
+		return nil
+	})
+}
+
+func (w HotkeyEdit) WidgetInfo() (name string, disabled, hidden bool, font *Font, toolTipText string, minSize, maxSize Size, stretchFactor, row, rowSpan, column, columnSpan int, contextMenuItems []MenuItem, OnKeyDown walk.KeyEventHandler, OnMouseDown walk.MouseEventHandler, OnMouseMove walk.MouseEventHandler, OnMouseUp walk.MouseEventHandler, OnSizeChanged walk.EventHandler) {
+	return w.Name, false, false, &w.Font, "", w.MinSize, w.MaxSize, w.StretchFactor, w.Row, w.RowSpan, w.Column, w.ColumnSpan, w.ContextMenuItems, w.OnKeyDown, w.OnMouseDown, w.OnMouseMove, w.OnMouseUp, w.OnSizeChanged
+}
@@ -0,0 +1,69 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+// IPAddressFieldRange restricts the values of a field of an IPAddressEdit.
+type IPAddressFieldRange struct {
+	Field    int
+	Min, Max byte
+}
+
+type IPAddressEdit struct {
+	AssignTo         **walk.IPAddressEdit
+	Name             string
+	Enabled          Property
+	Visible          Property
+	Font             Font
+	ToolTipText      Property
+	MinSize          Size
+	MaxSize          Size
+	StretchFactor    int
+	Row              int
+	RowSpan          int
+	Column           int
+	ColumnSpan       int
+	ContextMenuItems []MenuItem
+	OnKeyDown        walk.KeyEventHandler
+	OnMouseDown      walk.MouseEventHandler
+	OnMouseMove      walk.MouseEventHandler
+	OnMouseUp        walk.MouseEventHandler
+	OnSizeChanged    walk.EventHandler
+	FieldRanges      []IPAddressFieldRange
+	Value            Property
+	OnValueChanged   walk.EventHandler
+}
+
+func (ie IPAddressEdit) Create(builder *Builder) error {
+	w, err := walk.NewIPAddressEdit(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	return builder.InitWidget(ie, w, func() error {
+		for _, r := range ie.FieldRanges {
+			if err := w.SetFieldRange(r.Field, r.Min, r.Max); err != nil {
+				return err
+			}
+		}
+
+		if ie.OnValueChanged != nil {
+			w.ValueChanged().Attach(ie.OnValueChanged)
+		}
+
+		if ie.AssignTo != nil {
+			*ie.AssignTo = w
+		}
+
+		return nil
+	})
+}
+
+func (w IPAddressEdit) WidgetInfo() (name string, disabled, hidden bool, font *Font, toolTipText string, minSize, maxSize Size, stretchFactor, row, rowSpan, column, columnSpan int, contextMenuItems []MenuItem, OnKeyDown walk.KeyEventHandler, OnMouseDown walk.MouseEventHandler, OnMouseMove walk.MouseEventHandler, OnMouseUp walk.MouseEventHandler, OnSizeChanged walk.EventHandler) {
+	return w.Name, false, false, &w.Font, "", w.MinSize, w.MaxSize, w.StretchFactor, w.Row, w.RowSpan, w.Column, w.ColumnSpan, w.ContextMenuItems, w.OnKeyDown, w.OnMouseDown, w.OnMouseMove, w.OnMouseUp, w.OnSizeChanged
+}
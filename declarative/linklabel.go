@@ -0,0 +1,56 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type LinkLabel struct {
+	AssignTo         **walk.LinkLabel
+	Name             string
+	Enabled          Property
+	Visible          Property
+	Font             Font
+	ToolTipText      Property
+	MinSize          Size
+	MaxSize          Size
+	StretchFactor    int
+	Row              int
+	RowSpan          int
+	Column           int
+	ColumnSpan       int
+	ContextMenuItems []MenuItem
+	OnKeyDown        walk.KeyEventHandler
+	OnMouseDown      walk.MouseEventHandler
+	OnMouseMove      walk.MouseEventHandler
+	OnMouseUp        walk.MouseEventHandler
+	OnSizeChanged    walk.EventHandler
+	Text             Property
+	OnLinkActivated  walk.LinkLabelLinkEventHandler
+}
+
+func (ll LinkLabel) Create(builder *Builder) error {
+	w, err := walk.NewLinkLabel(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	return builder.InitWidget(ll, w, func() error {
+		if ll.OnLinkActivated != nil {
+			w.LinkActivated().Attach(ll.OnLinkActivated)
+		}
+
+		if ll.AssignTo != nil {
+			*ll.AssignTo = w
+		}
+
+		return nil
+	})
+}
+
+func (w LinkLabel) WidgetInfo() (name string, disabled, hidden bool, font *Font, toolTipText string, minSize, maxSize Size, stretchFactor, row, rowSpan, column, columnSpan int, contextMenuItems []MenuItem, OnKeyDown walk.KeyEventHandler, OnMouseDown walk.MouseEventHandler, OnMouseMove walk.MouseEventHandler, OnMouseUp walk.MouseEventHandler, OnSizeChanged walk.EventHandler) {
+	return w.Name, false, false, &w.Font, "", w.MinSize, w.MaxSize, w.StretchFactor, w.Row, w.RowSpan, w.Column, w.ColumnSpan, w.ContextMenuItems, w.OnKeyDown, w.OnMouseDown, w.OnMouseMove, w.OnMouseUp, w.OnSizeChanged
+}
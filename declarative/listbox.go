@@ -32,6 +32,7 @@ type ListBox struct {
 	Precision             int
 	DataMember            string
 	Model                 interface{}
+	ItemActivationMode    walk.ItemActivationMode
 	OnCurrentIndexChanged walk.EventHandler
 	OnItemActivated       walk.EventHandler
 }
@@ -52,11 +53,15 @@ func (lb ListBox) Create(builder *Builder) error {
 			return err
 		}
 
+		if err := w.SetItemActivationMode(lb.ItemActivationMode); err != nil {
+			return err
+		}
+
 		if lb.OnCurrentIndexChanged != nil {
 			w.CurrentIndexChanged().Attach(lb.OnCurrentIndexChanged)
 		}
 		if lb.OnItemActivated != nil {
-			w.DblClicked().Attach(lb.OnItemActivated)
+			w.ItemActivated().Attach(lb.OnItemActivated)
 		}
 
 		if lb.AssignTo != nil {
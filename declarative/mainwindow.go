@@ -29,6 +29,7 @@ type MainWindow struct {
 	Children         []Widget
 	MenuItems        []MenuItem
 	ToolBarItems     []MenuItem
+	StatusBarItems   []StatusBarItem
 }
 
 func (mw MainWindow) Create() error {
@@ -78,6 +79,12 @@ func (mw MainWindow) Create() error {
 		builder.deferBuildMenuActions(w.Menu(), mw.MenuItems)
 		builder.deferBuildActions(w.ToolBar().Actions(), mw.ToolBarItems)
 
+		for i := range mw.StatusBarItems {
+			if err := mw.StatusBarItems[i].Create(w.StatusBar()); err != nil {
+				return err
+			}
+		}
+
 		builder.Defer(func() error {
 			w.Show()
 
@@ -0,0 +1,66 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type MaskedEdit struct {
+	AssignTo          **walk.MaskedEdit
+	Name              string
+	Enabled           Property
+	Visible           Property
+	Font              Font
+	ToolTipText       Property
+	MinSize           Size
+	MaxSize           Size
+	StretchFactor     int
+	Row               int
+	RowSpan           int
+	Column            int
+	ColumnSpan        int
+	ContextMenuItems  []MenuItem
+	OnKeyDown         walk.KeyEventHandler
+	OnMouseDown       walk.MouseEventHandler
+	OnMouseMove       walk.MouseEventHandler
+	OnMouseUp         walk.MouseEventHandler
+	OnSizeChanged     walk.EventHandler
+	Mask              string
+	Text              Property
+	ReadOnly          Property
+	OnEditingFinished walk.EventHandler
+	OnReturnPressed   walk.EventHandler
+	OnTextChanged     walk.EventHandler
+}
+
+func (me MaskedEdit) Create(builder *Builder) error {
+	w, err := walk.NewMaskedEdit(builder.Parent(), me.Mask)
+	if err != nil {
+		return err
+	}
+
+	return builder.InitWidget(me, w, func() error {
+		if me.OnEditingFinished != nil {
+			w.EditingFinished().Attach(me.OnEditingFinished)
+		}
+		if me.OnReturnPressed != nil {
+			w.ReturnPressed().Attach(me.OnReturnPressed)
+		}
+		if me.OnTextChanged != nil {
+			w.TextChanged().Attach(me.OnTextChanged)
+		}
+
+		if me.AssignTo != nil {
+			*me.AssignTo = w
+		}
+
+		return nil
+	})
+}
+
+func (w MaskedEdit) WidgetInfo() (name string, disabled, hidden bool, font *Font, toolTipText string, minSize, maxSize Size, stretchFactor, row, rowSpan, column, columnSpan int, contextMenuItems []MenuItem, OnKeyDown walk.KeyEventHandler, OnMouseDown walk.MouseEventHandler, OnMouseMove walk.MouseEventHandler, OnMouseUp walk.MouseEventHandler, OnSizeChanged walk.EventHandler) {
+	return w.Name, false, false, &w.Font, "", w.MinSize, w.MaxSize, w.StretchFactor, w.Row, w.RowSpan, w.Column, w.ColumnSpan, w.ContextMenuItems, w.OnKeyDown, w.OnMouseDown, w.OnMouseMove, w.OnMouseUp, w.OnSizeChanged
+}
@@ -30,7 +30,10 @@ type ProgressBar struct {
 	OnSizeChanged    walk.EventHandler
 	MinValue         int
 	MaxValue         int
-	Value            int
+	MarqueeMode      bool
+	State            walk.ProgressBarState
+	Value            Property
+	OnValueChanged   walk.EventHandler
 }
 
 func (pb ProgressBar) Create(builder *Builder) error {
@@ -41,7 +44,20 @@ func (pb ProgressBar) Create(builder *Builder) error {
 
 	return builder.InitWidget(pb, w, func() error {
 		w.SetRange(pb.MinValue, pb.MaxValue)
-		w.SetValue(pb.Value)
+
+		if err := w.SetMarqueeMode(pb.MarqueeMode); err != nil {
+			return err
+		}
+
+		if pb.State != 0 {
+			if err := w.SetState(pb.State); err != nil {
+				return err
+			}
+		}
+
+		if pb.OnValueChanged != nil {
+			w.ValueChanged().Attach(pb.OnValueChanged)
+		}
 
 		if pb.AssignTo != nil {
 			*pb.AssignTo = w
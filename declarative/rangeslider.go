@@ -0,0 +1,70 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type RangeSlider struct {
+	AssignTo         **walk.RangeSlider
+	Name             string
+	Enabled          Property
+	Visible          Property
+	Font             Font
+	ToolTipText      Property
+	MinSize          Size
+	MaxSize          Size
+	StretchFactor    int
+	Row              int
+	RowSpan          int
+	Column           int
+	ColumnSpan       int
+	ContextMenuItems []MenuItem
+	OnKeyDown        walk.KeyEventHandler
+	OnMouseDown      walk.MouseEventHandler
+	OnMouseMove      walk.MouseEventHandler
+	OnMouseUp        walk.MouseEventHandler
+	OnSizeChanged    walk.EventHandler
+	MinValue         int
+	MaxValue         int
+	PageSize         int
+	Low              Property
+	High             Property
+	OnRangeChanged   walk.EventHandler
+}
+
+func (rs RangeSlider) Create(builder *Builder) error {
+	w, err := walk.NewRangeSlider(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	return builder.InitWidget(rs, w, func() error {
+		if rs.MinValue != 0 || rs.MaxValue != 0 {
+			if err := w.SetRange(rs.MinValue, rs.MaxValue); err != nil {
+				return err
+			}
+		}
+
+		if rs.PageSize > 0 {
+			w.SetPageSize(rs.PageSize)
+		}
+
+		if rs.OnRangeChanged != nil {
+			w.RangeChanged().Attach(rs.OnRangeChanged)
+		}
+
+		if rs.AssignTo != nil {
+			*rs.AssignTo = w
+		}
+
+		return nil
+	})
+}
+
+func (w RangeSlider) WidgetInfo() (name string, disabled, hidden bool, font *Font, toolTipText string, minSize, maxSize Size, stretchFactor, row, rowSpan, column, columnSpan int, contextMenuItems []MenuItem, OnKeyDown walk.KeyEventHandler, OnMouseDown walk.MouseEventHandler, OnMouseMove walk.MouseEventHandler, OnMouseUp walk.MouseEventHandler, OnSizeChanged walk.EventHandler) {
+	return w.Name, false, false, &w.Font, "", w.MinSize, w.MaxSize, w.StretchFactor, w.Row, w.RowSpan, w.Column, w.ColumnSpan, w.ContextMenuItems, w.OnKeyDown, w.OnMouseDown, w.OnMouseMove, w.OnMouseUp, w.OnSizeChanged
+}
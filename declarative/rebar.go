@@ -0,0 +1,66 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type Rebar struct {
+	AssignTo         **walk.Rebar
+	Name             string
+	Enabled          Property
+	Visible          Property
+	Font             Font
+	ToolTipText      Property
+	MinSize          Size
+	MaxSize          Size
+	StretchFactor    int
+	Row              int
+	RowSpan          int
+	Column           int
+	ColumnSpan       int
+	ContextMenuItems []MenuItem
+	OnKeyDown        walk.KeyEventHandler
+	OnMouseDown      walk.MouseEventHandler
+	OnMouseMove      walk.MouseEventHandler
+	OnMouseUp        walk.MouseEventHandler
+	OnSizeChanged    walk.EventHandler
+	Children         []Widget
+	BandTitles       []string
+}
+
+func (rb Rebar) Create(builder *Builder) error {
+	w, err := walk.NewRebar(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	return builder.InitWidget(rb, w, func() error {
+		for i, title := range rb.BandTitles {
+			if i == w.Children().Len() {
+				break
+			}
+
+			if err := w.SetBandTitle(w.Children().At(i), title); err != nil {
+				return err
+			}
+		}
+
+		if rb.AssignTo != nil {
+			*rb.AssignTo = w
+		}
+
+		return nil
+	})
+}
+
+func (w Rebar) WidgetInfo() (name string, disabled, hidden bool, font *Font, toolTipText string, minSize, maxSize Size, stretchFactor, row, rowSpan, column, columnSpan int, contextMenuItems []MenuItem, OnKeyDown walk.KeyEventHandler, OnMouseDown walk.MouseEventHandler, OnMouseMove walk.MouseEventHandler, OnMouseUp walk.MouseEventHandler, OnSizeChanged walk.EventHandler) {
+	return w.Name, false, false, &w.Font, "", w.MinSize, w.MaxSize, w.StretchFactor, w.Row, w.RowSpan, w.Column, w.ColumnSpan, w.ContextMenuItems, w.OnKeyDown, w.OnMouseDown, w.OnMouseMove, w.OnMouseUp, w.OnSizeChanged
+}
+
+func (rb Rebar) ContainerInfo() (DataBinder, Layout, []Widget) {
+	return DataBinder{}, nil, rb.Children
+}
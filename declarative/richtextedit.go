@@ -0,0 +1,66 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type RichTextEdit struct {
+	AssignTo         **walk.RichTextEdit
+	Name             string
+	Enabled          Property
+	Visible          Property
+	Font             Font
+	ToolTipText      Property
+	MinSize          Size
+	MaxSize          Size
+	StretchFactor    int
+	Row              int
+	RowSpan          int
+	Column           int
+	ColumnSpan       int
+	ContextMenuItems []MenuItem
+	OnKeyDown        walk.KeyEventHandler
+	OnMouseDown      walk.MouseEventHandler
+	OnMouseMove      walk.MouseEventHandler
+	OnMouseUp        walk.MouseEventHandler
+	OnSizeChanged    walk.EventHandler
+	AutoURLDetection bool
+	ReadOnly         Property
+	Text             Property
+	OnTextChanged    walk.EventHandler
+	OnLinkClicked    walk.StringEventHandler
+}
+
+func (rte RichTextEdit) Create(builder *Builder) error {
+	w, err := walk.NewRichTextEdit(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	return builder.InitWidget(rte, w, func() error {
+		if err := w.SetAutoURLDetection(rte.AutoURLDetection); err != nil {
+			return err
+		}
+
+		if rte.OnTextChanged != nil {
+			w.TextChanged().Attach(rte.OnTextChanged)
+		}
+		if rte.OnLinkClicked != nil {
+			w.LinkClicked().Attach(rte.OnLinkClicked)
+		}
+
+		if rte.AssignTo != nil {
+			*rte.AssignTo = w
+		}
+
+		return nil
+	})
+}
+
+func (w RichTextEdit) WidgetInfo() (name string, disabled, hidden bool, font *Font, toolTipText string, minSize, maxSize Size, stretchFactor, row, rowSpan, column, columnSpan int, contextMenuItems []MenuItem, OnKeyDown walk.KeyEventHandler, OnMouseDown walk.MouseEventHandler, OnMouseMove walk.MouseEventHandler, OnMouseUp walk.MouseEventHandler, OnSizeChanged walk.EventHandler) {
+	return w.Name, false, false, &w.Font, "", w.MinSize, w.MaxSize, w.StretchFactor, w.Row, w.RowSpan, w.Column, w.ColumnSpan, w.ContextMenuItems, w.OnKeyDown, w.OnMouseDown, w.OnMouseMove, w.OnMouseUp, w.OnSizeChanged
+}
@@ -0,0 +1,71 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type SearchBox struct {
+	AssignTo            **walk.SearchBox
+	Name                string
+	Enabled             Property
+	Visible             Property
+	Font                Font
+	ToolTipText         Property
+	MinSize             Size
+	MaxSize             Size
+	StretchFactor       int
+	Row                 int
+	RowSpan             int
+	Column              int
+	ColumnSpan          int
+	ContextMenuItems    []MenuItem
+	OnKeyDown           walk.KeyEventHandler
+	OnMouseDown         walk.MouseEventHandler
+	OnMouseMove         walk.MouseEventHandler
+	OnMouseUp           walk.MouseEventHandler
+	OnSizeChanged       walk.EventHandler
+	Text                Property
+	ReadOnly            Property
+	CueBanner           string
+	SearchDelay         int
+	OnSearchTextChanged walk.StringEventHandler
+}
+
+func (sb SearchBox) Create(builder *Builder) error {
+	w, err := walk.NewSearchBox(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	return builder.InitWidget(sb, w, func() error {
+		if sb.CueBanner != "" {
+			if err := w.SetCueBanner(sb.CueBanner); err != nil {
+				return err
+			}
+		}
+
+		if sb.SearchDelay > 0 {
+			if err := w.SetSearchDelay(sb.SearchDelay); err != nil {
+				return err
+			}
+		}
+
+		if sb.OnSearchTextChanged != nil {
+			w.SearchTextChanged().Attach(sb.OnSearchTextChanged)
+		}
+
+		if sb.AssignTo != nil {
+			*sb.AssignTo = w
+		}
+
+		return nil
+	})
+}
+
+func (w SearchBox) WidgetInfo() (name string, disabled, hidden bool, font *Font, toolTipText string, minSize, maxSize Size, stretchFactor, row, rowSpan, column, columnSpan int, contextMenuItems []MenuItem, OnKeyDown walk.KeyEventHandler, OnMouseDown walk.MouseEventHandler, OnMouseMove walk.MouseEventHandler, OnMouseUp walk.MouseEventHandler, OnSizeChanged walk.EventHandler) {
+	return w.Name, false, false, &w.Font, "", w.MinSize, w.MaxSize, w.StretchFactor, w.Row, w.RowSpan, w.Column, w.ColumnSpan, w.ContextMenuItems, w.OnKeyDown, w.OnMouseDown, w.OnMouseMove, w.OnMouseUp, w.OnSizeChanged
+}
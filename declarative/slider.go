@@ -0,0 +1,88 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type Slider struct {
+	AssignTo         **walk.Slider
+	Name             string
+	Enabled          Property
+	Visible          Property
+	Font             Font
+	ToolTipText      Property
+	MinSize          Size
+	MaxSize          Size
+	StretchFactor    int
+	Row              int
+	RowSpan          int
+	Column           int
+	ColumnSpan       int
+	ContextMenuItems []MenuItem
+	OnKeyDown        walk.KeyEventHandler
+	OnMouseDown      walk.MouseEventHandler
+	OnMouseMove      walk.MouseEventHandler
+	OnMouseUp        walk.MouseEventHandler
+	OnSizeChanged    walk.EventHandler
+	Orientation      Orientation
+	MinValue         int
+	MaxValue         int
+	PageSize         int
+	LineSize         int
+	TickFrequency    int
+	TicksHidden      bool
+	ToolTipsHidden   bool
+	Value            Property
+	OnValueChanged   walk.EventHandler
+}
+
+func (s Slider) Create(builder *Builder) error {
+	w, err := walk.NewSliderWithOrientation(builder.Parent(), walk.Orientation(s.Orientation))
+	if err != nil {
+		return err
+	}
+
+	return builder.InitWidget(s, w, func() error {
+		if s.MinValue != 0 || s.MaxValue != 0 {
+			if err := w.SetRange(s.MinValue, s.MaxValue); err != nil {
+				return err
+			}
+		}
+
+		if s.PageSize > 0 {
+			w.SetPageSize(s.PageSize)
+		}
+		if s.LineSize > 0 {
+			w.SetLineSize(s.LineSize)
+		}
+
+		if s.TickFrequency > 0 {
+			if err := w.SetTickFrequency(s.TickFrequency); err != nil {
+				return err
+			}
+		}
+		if err := w.SetTicksVisible(!s.TicksHidden); err != nil {
+			return err
+		}
+
+		w.SetToolTipsShown(!s.ToolTipsHidden)
+
+		if s.OnValueChanged != nil {
+			w.ValueChanged().Attach(s.OnValueChanged)
+		}
+
+		if s.AssignTo != nil {
+			*s.AssignTo = w
+		}
+
+		return nil
+	})
+}
+
+func (w Slider) WidgetInfo() (name string, disabled, hidden bool, font *Font, toolTipText string, minSize, maxSize Size, stretchFactor, row, rowSpan, column, columnSpan int, contextMenuItems []MenuItem, OnKeyDown walk.KeyEventHandler, OnMouseDown walk.MouseEventHandler, OnMouseMove walk.MouseEventHandler, OnMouseUp walk.MouseEventHandler, OnSizeChanged walk.EventHandler) {
+	return w.Name, false, false, &w.Font, "", w.MinSize, w.MaxSize, w.StretchFactor, w.Row, w.RowSpan, w.Column, w.ColumnSpan, w.ContextMenuItems, w.OnKeyDown, w.OnMouseDown, w.OnMouseMove, w.OnMouseUp, w.OnSizeChanged
+}
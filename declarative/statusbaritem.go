@@ -0,0 +1,65 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type StatusBarItem struct {
+	AssignTo        **walk.StatusBarItem
+	Icon            *walk.Icon
+	Text            string
+	ToolTipText     string
+	Width           int
+	ProgressVisible bool
+	OnClicked       walk.EventHandler
+	OnDoubleClicked walk.EventHandler
+	OnDraw          walk.StatusBarItemDrawFunc
+}
+
+func (sbi StatusBarItem) Create(sb *walk.StatusBar) error {
+	w := walk.NewStatusBarItem()
+
+	if err := w.SetIcon(sbi.Icon); err != nil {
+		return err
+	}
+	if err := w.SetText(sbi.Text); err != nil {
+		return err
+	}
+	if err := w.SetToolTipText(sbi.ToolTipText); err != nil {
+		return err
+	}
+	if err := w.SetWidth(sbi.Width); err != nil {
+		return err
+	}
+
+	if sbi.OnDraw != nil {
+		if err := w.SetOwnerDraw(sbi.OnDraw); err != nil {
+			return err
+		}
+	}
+
+	if sbi.OnClicked != nil {
+		w.Clicked().Attach(sbi.OnClicked)
+	}
+	if sbi.OnDoubleClicked != nil {
+		w.DoubleClicked().Attach(sbi.OnDoubleClicked)
+	}
+
+	if err := sb.Items().Add(w); err != nil {
+		return err
+	}
+
+	if err := w.SetProgressVisible(sbi.ProgressVisible); err != nil {
+		return err
+	}
+
+	if sbi.AssignTo != nil {
+		*sbi.AssignTo = w
+	}
+
+	return nil
+}
@@ -36,10 +36,13 @@ type TableView struct {
 	LastColumnStretched        bool
 	ColumnsOrderable           Property
 	ColumnsSizable             Property
+	ColumnChooserEnabled       bool
 	SingleItemSelection        bool
+	ItemActivationMode         walk.ItemActivationMode
 	OnCurrentIndexChanged      walk.EventHandler
 	OnSelectedIndexesChanged   walk.EventHandler
 	OnItemActivated            walk.EventHandler
+	OnPasteFailed              walk.ErrorEventHandler
 }
 
 func (tv TableView) Create(builder *Builder) error {
@@ -63,6 +66,7 @@ func (tv TableView) Create(builder *Builder) error {
 			w.SetAlternatingRowBGColor(tv.AlternatingRowBGColor)
 		}
 		w.SetCheckBoxes(tv.CheckBoxes)
+		w.SetColumnChooserEnabled(tv.ColumnChooserEnabled)
 		w.SetItemStateChangedEventDelay(tv.ItemStateChangedEventDelay)
 		if err := w.SetLastColumnStretched(tv.LastColumnStretched); err != nil {
 			return err
@@ -70,6 +74,9 @@ func (tv TableView) Create(builder *Builder) error {
 		if err := w.SetSingleItemSelection(tv.SingleItemSelection); err != nil {
 			return err
 		}
+		if err := w.SetItemActivationMode(tv.ItemActivationMode); err != nil {
+			return err
+		}
 
 		if tv.OnCurrentIndexChanged != nil {
 			w.CurrentIndexChanged().Attach(tv.OnCurrentIndexChanged)
@@ -80,6 +87,9 @@ func (tv TableView) Create(builder *Builder) error {
 		if tv.OnItemActivated != nil {
 			w.ItemActivated().Attach(tv.OnItemActivated)
 		}
+		if tv.OnPasteFailed != nil {
+			w.PasteFailed().Attach(tv.OnPasteFailed)
+		}
 
 		if tv.AssignTo != nil {
 			*tv.AssignTo = w
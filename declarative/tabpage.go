@@ -27,6 +27,8 @@ type TabPage struct {
 	Layout           Layout
 	Children         []Widget
 	Title            Property
+	Image            walk.Image
+	NotClosable      bool
 	Content          Widget
 }
 
@@ -37,6 +39,11 @@ func (tp TabPage) Create(builder *Builder) error {
 	}
 
 	return builder.InitWidget(tp, w, func() error {
+		if err := w.SetImage(tp.Image); err != nil {
+			return err
+		}
+		w.SetClosable(!tp.NotClosable)
+
 		if tp.Content != nil && len(tp.Children) == 0 {
 			if err := tp.Content.Create(builder); err != nil {
 				return err
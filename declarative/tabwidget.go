@@ -31,7 +31,11 @@ type TabWidget struct {
 	ContentMargins        Margins
 	ContentMarginsZero    bool
 	Pages                 []TabPage
+	TabsClosable          bool
+	NewTabButtonVisible   bool
 	OnCurrentIndexChanged walk.EventHandler
+	OnPageClosing         walk.TabPageClosingEventHandler
+	OnNewTabRequested     walk.EventHandler
 }
 
 func (tw TabWidget) Create(builder *Builder) error {
@@ -60,9 +64,20 @@ func (tw TabWidget) Create(builder *Builder) error {
 			}
 		}
 
+		w.SetTabsClosable(tw.TabsClosable)
+		if err := w.SetNewTabButtonVisible(tw.NewTabButtonVisible); err != nil {
+			return err
+		}
+
 		if tw.OnCurrentIndexChanged != nil {
 			w.CurrentIndexChanged().Attach(tw.OnCurrentIndexChanged)
 		}
+		if tw.OnPageClosing != nil {
+			w.PageClosing().Attach(tw.OnPageClosing)
+		}
+		if tw.OnNewTabRequested != nil {
+			w.NewTabRequested().Attach(tw.OnNewTabRequested)
+		}
 
 		if tw.AssignTo != nil {
 			*tw.AssignTo = w
@@ -0,0 +1,74 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"github.com/lxn/walk"
+)
+
+type TagEdit struct {
+	AssignTo         **walk.TagEdit
+	Name             string
+	Enabled          Property
+	Visible          Property
+	Font             Font
+	ToolTipText      Property
+	MinSize          Size
+	MaxSize          Size
+	StretchFactor    int
+	Row              int
+	RowSpan          int
+	Column           int
+	ColumnSpan       int
+	ContextMenuItems []MenuItem
+	OnKeyDown        walk.KeyEventHandler
+	OnMouseDown      walk.MouseEventHandler
+	OnMouseMove      walk.MouseEventHandler
+	OnMouseUp        walk.MouseEventHandler
+	OnSizeChanged    walk.EventHandler
+	CompletionModel  walk.ListModel
+	Validator        Validator
+	Tags             Property
+	OnTagsChanged    walk.EventHandler
+	OnTagRejected    walk.ErrorEventHandler
+}
+
+func (te TagEdit) Create(builder *Builder) error {
+	w, err := walk.NewTagEdit(builder.Parent())
+	if err != nil {
+		return err
+	}
+
+	return builder.InitWidget(te, w, func() error {
+		w.SetCompletionModel(te.CompletionModel)
+
+		if te.Validator != nil {
+			validator, err := te.Validator.Create()
+			if err != nil {
+				return err
+			}
+
+			w.SetValidator(validator)
+		}
+
+		if te.OnTagsChanged != nil {
+			w.TagsChanged().Attach(te.OnTagsChanged)
+		}
+
+		if te.OnTagRejected != nil {
+			w.TagRejected().Attach(te.OnTagRejected)
+		}
+
+		if te.AssignTo != nil {
+			*te.AssignTo = w
+		}
+
+		return nil
+	})
+}
+
+func (w TagEdit) WidgetInfo() (name string, disabled, hidden bool, font *Font, toolTipText string, minSize, maxSize Size, stretchFactor, row, rowSpan, column, columnSpan int, contextMenuItems []MenuItem, OnKeyDown walk.KeyEventHandler, OnMouseDown walk.MouseEventHandler, OnMouseMove walk.MouseEventHandler, OnMouseUp walk.MouseEventHandler, OnSizeChanged walk.EventHandler) {
+	return w.Name, false, false, &w.Font, "", w.MinSize, w.MaxSize, w.StretchFactor, w.Row, w.RowSpan, w.Column, w.ColumnSpan, w.ContextMenuItems, w.OnKeyDown, w.OnMouseDown, w.OnMouseMove, w.OnMouseUp, w.OnSizeChanged
+}
@@ -30,6 +30,10 @@ type TextEdit struct {
 	OnSizeChanged    walk.EventHandler
 	Text             Property
 	ReadOnly         Property
+	TabWidth         int
+	AcceptsTab       bool
+	TabsToSpaces     bool
+	ShowWhitespace   bool
 }
 
 func (te TextEdit) Create(builder *Builder) error {
@@ -39,6 +43,16 @@ func (te TextEdit) Create(builder *Builder) error {
 	}
 
 	return builder.InitWidget(te, w, func() error {
+		if te.TabWidth > 0 {
+			if err := w.SetTabWidth(te.TabWidth); err != nil {
+				return err
+			}
+		}
+
+		w.SetAcceptsTab(te.AcceptsTab)
+		w.SetConvertsTabsToSpaces(te.TabsToSpaces)
+		w.SetShowsWhitespace(te.ShowWhitespace)
+
 		if te.AssignTo != nil {
 			*te.AssignTo = w
 		}
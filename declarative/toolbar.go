@@ -29,8 +29,11 @@ type ToolBar struct {
 	OnMouseUp        walk.MouseEventHandler
 	OnSizeChanged    walk.EventHandler
 	Actions          []*walk.Action
+	Items            []MenuItem
 	MaxTextRows      int
 	Orientation      Orientation
+	ButtonStyle      walk.ToolBarButtonStyle
+	ButtonSize       Size
 }
 
 func (tb ToolBar) Create(builder *Builder) (err error) {
@@ -59,10 +62,22 @@ func (tb ToolBar) Create(builder *Builder) (err error) {
 			return err
 		}
 
+		if err := w.SetButtonStyle(tb.ButtonStyle); err != nil {
+			return err
+		}
+
+		if tb.ButtonSize.Width > 0 && tb.ButtonSize.Height > 0 {
+			if err := w.SetButtonSize(tb.ButtonSize.toW()); err != nil {
+				return err
+			}
+		}
+
 		if err := addToActionList(w.Actions(), tb.Actions); err != nil {
 			return err
 		}
 
+		builder.deferBuildActions(w.Actions(), tb.Items)
+
 		if tb.AssignTo != nil {
 			*tb.AssignTo = w
 		}
@@ -29,7 +29,9 @@ type TreeView struct {
 	OnMouseUp            walk.MouseEventHandler
 	OnSizeChanged        walk.EventHandler
 	Model                walk.TreeModel
+	ItemActivationMode   walk.ItemActivationMode
 	OnCurrentItemChanged walk.EventHandler
+	OnItemActivated      walk.EventHandler
 	OnItemCollapsed      walk.TreeItemEventHandler
 	OnItemExpanded       walk.TreeItemEventHandler
 }
@@ -45,10 +47,18 @@ func (tv TreeView) Create(builder *Builder) error {
 			return err
 		}
 
+		if err := w.SetItemActivationMode(tv.ItemActivationMode); err != nil {
+			return err
+		}
+
 		if tv.OnCurrentItemChanged != nil {
 			w.CurrentItemChanged().Attach(tv.OnCurrentItemChanged)
 		}
 
+		if tv.OnItemActivated != nil {
+			w.ItemActivated().Attach(tv.OnItemActivated)
+		}
+
 		if tv.OnItemCollapsed != nil {
 			w.ItemCollapsed().Attach(tv.OnItemCollapsed)
 		}
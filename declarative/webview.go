@@ -9,27 +9,33 @@ import (
 )
 
 type WebView struct {
-	AssignTo         **walk.WebView
-	Name             string
-	Enabled          Property
-	Visible          Property
-	Font             Font
-	ToolTipText      Property
-	MinSize          Size
-	MaxSize          Size
-	StretchFactor    int
-	Row              int
-	RowSpan          int
-	Column           int
-	ColumnSpan       int
-	ContextMenuItems []MenuItem
-	OnKeyDown        walk.KeyEventHandler
-	OnMouseDown      walk.MouseEventHandler
-	OnMouseMove      walk.MouseEventHandler
-	OnMouseUp        walk.MouseEventHandler
-	OnSizeChanged    walk.EventHandler
-	URL              Property
-	OnURLChanged     walk.EventHandler
+	AssignTo              **walk.WebView
+	Name                  string
+	Enabled               Property
+	Visible               Property
+	Font                  Font
+	ToolTipText           Property
+	MinSize               Size
+	MaxSize               Size
+	StretchFactor         int
+	Row                   int
+	RowSpan               int
+	Column                int
+	ColumnSpan            int
+	ContextMenuItems      []MenuItem
+	OnKeyDown             walk.KeyEventHandler
+	OnMouseDown           walk.MouseEventHandler
+	OnMouseMove           walk.MouseEventHandler
+	OnMouseUp             walk.MouseEventHandler
+	OnSizeChanged         walk.EventHandler
+	URL                   Property
+	HTML                  string
+	OnURLChanged          walk.EventHandler
+	OnNavigating          walk.WebViewNavigatingEventHandler
+	OnNavigated           walk.StringEventHandler
+	OnDocumentCompleted   walk.StringEventHandler
+	OnCanGoBackChanged    walk.EventHandler
+	OnCanGoForwardChanged walk.EventHandler
 }
 
 func (wv WebView) Create(builder *Builder) error {
@@ -39,9 +45,30 @@ func (wv WebView) Create(builder *Builder) error {
 	}
 
 	return builder.InitWidget(wv, w, func() error {
+		if wv.HTML != "" {
+			if err := w.SetHTML(wv.HTML); err != nil {
+				return err
+			}
+		}
+
 		if wv.OnURLChanged != nil {
 			w.URLChanged().Attach(wv.OnURLChanged)
 		}
+		if wv.OnNavigating != nil {
+			w.Navigating().Attach(wv.OnNavigating)
+		}
+		if wv.OnNavigated != nil {
+			w.Navigated().Attach(wv.OnNavigated)
+		}
+		if wv.OnDocumentCompleted != nil {
+			w.DocumentCompleted().Attach(wv.OnDocumentCompleted)
+		}
+		if wv.OnCanGoBackChanged != nil {
+			w.CanGoBackChanged().Attach(wv.OnCanGoBackChanged)
+		}
+		if wv.OnCanGoForwardChanged != nil {
+			w.CanGoForwardChanged().Attach(wv.OnCanGoForwardChanged)
+		}
 
 		if wv.AssignTo != nil {
 			*wv.AssignTo = w
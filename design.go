@@ -0,0 +1,261 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"reflect"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const designAdornerSize = 5
+
+// WidgetDesignInfo describes a Widget and its descendants, so that GUI
+// designer tools can serialize the UI hierarchy.
+type WidgetDesignInfo struct {
+	Type     string
+	Name     string
+	Bounds   Rectangle
+	Children []WidgetDesignInfo
+}
+
+// NewWidgetDesignInfo returns a *WidgetDesignInfo for widget and all of its
+// descendants.
+func NewWidgetDesignInfo(widget Widget) *WidgetDesignInfo {
+	info := &WidgetDesignInfo{
+		Type:   reflect.Indirect(reflect.ValueOf(widget)).Type().Name(),
+		Name:   widget.Name(),
+		Bounds: widget.Bounds(),
+	}
+
+	if container, ok := widget.(Container); ok {
+		if children := container.Children(); children != nil {
+			for _, child := range children.items {
+				info.Children = append(info.Children, *NewWidgetDesignInfo(child))
+			}
+		}
+	}
+
+	return info
+}
+
+// DesignMode returns if the *WidgetBase is in design mode.
+func (wb *WidgetBase) DesignMode() bool {
+	return wb.designMode
+}
+
+// SetDesignMode sets if the *WidgetBase and its descendants are in design mode.
+//
+// In design mode, widgets do not react to user input. Mouse and key events are
+// still published, so designer tools can implement selection and dragging,
+// but native controls never see the input and notifications like Clicked are
+// not raised.
+func (wb *WidgetBase) SetDesignMode(value bool) {
+	walkDescendants(wb.widget, func(w Widget) bool {
+		w.BaseWidget().designMode = value
+		w.Invalidate()
+
+		return true
+	})
+}
+
+// DesignSelected returns if the *WidgetBase is selected in design mode.
+func (wb *WidgetBase) DesignSelected() bool {
+	return wb.designSelected
+}
+
+// SetDesignSelected sets if the *WidgetBase is selected in design mode.
+//
+// The parent of a selected widget draws selection adorners around it.
+func (wb *WidgetBase) SetDesignSelected(value bool) {
+	if value == wb.designSelected {
+		return
+	}
+
+	wb.designSelected = value
+
+	if wb.parent != nil {
+		wb.parent.Invalidate()
+	}
+}
+
+func (wb *WidgetBase) designModeWndProc(msg uint32, wParam, lParam uintptr) (result uintptr, handled bool) {
+	switch msg {
+	case WM_LBUTTONDOWN, WM_MBUTTONDOWN, WM_RBUTTONDOWN:
+		if msg == WM_LBUTTONDOWN {
+			SetCapture(wb.hWnd)
+		}
+		wb.publishMouseEvent(&wb.mouseDownPublisher, wParam, lParam)
+
+	case WM_LBUTTONUP, WM_MBUTTONUP, WM_RBUTTONUP:
+		if msg == WM_LBUTTONUP {
+			ReleaseCapture()
+		}
+		wb.publishMouseEvent(&wb.mouseUpPublisher, wParam, lParam)
+
+	case WM_MOUSEMOVE:
+		wb.publishMouseEvent(&wb.mouseMovePublisher, wParam, lParam)
+
+	case WM_LBUTTONDBLCLK, WM_MBUTTONDBLCLK, WM_RBUTTONDBLCLK, WM_MOUSEWHEEL:
+
+	case WM_KEYDOWN:
+		wb.keyDownPublisher.Publish(int(wParam))
+
+	case WM_KEYUP, WM_CHAR, WM_SYSCHAR:
+
+	case WM_COMMAND, WM_NOTIFY:
+		// Swallow notifications, so no Clicked etc. events get raised.
+
+	case WM_NCHITTEST:
+		if wb.parent == nil {
+			// Top-level windows keep their caption and sizing borders.
+			return 0, false
+		}
+
+		// Make sure e.g. labels do not pass the mouse on to their parent.
+		return HTCLIENT, true
+
+	case WM_SETCURSOR:
+		if LOWORD(uint32(lParam)) != HTCLIENT {
+			return 0, false
+		}
+
+		SetCursor(CursorArrow().handle())
+		return 1, true
+
+	default:
+		return 0, false
+	}
+
+	return 0, true
+}
+
+// DesignGridSize returns the spacing of the grid drawn in design mode.
+func (cb *ContainerBase) DesignGridSize() int {
+	return cb.designGridSize
+}
+
+// SetDesignGridSize sets the spacing of the grid drawn in design mode.
+//
+// A value of 0 disables the grid.
+func (cb *ContainerBase) SetDesignGridSize(value int) error {
+	if value < 0 {
+		return newError("value must be >= 0")
+	}
+
+	cb.designGridSize = value
+
+	if cb.designMode {
+		cb.Invalidate()
+	}
+
+	return nil
+}
+
+// DesignHitTest returns the innermost descendant of the *ContainerBase at p,
+// which is relative to the client area of the *ContainerBase, or nil if there
+// is none.
+func (cb *ContainerBase) DesignHitTest(p Point) Widget {
+	pt := POINT{int32(p.X), int32(p.Y)}
+	if !ClientToScreen(cb.hWnd, &pt) {
+		newAPIError("ClientToScreen")
+		return nil
+	}
+
+	return designHitTest(cb.widget.(Container), pt)
+}
+
+func designHitTest(container Container, screenPt POINT) Widget {
+	children := container.Children()
+	if children == nil {
+		return nil
+	}
+
+	for i := len(children.items) - 1; i >= 0; i-- {
+		child := children.items[i]
+		if !child.Visible() {
+			continue
+		}
+
+		var r RECT
+		if !GetWindowRect(child.Handle(), &r) {
+			lastError("GetWindowRect")
+			continue
+		}
+
+		if screenPt.X < r.Left || screenPt.X >= r.Right || screenPt.Y < r.Top || screenPt.Y >= r.Bottom {
+			continue
+		}
+
+		if c, ok := child.(Container); ok {
+			if hit := designHitTest(c, screenPt); hit != nil {
+				return hit
+			}
+		}
+
+		return child
+	}
+
+	return nil
+}
+
+func (cb *ContainerBase) paintDesignAdorners(canvas *Canvas) error {
+	bounds := cb.ClientBounds()
+
+	if cb.designGridSize > 0 {
+		pen, err := NewCosmeticPen(PenDot, RGB(192, 192, 192))
+		if err != nil {
+			return err
+		}
+		defer pen.Dispose()
+
+		for x := bounds.X; x < bounds.X+bounds.Width; x += cb.designGridSize {
+			if err := canvas.DrawLine(pen, Point{x, bounds.Y}, Point{x, bounds.Y + bounds.Height}); err != nil {
+				return err
+			}
+		}
+		for y := bounds.Y; y < bounds.Y+bounds.Height; y += cb.designGridSize {
+			if err := canvas.DrawLine(pen, Point{bounds.X, y}, Point{bounds.X + bounds.Width, y}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cb.children == nil {
+		return nil
+	}
+
+	brush, err := NewSolidColorBrush(RGB(0, 0, 0))
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	for _, child := range cb.children.items {
+		if !child.BaseWidget().designSelected {
+			continue
+		}
+
+		b := child.Bounds()
+		s := designAdornerSize
+
+		for _, p := range []Point{
+			{b.X - s, b.Y - s},
+			{b.X + (b.Width-s)/2, b.Y - s},
+			{b.X + b.Width, b.Y - s},
+			{b.X - s, b.Y + (b.Height-s)/2},
+			{b.X + b.Width, b.Y + (b.Height-s)/2},
+			{b.X - s, b.Y + b.Height},
+			{b.X + (b.Width-s)/2, b.Y + b.Height},
+			{b.X + b.Width, b.Y + b.Height},
+		} {
+			if err := canvas.FillRectangle(brush, Rectangle{p.X, p.Y, s, s}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
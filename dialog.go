@@ -6,6 +6,7 @@ package walk
 
 import (
 	"syscall"
+	"time"
 	"unsafe"
 )
 
@@ -44,6 +45,8 @@ type Dialog struct {
 	defaultButton        *PushButton
 	cancelButton         *PushButton
 	centerInOwnerWhenRun bool
+	autoScale            bool
+	autoScaled           bool
 }
 
 func NewDialog(owner RootWidget) (*Dialog, error) {
@@ -83,28 +86,12 @@ func (dlg *Dialog) SetDefaultButton(button *PushButton) error {
 		return newError("not a descendant of the dialog")
 	}
 
-	succeeded := false
-	if dlg.defaultButton != nil {
-		if err := dlg.defaultButton.setAndClearStyleBits(BS_PUSHBUTTON, BS_DEFPUSHBUTTON); err != nil {
-			return err
-		}
-		defer func() {
-			if !succeeded {
-				dlg.defaultButton.setAndClearStyleBits(BS_DEFPUSHBUTTON, BS_PUSHBUTTON)
-			}
-		}()
-	}
-
-	if button != nil {
-		if err := button.setAndClearStyleBits(BS_DEFPUSHBUTTON, BS_PUSHBUTTON); err != nil {
-			return err
-		}
+	if err := setDefaultButtonStyle(dlg.defaultButton, button); err != nil {
+		return err
 	}
 
 	dlg.defaultButton = button
 
-	succeeded = true
-
 	return nil
 }
 
@@ -189,6 +176,10 @@ func (dlg *Dialog) focusFirstCandidateDescendant() {
 }
 
 func (dlg *Dialog) Show() {
+	if dlg.autoScale && !dlg.autoScaled {
+		dlg.applyAutoScale()
+	}
+
 	if dlg.owner != nil {
 		var size Size
 		if layout := dlg.Layout(); layout != nil {
@@ -220,6 +211,14 @@ func (dlg *Dialog) Show() {
 }
 
 func (dlg *Dialog) Run() int {
+	start := time.Now()
+	name := dialogTelemetryName(dlg)
+
+	recordTelemetry(TelemetryDialogOpened, name, start, 0)
+	defer func() {
+		recordTelemetry(TelemetryDialogClosed, name, start, dlg.result)
+	}()
+
 	dlg.Show()
 
 	if dlg.owner != nil {
@@ -0,0 +1,146 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// The font dialogs are assumed to be designed with, when auto scaling.
+const (
+	autoScaleReferenceFontFamily    = "Segoe UI"
+	autoScaleReferenceFontPointSize = 9
+)
+
+var (
+	messageFontSingleton        *Font
+	autoScaleReferenceFontCache *Font
+)
+
+// messageFont returns the font the user chose for message boxes, which is
+// the one dialogs are supposed to use.
+func messageFont() *Font {
+	if messageFontSingleton == nil {
+		var ncm NONCLIENTMETRICS
+		ncm.CbSize = uint32(unsafe.Sizeof(ncm))
+
+		if SystemParametersInfo(SPI_GETNONCLIENTMETRICS, ncm.CbSize, unsafe.Pointer(&ncm), 0) {
+			messageFontSingleton, _ = newFontFromLOGFONT(&ncm.LfMessageFont, screenDPIY)
+		}
+
+		if messageFontSingleton == nil {
+			messageFontSingleton = defaultFont
+		}
+	}
+
+	return messageFontSingleton
+}
+
+func autoScaleReferenceFont() *Font {
+	if autoScaleReferenceFontCache == nil {
+		autoScaleReferenceFontCache, _ = NewFont(autoScaleReferenceFontFamily, autoScaleReferenceFontPointSize, 0)
+
+		if autoScaleReferenceFontCache == nil {
+			autoScaleReferenceFontCache = defaultFont
+		}
+	}
+
+	return autoScaleReferenceFontCache
+}
+
+// AutoScale returns if the *Dialog scales its layout according to the message
+// font chosen by the user.
+func (dlg *Dialog) AutoScale() bool {
+	return dlg.autoScale
+}
+
+// SetAutoScale sets if the *Dialog scales its layout according to the message
+// font chosen by the user.
+//
+// When the *Dialog is shown for the first time, it switches to the message
+// font and scales minimum and maximum sizes, layout margins and spacings and
+// its own size by the ratio of the dialog base units of that font to those of
+// Segoe UI 9pt, the font dialogs are assumed to be designed with. Both fonts
+// are measured at the same DPI, so this is independent of DPI scaling.
+func (dlg *Dialog) SetAutoScale(value bool) {
+	dlg.autoScale = value
+}
+
+// applyAutoScale switches the *Dialog to the message font and scales it.
+func (dlg *Dialog) applyAutoScale() {
+	dlg.autoScaled = true
+
+	font := messageFont()
+
+	base := fontDialogBaseUnits(dlg.hWnd, font)
+	ref := fontDialogBaseUnits(dlg.hWnd, autoScaleReferenceFont())
+
+	if ref.Width == 0 || ref.Height == 0 {
+		return
+	}
+
+	scale := func(s Size) Size {
+		return Size{
+			int(MulDiv(int32(s.Width), int32(base.Width), int32(ref.Width))),
+			int(MulDiv(int32(s.Height), int32(base.Height), int32(ref.Height))),
+		}
+	}
+
+	dlg.SetSuspended(true)
+	defer dlg.SetSuspended(false)
+
+	dlg.SetFont(font)
+
+	autoScaleWidget(dlg, font, scale)
+
+	if size := dlg.Size(); size.Width > 0 && size.Height > 0 {
+		dlg.SetSize(scale(size))
+	}
+}
+
+// autoScaleWidget applies font to widget and its descendants, that don't have
+// their own font, and scales their sizes, margins and spacings.
+func autoScaleWidget(widget Widget, font *Font, scale func(Size) Size) {
+	wb := widget.BaseWidget()
+	if wb.hWnd == 0 {
+		return
+	}
+
+	if wb.font == nil {
+		setWidgetFont(wb.hWnd, font)
+	}
+
+	if wb.minSize != (Size{}) || wb.maxSize != (Size{}) {
+		wb.SetMinMaxSize(scale(wb.minSize), scale(wb.maxSize))
+	}
+
+	if container, ok := widget.(Container); ok {
+		if layout := container.Layout(); layout != nil {
+			m := layout.Margins()
+			topLeft := scale(Size{m.HNear, m.VNear})
+			bottomRight := scale(Size{m.HFar, m.VFar})
+			layout.SetMargins(Margins{topLeft.Width, topLeft.Height, bottomRight.Width, bottomRight.Height})
+
+			spacing := scale(Size{layout.Spacing(), layout.Spacing()})
+			layout.SetSpacing(spacing.Width)
+		}
+	}
+
+	switch w := widget.(type) {
+	case *TabWidget:
+		pages := w.Pages()
+		for i := 0; i < pages.Len(); i++ {
+			autoScaleWidget(pages.At(i), font, scale)
+		}
+
+	case Container:
+		children := w.Children()
+		for i := 0; i < children.Len(); i++ {
+			autoScaleWidget(children.At(i), font, scale)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// baseDPI is the DPI at which one device independent pixel (DIP) equals one
+// physical pixel, i.e. a scaling factor of 100%.
+const baseDPI = 96
+
+var (
+	libuser32           = syscall.NewLazyDLL("user32.dll")
+	procGetDpiForWindow = libuser32.NewProc("GetDpiForWindow")
+)
+
+// IntFromDIP converts value from device independent pixels to pixels at dpi.
+func IntFromDIP(value, dpi int) int {
+	return int(MulDiv(int32(value), int32(dpi), baseDPI))
+}
+
+// IntToDIP converts value from pixels at dpi to device independent pixels.
+func IntToDIP(value, dpi int) int {
+	return int(MulDiv(int32(value), baseDPI, int32(dpi)))
+}
+
+// PointFromDIP converts value from device independent pixels to pixels at
+// dpi.
+func PointFromDIP(value Point, dpi int) Point {
+	return Point{IntFromDIP(value.X, dpi), IntFromDIP(value.Y, dpi)}
+}
+
+// PointToDIP converts value from pixels at dpi to device independent pixels.
+func PointToDIP(value Point, dpi int) Point {
+	return Point{IntToDIP(value.X, dpi), IntToDIP(value.Y, dpi)}
+}
+
+// SizeFromDIP converts value from device independent pixels to pixels at dpi.
+func SizeFromDIP(value Size, dpi int) Size {
+	return Size{IntFromDIP(value.Width, dpi), IntFromDIP(value.Height, dpi)}
+}
+
+// SizeToDIP converts value from pixels at dpi to device independent pixels.
+func SizeToDIP(value Size, dpi int) Size {
+	return Size{IntToDIP(value.Width, dpi), IntToDIP(value.Height, dpi)}
+}
+
+// RectangleFromDIP converts value from device independent pixels to pixels at
+// dpi.
+func RectangleFromDIP(value Rectangle, dpi int) Rectangle {
+	return Rectangle{
+		IntFromDIP(value.X, dpi),
+		IntFromDIP(value.Y, dpi),
+		IntFromDIP(value.Width, dpi),
+		IntFromDIP(value.Height, dpi),
+	}
+}
+
+// RectangleToDIP converts value from pixels at dpi to device independent
+// pixels.
+func RectangleToDIP(value Rectangle, dpi int) Rectangle {
+	return Rectangle{
+		IntToDIP(value.X, dpi),
+		IntToDIP(value.Y, dpi),
+		IntToDIP(value.Width, dpi),
+		IntToDIP(value.Height, dpi),
+	}
+}
+
+// MarginsFromDIP converts value from device independent pixels to pixels at
+// dpi.
+func MarginsFromDIP(value Margins, dpi int) Margins {
+	return Margins{
+		IntFromDIP(value.HNear, dpi),
+		IntFromDIP(value.VNear, dpi),
+		IntFromDIP(value.HFar, dpi),
+		IntFromDIP(value.VFar, dpi),
+	}
+}
+
+// MarginsToDIP converts value from pixels at dpi to device independent
+// pixels.
+func MarginsToDIP(value Margins, dpi int) Margins {
+	return Margins{
+		IntToDIP(value.HNear, dpi),
+		IntToDIP(value.VNear, dpi),
+		IntToDIP(value.HFar, dpi),
+		IntToDIP(value.VFar, dpi),
+	}
+}
+
+// screenDPI returns the DPI of the primary screen.
+func screenDPI() int {
+	hdc := GetDC(0)
+	defer ReleaseDC(0, hdc)
+
+	return int(GetDeviceCaps(hdc, LOGPIXELSY))
+}
+
+// DPI returns the current DPI of the *WidgetBase.
+//
+// On systems supporting per-monitor DPI, this is the DPI of the monitor the
+// widget is on, otherwise the DPI of the screen.
+func (wb *WidgetBase) DPI() int {
+	if wb.hWnd != 0 && procGetDpiForWindow.Find() == nil {
+		if dpi, _, _ := procGetDpiForWindow.Call(uintptr(wb.hWnd)); dpi != 0 {
+			return int(dpi)
+		}
+	}
+
+	return screenDPI()
+}
+
+// IntFromDIP converts value from device independent pixels to pixels at the
+// current DPI of the *WidgetBase.
+func (wb *WidgetBase) IntFromDIP(value int) int {
+	return IntFromDIP(value, wb.DPI())
+}
+
+// SizeFromDIP converts value from device independent pixels to pixels at the
+// current DPI of the *WidgetBase.
+func (wb *WidgetBase) SizeFromDIP(value Size) Size {
+	return SizeFromDIP(value, wb.DPI())
+}
+
+// MarginsFromDIP converts value from device independent pixels to pixels at
+// the current DPI of the *WidgetBase, e.g. for the margins of a Layout.
+func (wb *WidgetBase) MarginsFromDIP(value Margins) Margins {
+	return MarginsFromDIP(value, wb.DPI())
+}
+
+// BoundsDIP returns the outer bounding box of the *WidgetBase, in device
+// independent pixels.
+func (wb *WidgetBase) BoundsDIP() Rectangle {
+	return RectangleToDIP(wb.widget.Bounds(), wb.DPI())
+}
+
+// SetBoundsDIP sets the outer bounding box of the *WidgetBase, in device
+// independent pixels.
+func (wb *WidgetBase) SetBoundsDIP(value Rectangle) error {
+	return wb.widget.SetBounds(RectangleFromDIP(value, wb.DPI()))
+}
+
+// SizeDIP returns the outer size of the *WidgetBase, in device independent
+// pixels.
+func (wb *WidgetBase) SizeDIP() Size {
+	return SizeToDIP(wb.widget.Size(), wb.DPI())
+}
+
+// SetSizeDIP sets the outer size of the *WidgetBase, in device independent
+// pixels.
+func (wb *WidgetBase) SetSizeDIP(value Size) error {
+	return wb.widget.SetSize(SizeFromDIP(value, wb.DPI()))
+}
+
+// ClientBoundsDIP returns the inner bounding box of the *WidgetBase, in device
+// independent pixels.
+func (wb *WidgetBase) ClientBoundsDIP() Rectangle {
+	return RectangleToDIP(wb.widget.ClientBounds(), wb.DPI())
+}
+
+// MinSizeDIP returns the minimum outer size of the *WidgetBase, in device
+// independent pixels.
+func (wb *WidgetBase) MinSizeDIP() Size {
+	return SizeToDIP(wb.widget.MinSize(), wb.DPI())
+}
+
+// MaxSizeDIP returns the maximum outer size of the *WidgetBase, in device
+// independent pixels.
+func (wb *WidgetBase) MaxSizeDIP() Size {
+	return SizeToDIP(wb.widget.MaxSize(), wb.DPI())
+}
+
+// SetMinMaxSizeDIP sets the minimum and maximum outer size of the
+// *WidgetBase, in device independent pixels.
+func (wb *WidgetBase) SetMinMaxSizeDIP(min, max Size) error {
+	dpi := wb.DPI()
+
+	return wb.widget.SetMinMaxSize(SizeFromDIP(min, dpi), SizeFromDIP(max, dpi))
+}
@@ -0,0 +1,43 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+type DPIChangedEventHandler func(window *TopLevelWindow, dpi int)
+
+type DPIChangedEvent struct {
+	handlers []DPIChangedEventHandler
+}
+
+func (e *DPIChangedEvent) Attach(handler DPIChangedEventHandler) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	return len(e.handlers) - 1
+}
+
+func (e *DPIChangedEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type DPIChangedEventPublisher struct {
+	event DPIChangedEvent
+}
+
+func (p *DPIChangedEventPublisher) Event() *DPIChangedEvent {
+	return &p.event
+}
+
+func (p *DPIChangedEventPublisher) Publish(window *TopLevelWindow, dpi int) {
+	for _, handler := range p.event.handlers {
+		if handler != nil {
+			handler(window, dpi)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"time"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	defaultDragAutoScrollHotZone  = 24
+	defaultDragAutoScrollMaxSpeed = 40
+
+	dragAutoScrollInterval = 25 * time.Millisecond
+)
+
+// DragAutoScrollOptions configure how a widget scrolls, while the user drags
+// something near one of its edges.
+//
+// The zero value enables auto scrolling with default settings.
+type DragAutoScrollOptions struct {
+	// Disabled turns auto scrolling off.
+	Disabled bool
+
+	// HotZone is the distance from the edges in device independent pixels,
+	// within which scrolling starts. It defaults to 24.
+	HotZone int
+
+	// MaxSpeed is the number of lines scrolled per second, when the mouse
+	// pointer is at or beyond the edge. Closer to the inner border of the hot
+	// zone the speed decreases proportionally. It defaults to 40.
+	MaxSpeed int
+}
+
+// dragAutoScroller scrolls a widget, while a drag operation started in it is
+// near one of its edges.
+type dragAutoScroller struct {
+	wb       *WidgetBase
+	options  DragAutoScrollOptions
+	button   int32
+	timerId  uintptr
+	lastTick time.Time
+	pending  [2]float64
+}
+
+func (das *dragAutoScroller) setOptions(options DragAutoScrollOptions) {
+	das.options = options
+
+	if options.Disabled {
+		das.stop()
+	}
+}
+
+// start starts scrolling, until the mouse button with the virtual key code
+// button is released.
+func (das *dragAutoScroller) start(wb *WidgetBase, button int32) {
+	if das.options.Disabled || das.timerId != 0 {
+		return
+	}
+
+	das.wb = wb
+	das.button = button
+	das.lastTick = time.Now()
+	das.pending = [2]float64{}
+
+	das.timerId = startTimer(dragAutoScrollInterval, das.tick)
+}
+
+func (das *dragAutoScroller) stop() {
+	stopTimer(das.timerId)
+	das.timerId = 0
+}
+
+func (das *dragAutoScroller) tick() {
+	if das.wb.hWnd == 0 || GetKeyState(das.button) >= 0 {
+		das.stop()
+		return
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(das.lastTick).Seconds()
+	das.lastTick = now
+
+	var pt POINT
+	if !GetCursorPos(&pt) {
+		return
+	}
+	ScreenToClient(das.wb.hWnd, &pt)
+
+	hotZone := das.options.HotZone
+	if hotZone <= 0 {
+		hotZone = defaultDragAutoScrollHotZone
+	}
+	hotZone = das.wb.IntFromDIP(hotZone)
+
+	maxSpeed := das.options.MaxSpeed
+	if maxSpeed <= 0 {
+		maxSpeed = defaultDragAutoScrollMaxSpeed
+	}
+
+	cb := das.wb.ClientBounds()
+
+	speed := func(pos, size int) float64 {
+		var proximity int
+
+		switch {
+		case pos < hotZone:
+			proximity = pos - hotZone
+
+		case pos >= size-hotZone:
+			proximity = pos - (size - hotZone)
+
+		default:
+			return 0
+		}
+
+		if proximity < -hotZone {
+			proximity = -hotZone
+		} else if proximity > hotZone {
+			proximity = hotZone
+		}
+
+		return float64(maxSpeed) * float64(proximity) / float64(hotZone)
+	}
+
+	das.scroll(0, WM_VSCROLL, SB_LINEUP, SB_LINEDOWN, speed(int(pt.Y), cb.Height)*elapsed)
+	das.scroll(1, WM_HSCROLL, SB_LINELEFT, SB_LINERIGHT, speed(int(pt.X), cb.Width)*elapsed)
+}
+
+// scroll scrolls by the whole lines of lines plus the fraction left over from
+// previous ticks.
+func (das *dragAutoScroller) scroll(axis int, msg uint32, back, forward uintptr, lines float64) {
+	if lines == 0 {
+		das.pending[axis] = 0
+		return
+	}
+
+	das.pending[axis] += lines
+
+	for das.pending[axis] <= -1 {
+		das.wb.SendMessage(msg, back, 0)
+		das.pending[axis]++
+	}
+
+	for das.pending[axis] >= 1 {
+		das.wb.SendMessage(msg, forward, 0)
+		das.pending[axis]--
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"encoding/base64"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// encryptedValuePrefix marks values protected by EncryptedSettings, so
+// values stored in plain text before are still read.
+const encryptedValuePrefix = "dpapi:"
+
+const cryptProtectUIForbidden = 0x1
+
+var (
+	libcrypt32             = syscall.NewLazyDLL("crypt32.dll")
+	procCryptProtectData   = libcrypt32.NewProc("CryptProtectData")
+	procCryptUnprotectData = libcrypt32.NewProc("CryptUnprotectData")
+	procLocalFree          = libkernel32.NewProc("LocalFree")
+)
+
+type dataBlob struct {
+	cbData uint32
+	pbData *byte
+}
+
+func newDataBlob(b []byte) *dataBlob {
+	if len(b) == 0 {
+		return &dataBlob{}
+	}
+
+	return &dataBlob{uint32(len(b)), &b[0]}
+}
+
+func (blob *dataBlob) bytes() []byte {
+	if blob.cbData == 0 {
+		return nil
+	}
+
+	b := make([]byte, blob.cbData)
+	copy(b, (*[1 << 30]byte)(unsafe.Pointer(blob.pbData))[:blob.cbData])
+
+	return b
+}
+
+// EncryptedSettings wraps Settings, protecting the values of selected keys
+// with the Windows data protection API, so only the current user on the
+// current machine can read them, e.g. for access tokens or passwords.
+//
+// The values are stored base64 encoded in the wrapped Settings. Values stored
+// in plain text before a key was protected are still returned by Get and
+// protected by the next Put.
+type EncryptedSettings struct {
+	settings         Settings
+	keys             map[string]bool
+	entropy          []byte
+	changedPublisher StringEventPublisher
+}
+
+// NewEncryptedSettings returns a new *EncryptedSettings, that wraps settings
+// and protects the values of keys.
+func NewEncryptedSettings(settings Settings, keys ...string) *EncryptedSettings {
+	es := &EncryptedSettings{settings: settings, keys: make(map[string]bool)}
+
+	for _, key := range keys {
+		es.keys[key] = true
+	}
+
+	return es
+}
+
+// Settings returns the wrapped Settings.
+func (es *EncryptedSettings) Settings() Settings {
+	return es.settings
+}
+
+// Protect makes the *EncryptedSettings protect the value of key.
+func (es *EncryptedSettings) Protect(key string) {
+	es.keys[key] = true
+}
+
+// IsProtected returns if the value of key is protected.
+func (es *EncryptedSettings) IsProtected(key string) bool {
+	return es.keys[key]
+}
+
+// SetEntropy sets additional data, that is needed to unprotect values, e.g.
+// to keep other applications of the same user from reading them. Values
+// protected with other entropy can't be read anymore.
+func (es *EncryptedSettings) SetEntropy(entropy []byte) {
+	es.entropy = append([]byte(nil), entropy...)
+}
+
+// Get returns the value for key, unprotecting it if necessary. Values that
+// can't be unprotected are reported as not found.
+func (es *EncryptedSettings) Get(key string) (string, bool) {
+	value, ok := es.settings.Get(key)
+	if !ok || !es.keys[key] || !strings.HasPrefix(value, encryptedValuePrefix) {
+		return value, ok
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedValuePrefix))
+	if err != nil {
+		return "", false
+	}
+
+	plain, err := es.unprotect(data)
+	if err != nil {
+		return "", false
+	}
+
+	return string(plain), true
+}
+
+// Put stores value for key, protecting it if necessary.
+func (es *EncryptedSettings) Put(key, value string) error {
+	if !es.keys[key] {
+		return es.settings.Put(key, value)
+	}
+
+	data, err := es.protect([]byte(value))
+	if err != nil {
+		return err
+	}
+
+	return es.settings.Put(key, encryptedValuePrefix+base64.StdEncoding.EncodeToString(data))
+}
+
+func (es *EncryptedSettings) Load() error {
+	return es.settings.Load()
+}
+
+func (es *EncryptedSettings) Save() error {
+	return es.settings.Save()
+}
+
+// Changed returns the Changed event of the wrapped Settings, if they
+// implement SettingsChangeNotifier, or an event that is never published.
+func (es *EncryptedSettings) Changed() *StringEvent {
+	if notifier, ok := es.settings.(SettingsChangeNotifier); ok {
+		return notifier.Changed()
+	}
+
+	return es.changedPublisher.Event()
+}
+
+func (es *EncryptedSettings) protect(plain []byte) ([]byte, error) {
+	var out dataBlob
+
+	if ret, _, err := procCryptProtectData.Call(
+		uintptr(unsafe.Pointer(newDataBlob(plain))),
+		0,
+		uintptr(unsafe.Pointer(newDataBlob(es.entropy))),
+		0,
+		0,
+		cryptProtectUIForbidden,
+		uintptr(unsafe.Pointer(&out))); ret == 0 {
+
+		return nil, callError("CryptProtectData", err)
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(out.pbData)))
+
+	return out.bytes(), nil
+}
+
+func (es *EncryptedSettings) unprotect(data []byte) ([]byte, error) {
+	var out dataBlob
+
+	if ret, _, err := procCryptUnprotectData.Call(
+		uintptr(unsafe.Pointer(newDataBlob(data))),
+		0,
+		uintptr(unsafe.Pointer(newDataBlob(es.entropy))),
+		0,
+		0,
+		cryptProtectUIForbidden,
+		uintptr(unsafe.Pointer(&out))); ret == 0 {
+
+		return nil, callError("CryptUnprotectData", err)
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(out.pbData)))
+
+	return out.bytes(), nil
+}
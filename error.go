@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"log"
 	"runtime/debug"
+	"syscall"
 )
 
 import . "github.com/lxn/go-winapi"
@@ -17,18 +18,76 @@ var (
 	panicOnError bool
 )
 
+// Error is the error type returned by walk.
+//
+// If a Win32 API call failed, API and Code report which one and why, so
+// applications can react to specific failures:
+//
+//	if errors.Is(err, syscall.ERROR_ACCESS_DENIED) {
+//		...
+//	}
 type Error struct {
 	inner   error
 	message string
 	stack   []byte
+	api     string
+	code    syscall.Errno
+	hresult HRESULT
+	widget  string
 }
 
+// facilityWin32 is the facility of HRESULTs wrapping a Win32 error code.
+const facilityWin32 = 7
+
 func (err *Error) Inner() error {
 	return err.inner
 }
 
+// Unwrap returns the inner error or, if there is none, the Win32 error code,
+// for use by errors.Is and errors.As.
+func (err *Error) Unwrap() error {
+	if err.inner != nil {
+		return err.inner
+	}
+
+	if err.code != 0 {
+		return err.code
+	}
+
+	return nil
+}
+
+// API returns the name of the Win32 API function that failed, if any.
+func (err *Error) API() string {
+	return err.api
+}
+
+// Code returns the Win32 error code reported by the failed API function, or 0
+// if it is unknown.
+//
+// For a failed COM call, it is only known if the HRESULT wraps a Win32 error
+// code.
+func (err *Error) Code() syscall.Errno {
+	return err.code
+}
+
+// HRESULT returns the HRESULT returned by the failed COM call, or 0 if the
+// error is not due to one.
+func (err *Error) HRESULT() HRESULT {
+	return err.hresult
+}
+
+// WidgetName returns the name of the Widget the error occurred for, if any.
+func (err *Error) WidgetName() string {
+	return err.widget
+}
+
 func (err *Error) Message() string {
 	if err.message != "" {
+		if err.widget != "" {
+			return fmt.Sprintf("%s (widget %q)", err.message, err.widget)
+		}
+
 		return err.message
 	}
 
@@ -85,16 +144,59 @@ func newErrorNoPanic(message string) error {
 	return processErrorNoPanic(newErr(message))
 }
 
-func lastError(win32FuncName string) error {
-	if errno := GetLastError(); errno != ERROR_SUCCESS {
-		return newError(fmt.Sprintf("%s: Error %d", win32FuncName, errno))
+func newAPIErr(funcName string, code syscall.Errno) *Error {
+	err := &Error{api: funcName, code: code, stack: debug.Stack()}
+
+	if code != 0 {
+		err.message = fmt.Sprintf("%s: %s (Error %d)", funcName, code.Error(), uint32(code))
+	} else {
+		err.message = funcName
 	}
 
-	return newError(win32FuncName)
+	return err
+}
+
+func lastError(win32FuncName string) error {
+	return processError(newAPIErr(win32FuncName, syscall.Errno(GetLastError())))
+}
+
+// lastWidgetError is like lastError, but also records the name of the widget
+// the call was made for.
+func lastWidgetError(wb *WidgetBase, win32FuncName string) error {
+	err := newAPIErr(win32FuncName, syscall.Errno(GetLastError()))
+	err.widget = wb.name
+
+	return processError(err)
+}
+
+// callError returns the error for a failed call of a lazily loaded API
+// function, where callErr is the error returned by syscall.LazyProc.Call.
+func callError(funcName string, callErr error) error {
+	errno, _ := callErr.(syscall.Errno)
+
+	return processError(newAPIErr(funcName, errno))
+}
+
+// newAPIError returns the error for a failed call of an API function, that
+// does not report an error code, like SendMessage or most of GDI.
+func newAPIError(funcName string) error {
+	return processError(newAPIErr(funcName, 0))
 }
 
 func errorFromHRESULT(funcName string, hr HRESULT) error {
-	return newError(fmt.Sprintf("%s: Error %d", funcName, hr))
+	var code syscall.Errno
+	if uint32(hr)>>16&0x1FFF == facilityWin32 {
+		code = syscall.Errno(uint32(hr) & 0xFFFF)
+	}
+
+	err := newAPIErr(funcName, code)
+	err.hresult = hr
+	err.message = fmt.Sprintf("%s: Error 0x%08X", funcName, uint32(hr))
+	if code != 0 {
+		err.message = fmt.Sprintf("%s: %s (Error 0x%08X)", funcName, code.Error(), uint32(hr))
+	}
+
+	return processError(err)
 }
 
 func wrapErr(err error) error {
@@ -22,6 +22,19 @@ func (e *Event) Attach(handler EventHandler) int {
 	return len(e.handlers) - 1
 }
 
+// AttachOwned attaches handler and automatically detaches it again, as soon as
+// owner is disposed of.
+//
+// Use this when a widget subscribes to an event of a longer lived object, so
+// the handler does not outlive the widget.
+func (e *Event) AttachOwned(owner Widget, handler EventHandler) {
+	handle := e.Attach(handler)
+
+	owner.BaseWidget().Disposing().Attach(func() {
+		e.Detach(handle)
+	})
+}
+
 func (e *Event) Detach(handle int) {
 	e.handlers[handle] = nil
 }
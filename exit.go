@@ -0,0 +1,107 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+// Exiting returns the *CancelEvent that is published, before the application
+// exits using Shutdown or Restart, or because the user closes the window,
+// whose message loop the application runs. It is not published, when the
+// message loop goes on without windows, e.g. in tray applications.
+//
+// Handlers may cancel, e.g. after asking the user about unsaved changes.
+func (app *Application) Exiting() *CancelEvent {
+	return app.exitingPublisher.Event()
+}
+
+// Shutdown exits the application gracefully with exitCode. It returns false,
+// if the shutdown was canceled.
+//
+// The steps are, in this order:
+//
+//  1. The Exiting event is published. If a handler cancels, Shutdown stops.
+//  2. The Closing event of each top-level window is published, owned
+//     windows first. If a handler cancels, Shutdown stops.
+//  3. The state of persistent top-level windows and their descendants is
+//     saved to the Settings.
+//  4. All top-level windows are disposed of.
+//  5. The Settings are saved.
+//  6. Exit is called, which posts WM_QUIT.
+//
+// Unlike Shutdown, Exit neither asks nor saves anything.
+func (app *Application) Shutdown(exitCode int) bool {
+	if !app.shutdown() {
+		return false
+	}
+
+	app.Exit(exitCode)
+
+	return true
+}
+
+// shutdown does everything Shutdown does, except calling Exit.
+func (app *Application) shutdown() bool {
+	var canceled bool
+	app.exitingPublisher.Publish(&canceled)
+	if canceled {
+		return false
+	}
+
+	windows := topLevelWindowsOwnedFirst()
+
+	for _, tlw := range windows {
+		tlw.closingPublisher.Publish(&canceled, CloseReasonUnknown)
+		if canceled {
+			return false
+		}
+	}
+
+	for _, tlw := range windows {
+		tlw.persistState(false)
+	}
+
+	for _, tlw := range windows {
+		tlw.close()
+	}
+
+	app.settingsSavedOnExit = true
+	app.saveSettings()
+
+	return true
+}
+
+// topLevelWindowsOwnedFirst returns the top-level windows, with owned windows
+// before their owners.
+func topLevelWindowsOwnedFirst() []*TopLevelWindow {
+	var windows []*TopLevelWindow
+	added := make(map[*TopLevelWindow]bool)
+
+	var add func(tlw *TopLevelWindow)
+	add = func(tlw *TopLevelWindow) {
+		if added[tlw] {
+			return
+		}
+		added[tlw] = true
+
+		for other := range topLevelWindows {
+			if other.owner != nil && other.owner.BaseWidget() == &tlw.WidgetBase {
+				add(other)
+			}
+		}
+
+		windows = append(windows, tlw)
+	}
+
+	for tlw := range topLevelWindows {
+		add(tlw)
+	}
+
+	return windows
+}
+
+// closesApplication returns if closing tlw ends the application, because it
+// ends the outermost message loop. Windowless message loops, e.g. of tray
+// applications, only end on Exit.
+func (tlw *TopLevelWindow) closesApplication() bool {
+	return len(messageLoopWindows) > 0 && messageLoopWindows[0] == tlw
+}
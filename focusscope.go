@@ -0,0 +1,177 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import . "github.com/lxn/go-winapi"
+
+// FocusScope returns if the *Composite is a focus scope.
+func (c *Composite) FocusScope() bool {
+	return c.focusScope
+}
+
+// SetFocusScope sets if the *Composite is a focus scope.
+//
+// Inside a focus scope, the Tab key cycles through the descendants of the
+// *Composite only, and Enter resp. Escape click the DefaultButton resp.
+// CancelButton of the *Composite, if set. Otherwise the buttons of the
+// enclosing focus scope or *Dialog are used.
+func (c *Composite) SetFocusScope(value bool) {
+	c.focusScope = value
+}
+
+// DefaultButton returns the *PushButton that is clicked when Enter is pressed
+// inside the *Composite, if it is a focus scope.
+func (c *Composite) DefaultButton() *PushButton {
+	return c.defaultButton
+}
+
+// SetDefaultButton sets the *PushButton that is clicked when Enter is pressed
+// inside the *Composite, if it is a focus scope.
+func (c *Composite) SetDefaultButton(button *PushButton) error {
+	if button != nil && !IsChild(c.hWnd, button.hWnd) {
+		return newError("not a descendant of the composite")
+	}
+
+	if err := setDefaultButtonStyle(c.defaultButton, button); err != nil {
+		return err
+	}
+
+	c.defaultButton = button
+
+	return nil
+}
+
+// CancelButton returns the *PushButton that is clicked when Escape is pressed
+// inside the *Composite, if it is a focus scope.
+func (c *Composite) CancelButton() *PushButton {
+	return c.cancelButton
+}
+
+// SetCancelButton sets the *PushButton that is clicked when Escape is pressed
+// inside the *Composite, if it is a focus scope.
+func (c *Composite) SetCancelButton(button *PushButton) error {
+	if button != nil && !IsChild(c.hWnd, button.hWnd) {
+		return newError("not a descendant of the composite")
+	}
+
+	c.cancelButton = button
+
+	return nil
+}
+
+func setDefaultButtonStyle(old, button *PushButton) error {
+	succeeded := false
+	if old != nil {
+		if err := old.setAndClearStyleBits(BS_PUSHBUTTON, BS_DEFPUSHBUTTON); err != nil {
+			return err
+		}
+		defer func() {
+			if !succeeded {
+				old.setAndClearStyleBits(BS_DEFPUSHBUTTON, BS_PUSHBUTTON)
+			}
+		}()
+	}
+
+	if button != nil {
+		if err := button.setAndClearStyleBits(BS_DEFPUSHBUTTON, BS_PUSHBUTTON); err != nil {
+			return err
+		}
+	}
+
+	succeeded = true
+
+	return nil
+}
+
+// focusScopeOf returns the innermost *Composite focus scope containing widget,
+// or nil if there is none.
+func focusScopeOf(widget Widget) *Composite {
+	for widget != nil {
+		if c, ok := widget.(*Composite); ok && c.focusScope {
+			return c
+		}
+
+		parent := widget.Parent()
+		if parent == nil {
+			break
+		}
+		widget = parent
+	}
+
+	return nil
+}
+
+// dialogButtonFor returns the button, as selected by f, of the innermost focus
+// scope or dialog containing widget, that has such a button.
+func dialogButtonFor(widget Widget, f func(d dialogish) *PushButton) *PushButton {
+	for scope := focusScopeOf(widget); scope != nil; {
+		if button := f(scope); button != nil {
+			return button
+		}
+
+		parent := scope.Parent()
+		if parent == nil {
+			break
+		}
+		scope = focusScopeOf(parent)
+	}
+
+	if dlg, ok := rootWidget(widget).(dialogish); ok {
+		return f(dlg)
+	}
+
+	return nil
+}
+
+func defaultButtonFor(widget Widget) *PushButton {
+	return dialogButtonFor(widget, func(d dialogish) *PushButton {
+		return d.DefaultButton()
+	})
+}
+
+func cancelButtonFor(widget Widget) *PushButton {
+	return dialogButtonFor(widget, func(d dialogish) *PushButton {
+		return d.CancelButton()
+	})
+}
+
+// focusedWidget returns the Widget that has the keyboard focus, or its closest
+// ancestor known to walk, e.g. for the edit part of a combo box.
+func focusedWidget() Widget {
+	for hwnd := GetFocus(); hwnd != 0; hwnd = GetAncestor(hwnd, GA_PARENT) {
+		if widget := widgetFromHWND(hwnd); widget != nil {
+			return widget
+		}
+	}
+
+	return nil
+}
+
+// handleFocusScopeTab moves the focus to the next resp. previous tab stop
+// inside the focus scope containing the focused widget, if msg is a Tab key
+// press. It returns if msg was handled.
+func handleFocusScopeTab(msg *MSG) bool {
+	if msg.Message != WM_KEYDOWN || msg.WParam != VK_TAB || GetKeyState(VK_CONTROL) < 0 {
+		return false
+	}
+
+	widget := focusedWidget()
+	scope := focusScopeOf(widget)
+	if scope == nil {
+		return false
+	}
+
+	previous := GetKeyState(VK_SHIFT) < 0
+
+	next := GetNextDlgTabItem(scope.hWnd, widget.Handle(), previous)
+	if next == 0 {
+		// Leave the key to the dialog manager.
+		return false
+	}
+
+	SetFocus(next)
+
+	return true
+}
@@ -4,7 +4,10 @@
 
 package walk
 
-import "syscall"
+import (
+	"syscall"
+	"time"
+)
 
 import . "github.com/lxn/go-winapi"
 
@@ -25,6 +28,8 @@ var (
 )
 
 func init() {
+	defer traceStartup("Fonts", time.Now())
+
 	// Retrieve screen DPI
 	hDC := GetDC(0)
 	defer ReleaseDC(0, hDC)
@@ -62,7 +67,7 @@ func NewFont(family string, pointSize int, style FontStyle) (*Font, error) {
 
 	hFont := font.createForDPI(screenDPIY)
 	if hFont == 0 {
-		return nil, newError("CreateFontIndirect failed")
+		return nil, newAPIError("CreateFontIndirect")
 	}
 
 	font.dpi2hFont[screenDPIY] = hFont
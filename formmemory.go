@@ -0,0 +1,195 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"strconv"
+	"time"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// formMemoryScope is the settings scope of remembered values, so they don't
+// collide with the state of persistent widgets.
+const formMemoryScope = "FormMemory"
+
+// FormMemory returns if input widgets remember their values.
+func (app *Application) FormMemory() bool {
+	return app.formMemory
+}
+
+// SetFormMemory sets if input widgets remember their values, e.g. for tools
+// with repetitive data entry.
+//
+// If enabled, the values of named LineEdits, TextEdits, ComboBoxes,
+// CheckBoxes, NumberEdits and DateEdits are saved to the Settings, when their
+// top-level window is hidden or closed, and restored, when it is shown again,
+// also after the next launch. LineEdits in password mode are never
+// remembered. Use SetExcludedFromFormMemory to exclude other widgets or whole
+// windows.
+func (app *Application) SetFormMemory(enabled bool) {
+	app.formMemory = enabled
+}
+
+// ExcludedFromFormMemory returns if the *WidgetBase and, if it is a
+// container, its descendants don't remember their values.
+func (wb *WidgetBase) ExcludedFromFormMemory() bool {
+	return wb.excludedFromFormMemory
+}
+
+// SetExcludedFromFormMemory sets if the *WidgetBase and, if it is a container
+// or a top-level window, its descendants don't remember their values.
+func (wb *WidgetBase) SetExcludedFromFormMemory(value bool) {
+	wb.excludedFromFormMemory = value
+}
+
+// formMemoryValue returns funcs to get and set the value of widget as string,
+// or ok false if widget doesn't remember its value.
+func formMemoryValue(widget Widget) (get func() string, set func(value string) error, ok bool) {
+	switch w := widget.(type) {
+	case *LineEdit:
+		if w.PasswordMode() {
+			return nil, nil, false
+		}
+
+		return w.Text, w.SetText, true
+
+	case *TextEdit:
+		return w.Text, w.SetText, true
+
+	case *ComboBox:
+		if !w.hasStyleBits(CBS_DROPDOWNLIST) {
+			return w.Text, w.SetText, true
+		}
+
+		return func() string {
+				return strconv.Itoa(w.CurrentIndex())
+			}, func(value string) error {
+				index, err := strconv.Atoi(value)
+				if err != nil {
+					return wrapError(err)
+				}
+
+				return w.SetCurrentIndex(index)
+			}, true
+
+	case *CheckBox:
+		return func() string {
+				return strconv.FormatBool(w.Checked())
+			}, func(value string) error {
+				checked, err := strconv.ParseBool(value)
+				if err != nil {
+					return wrapError(err)
+				}
+
+				w.SetChecked(checked)
+
+				return nil
+			}, true
+
+	case *NumberEdit:
+		return func() string {
+				return strconv.FormatFloat(w.Value(), 'g', -1, 64)
+			}, func(value string) error {
+				f, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return wrapError(err)
+				}
+
+				return w.SetValue(f)
+			}, true
+
+	case *DateEdit:
+		return func() string {
+				if t := w.Value(); !t.IsZero() {
+					return t.Format(time.RFC3339)
+				}
+
+				return ""
+			}, func(value string) error {
+				var t time.Time
+				if value != "" {
+					var err error
+					if t, err = time.Parse(time.RFC3339, value); err != nil {
+						return wrapError(err)
+					}
+				}
+
+				return w.SetValue(t)
+			}, true
+	}
+
+	return nil, nil, false
+}
+
+// forEachFormMemoryWidget calls f for widget and its descendants, that
+// remember their values.
+func forEachFormMemoryWidget(widget Widget, f func(widget Widget, get func() string, set func(value string) error)) {
+	wb := widget.BaseWidget()
+	if wb.hWnd == 0 || wb.excludedFromFormMemory {
+		return
+	}
+
+	if get, set, ok := formMemoryValue(widget); ok && wb.name != "" {
+		f(widget, get, set)
+	}
+
+	switch w := widget.(type) {
+	case *TabWidget:
+		pages := w.Pages()
+		for i := 0; i < pages.Len(); i++ {
+			forEachFormMemoryWidget(pages.At(i), f)
+		}
+
+	case Container:
+		children := w.Children()
+		for i := 0; i < children.Len(); i++ {
+			forEachFormMemoryWidget(children.At(i), f)
+		}
+	}
+}
+
+// saveFormMemory stores the values of root and its descendants under the
+// scoped paths of the widgets and returns the first error.
+func saveFormMemory(root Widget) (err error) {
+	settings := NewSettingsScope(appSingleton.settings, formMemoryScope)
+
+	puttingState = true
+	defer func() {
+		puttingState = false
+	}()
+
+	forEachFormMemoryWidget(root, func(widget Widget, get func() string, set func(value string) error) {
+		// Quoting keeps multi-line values on one line.
+		if e := settings.Put(widget.BaseWidget().path(), strconv.Quote(get())); e != nil && err == nil {
+			err = wrapError(e)
+		}
+	})
+
+	return
+}
+
+// restoreFormMemory restores the values of root and its descendants and
+// returns the first error.
+func restoreFormMemory(root Widget) (err error) {
+	settings := NewSettingsScope(appSingleton.settings, formMemoryScope)
+
+	forEachFormMemoryWidget(root, func(widget Widget, get func() string, set func(value string) error) {
+		quoted, ok := settings.Get(widget.BaseWidget().path())
+		if !ok {
+			return
+		}
+
+		value, e := strconv.Unquote(quoted)
+		if e == nil {
+			e = set(value)
+		}
+		if e != nil && err == nil {
+			err = wrapError(e)
+		}
+	})
+
+	return
+}
@@ -14,11 +14,17 @@ func init() {
 	MustRegisterWindowClass(groupBoxWindowClass)
 }
 
+// GroupBox is a container with a frame and a title.
+//
+// A checkable *GroupBox shows a check box in place of its title, that enables
+// or disables all its children, like many settings dialogs do.
 type GroupBox struct {
 	WidgetBase
-	hWndGroupBox          HWND
-	composite             *Composite
-	titleChangedPublisher EventPublisher
+	hWndGroupBox            HWND
+	hWndCheckBox            HWND
+	composite               *Composite
+	titleChangedPublisher   EventPublisher
+	checkedChangedPublisher EventPublisher
 }
 
 func NewGroupBox(parent Container) (*GroupBox, error) {
@@ -42,7 +48,7 @@ func NewGroupBox(parent Container) (*GroupBox, error) {
 
 	gb.hWndGroupBox = CreateWindowEx(
 		0, syscall.StringToUTF16Ptr("BUTTON"), nil,
-		WS_CHILD|WS_VISIBLE|BS_GROUPBOX,
+		WS_CHILD|WS_CLIPSIBLINGS|WS_VISIBLE|BS_GROUPBOX,
 		0, 0, 80, 24, gb.hWnd, 0, 0, nil)
 	if gb.hWndGroupBox == 0 {
 		return nil, lastError("CreateWindowEx(BUTTON)")
@@ -67,6 +73,16 @@ func NewGroupBox(parent Container) (*GroupBox, error) {
 		},
 		gb.titleChangedPublisher.Event()))
 
+	gb.MustRegisterProperty("Checked", NewBoolProperty(
+		func() bool {
+			return gb.Checked()
+		},
+		func(v bool) error {
+			gb.SetChecked(v)
+			return nil
+		},
+		gb.checkedChangedPublisher.Event()))
+
 	succeeded = true
 
 	return gb, nil
@@ -108,8 +124,13 @@ func (gb *GroupBox) ClientBounds() Rectangle {
 func (gb *GroupBox) SetFont(value *Font) {
 	if value != gb.font {
 		setWidgetFont(gb.hWndGroupBox, value)
+		if gb.hWndCheckBox != 0 {
+			setWidgetFont(gb.hWndCheckBox, value)
+		}
 
 		gb.font = value
+
+		gb.updateCheckBoxBounds()
 	}
 }
 
@@ -132,7 +153,118 @@ func (gb *GroupBox) Title() string {
 }
 
 func (gb *GroupBox) SetTitle(value string) error {
-	return setWidgetText(gb.hWndGroupBox, value)
+	if err := setWidgetText(gb.hWndGroupBox, value); err != nil {
+		return err
+	}
+
+	if gb.hWndCheckBox != 0 {
+		if err := setWidgetText(gb.hWndCheckBox, value); err != nil {
+			return err
+		}
+
+		gb.updateCheckBoxBounds()
+	}
+
+	return nil
+}
+
+// Checkable returns if the *GroupBox shows a check box in place of its title.
+func (gb *GroupBox) Checkable() bool {
+	return gb.hWndCheckBox != 0
+}
+
+// SetCheckable sets if the *GroupBox shows a check box in place of its title,
+// that enables or disables its children. A new check box is checked.
+func (gb *GroupBox) SetCheckable(value bool) error {
+	if value == gb.Checkable() {
+		return nil
+	}
+
+	if !value {
+		if !DestroyWindow(gb.hWndCheckBox) {
+			return lastError("DestroyWindow")
+		}
+		gb.hWndCheckBox = 0
+
+		gb.SetChecked(true)
+
+		return nil
+	}
+
+	gb.hWndCheckBox = CreateWindowEx(
+		0, syscall.StringToUTF16Ptr("BUTTON"), syscall.StringToUTF16Ptr(gb.Title()),
+		WS_CHILD|WS_TABSTOP|WS_VISIBLE|BS_AUTOCHECKBOX,
+		0, 0, 80, 24, gb.hWnd, 0, 0, nil)
+	if gb.hWndCheckBox == 0 {
+		return lastError("CreateWindowEx(BUTTON)")
+	}
+
+	setWidgetFont(gb.hWndCheckBox, gb.Font())
+
+	// The check box must be above the group box to cover its title.
+	SetWindowPos(gb.hWndCheckBox, HWND_TOP, 0, 0, 0, 0, SWP_NOMOVE|SWP_NOSIZE)
+
+	SendMessage(gb.hWndCheckBox, BM_SETCHECK, BST_CHECKED, 0)
+
+	gb.updateCheckBoxBounds()
+
+	return nil
+}
+
+// Checked returns if the check box of a checkable *GroupBox is checked. A
+// *GroupBox that is not checkable is always checked.
+func (gb *GroupBox) Checked() bool {
+	if gb.hWndCheckBox == 0 {
+		return true
+	}
+
+	return SendMessage(gb.hWndCheckBox, BM_GETCHECK, 0, 0) == BST_CHECKED
+}
+
+// SetChecked sets if the check box of a checkable *GroupBox is checked and
+// enables or disables the children of the *GroupBox accordingly.
+//
+// The children are enabled or disabled even if value equals Checked, so this
+// can be called to apply the state to children added later.
+func (gb *GroupBox) SetChecked(value bool) {
+	changed := value != gb.Checked()
+
+	if gb.hWndCheckBox != 0 {
+		var chk uintptr
+		if value {
+			chk = BST_CHECKED
+		}
+
+		SendMessage(gb.hWndCheckBox, BM_SETCHECK, chk, 0)
+	}
+
+	gb.composite.SetEnabled(value)
+
+	if changed {
+		gb.checkedChangedPublisher.Publish()
+	}
+}
+
+// CheckedChanged returns the event that is published, when the check box of
+// a checkable *GroupBox was checked or unchecked.
+func (gb *GroupBox) CheckedChanged() *Event {
+	return gb.checkedChangedPublisher.Event()
+}
+
+// updateCheckBoxBounds places the check box over the title of the group box.
+func (gb *GroupBox) updateCheckBoxBounds() {
+	if gb.hWndCheckBox == 0 {
+		return
+	}
+
+	textSize := gb.calculateTextSizeImpl(gb.Title())
+
+	width := textSize.Width + int(GetSystemMetrics(SM_CXMENUCHECK)) + gb.IntFromDIP(6)
+	height := maxi(textSize.Height, int(GetSystemMetrics(SM_CYMENUCHECK)))
+
+	if !MoveWindow(gb.hWndCheckBox, int32(gb.IntFromDIP(6)), 0, int32(width), int32(height), true) {
+		lastError("MoveWindow")
+	}
 }
 
 func (gb *GroupBox) Children() *WidgetList {
@@ -155,7 +287,19 @@ func (gb *GroupBox) SetLayout(value Layout) error {
 func (gb *GroupBox) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	if gb.composite != nil {
 		switch msg {
-		case WM_COMMAND, WM_NOTIFY:
+		case WM_COMMAND:
+			if gb.hWndCheckBox != 0 && HWND(lParam) == gb.hWndCheckBox {
+				if HIWORD(uint32(wParam)) == BN_CLICKED {
+					gb.composite.SetEnabled(gb.Checked())
+
+					gb.checkedChangedPublisher.Publish()
+				}
+				break
+			}
+
+			gb.composite.WndProc(hwnd, msg, wParam, lParam)
+
+		case WM_NOTIFY:
 			gb.composite.WndProc(hwnd, msg, wParam, lParam)
 
 		case WM_SETTEXT:
@@ -177,6 +321,8 @@ func (gb *GroupBox) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintp
 
 			gbcb := gb.ClientBounds()
 			gb.composite.SetBounds(gbcb)
+
+			gb.updateCheckBoxBounds()
 		}
 	}
 
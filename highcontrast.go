@@ -0,0 +1,76 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	spiGetHighContrast = 0x0042
+	spiSetHighContrast = 0x0043
+
+	hcfHighContrastOn = 0x00000001
+)
+
+type highContrastInfo struct {
+	cbSize            uint32
+	dwFlags           uint32
+	lpszDefaultScheme *uint16
+}
+
+var (
+	highContrastKnown bool
+	highContrast      bool
+)
+
+// HighContrast returns if the user turned on a high contrast theme.
+//
+// While it is on, walk ignores the dark ColorScheme and custom colors, e.g.
+// of menu items, the alternating rows of a TableView or the background of a
+// LineErrorPresenter, and draws with the system colors instead. Widgets
+// painting themselves should use the Palette, which then consists of system
+// colors as well.
+func (app *Application) HighContrast() bool {
+	if !highContrastKnown {
+		highContrast = highContrastOn()
+		highContrastKnown = true
+	}
+
+	return highContrast
+}
+
+// HighContrastChanged returns the *Event that is published after the user
+// turned a high contrast theme on or off. It is published after
+// SystemColorsChanged.
+func (app *Application) HighContrastChanged() *Event {
+	return app.highContrastChangedPublisher.Event()
+}
+
+func highContrastOn() bool {
+	var hc highContrastInfo
+	hc.cbSize = uint32(unsafe.Sizeof(hc))
+
+	if !SystemParametersInfo(spiGetHighContrast, hc.cbSize, unsafe.Pointer(&hc), 0) {
+		return false
+	}
+
+	return hc.dwFlags&hcfHighContrastOn != 0
+}
+
+// updateHighContrast queries the high contrast setting again and returns if it
+// changed.
+func updateHighContrast() bool {
+	on := highContrastOn()
+	if highContrastKnown && on == highContrast {
+		return false
+	}
+
+	highContrast, highContrastKnown = on, true
+
+	return true
+}
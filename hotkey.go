@@ -0,0 +1,107 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import . "github.com/lxn/go-winapi"
+
+// Modifiers is a combination of modifier keys.
+type Modifiers uint32
+
+const (
+	ModAlt     Modifiers = 0x0001
+	ModControl Modifiers = 0x0002
+	ModShift   Modifiers = 0x0004
+	ModWin     Modifiers = 0x0008
+)
+
+// Hotkey is a key combination, as recorded by a *HotkeyEdit. Pass its fields
+// to RegisterGlobalHotkey to register it. The zero value is no hotkey.
+type Hotkey struct {
+	Modifiers Modifiers
+
+	// Key is a virtual key code like VK_F12.
+	Key int
+}
+
+// modNoRepeat keeps a held down hotkey from being reported repeatedly.
+const modNoRepeat = 0x4000
+
+type globalHotkey struct {
+	modifiers Modifiers
+	key       int
+	publisher EventPublisher
+}
+
+var (
+	globalHotkeysById  = make(map[int]*globalHotkey)
+	nextGlobalHotkeyId = 1
+)
+
+// RegisterGlobalHotkey registers a system-wide hotkey, consisting of
+// modifiers and key, a virtual key code like VK_F12, and returns the *Event
+// published when it is pressed, even if the application is not active.
+//
+// RegisterGlobalHotkey must be called on the UI thread. It fails, if another
+// application registered the hotkey already.
+func (app *Application) RegisterGlobalHotkey(modifiers Modifiers, key int) (*Event, error) {
+	if findGlobalHotkey(modifiers, key) != 0 {
+		return nil, newError("hotkey already registered")
+	}
+
+	id := nextGlobalHotkeyId
+
+	if !RegisterHotKey(0, int32(id), uint32(modifiers)|modNoRepeat, uint32(key)) {
+		return nil, lastError("RegisterHotKey")
+	}
+
+	nextGlobalHotkeyId++
+
+	hk := &globalHotkey{modifiers: modifiers, key: key}
+	globalHotkeysById[id] = hk
+
+	return hk.publisher.Event(), nil
+}
+
+// UnregisterGlobalHotkey unregisters the system-wide hotkey, consisting of
+// modifiers and key, that was registered using RegisterGlobalHotkey.
+func (app *Application) UnregisterGlobalHotkey(modifiers Modifiers, key int) error {
+	id := findGlobalHotkey(modifiers, key)
+	if id == 0 {
+		return newError("hotkey not registered")
+	}
+
+	if !UnregisterHotKey(0, int32(id)) {
+		return lastError("UnregisterHotKey")
+	}
+
+	delete(globalHotkeysById, id)
+
+	return nil
+}
+
+func findGlobalHotkey(modifiers Modifiers, key int) int {
+	for id, hk := range globalHotkeysById {
+		if hk.modifiers == modifiers && hk.key == key {
+			return id
+		}
+	}
+
+	return 0
+}
+
+// handleGlobalHotkey publishes the event of a global hotkey, if msg reports
+// that it was pressed. It returns if msg was handled.
+func handleGlobalHotkey(msg *MSG) bool {
+	// Hotkeys registered without a window are posted to the thread.
+	if msg.Message != WM_HOTKEY || msg.HWnd != 0 {
+		return false
+	}
+
+	if hk, ok := globalHotkeysById[int(msg.WParam)]; ok {
+		hk.publisher.Publish()
+	}
+
+	return true
+}
@@ -0,0 +1,232 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"errors"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	hkmSetHotkey = WM_USER + 1
+	hkmGetHotkey = WM_USER + 2
+	hkmSetRules  = WM_USER + 3
+
+	hotkeyfShift   = 0x01
+	hotkeyfControl = 0x02
+	hotkeyfAlt     = 0x04
+
+	hkcombNone = 0x0001
+	hkcombS    = 0x0002
+)
+
+// reservedHotkeys are used by Windows, or like F12 by debuggers, and should
+// not be registered as global hotkeys.
+var reservedHotkeys = []Hotkey{
+	{ModAlt, VK_TAB},
+	{ModAlt | ModShift, VK_TAB},
+	{ModAlt, VK_ESCAPE},
+	{ModControl, VK_ESCAPE},
+	{ModControl | ModShift, VK_ESCAPE},
+	{ModAlt, VK_F4},
+	{ModAlt, VK_SPACE},
+	{ModControl | ModAlt, VK_DELETE},
+	{0, VK_F12},
+}
+
+// HotkeyReserved returns if hotkey is used by Windows or debuggers and should
+// not be registered as global hotkey.
+func HotkeyReserved(hotkey Hotkey) bool {
+	for _, hk := range reservedHotkeys {
+		if hk == hotkey {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HotkeyEdit wraps the hot key control, which records a key combination
+// pressed by the user, e.g. to configure a global hotkey.
+//
+// The Windows key can not be recorded. By default, keys without Ctrl or Alt
+// are recorded with Ctrl+Alt, see SetUnmodifiedAllowed. Reserved combinations,
+// see HotkeyReserved, are rejected.
+type HotkeyEdit struct {
+	WidgetBase
+	hotkey                  Hotkey
+	unmodifiedAllowed       bool
+	hotkeyChangedPublisher  EventPublisher
+	hotkeyRejectedPublisher ErrorEventPublisher
+}
+
+// NewHotkeyEdit returns a new *HotkeyEdit without hotkey.
+func NewHotkeyEdit(parent Container) (*HotkeyEdit, error) {
+	he := new(HotkeyEdit)
+
+	if err := InitChildWidget(
+		he,
+		parent,
+		"msctls_hotkey32",
+		WS_TABSTOP|WS_VISIBLE,
+		WS_EX_CLIENTEDGE); err != nil {
+		return nil, err
+	}
+
+	he.updateRules()
+
+	he.MustRegisterProperty("Hotkey", NewProperty(
+		func() interface{} {
+			return he.Hotkey()
+		},
+		func(v interface{}) error {
+			return he.SetHotkey(v.(Hotkey))
+		},
+		he.hotkeyChangedPublisher.Event()))
+
+	return he, nil
+}
+
+func (*HotkeyEdit) LayoutFlags() LayoutFlags {
+	return GrowableHorz
+}
+
+func (he *HotkeyEdit) MinSizeHint() Size {
+	return he.dialogBaseUnitsToPixels(Size{64, 12})
+}
+
+func (he *HotkeyEdit) SizeHint() Size {
+	return he.MinSizeHint()
+}
+
+// Hotkey returns the recorded key combination, or the zero Hotkey.
+func (he *HotkeyEdit) Hotkey() Hotkey {
+	return he.hotkey
+}
+
+// SetHotkey sets the key combination shown. It must not contain the Windows
+// key and must not be reserved.
+func (he *HotkeyEdit) SetHotkey(hotkey Hotkey) error {
+	if hotkey.Modifiers&ModWin != 0 {
+		return newError("the Windows key is not supported")
+	}
+	if HotkeyReserved(hotkey) {
+		return newError("reserved hotkey")
+	}
+
+	he.SendMessage(hkmSetHotkey, uintptr(hotkeyToControl(hotkey)), 0)
+
+	he.setHotkey(hotkey)
+
+	return nil
+}
+
+func (he *HotkeyEdit) setHotkey(hotkey Hotkey) {
+	if hotkey == he.hotkey {
+		return
+	}
+
+	he.hotkey = hotkey
+
+	he.hotkeyChangedPublisher.Publish()
+}
+
+// Clear removes the key combination.
+func (he *HotkeyEdit) Clear() {
+	he.SetHotkey(Hotkey{})
+}
+
+// UnmodifiedAllowed returns if keys without Ctrl or Alt are recorded as they
+// are pressed.
+func (he *HotkeyEdit) UnmodifiedAllowed() bool {
+	return he.unmodifiedAllowed
+}
+
+// SetUnmodifiedAllowed sets if keys without Ctrl or Alt are recorded as they
+// are pressed. Otherwise, which is the default, Ctrl+Alt is added to them, as
+// global hotkeys without would hide the keys from other applications.
+func (he *HotkeyEdit) SetUnmodifiedAllowed(value bool) {
+	he.unmodifiedAllowed = value
+
+	he.updateRules()
+}
+
+func (he *HotkeyEdit) updateRules() {
+	if he.unmodifiedAllowed {
+		he.SendMessage(hkmSetRules, 0, 0)
+	} else {
+		he.SendMessage(hkmSetRules, hkcombNone|hkcombS, hotkeyfControl|hotkeyfAlt)
+	}
+}
+
+// HotkeyChanged returns the event that is published, when the key
+// combination changed.
+func (he *HotkeyEdit) HotkeyChanged() *Event {
+	return he.hotkeyChangedPublisher.Event()
+}
+
+// HotkeyRejected returns the *ErrorEvent that is published, when the user
+// pressed a reserved key combination. The previous one is shown again.
+func (he *HotkeyEdit) HotkeyRejected() *ErrorEvent {
+	return he.hotkeyRejectedPublisher.Event()
+}
+
+// hotkeyFromControl converts the value of the control, a virtual key code in
+// the low byte and HOTKEYF flags in the high byte.
+func hotkeyFromControl(value uint16) Hotkey {
+	key, flags := int(value&0xFF), byte(value>>8)
+	if key == 0 {
+		return Hotkey{}
+	}
+
+	var mod Modifiers
+	if flags&hotkeyfShift != 0 {
+		mod |= ModShift
+	}
+	if flags&hotkeyfControl != 0 {
+		mod |= ModControl
+	}
+	if flags&hotkeyfAlt != 0 {
+		mod |= ModAlt
+	}
+
+	return Hotkey{mod, key}
+}
+
+func hotkeyToControl(hotkey Hotkey) uint16 {
+	var flags uint16
+	if hotkey.Modifiers&ModShift != 0 {
+		flags |= hotkeyfShift
+	}
+	if hotkey.Modifiers&ModControl != 0 {
+		flags |= hotkeyfControl
+	}
+	if hotkey.Modifiers&ModAlt != 0 {
+		flags |= hotkeyfAlt
+	}
+
+	return flags<<8 | uint16(hotkey.Key&0xFF)
+}
+
+func (he *HotkeyEdit) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_COMMAND:
+		if HIWORD(uint32(wParam)) == EN_CHANGE {
+			hotkey := hotkeyFromControl(uint16(he.SendMessage(hkmGetHotkey, 0, 0)))
+
+			if HotkeyReserved(hotkey) {
+				he.SendMessage(hkmSetHotkey, uintptr(hotkeyToControl(he.hotkey)), 0)
+
+				he.hotkeyRejectedPublisher.Publish(errors.New(tr("This key combination is reserved.", "walk")))
+				break
+			}
+
+			he.setHotkey(hotkey)
+		}
+	}
+
+	return he.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
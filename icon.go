@@ -90,7 +90,7 @@ func createAlphaCursorOrIconFromImage(im image.Image, hotspot image.Point, fIcon
 	// Create an empty mask bitmap.
 	hMonoBitmap := CreateBitmap(int32(im.Bounds().Dx()), int32(im.Bounds().Dy()), 1, 1, nil)
 	if hMonoBitmap == 0 {
-		return 0, newError("CreateBitmap failed")
+		return 0, newAPIError("CreateBitmap")
 	}
 	defer DeleteObject(HGDIOBJ(hMonoBitmap))
 
@@ -0,0 +1,49 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import . "github.com/lxn/go-winapi"
+
+var idleFuncs []func()
+
+// Idle returns the *Event that is published by the message loop, whenever it
+// has processed all pending messages.
+//
+// This is the place for low-priority work, that has to be done frequently,
+// like updating the enabled state of actions. Handlers should be quick, as
+// the *Event is published often.
+func (app *Application) Idle() *Event {
+	return app.idlePublisher.Event()
+}
+
+// QueueIdle enqueues func f to be called once, the next time the message loop
+// has processed all pending messages, before the Idle event is published.
+//
+// QueueIdle must be called on the UI thread. From other goroutines, use
+// Synchronize instead.
+func (app *Application) QueueIdle(f func()) {
+	idleFuncs = append(idleFuncs, f)
+}
+
+// runIdle calls the queued idle funcs and publishes the Idle event, if the
+// message queue of the calling thread is empty.
+func runIdle() {
+	var msg MSG
+	if PeekMessage(&msg, 0, 0, 0, PM_NOREMOVE) {
+		return
+	}
+
+	funcs := idleFuncs
+	idleFuncs = nil
+
+	beginChangeBatch()
+	defer endChangeBatch()
+
+	for _, f := range funcs {
+		f()
+	}
+
+	appSingleton.idlePublisher.Publish()
+}
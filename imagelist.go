@@ -24,7 +24,7 @@ func NewImageList(imageSize Size, maskColor Color) (*ImageList, error) {
 		8,
 		8)
 	if hIml == 0 {
-		return nil, newError("ImageList_Create failed")
+		return nil, newAPIError("ImageList_Create")
 	}
 
 	return &ImageList{hIml: hIml, maskColor: maskColor}, nil
@@ -42,7 +42,7 @@ func (il *ImageList) Add(bitmap, maskBitmap *Bitmap) (int, error) {
 
 	index := int(ImageList_Add(il.hIml, bitmap.handle(), maskHandle))
 	if index == -1 {
-		return 0, newError("ImageList_Add failed")
+		return 0, newAPIError("ImageList_Add")
 	}
 
 	return index, nil
@@ -58,7 +58,7 @@ func (il *ImageList) AddMasked(bitmap *Bitmap) (int32, error) {
 		bitmap.handle(),
 		COLORREF(il.maskColor))
 	if index == -1 {
-		return 0, newError("ImageList_AddMasked failed")
+		return 0, newAPIError("ImageList_AddMasked")
 	}
 
 	return index, nil
@@ -84,7 +84,7 @@ func imageListForImage(image interface{}) (hIml HIMAGELIST, isSysIml bool, err e
 
 		hIml = ImageList_Create(w, h, ILC_MASK|ILC_COLOR24, 8, 8)
 		if hIml == 0 {
-			return 0, false, newError("ImageList_Create failed")
+			return 0, false, newAPIError("ImageList_Create")
 		}
 	}
 
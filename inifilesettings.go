@@ -14,7 +14,8 @@ import (
 )
 
 type IniFileSettings struct {
-	data map[string]string
+	data             map[string]string
+	changedPublisher StringEventPublisher
 }
 
 func NewIniFileSettings() *IniFileSettings {
@@ -31,11 +32,23 @@ func (ifs *IniFileSettings) Put(key, value string) error {
 		return newError("either key or value contains at least one of the invalid characters '=\\r\\n'")
 	}
 
+	if old, ok := ifs.data[key]; ok && old == value {
+		return nil
+	}
+
 	ifs.data[key] = value
 
+	ifs.changedPublisher.Publish(key)
+
 	return nil
 }
 
+// Changed returns the event that is published with the key of a value, that
+// changed by Put or Load.
+func (ifs *IniFileSettings) Changed() *StringEvent {
+	return ifs.changedPublisher.Event()
+}
+
 func (ifs *IniFileSettings) filePath() (string, error) {
 	appDataPath, err := AppDataPath()
 	if err != nil {
@@ -123,7 +136,11 @@ func (ifs *IniFileSettings) Load() error {
 			key := strings.TrimSpace(lineStr[:assignIndex])
 			val := strings.TrimSpace(lineStr[assignIndex+1:])
 
-			ifs.data[key] = val
+			if old, ok := ifs.data[key]; !ok || old != val {
+				ifs.data[key] = val
+
+				ifs.changedPublisher.Publish(key)
+			}
 		}
 
 		return nil
@@ -0,0 +1,184 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"net"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	ipmClearAddress = WM_USER + 100
+	ipmSetAddress   = WM_USER + 101
+	ipmGetAddress   = WM_USER + 102
+	ipmSetRange     = WM_USER + 103
+	ipmIsBlank      = WM_USER + 105
+)
+
+// IPAddressEdit wraps the IP address control, which edits an IPv4 address in
+// four fields.
+//
+// Its Value property can be bound to fields of type net.IP or string.
+type IPAddressEdit struct {
+	WidgetBase
+	ranges                [4][2]byte
+	valueChangedPublisher EventPublisher
+}
+
+// NewIPAddressEdit returns a new, blank *IPAddressEdit.
+func NewIPAddressEdit(parent Container) (*IPAddressEdit, error) {
+	ie := &IPAddressEdit{ranges: [4][2]byte{{0, 255}, {0, 255}, {0, 255}, {0, 255}}}
+
+	if err := InitChildWidget(
+		ie,
+		parent,
+		"SysIPAddress32",
+		WS_TABSTOP|WS_VISIBLE,
+		0); err != nil {
+		return nil, err
+	}
+
+	ie.MustRegisterProperty("Value", NewProperty(
+		func() interface{} {
+			return ie.Value()
+		},
+		func(v interface{}) error {
+			// Fields of type string are empty without an address.
+			if s, ok := v.(string); ok {
+				return ie.SetText(s)
+			}
+
+			return ie.SetValue(v.(net.IP))
+		},
+		ie.valueChangedPublisher.Event()))
+
+	return ie, nil
+}
+
+func (*IPAddressEdit) LayoutFlags() LayoutFlags {
+	return GrowableHorz
+}
+
+func (ie *IPAddressEdit) MinSizeHint() Size {
+	return ie.dialogBaseUnitsToPixels(Size{72, 12})
+}
+
+func (ie *IPAddressEdit) SizeHint() Size {
+	return ie.MinSizeHint()
+}
+
+// Value returns the address, or nil if all fields are blank. Blank fields
+// count as 0.
+func (ie *IPAddressEdit) Value() net.IP {
+	if ie.SendMessage(ipmIsBlank, 0, 0) != 0 {
+		return nil
+	}
+
+	var addr uint32
+	ie.SendMessage(ipmGetAddress, 0, uintptr(unsafe.Pointer(&addr)))
+
+	return net.IPv4(byte(addr>>24), byte(addr>>16), byte(addr>>8), byte(addr))
+}
+
+// SetValue sets the address, which must be an IPv4 address. A nil value
+// blanks all fields.
+func (ie *IPAddressEdit) SetValue(value net.IP) error {
+	if value == nil {
+		ie.SendMessage(ipmClearAddress, 0, 0)
+	} else {
+		ip := value.To4()
+		if ip == nil {
+			return newError("not an IPv4 address")
+		}
+
+		for i, b := range ip {
+			if b < ie.ranges[i][0] || b > ie.ranges[i][1] {
+				return newError("address out of range")
+			}
+		}
+
+		addr := uint32(ip[0])<<24 | uint32(ip[1])<<16 | uint32(ip[2])<<8 | uint32(ip[3])
+		ie.SendMessage(ipmSetAddress, 0, uintptr(addr))
+	}
+
+	ie.valueChangedPublisher.Publish()
+
+	return nil
+}
+
+// Text returns the address in dotted notation, or an empty string if all
+// fields are blank.
+func (ie *IPAddressEdit) Text() string {
+	if ip := ie.Value(); ip != nil {
+		return ip.String()
+	}
+
+	return ""
+}
+
+// SetText sets the address in dotted notation. An empty string blanks all
+// fields.
+func (ie *IPAddressEdit) SetText(value string) error {
+	if value == "" {
+		return ie.SetValue(nil)
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return newError("invalid IP address")
+	}
+
+	return ie.SetValue(ip)
+}
+
+// FieldRange returns the range of values field 0 to 3 accepts.
+func (ie *IPAddressEdit) FieldRange(field int) (min, max byte) {
+	if field < 0 || field > 3 {
+		return 0, 0
+	}
+
+	return ie.ranges[field][0], ie.ranges[field][1]
+}
+
+// SetFieldRange sets the range of values field 0 to 3 accepts, e.g. 10 to 10
+// for the first field, to restrict input to a private network. Values out of
+// range are corrected, when the user leaves the field.
+func (ie *IPAddressEdit) SetFieldRange(field int, min, max byte) error {
+	if field < 0 || field > 3 {
+		return newError("field out of range")
+	}
+	if min > max {
+		return newError("invalid range")
+	}
+
+	if 0 == ie.SendMessage(ipmSetRange, uintptr(field), uintptr(max)<<8|uintptr(min)) {
+		return newAPIError("SendMessage(IPM_SETRANGE)")
+	}
+
+	ie.ranges[field] = [2]byte{min, max}
+
+	return nil
+}
+
+// ValueChanged returns the event that is published, when the address
+// changed.
+func (ie *IPAddressEdit) ValueChanged() *Event {
+	return ie.valueChangedPublisher.Event()
+}
+
+func (ie *IPAddressEdit) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_COMMAND:
+		// The control receives EN_CHANGE from its fields too, but only
+		// the one it sends itself is reflected by the parent.
+		if HIWORD(uint32(wParam)) == EN_CHANGE && HWND(lParam) == hwnd {
+			ie.valueChangedPublisher.Publish()
+		}
+	}
+
+	return ie.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
@@ -0,0 +1,33 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+// ItemActivationMode specifies how the user activates items of a TableView,
+// TreeView or ListBox, which publishes ItemActivated then.
+type ItemActivationMode int
+
+const (
+	// ItemActivationDoubleClick activates an item, when it is double
+	// clicked.
+	ItemActivationDoubleClick ItemActivationMode = iota
+
+	// ItemActivationSingleClick activates an item, when it is clicked.
+	ItemActivationSingleClick
+
+	// ItemActivationHover highlights the item under the mouse and activates
+	// it, when it is clicked, like the web style of the Windows Explorer.
+	ItemActivationHover
+)
+
+const (
+	lvsExTrackSelect       = 0x00000008
+	lvsExOneClickActivate  = 0x00000040
+	lvsExUnderlineHot      = 0x00000800
+	lvsExItemActivationAll = lvsExTrackSelect | lvsExOneClickActivate | lvsExUnderlineHot
+)
+
+func (mode ItemActivationMode) valid() bool {
+	return mode >= ItemActivationDoubleClick && mode <= ItemActivationHover
+}
@@ -0,0 +1,352 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONSettings is a Settings implementation, that stores its data in a JSON
+// file.
+//
+// Keys are hierarchical, with levels separated by "/", e.g.
+// "MainWindow/Geometry", and map to nested JSON objects. The file is located
+// at %APPDATA%\<OrganizationName>\<ProductName>\settings.json.
+//
+// A key may have a value and nested keys at the same time, like the state of
+// a window and that of its children. The value is then stored in the object
+// under "@". Empty levels, like those of unnamed widgets, are stored as
+// "@empty". Levels that start with "@" get another "@" in front.
+type JSONSettings struct {
+	data             map[string]interface{}
+	changedPublisher StringEventPublisher
+}
+
+// NewJSONSettings returns a new, empty *JSONSettings.
+func NewJSONSettings() *JSONSettings {
+	return &JSONSettings{data: make(map[string]interface{})}
+}
+
+// Get returns the value for key.
+//
+// Values that were not stored as strings, e.g. using PutInt, are returned in
+// their JSON encoding.
+func (js *JSONSettings) Get(key string) (string, bool) {
+	val, ok := js.value(key)
+	if !ok {
+		return "", false
+	}
+
+	switch v := val.(type) {
+	case string:
+		return v, true
+
+	case map[string]interface{}:
+		return "", false
+	}
+
+	b, err := json.Marshal(val)
+	if err != nil {
+		return "", false
+	}
+
+	return string(b), true
+}
+
+// Put stores value for key.
+func (js *JSONSettings) Put(key, value string) error {
+	return js.putValue(key, value)
+}
+
+// GetInt returns the value for key as an int.
+func (js *JSONSettings) GetInt(key string) (int, bool) {
+	switch v := js.valueOrNil(key).(type) {
+	case float64:
+		return int(v), true
+
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n, true
+		}
+	}
+
+	return 0, false
+}
+
+// PutInt stores value for key as a JSON number.
+func (js *JSONSettings) PutInt(key string, value int) error {
+	return js.putValue(key, float64(value))
+}
+
+// GetBool returns the value for key as a bool.
+func (js *JSONSettings) GetBool(key string) (bool, bool) {
+	switch v := js.valueOrNil(key).(type) {
+	case bool:
+		return v, true
+
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b, true
+		}
+	}
+
+	return false, false
+}
+
+// PutBool stores value for key as a JSON boolean.
+func (js *JSONSettings) PutBool(key string, value bool) error {
+	return js.putValue(key, value)
+}
+
+// GetRect returns the value for key as a Rectangle.
+//
+// Rectangles are stored as JSON arrays of the form [x, y, width, height].
+func (js *JSONSettings) GetRect(key string) (Rectangle, bool) {
+	a, ok := js.valueOrNil(key).([]interface{})
+	if !ok || len(a) != 4 {
+		return Rectangle{}, false
+	}
+
+	var n [4]int
+	for i, v := range a {
+		f, ok := v.(float64)
+		if !ok {
+			return Rectangle{}, false
+		}
+
+		n[i] = int(f)
+	}
+
+	return Rectangle{n[0], n[1], n[2], n[3]}, true
+}
+
+// PutRect stores value for key as a JSON array of the form
+// [x, y, width, height].
+func (js *JSONSettings) PutRect(key string, value Rectangle) error {
+	return js.putValue(key, []interface{}{
+		float64(value.X),
+		float64(value.Y),
+		float64(value.Width),
+		float64(value.Height),
+	})
+}
+
+// jsonSettingsValueKey is the member of an object, that holds the value of
+// the key the object belongs to.
+const jsonSettingsValueKey = "@"
+
+// jsonSettingsEmptySegment stands for an empty level of a key.
+const jsonSettingsEmptySegment = "@empty"
+
+// jsonSettingsSegments returns the escaped levels of key.
+func jsonSettingsSegments(key string) []string {
+	segments := strings.Split(key, "/")
+
+	for i, segment := range segments {
+		switch {
+		case segment == "":
+			segments[i] = jsonSettingsEmptySegment
+
+		case strings.HasPrefix(segment, "@"):
+			segments[i] = "@" + segment
+		}
+	}
+
+	return segments
+}
+
+// unescapeJSONSettingsSegment undoes the escaping of jsonSettingsSegments.
+func unescapeJSONSettingsSegment(segment string) string {
+	switch {
+	case segment == jsonSettingsEmptySegment:
+		return ""
+
+	case strings.HasPrefix(segment, "@@"):
+		return segment[1:]
+	}
+
+	return segment
+}
+
+func (js *JSONSettings) value(key string) (interface{}, bool) {
+	segments := jsonSettingsSegments(key)
+
+	m := js.data
+	for _, segment := range segments[:len(segments)-1] {
+		var ok bool
+		if m, ok = m[segment].(map[string]interface{}); !ok {
+			return nil, false
+		}
+	}
+
+	val, ok := m[segments[len(segments)-1]]
+	if child, isGroup := val.(map[string]interface{}); isGroup {
+		val, ok = child[jsonSettingsValueKey]
+	}
+
+	return val, ok
+}
+
+func (js *JSONSettings) valueOrNil(key string) interface{} {
+	val, _ := js.value(key)
+	return val
+}
+
+func (js *JSONSettings) putValue(key string, value interface{}) error {
+	segments := jsonSettingsSegments(key)
+
+	m := js.data
+	for _, segment := range segments[:len(segments)-1] {
+		child, ok := m[segment].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+
+			// An existing value stays the value of the key.
+			if old, ok := m[segment]; ok {
+				child[jsonSettingsValueKey] = old
+			}
+
+			m[segment] = child
+		}
+
+		m = child
+	}
+
+	last := segments[len(segments)-1]
+	if child, ok := m[last].(map[string]interface{}); ok {
+		m, last = child, jsonSettingsValueKey
+	}
+
+	if old, ok := m[last]; ok && reflect.DeepEqual(old, value) {
+		return nil
+	}
+
+	m[last] = value
+
+	js.changedPublisher.Publish(key)
+
+	return nil
+}
+
+// Changed returns the event that is published with the key of a value, that
+// changed by Put, one of the typed Put methods or Load.
+func (js *JSONSettings) Changed() *StringEvent {
+	return js.changedPublisher.Event()
+}
+
+// flattenJSONSettings adds the values of m to values, keyed by their
+// hierarchical keys.
+func flattenJSONSettings(m map[string]interface{}, prefix string, values map[string]interface{}) {
+	for k, v := range m {
+		if k == jsonSettingsValueKey {
+			values[strings.TrimSuffix(prefix, "/")] = v
+			continue
+		}
+
+		k = unescapeJSONSettingsSegment(k)
+
+		if child, ok := v.(map[string]interface{}); ok {
+			flattenJSONSettings(child, prefix+k+"/", values)
+		} else {
+			values[prefix+k] = v
+		}
+	}
+}
+
+func (js *JSONSettings) filePath() (string, error) {
+	appDataPath, err := AppDataPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(
+		appDataPath,
+		appSingleton.OrganizationName(),
+		appSingleton.ProductName(),
+		"settings.json"), nil
+}
+
+// Load reads the settings from the file, if it exists.
+func (js *JSONSettings) Load() error {
+	filePath, err := js.filePath()
+	if err != nil {
+		return err
+	}
+
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return wrapError(err)
+	}
+
+	data := make(map[string]interface{})
+	if err := json.Unmarshal(b, &data); err != nil {
+		return wrapError(err)
+	}
+
+	oldValues := make(map[string]interface{})
+	flattenJSONSettings(js.data, "", oldValues)
+
+	newValues := make(map[string]interface{})
+	flattenJSONSettings(data, "", newValues)
+
+	js.data = data
+
+	for key, val := range newValues {
+		if old, ok := oldValues[key]; !ok || !reflect.DeepEqual(old, val) {
+			js.changedPublisher.Publish(key)
+		}
+	}
+
+	for key := range oldValues {
+		if _, ok := newValues[key]; !ok {
+			js.changedPublisher.Publish(key)
+		}
+	}
+
+	return nil
+}
+
+// Save writes the settings to the file.
+//
+// The data is written to a temporary file first, which then replaces the
+// file, so that a crash while saving doesn't leave a truncated file behind.
+func (js *JSONSettings) Save() error {
+	filePath, err := js.filePath()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(js.data, "", "\t")
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return wrapError(err)
+	}
+
+	tempPath := filePath + ".tmp"
+
+	if err := ioutil.WriteFile(tempPath, b, 0644); err != nil {
+		return wrapError(err)
+	}
+
+	if err := os.Rename(tempPath, filePath); err != nil {
+		os.Remove(tempPath)
+		return wrapError(err)
+	}
+
+	return nil
+}
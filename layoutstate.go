@@ -0,0 +1,63 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"encoding/json"
+)
+
+// layoutStates, if not nil, replaces App().Settings() as the store used by
+// getState and putState.
+var layoutStates map[string]string
+
+// SaveLayoutState returns the arrangement of the *ContainerBase and its
+// descendants, like splitter positions, current tab pages and table view
+// columns.
+//
+// The state of the same widgets is saved as by SaveState, but into the
+// returned byte slice instead of App().Settings(). Pass it to LoadLayoutState
+// to restore the arrangement, e.g. to implement undo or "reset layout".
+func (cb *ContainerBase) SaveLayoutState() ([]byte, error) {
+	states := make(map[string]string)
+
+	if err := cb.withLayoutStates(states, func(p Persistable) error {
+		return p.SaveState()
+	}); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(states)
+}
+
+// LoadLayoutState restores an arrangement of the *ContainerBase and its
+// descendants, that was returned by SaveLayoutState.
+func (cb *ContainerBase) LoadLayoutState(state []byte) error {
+	states := make(map[string]string)
+
+	if err := json.Unmarshal(state, &states); err != nil {
+		return wrapError(err)
+	}
+
+	return cb.withLayoutStates(states, func(p Persistable) error {
+		return p.RestoreState()
+	})
+}
+
+func (cb *ContainerBase) withLayoutStates(states map[string]string, f func(p Persistable) error) error {
+	persistable, ok := cb.widget.(Persistable)
+	if !ok {
+		return newError("container does not support persistence")
+	}
+
+	old := layoutStates
+	layoutStates = states
+	defer func() {
+		layoutStates = old
+	}()
+
+	return BatchChanges(func() error {
+		return f(persistable)
+	})
+}
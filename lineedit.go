@@ -89,7 +89,7 @@ func NewLineEdit(parent Container) (*LineEdit, error) {
 func (le *LineEdit) CueBanner() string {
 	buf := make([]uint16, 128)
 	if FALSE == le.SendMessage(EM_GETCUEBANNER, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf))) {
-		newError("EM_GETCUEBANNER failed")
+		newAPIError("EM_GETCUEBANNER")
 		return ""
 	}
 
@@ -98,7 +98,7 @@ func (le *LineEdit) CueBanner() string {
 
 func (le *LineEdit) SetCueBanner(value string) error {
 	if FALSE == le.SendMessage(EM_SETCUEBANNER, FALSE, uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(value)))) {
-		return newError("EM_SETCUEBANNER failed")
+		return newAPIError("EM_SETCUEBANNER")
 	}
 
 	return nil
@@ -148,7 +148,7 @@ func (le *LineEdit) ReadOnly() bool {
 
 func (le *LineEdit) SetReadOnly(readOnly bool) error {
 	if 0 == le.SendMessage(EM_SETREADONLY, uintptr(BoolToBOOL(readOnly)), 0) {
-		return newError("SendMessage(EM_SETREADONLY)")
+		return newAPIError("SendMessage(EM_SETREADONLY)")
 	}
 
 	le.readOnlyChangedPublisher.Publish()
@@ -194,7 +194,7 @@ func (le *LineEdit) initCharWidth() {
 
 	hdc := GetDC(le.hWnd)
 	if hdc == 0 {
-		newError("GetDC failed")
+		newAPIError("GetDC")
 		return
 	}
 	defer ReleaseDC(le.hWnd, hdc)
@@ -205,7 +205,7 @@ func (le *LineEdit) initCharWidth() {
 
 	var s SIZE
 	if !GetTextExtentPoint32(hdc, &buf[0], int32(len(buf)), &s) {
-		newError("GetTextExtentPoint32 failed")
+		newAPIError("GetTextExtentPoint32")
 		return
 	}
 	le.charWidth = int(s.CX)
@@ -73,6 +73,7 @@ func NewLineErrorPresenter(parent Container) (*LineErrorPresenter, error) {
 		widget := lep.curWidget
 
 		if button == LeftButton && widget != nil {
+			widget.ScrollIntoView()
 			widget.SetFocus()
 
 			if textSel, ok := widget.(textSelectable); ok {
@@ -143,7 +144,9 @@ func (lep *LineErrorPresenter) PresentError(err error, widget Widget) {
 	var background Brush
 
 	if err != nil {
-		background = lineErrorPresenterBackground
+		if !appSingleton.HighContrast() {
+			background = lineErrorPresenterBackground
+		}
 
 		var labelText string
 		if widget != nil {
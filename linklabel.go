@@ -0,0 +1,151 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	lwsTransparent = 0x0001
+
+	lmGetIdealSize = WM_USER + 0x301
+
+	maxLinkIDText    = 48
+	maxLinkURLLength = 2048 + 32 + len("://") + 1 // L_MAX_URL_LENGTH, sizeof counts the NUL
+)
+
+type lItem struct {
+	Mask      uint32
+	ILink     int32
+	State     uint32
+	StateMask uint32
+	SzID      [maxLinkIDText]uint16
+	SzUrl     [maxLinkURLLength]uint16
+}
+
+type nmLink struct {
+	Hdr  NMHDR
+	Item lItem
+}
+
+// LinkLabelLink is a link of a LinkLabel.
+type LinkLabelLink struct {
+	// Index is the zero based index of the link within the text.
+	Index int
+
+	// ID is the value of the id attribute of the link.
+	ID string
+
+	// URL is the value of the href attribute of the link.
+	URL string
+}
+
+// LinkLabel is a label, whose text may contain links like
+//
+//	Read the <a href="https://example.com/license">license</a> or <a id="more">more</a>.
+//
+// Activating a link by mouse or keyboard publishes the LinkActivated event.
+// The links are not opened by the *LinkLabel itself.
+type LinkLabel struct {
+	WidgetBase
+	textChangedPublisher   EventPublisher
+	linkActivatedPublisher LinkLabelLinkEventPublisher
+}
+
+// NewLinkLabel returns a new *LinkLabel.
+func NewLinkLabel(parent Container) (*LinkLabel, error) {
+	ll := new(LinkLabel)
+
+	if err := InitChildWidget(
+		ll,
+		parent,
+		"SysLink",
+		WS_TABSTOP|WS_VISIBLE|lwsTransparent,
+		0); err != nil {
+		return nil, err
+	}
+
+	ll.MustRegisterProperty("Text", NewProperty(
+		func() interface{} {
+			return ll.Text()
+		},
+		func(v interface{}) error {
+			return ll.SetText(v.(string))
+		},
+		ll.textChangedPublisher.Event()))
+
+	return ll, nil
+}
+
+func (*LinkLabel) LayoutFlags() LayoutFlags {
+	return GrowableVert
+}
+
+func (ll *LinkLabel) MinSizeHint() Size {
+	var s SIZE
+
+	ll.SendMessage(lmGetIdealSize, uintptr(0x7fff), uintptr(unsafe.Pointer(&s)))
+
+	return Size{int(s.CX), int(s.CY)}
+}
+
+func (ll *LinkLabel) SizeHint() Size {
+	return ll.MinSizeHint()
+}
+
+// Text returns the text of the *LinkLabel, including the markup of the links.
+func (ll *LinkLabel) Text() string {
+	return widgetText(ll.hWnd)
+}
+
+// SetText sets the text of the *LinkLabel, which may contain links as <a> tags
+// with id and href attributes.
+func (ll *LinkLabel) SetText(value string) error {
+	if value == ll.Text() {
+		return nil
+	}
+
+	if err := setWidgetText(ll.hWnd, value); err != nil {
+		return err
+	}
+
+	return ll.updateParentLayout()
+}
+
+// LinkActivated returns the event that is published, when the user activates
+// a link of the *LinkLabel.
+func (ll *LinkLabel) LinkActivated() *LinkLabelLinkEvent {
+	return ll.linkActivatedPublisher.Event()
+}
+
+func (ll *LinkLabel) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_SETTEXT:
+		// Handlers must see the new text, so it is set first.
+		result := ll.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+
+		ll.textChangedPublisher.Publish()
+
+		return result
+
+	case WM_NOTIFY:
+		nml := (*nmLink)(unsafe.Pointer(lParam))
+
+		switch int32(nml.Hdr.Code) {
+		case NM_CLICK, NM_RETURN:
+			ll.linkActivatedPublisher.Publish(&LinkLabelLink{
+				Index: int(nml.Item.ILink),
+				ID:    syscall.UTF16ToString(nml.Item.SzID[:]),
+				URL:   syscall.UTF16ToString(nml.Item.SzUrl[:]),
+			})
+		}
+	}
+
+	return ll.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
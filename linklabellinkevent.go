@@ -0,0 +1,43 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+type LinkLabelLinkEventHandler func(link *LinkLabelLink)
+
+type LinkLabelLinkEvent struct {
+	handlers []LinkLabelLinkEventHandler
+}
+
+func (e *LinkLabelLinkEvent) Attach(handler LinkLabelLinkEventHandler) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	return len(e.handlers) - 1
+}
+
+func (e *LinkLabelLinkEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type LinkLabelLinkEventPublisher struct {
+	event LinkLabelLinkEvent
+}
+
+func (p *LinkLabelLinkEventPublisher) Event() *LinkLabelLinkEvent {
+	return &p.event
+}
+
+func (p *LinkLabelLinkEventPublisher) Publish(link *LinkLabelLink) {
+	for _, handler := range p.event.handlers {
+		if handler != nil {
+			handler(link)
+		}
+	}
+}
@@ -27,6 +27,8 @@ type ListBox struct {
 	maxItemTextWidth             int
 	currentIndexChangedPublisher EventPublisher
 	dblClickedPublisher          EventPublisher
+	itemActivatedPublisher       EventPublisher
+	itemActivationMode           ItemActivationMode
 }
 
 func NewListBox(parent Container) (*ListBox, error) {
@@ -71,7 +73,7 @@ func (lb *ListBox) insertItemAt(index int) error {
 	lp := uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(str)))
 	ret := int(lb.SendMessage(LB_INSERTSTRING, uintptr(index), lp))
 	if ret == LB_ERRSPACE || ret == LB_ERR {
-		return newError("SendMessage(LB_INSERTSTRING)")
+		return newAPIError("SendMessage(LB_INSERTSTRING)")
 	}
 	return nil
 }
@@ -110,7 +112,7 @@ func (lb *ListBox) attachModel() {
 
 	itemChangedHandler := func(index int) {
 		if CB_ERR == lb.SendMessage(LB_DELETESTRING, uintptr(index), 0) {
-			newError("SendMessage(CB_DELETESTRING)")
+			newAPIError("SendMessage(CB_DELETESTRING)")
 		}
 
 		lb.insertItemAt(index)
@@ -215,7 +217,7 @@ func (lb *ListBox) SetPrecision(value int) {
 func (lb *ListBox) calculateMaxItemTextWidth() int {
 	hdc := GetDC(lb.hWnd)
 	if hdc == 0 {
-		newError("GetDC failed")
+		newAPIError("GetDC")
 		return -1
 	}
 	defer ReleaseDC(lb.hWnd, hdc)
@@ -235,7 +237,7 @@ func (lb *ListBox) calculateMaxItemTextWidth() int {
 		str := syscall.StringToUTF16(item)
 
 		if !GetTextExtentPoint32(hdc, &str[0], int32(len(str)-1), &s) {
-			newError("GetTextExtentPoint32 failed")
+			newAPIError("GetTextExtentPoint32")
 			return -1
 		}
 
@@ -289,6 +291,41 @@ func (lb *ListBox) DblClicked() *Event {
 	return lb.dblClickedPublisher.Event()
 }
 
+// ItemActivated returns the event that is published after the current item
+// was activated by clicking it as specified by the ItemActivationMode.
+func (lb *ListBox) ItemActivated() *Event {
+	return lb.itemActivatedPublisher.Event()
+}
+
+// ItemActivationMode returns how the user activates items.
+func (lb *ListBox) ItemActivationMode() ItemActivationMode {
+	return lb.itemActivationMode
+}
+
+// SetItemActivationMode sets how the user activates items.
+//
+// With ItemActivationHover, the item under the mouse becomes current.
+func (lb *ListBox) SetItemActivationMode(mode ItemActivationMode) error {
+	if !mode.valid() {
+		return newError("invalid mode")
+	}
+
+	lb.itemActivationMode = mode
+
+	return nil
+}
+
+// itemAt returns the index of the item at the client coordinates in lParam,
+// or -1 if there is none.
+func (lb *ListBox) itemAt(lParam uintptr) int {
+	ret := uint32(lb.SendMessage(lbItemFromPoint, 0, lParam))
+	if HIWORD(ret) != 0 {
+		return -1
+	}
+
+	return int(LOWORD(ret))
+}
+
 func (lb *ListBox) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	switch msg {
 	case WM_COMMAND:
@@ -299,6 +336,27 @@ func (lb *ListBox) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintpt
 
 		case LBN_DBLCLK:
 			lb.dblClickedPublisher.Publish()
+
+			if lb.itemActivationMode == ItemActivationDoubleClick {
+				lb.itemActivatedPublisher.Publish()
+			}
+		}
+
+	case WM_MOUSEMOVE:
+		if lb.itemActivationMode == ItemActivationHover && wParam&MK_LBUTTON == 0 {
+			if index := lb.itemAt(lParam); index > -1 && index != lb.CurrentIndex() {
+				lb.SetCurrentIndex(index)
+			}
+		}
+
+	case WM_LBUTTONUP:
+		if lb.itemActivationMode != ItemActivationDoubleClick && lb.itemAt(lParam) > -1 {
+			// Let the list box update the selection first.
+			ret := lb.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+
+			lb.itemActivatedPublisher.Publish()
+
+			return ret
 		}
 	}
 
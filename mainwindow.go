@@ -23,6 +23,7 @@ type MainWindow struct {
 	windowPlacement *WINDOWPLACEMENT
 	menu            *Menu
 	toolBar         *ToolBar
+	statusBar       *StatusBar
 	clientComposite *Composite
 }
 
@@ -58,6 +59,15 @@ func NewMainWindow() (*MainWindow, error) {
 		return nil, err
 	}
 
+	if mw.statusBar, err = NewStatusBar(mw); err != nil {
+		return nil, err
+	}
+	// The StatusBar is shown, once it has items.
+	mw.statusBar.SetVisible(false)
+	mw.statusBar.visibleChangedPublisher.Event().Attach(func() {
+		mw.clientComposite.SetBounds(mw.ClientBounds())
+	})
+
 	if mw.clientComposite, err = NewComposite(mw); err != nil {
 		return nil, err
 	}
@@ -107,6 +117,12 @@ func (mw *MainWindow) ToolBar() *ToolBar {
 	return mw.toolBar
 }
 
+// StatusBar returns the *StatusBar at the bottom of the *MainWindow, which is
+// hidden, until items are added to it.
+func (mw *MainWindow) StatusBar() *StatusBar {
+	return mw.statusBar
+}
+
 func (mw *MainWindow) ClientBounds() Rectangle {
 	bounds := mw.WidgetBase.ClientBounds()
 
@@ -117,6 +133,10 @@ func (mw *MainWindow) ClientBounds() Rectangle {
 		bounds.Height -= tlbBounds.Height
 	}
 
+	if mw.statusBar.visible {
+		bounds.Height -= mw.statusBar.Height()
+	}
+
 	return bounds
 }
 
@@ -155,7 +175,7 @@ func (mw *MainWindow) SetFullscreen(fullscreen bool) error {
 		if !GetMonitorInfo(MonitorFromWindow(
 			mw.hWnd, MONITOR_DEFAULTTOPRIMARY), &mi) {
 
-			return newError("GetMonitorInfo")
+			return lastWidgetError(&mw.WidgetBase, "GetMonitorInfo")
 		}
 
 		if err := mw.ensureStyleBits(WS_OVERLAPPEDWINDOW, false); err != nil {
@@ -226,6 +246,7 @@ func (mw *MainWindow) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uin
 	switch msg {
 	case WM_SIZE, WM_SIZING:
 		mw.toolBar.SendMessage(TB_AUTOSIZE, 0, 0)
+		mw.statusBar.SendMessage(WM_SIZE, 0, 0)
 
 		mw.clientComposite.SetBounds(mw.ClientBounds())
 	}
@@ -0,0 +1,430 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"errors"
+	"unicode"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const maskedEditPromptChar = '_'
+
+// maskPosition is a position of the mask of a MaskedEdit, which is either a
+// literal or takes a character of a class.
+type maskPosition struct {
+	literal  bool
+	char     rune
+	required bool
+}
+
+func (mp maskPosition) accepts(r rune) bool {
+	switch mp.char {
+	case '0', '9':
+		return unicode.IsDigit(r)
+
+	case 'L', '?':
+		return unicode.IsLetter(r)
+
+	case 'A', 'a':
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+
+	return unicode.IsPrint(r)
+}
+
+// parseMask parses mask into its positions.
+func parseMask(mask string) ([]maskPosition, error) {
+	var positions []maskPosition
+
+	var escaped bool
+	for _, r := range mask {
+		if escaped {
+			positions = append(positions, maskPosition{literal: true, char: r})
+			escaped = false
+			continue
+		}
+
+		switch r {
+		case '\\':
+			escaped = true
+
+		case '0', 'L', 'A', '&':
+			positions = append(positions, maskPosition{char: r, required: true})
+
+		case '9', '?', 'a', 'C':
+			positions = append(positions, maskPosition{char: r})
+
+		default:
+			positions = append(positions, maskPosition{literal: true, char: r})
+		}
+	}
+
+	if escaped {
+		return nil, newError("mask ends with an escape character")
+	}
+
+	return positions, nil
+}
+
+// MaskedEdit is a single-line edit for formatted input like phone numbers or
+// dates, which only accepts characters allowed by its mask.
+//
+// The characters of the mask are:
+//
+//	0  digit, required
+//	9  digit, optional
+//	L  letter, required
+//	?  letter, optional
+//	A  letter or digit, required
+//	a  letter or digit, optional
+//	&  any character, required
+//	C  any character, optional
+//	\  makes the next character a literal
+//
+// Any other character is a literal, which the caret skips while typing, e.g.
+// "(000) 000-0000" or "00/00/0000". Positions not yet entered show an
+// underscore.
+//
+// The Text property is validated to be complete, so a DataBinder does not
+// submit it before all required positions are entered.
+type MaskedEdit struct {
+	LineEdit
+	mask      string
+	positions []maskPosition
+	values    []rune
+}
+
+// NewMaskedEdit returns a new *MaskedEdit with mask.
+func NewMaskedEdit(parent Container, mask string) (*MaskedEdit, error) {
+	me := new(MaskedEdit)
+
+	if err := InitChildWidget(
+		me,
+		parent,
+		"EDIT",
+		WS_TABSTOP|WS_VISIBLE|ES_AUTOHSCROLL,
+		WS_EX_CLIENTEDGE); err != nil {
+		return nil, err
+	}
+
+	var succeeded bool
+	defer func() {
+		if !succeeded {
+			me.Dispose()
+		}
+	}()
+
+	me.MustRegisterProperty("ReadOnly", NewProperty(
+		func() interface{} {
+			return me.ReadOnly()
+		},
+		func(v interface{}) error {
+			return me.SetReadOnly(v.(bool))
+		},
+		me.readOnlyChangedPublisher.Event()))
+
+	textProperty := NewProperty(
+		func() interface{} {
+			return me.Text()
+		},
+		func(v interface{}) error {
+			return me.SetText(v.(string))
+		},
+		me.textChangedPublisher.Event())
+	if err := textProperty.SetValidator(maskCompleteValidator{me}); err != nil {
+		return nil, err
+	}
+	me.MustRegisterProperty("Text", textProperty)
+
+	if err := me.SetMask(mask); err != nil {
+		return nil, err
+	}
+
+	succeeded = true
+
+	return me, nil
+}
+
+// Mask returns the mask of the *MaskedEdit.
+func (me *MaskedEdit) Mask() string {
+	return me.mask
+}
+
+// SetMask sets the mask of the *MaskedEdit and keeps the entered characters,
+// as far as the new mask accepts them.
+func (me *MaskedEdit) SetMask(mask string) error {
+	positions, err := parseMask(mask)
+	if err != nil {
+		return err
+	}
+
+	text := me.Text()
+
+	me.mask = mask
+	me.positions = positions
+	me.values = make([]rune, len(positions))
+
+	me.SetMaxLength(len(positions))
+
+	return me.SetText(text)
+}
+
+// Text returns the text including literals, with spaces for the positions
+// not entered.
+func (me *MaskedEdit) Text() string {
+	return me.text(' ')
+}
+
+func (me *MaskedEdit) text(prompt rune) string {
+	runes := make([]rune, len(me.positions))
+
+	for i, p := range me.positions {
+		switch {
+		case p.literal:
+			runes[i] = p.char
+
+		case me.values[i] != 0:
+			runes[i] = me.values[i]
+
+		default:
+			runes[i] = prompt
+		}
+	}
+
+	return string(runes)
+}
+
+// SetText sets the entered characters from value, which may contain the
+// literals of the mask or not, e.g. "(555) 123-4567" or "5551234567".
+func (me *MaskedEdit) SetText(value string) error {
+	for i := range me.values {
+		me.values[i] = 0
+	}
+
+	runes := []rune(value)
+
+	var j int
+	for i, p := range me.positions {
+		if j == len(runes) {
+			break
+		}
+
+		if p.literal {
+			if runes[j] == p.char {
+				j++
+			}
+			continue
+		}
+
+		for j < len(runes) {
+			r := runes[j]
+			j++
+
+			if r == ' ' || r == maskedEditPromptChar {
+				break
+			}
+			if p.accepts(r) {
+				me.values[i] = r
+				break
+			}
+		}
+	}
+
+	return me.update(-1)
+}
+
+// UnmaskedText returns the entered characters without literals, e.g.
+// "5551234567".
+func (me *MaskedEdit) UnmaskedText() string {
+	var runes []rune
+
+	for i, p := range me.positions {
+		if !p.literal && me.values[i] != 0 {
+			runes = append(runes, me.values[i])
+		}
+	}
+
+	return string(runes)
+}
+
+// MaskCompleted returns if all required positions are entered.
+func (me *MaskedEdit) MaskCompleted() bool {
+	for i, p := range me.positions {
+		if p.required && me.values[i] == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// update shows the text and, if caret is not -1, moves the caret there.
+func (me *MaskedEdit) update(caret int) error {
+	if err := setWidgetText(me.hWnd, me.text(maskedEditPromptChar)); err != nil {
+		return err
+	}
+
+	if caret > -1 {
+		me.SetTextSelection(caret, caret)
+	}
+
+	return nil
+}
+
+// nextInput returns the first input position from index on, or the number of
+// positions, if there is none.
+func (me *MaskedEdit) nextInput(index int) int {
+	for index < len(me.positions) && me.positions[index].literal {
+		index++
+	}
+
+	return index
+}
+
+// clear removes the entered characters from start to end.
+func (me *MaskedEdit) clear(start, end int) {
+	for i := start; i < end && i < len(me.values); i++ {
+		me.values[i] = 0
+	}
+}
+
+// typeChar enters r at the caret, replacing the selection, and reports if it
+// was accepted.
+func (me *MaskedEdit) typeChar(r rune) bool {
+	start, end := me.TextSelection()
+	me.clear(start, end)
+
+	i := start
+	if i < len(me.positions) && me.positions[i].literal && me.positions[i].char == r {
+		// Typing a literal just skips it.
+		me.update(me.nextInput(i + 1))
+		return true
+	}
+
+	i = me.nextInput(i)
+	if i == len(me.positions) || !me.positions[i].accepts(r) {
+		if start != end {
+			me.update(start)
+		}
+		return false
+	}
+
+	me.values[i] = r
+
+	me.update(me.nextInput(i + 1))
+
+	return true
+}
+
+func (me *MaskedEdit) paste() {
+	text, err := clipboardText()
+	if err != nil {
+		return
+	}
+
+	// Characters the mask does not accept are dropped.
+	for _, r := range text {
+		me.typeChar(r)
+	}
+}
+
+func (me *MaskedEdit) deleteSelection(backward bool) {
+	start, end := me.TextSelection()
+
+	if start == end {
+		if backward {
+			for start > 0 && me.positions[start-1].literal {
+				start--
+			}
+			if start == 0 {
+				return
+			}
+			start--
+		} else {
+			start = me.nextInput(start)
+			if start == len(me.positions) {
+				return
+			}
+		}
+		end = start + 1
+	}
+
+	me.clear(start, end)
+
+	me.update(start)
+}
+
+func (me *MaskedEdit) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	if me.ReadOnly() {
+		return me.LineEdit.WndProc(hwnd, msg, wParam, lParam)
+	}
+
+	switch msg {
+	case WM_CHAR:
+		switch wParam {
+		case VK_BACK:
+			me.deleteSelection(true)
+
+		case 0x16: // Ctrl+V
+			me.paste()
+
+		case 0x18: // Ctrl+X
+			me.SendMessage(WM_CUT, 0, 0)
+
+		case 0x03: // Ctrl+C
+			return me.LineEdit.WndProc(hwnd, msg, wParam, lParam)
+
+		default:
+			if wParam >= ' ' {
+				me.typeChar(rune(wParam))
+			}
+		}
+		return 0
+
+	case WM_KEYDOWN:
+		if wParam == VK_DELETE {
+			me.deleteSelection(false)
+			return 0
+		}
+
+	case WM_PASTE:
+		me.paste()
+		return 0
+
+	case WM_CUT, WM_CLEAR:
+		// Like a native edit control, nothing happens without a selection.
+		if start, end := me.TextSelection(); start == end {
+			return 0
+		}
+
+		if msg == WM_CUT {
+			me.LineEdit.WndProc(hwnd, WM_COPY, 0, 0)
+		}
+		me.deleteSelection(false)
+		return 0
+
+	case WM_UNDO, EM_UNDO:
+		// The undo buffer of the control knows nothing about the mask.
+		return 0
+	}
+
+	return me.LineEdit.WndProc(hwnd, msg, wParam, lParam)
+}
+
+// maskCompleteValidator validates, that the mask of a MaskedEdit is
+// complete.
+type maskCompleteValidator struct {
+	me *MaskedEdit
+}
+
+func (v maskCompleteValidator) Validate(value interface{}) error {
+	if !v.me.MaskCompleted() {
+		return errors.New(tr("Please complete the entry.", "walk"))
+	}
+
+	return nil
+}
@@ -11,10 +11,20 @@ import (
 
 import . "github.com/lxn/go-winapi"
 
+var menusByHandle = make(map[HMENU]*Menu)
+
 type Menu struct {
-	hMenu   HMENU
-	hWnd    HWND
-	actions *ActionList
+	actionPresentations
+	hMenu                HMENU
+	hWnd                 HWND
+	actions              *ActionList
+	beforePopupPublisher EventPublisher
+	nativeItemCount      int
+	system               bool
+	bar                  bool
+	merged               []mergedAction
+	mergeSeparators      []*Action
+	rightToLeft          bool
 }
 
 func newMenuBar() (*Menu, error) {
@@ -23,9 +33,18 @@ func newMenuBar() (*Menu, error) {
 		return nil, lastError("CreateMenu")
 	}
 
-	m := &Menu{hMenu: hMenu}
+	m := &Menu{hMenu: hMenu, bar: true}
 	m.actions = newActionList(m)
 
+	menusByHandle[hMenu] = m
+
+	if themedMenus() {
+		if err := m.applyTheme(); err != nil {
+			m.Dispose()
+			return nil, err
+		}
+	}
+
 	return m, nil
 }
 
@@ -52,12 +71,24 @@ func NewMenu() (*Menu, error) {
 	m := &Menu{hMenu: hMenu}
 	m.actions = newActionList(m)
 
+	menusByHandle[hMenu] = m
+
+	if themedMenus() {
+		if err := m.applyTheme(); err != nil {
+			m.Dispose()
+			return nil, err
+		}
+	}
+
 	return m, nil
 }
 
 func (m *Menu) Dispose() {
 	if m.hMenu != 0 {
-		DestroyMenu(m.hMenu)
+		delete(menusByHandle, m.hMenu)
+		if !m.system {
+			DestroyMenu(m.hMenu)
+		}
 		m.hMenu = 0
 	}
 }
@@ -70,19 +101,223 @@ func (m *Menu) Actions() *ActionList {
 	return m.actions
 }
 
+// RightToLeft returns if the *Menu is laid out from right to left.
+func (m *Menu) RightToLeft() bool {
+	return m.rightToLeft
+}
+
+// SetRightToLeft sets if the *Menu and its submenus are laid out from right to
+// left, as needed for languages like Arabic and Hebrew.
+//
+// Menus are also laid out from right to left, if App().RightToLeft() returns
+// true.
+func (m *Menu) SetRightToLeft(value bool) error {
+	m.rightToLeft = value
+
+	for _, action := range m.actions.actions {
+		if action.menu != nil {
+			if err := action.menu.SetRightToLeft(value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return m.updateItems()
+}
+
+func (m *Menu) isRightToLeft() bool {
+	return m.rightToLeft || appSingleton.rightToLeft
+}
+
+// popupLayoutFlags returns the TrackPopupMenuEx flags for the layout of the
+// *Menu.
+func (m *Menu) popupLayoutFlags() uint32 {
+	if m.isRightToLeft() {
+		return TPM_LAYOUTRTL | TPM_RIGHTALIGN
+	}
+
+	return 0
+}
+
+// updateItems reinitializes the native items of the *Menu, e.g. after a
+// setting affecting all of them changed.
+func (m *Menu) updateItems() error {
+	for _, action := range m.actions.actions {
+		if err := m.onActionChanged(action); err != nil {
+			return err
+		}
+	}
+
+	if m.bar && m.hWnd != 0 {
+		DrawMenuBar(m.hWnd)
+	}
+
+	return nil
+}
+
+// FindAction returns the first *Action named name among the Actions of the
+// *Menu and, recursively, its submenus, or nil if there is none.
+func (m *Menu) FindAction(name string) *Action {
+	for _, action := range m.actions.actions {
+		if action.name == name {
+			return action
+		}
+
+		if action.menu != nil {
+			if a := action.menu.FindAction(name); a != nil {
+				return a
+			}
+		}
+	}
+
+	return nil
+}
+
+// BeforePopup returns an *Event that is published right before the Menu is
+// shown as a popup or drop down menu.
+//
+// This allows to populate the Actions of the Menu just in time.
+func (m *Menu) BeforePopup() *Event {
+	return m.beforePopupPublisher.Event()
+}
+
+// Exec shows the *Menu as a popup menu at x, y in screen coordinates and blocks
+// until the user either chose an *Action, which is then returned, or canceled
+// the menu, in which case nil is returned.
+//
+// The Triggered event of the chosen *Action is not published, the caller is
+// expected to act upon the return value. The active window of the calling
+// thread becomes the owner of the popup menu.
+func (m *Menu) Exec(x, y int) (*Action, error) {
+	return m.exec(x, y, m.popupLayoutFlags(), nil)
+}
+
+// ExecAnchored works like Exec, but shows the *Menu next to anchor, which is
+// in screen coordinates, e.g. below a button.
+//
+// If there is not enough room on the side specified by placement, within the
+// work area of the monitor showing anchor, the *Menu is flipped to the
+// opposite side, so that it never covers anchor.
+func (m *Menu) ExecAnchored(anchor Rectangle, placement PopupPlacement) (*Action, error) {
+	params := TPMPARAMS{RcExclude: anchor.toRECT()}
+	params.CbSize = uint32(unsafe.Sizeof(params))
+
+	rtl := m.isRightToLeft()
+
+	var x, y int
+	var flags uint32
+
+	switch placement {
+	case PopupBelow, PopupAbove:
+		flags = TPM_VERTICAL
+
+		if placement == PopupBelow {
+			y = anchor.Y + anchor.Height
+			flags |= TPM_TOPALIGN
+		} else {
+			y = anchor.Y
+			flags |= TPM_BOTTOMALIGN
+		}
+
+		if rtl {
+			x = anchor.X + anchor.Width
+		} else {
+			x = anchor.X
+		}
+
+	case PopupRight, PopupLeft:
+		flags = TPM_HORIZONTAL | TPM_TOPALIGN
+		y = anchor.Y
+
+		if placement == PopupRight {
+			x = anchor.X + anchor.Width
+			flags |= TPM_LEFTALIGN
+		} else {
+			x = anchor.X
+			flags |= TPM_RIGHTALIGN
+		}
+	}
+
+	if rtl {
+		flags |= TPM_LAYOUTRTL
+
+		if placement != PopupRight {
+			flags |= TPM_RIGHTALIGN
+		}
+	}
+
+	return m.exec(x, y, flags, &params)
+}
+
+func (m *Menu) exec(x, y int, flags uint32, params *TPMPARAMS) (*Action, error) {
+	if m.IsDisposed() {
+		return nil, newError("menu is disposed")
+	}
+
+	hwndOwner := GetActiveWindow()
+	if hwndOwner == 0 {
+		return nil, newError("no active window")
+	}
+
+	actionId := uint16(TrackPopupMenuEx(
+		m.hMenu,
+		TPM_NOANIMATION|TPM_RETURNCMD|flags,
+		int32(x),
+		int32(y),
+		hwndOwner,
+		params))
+	if actionId == 0 {
+		return nil, nil
+	}
+
+	return actionsById[actionId], nil
+}
+
+// position returns the position of the menu item of action in the native menu.
+//
+// Items not managed by walk, like those of a system menu, come first.
+func (m *Menu) position(action *Action) uint32 {
+	return uint32(m.nativeItemCount + m.actions.indexInObserver(action))
+}
+
 func (m *Menu) initMenuItemInfoFromAction(mii *MENUITEMINFO, action *Action) {
 	mii.CbSize = uint32(unsafe.Sizeof(*mii))
 	mii.FMask = MIIM_FTYPE | MIIM_ID | MIIM_STATE | MIIM_STRING
-	if action.image != nil {
+	text := m.actionText(action)
+	if image := m.actionImage(action); image != nil {
 		mii.FMask |= MIIM_BITMAP
-		mii.HbmpItem = action.image.handle()
+		mii.HbmpItem = image.handle()
 	}
-	if action.text == "-" {
+	if text == "-" {
 		mii.FType = MFT_SEPARATOR
+	} else if action.OwnerDrawn() {
+		mii.FMask &^= MIIM_STRING
+		mii.FType = MFT_OWNERDRAW
+	} else if drawsMenuItem(action) {
+		// We keep the text, so the menu still handles mnemonics.
+		mii.FMask &^= MIIM_BITMAP
+		mii.FMask |= MIIM_DATA
+		mii.FType = MFT_OWNERDRAW
+		mii.DwTypeData = syscall.StringToUTF16Ptr(text)
+		mii.Cch = uint32(len([]rune(text)))
+		// The item data identifies the menu when measuring and drawing.
+		mii.DwItemData = uintptr(m.hMenu)
 	} else {
 		mii.FType = MFT_STRING
-		mii.DwTypeData = syscall.StringToUTF16Ptr(action.text)
-		mii.Cch = uint32(len([]rune(action.text)))
+		mii.DwTypeData = syscall.StringToUTF16Ptr(text)
+		mii.Cch = uint32(len([]rune(text)))
+	}
+
+	if m.isRightToLeft() {
+		mii.FType |= MFT_RIGHTORDER
+	}
+
+	switch action.menuBreak {
+	case MenuBreakColumn:
+		mii.FType |= MFT_MENUBREAK
+
+	case MenuBreakColumnWithBar:
+		mii.FType |= MFT_MENUBARBREAK
 	}
 	mii.WID = uint32(action.id)
 
@@ -92,6 +327,10 @@ func (m *Menu) initMenuItemInfoFromAction(mii *MENUITEMINFO, action *Action) {
 		mii.FState |= MFS_DISABLED
 	}
 
+	if action.Default() {
+		mii.FState |= MFS_DEFAULT
+	}
+
 	menu := action.menu
 	if menu != nil {
 		mii.FMask |= MIIM_SUBMENU
@@ -100,7 +339,7 @@ func (m *Menu) initMenuItemInfoFromAction(mii *MENUITEMINFO, action *Action) {
 }
 
 func (m *Menu) onActionChanged(action *Action) error {
-	if !action.Visible() {
+	if !m.actionVisible(action) {
 		return nil
 	}
 
@@ -108,14 +347,20 @@ func (m *Menu) onActionChanged(action *Action) error {
 
 	m.initMenuItemInfoFromAction(&mii, action)
 
-	if !SetMenuItemInfo(m.hMenu, uint32(m.actions.indexInObserver(action)), true, &mii) {
-		return newError("SetMenuItemInfo failed")
+	if !SetMenuItemInfo(m.hMenu, m.position(action), true, &mii) {
+		return newAPIError("SetMenuItemInfo")
 	}
 
 	return nil
 }
 
 func (m *Menu) onActionVisibleChanged(action *Action) error {
+	if m.actionHidden(action) {
+		// The action is not presented, so there is no item to insert or
+		// remove.
+		return nil
+	}
+
 	if action.Visible() {
 		return m.onInsertedAction(action)
 	}
@@ -131,18 +376,20 @@ func (m *Menu) onInsertedAction(action *Action) (err error) {
 		}
 	}()
 
-	if !action.Visible() {
+	if !m.actionVisible(action) {
 		return
 	}
 
-	index := m.actions.indexInObserver(action)
+	return m.insertItem(action)
+}
 
+func (m *Menu) insertItem(action *Action) error {
 	var mii MENUITEMINFO
 
 	m.initMenuItemInfoFromAction(&mii, action)
 
-	if !InsertMenuItem(m.hMenu, uint32(index), true, &mii) {
-		return newError("InsertMenuItem failed")
+	if !InsertMenuItem(m.hMenu, m.position(action), true, &mii) {
+		return newAPIError("InsertMenuItem")
 	}
 
 	menu := action.menu
@@ -154,18 +401,24 @@ func (m *Menu) onInsertedAction(action *Action) (err error) {
 		DrawMenuBar(m.hWnd)
 	}
 
-	return
+	return nil
 }
 
 func (m *Menu) onRemovingAction(action *Action) error {
-	index := m.actions.indexInObserver(action)
-
-	if !RemoveMenu(m.hMenu, uint32(index), MF_BYPOSITION) {
-		return lastError("RemoveMenu")
+	if err := m.removeItem(action); err != nil {
+		return err
 	}
 
 	action.removeChangedHandler(m)
 
+	return nil
+}
+
+func (m *Menu) removeItem(action *Action) error {
+	if !RemoveMenu(m.hMenu, m.position(action), MF_BYPOSITION) {
+		return lastError("RemoveMenu")
+	}
+
 	if m.hWnd != 0 {
 		DrawMenuBar(m.hWnd)
 	}
@@ -173,9 +426,31 @@ func (m *Menu) onRemovingAction(action *Action) error {
 	return nil
 }
 
+// SetActionPresentation sets how action appears in the *Menu, overriding its
+// text, image or visibility. Pass nil to remove the override.
+func (m *Menu) SetActionPresentation(action *Action, presentation *ActionPresentation) error {
+	if !m.actions.Contains(action) {
+		return newError("action not in menu")
+	}
+
+	if m.actionVisible(action) {
+		if err := m.removeItem(action); err != nil {
+			return err
+		}
+	}
+
+	m.setActionPresentation(action, presentation)
+
+	if m.actionVisible(action) {
+		return m.insertItem(action)
+	}
+
+	return nil
+}
+
 func (m *Menu) onClearingActions() error {
 	for i := m.actions.Len() - 1; i >= 0; i-- {
-		if action := m.actions.At(i); action.Visible() {
+		if action := m.actions.At(i); m.actionVisible(action) {
 			if err := m.onRemovingAction(action); err != nil {
 				return err
 			}
@@ -184,3 +459,122 @@ func (m *Menu) onClearingActions() error {
 
 	return nil
 }
+
+// actionFromMenuSelect returns the *Action a WM_MENUSELECT message refers to,
+// or nil if there is none.
+func actionFromMenuSelect(wParam, lParam uintptr) *Action {
+	flags := HIWORD(uint32(wParam))
+	if flags == 0xFFFF && lParam == 0 {
+		// The menu was closed.
+		return nil
+	}
+
+	if flags&MF_POPUP == 0 {
+		return actionsById[LOWORD(uint32(wParam))]
+	}
+
+	menu, ok := menusByHandle[HMENU(lParam)]
+	if !ok {
+		return nil
+	}
+
+	index := int(LOWORD(uint32(wParam))) - menu.nativeItemCount
+	for _, action := range menu.actions.actions {
+		if !menu.actionVisible(action) {
+			continue
+		}
+		if index == 0 {
+			return action
+		}
+		index--
+	}
+
+	return nil
+}
+
+func measureMenuItem(hwnd HWND, mis *MEASUREITEMSTRUCT) bool {
+	if mis.CtlType != ODT_MENU {
+		return false
+	}
+
+	action, ok := actionsById[uint16(mis.ItemID)]
+	if !ok || !action.OwnerDrawn() && !drawsMenuItem(action) {
+		return false
+	}
+
+	menu := menusByHandle[HMENU(mis.ItemData)]
+	if menu == nil && !action.OwnerDrawn() {
+		return false
+	}
+
+	canvas, err := newCanvasFromHWND(hwnd)
+	if err != nil {
+		return false
+	}
+	defer canvas.Dispose()
+
+	var size Size
+	if action.OwnerDrawn() {
+		size, err = action.measureFunc(action, canvas)
+	} else {
+		size, err = measureThemedMenuItem(action, menu.actionText(action), canvas, menu.bar)
+	}
+	if err != nil {
+		return false
+	}
+
+	mis.ItemWidth = uint32(size.Width)
+	mis.ItemHeight = uint32(size.Height)
+
+	return true
+}
+
+func drawMenuItem(dis *DRAWITEMSTRUCT) bool {
+	if dis.CtlType != ODT_MENU {
+		return false
+	}
+
+	action, ok := actionsById[uint16(dis.ItemID)]
+	if !ok || !action.OwnerDrawn() && !drawsMenuItem(action) {
+		return false
+	}
+
+	menu := menusByHandle[HMENU(dis.ItemData)]
+	if menu == nil && !action.OwnerDrawn() {
+		return false
+	}
+
+	canvas, err := newCanvasFromHDC(dis.HDC)
+	if err != nil {
+		return false
+	}
+	defer canvas.Dispose()
+
+	var state MenuItemState
+	if dis.ItemState&ODS_SELECTED != 0 {
+		state |= MenuItemSelected
+	}
+	if dis.ItemState&(ODS_DISABLED|ODS_GRAYED) != 0 {
+		state |= MenuItemDisabled
+	}
+	if dis.ItemState&ODS_CHECKED != 0 {
+		state |= MenuItemChecked
+	}
+
+	bounds := rectangleFromRECT(dis.RcItem)
+
+	if action.OwnerDrawn() {
+		return action.drawFunc(action, canvas, bounds, state) == nil
+	}
+
+	if err := drawThemedMenuItem(menu, action, canvas, bounds, state); err != nil {
+		return false
+	}
+
+	if action.menu != nil {
+		// Keep the system from drawing its submenu arrow over ours.
+		ExcludeClipRect(dis.HDC, dis.RcItem.Left, dis.RcItem.Top, dis.RcItem.Right, dis.RcItem.Bottom)
+	}
+
+	return true
+}
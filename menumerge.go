@@ -0,0 +1,211 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"strconv"
+	"strings"
+)
+
+// mergeGroupSize is the range of merge priorities that form a group of menu
+// items. Groups are separated from each other by separators.
+const mergeGroupSize = 100
+
+type mergedAction struct {
+	action   *Action
+	priority int
+}
+
+// MergeAction inserts action into the *Menu or one of its submenus, as
+// specified by location, e.g. "File/Export@300".
+//
+// The part before the "@" is the path of the submenu, its segments matching
+// the name or the text, without "&", of submenu Actions. Missing submenus are
+// created. The part after the "@" is the merge priority, which defaults to 0.
+//
+// Merged Actions follow the other Actions of a menu, ordered by priority, and
+// Actions with equal priority keep the order in which they were merged.
+// Priorities are grouped by hundreds, e.g. 300 to 399, and a separator is put
+// between groups. This allows independent modules, like plugins, to contribute
+// menu items in a stable order.
+func (m *Menu) MergeAction(location string, action *Action) error {
+	path, priority, err := parseMergeLocation(location)
+	if err != nil {
+		return err
+	}
+
+	target := m
+	for _, segment := range path {
+		if target, err = target.mergeSubmenu(segment, priority); err != nil {
+			return err
+		}
+	}
+
+	return target.merge(action, priority)
+}
+
+// UnmergeAction removes action, that was added using MergeAction, from the
+// *Menu or the submenu it was merged into.
+func (m *Menu) UnmergeAction(action *Action) error {
+	for _, ma := range m.merged {
+		if ma.action == action {
+			return m.unmerge(action)
+		}
+	}
+
+	for _, a := range m.actions.actions {
+		if a.menu != nil {
+			if err := a.menu.UnmergeAction(action); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func parseMergeLocation(location string) (path []string, priority int, err error) {
+	if i := strings.LastIndex(location, "@"); i > -1 {
+		if priority, err = strconv.Atoi(location[i+1:]); err != nil {
+			return nil, 0, newError("invalid merge priority")
+		}
+
+		location = location[:i]
+	}
+
+	for _, segment := range strings.Split(location, "/") {
+		if segment != "" {
+			path = append(path, segment)
+		}
+	}
+
+	return path, priority, nil
+}
+
+// mergeSubmenu returns the submenu of the *Menu identified by segment, merging
+// a new one with priority, if there is none.
+func (m *Menu) mergeSubmenu(segment string, priority int) (*Menu, error) {
+	for _, action := range m.actions.actions {
+		if action.menu == nil {
+			continue
+		}
+
+		if action.name == segment || strings.Replace(action.text, "&", "", -1) == segment {
+			return action.menu, nil
+		}
+	}
+
+	submenu, err := NewMenu()
+	if err != nil {
+		return nil, err
+	}
+
+	action := NewAction()
+	action.menu = submenu
+
+	if err := action.SetText(segment); err != nil {
+		return nil, err
+	}
+
+	if err := m.merge(action, priority); err != nil {
+		submenu.Dispose()
+		return nil, err
+	}
+
+	return submenu, nil
+}
+
+func (m *Menu) merge(action *Action, priority int) error {
+	if err := m.removeMergeSeparators(); err != nil {
+		return err
+	}
+
+	index := len(m.merged)
+	for i, ma := range m.merged {
+		if ma.priority > priority {
+			index = i
+			break
+		}
+	}
+
+	position := m.actions.Len()
+	if index < len(m.merged) {
+		position = m.actions.Index(m.merged[index].action)
+	}
+
+	if err := m.actions.Insert(position, action); err != nil {
+		m.insertMergeSeparators()
+		return err
+	}
+
+	m.merged = append(m.merged, mergedAction{})
+	copy(m.merged[index+1:], m.merged[index:])
+	m.merged[index] = mergedAction{action, priority}
+
+	return m.insertMergeSeparators()
+}
+
+func (m *Menu) unmerge(action *Action) error {
+	if err := m.removeMergeSeparators(); err != nil {
+		return err
+	}
+
+	if err := m.actions.Remove(action); err != nil {
+		m.insertMergeSeparators()
+		return err
+	}
+
+	for i, ma := range m.merged {
+		if ma.action == action {
+			m.merged = append(m.merged[:i], m.merged[i+1:]...)
+			break
+		}
+	}
+
+	return m.insertMergeSeparators()
+}
+
+func (m *Menu) removeMergeSeparators() error {
+	for len(m.mergeSeparators) > 0 {
+		last := len(m.mergeSeparators) - 1
+
+		if err := m.actions.Remove(m.mergeSeparators[last]); err != nil {
+			return err
+		}
+
+		m.mergeSeparators = m.mergeSeparators[:last]
+	}
+
+	return nil
+}
+
+// insertMergeSeparators puts separators between the groups of merged Actions
+// and between the other Actions and the first group.
+func (m *Menu) insertMergeSeparators() error {
+	for i, ma := range m.merged {
+		index := m.actions.Index(ma.action)
+
+		if i == 0 {
+			if index == 0 {
+				continue
+			}
+		} else if ma.priority/mergeGroupSize == m.merged[i-1].priority/mergeGroupSize {
+			continue
+		}
+
+		separator := NewAction()
+		if err := separator.SetText("-"); err != nil {
+			return err
+		}
+
+		if err := m.actions.Insert(index, separator); err != nil {
+			return err
+		}
+
+		m.mergeSeparators = append(m.mergeSeparators, separator)
+	}
+
+	return nil
+}
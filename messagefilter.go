@@ -0,0 +1,53 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import . "github.com/lxn/go-winapi"
+
+// MessageFilter is called by the message loop for each message, before it is
+// translated and dispatched. If it returns true, the message is considered
+// handled and neither translated nor dispatched.
+type MessageFilter func(msg *MSG) bool
+
+var messageFilters []MessageFilter
+
+// AddMessageFilter adds filter to the message loop and returns a handle that
+// can be passed to RemoveMessageFilter.
+//
+// Message filters see all messages posted to the UI thread, e.g. to implement
+// application-wide shortcuts or debugging overlays. Filters are called in the
+// order they were added, until one of them handles the message.
+func (app *Application) AddMessageFilter(filter MessageFilter) int {
+	for i, f := range messageFilters {
+		if f == nil {
+			messageFilters[i] = filter
+			return i
+		}
+	}
+
+	messageFilters = append(messageFilters, filter)
+	return len(messageFilters) - 1
+}
+
+// RemoveMessageFilter removes the MessageFilter identified by handle. Invalid
+// handles are ignored.
+func (app *Application) RemoveMessageFilter(handle int) {
+	if handle < 0 || handle >= len(messageFilters) {
+		return
+	}
+
+	messageFilters[handle] = nil
+}
+
+// filterMessage returns if one of the message filters handled msg.
+func filterMessage(msg *MSG) bool {
+	for _, filter := range messageFilters {
+		if filter != nil && filter(msg) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -20,7 +20,7 @@ type Metafile struct {
 func NewMetafile(referenceCanvas *Canvas) (*Metafile, error) {
 	hdc := CreateEnhMetaFile(referenceCanvas.hdc, nil, nil, nil)
 	if hdc == 0 {
-		return nil, newError("CreateEnhMetaFile failed")
+		return nil, newAPIError("CreateEnhMetaFile")
 	}
 
 	return &Metafile{hdc: hdc}, nil
@@ -29,7 +29,7 @@ func NewMetafile(referenceCanvas *Canvas) (*Metafile, error) {
 func NewMetafileFromFile(filePath string) (*Metafile, error) {
 	hemf := GetEnhMetaFile(syscall.StringToUTF16Ptr(filePath))
 	if hemf == 0 {
-		return nil, newError("GetEnhMetaFile failed")
+		return nil, newAPIError("GetEnhMetaFile")
 	}
 
 	mf := &Metafile{hemf: hemf}
@@ -55,7 +55,7 @@ func (mf *Metafile) Dispose() {
 func (mf *Metafile) Save(filePath string) error {
 	hemf := CopyEnhMetaFile(mf.hemf, syscall.StringToUTF16Ptr(filePath))
 	if hemf == 0 {
-		return newError("CopyEnhMetaFile failed")
+		return newAPIError("CopyEnhMetaFile")
 	}
 
 	DeleteEnhMetaFile(hemf)
@@ -67,7 +67,7 @@ func (mf *Metafile) readSizeFromHeader() error {
 	var hdr ENHMETAHEADER
 
 	if GetEnhMetaFileHeader(mf.hemf, uint32(unsafe.Sizeof(hdr)), &hdr) == 0 {
-		return newError("GetEnhMetaFileHeader failed")
+		return newAPIError("GetEnhMetaFileHeader")
 	}
 
 	mf.size = Size{
@@ -89,7 +89,7 @@ func (mf *Metafile) ensureFinished() error {
 
 	mf.hemf = CloseEnhMetaFile(mf.hdc)
 	if mf.hemf == 0 {
-		return newError("CloseEnhMetaFile failed")
+		return newAPIError("CloseEnhMetaFile")
 	}
 
 	mf.hdc = 0
@@ -109,7 +109,7 @@ func (mf *Metafile) drawStretched(hdc HDC, bounds Rectangle) error {
 	rc := bounds.toRECT()
 
 	if !PlayEnhMetaFile(hdc, mf.hemf, &rc) {
-		return newError("PlayEnhMetaFile failed")
+		return newAPIError("PlayEnhMetaFile")
 	}
 
 	return nil
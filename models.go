@@ -221,6 +221,22 @@ type ItemChecker interface {
 	SetChecked(index int, checked bool) error
 }
 
+// CellValueSetter is the interface that a TableModel must implement to make a
+// TableView editable, e.g. by pasting tabular text.
+type CellValueSetter interface {
+	// SetCellValue converts text to the type of the specified cell and
+	// stores it. If text can not be converted, it returns an error and leaves
+	// the cell unchanged.
+	SetCellValue(row, col int, text string) error
+}
+
+// RowAppender is the interface that an editable TableModel can implement to
+// allow a TableView to add rows, e.g. when more rows are pasted than exist.
+type RowAppender interface {
+	// AppendRows adds count empty rows at the end.
+	AppendRows(count int) error
+}
+
 // SortOrder specifies the order by which items are sorted.
 type SortOrder int
 
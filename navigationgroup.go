@@ -0,0 +1,142 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// maxNavigationGroupTabStops limits the search for the next tab stop outside
+// of a navigation group.
+const maxNavigationGroupTabStops = 1000
+
+// NavigationGroup returns if the *Composite is a navigation group.
+func (c *Composite) NavigationGroup() bool {
+	return c.navigationGroup
+}
+
+// SetNavigationGroup sets if the *Composite is a navigation group.
+//
+// A navigation group is a single tab stop: Tab moves the focus past all its
+// descendants, while the arrow keys cycle through them. When the focus enters
+// the group by Tab, the descendant that had the focus last gets it again.
+// This is how tool bars and groups of radio buttons behave.
+//
+// Descendants that handle arrow keys themselves, like edits, keep them.
+func (c *Composite) SetNavigationGroup(value bool) {
+	c.navigationGroup = value
+	c.navigationGroupFocus = 0
+}
+
+// navigationGroupOf returns the innermost *Composite navigation group
+// containing hwnd, or nil if there is none.
+func navigationGroupOf(hwnd HWND) *Composite {
+	for ; hwnd != 0; hwnd = GetAncestor(hwnd, GA_PARENT) {
+		if c, ok := widgetFromHWND(hwnd).(*Composite); ok && c.navigationGroup {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// focusTarget returns the descendant of the navigation group, that should
+// get the focus when the group is entered.
+func (c *Composite) focusTarget(entered HWND) HWND {
+	if h := c.navigationGroupFocus; h != 0 && IsChild(c.hWnd, h) && IsWindowVisible(h) && IsWindowEnabled(h) {
+		return h
+	}
+
+	return entered
+}
+
+// handleNavigationGroupKey implements the keyboard navigation of navigation
+// groups, if msg is an arrow or Tab key press. It returns if msg was handled.
+func handleNavigationGroupKey(msg *MSG) bool {
+	if msg.Message != WM_KEYDOWN || GetKeyState(VK_CONTROL) < 0 || GetKeyState(VK_MENU) < 0 {
+		return false
+	}
+
+	focus := GetFocus()
+	if focus == 0 {
+		return false
+	}
+
+	switch msg.WParam {
+	case VK_LEFT, VK_UP, VK_RIGHT, VK_DOWN:
+		group := navigationGroupOf(focus)
+		if group == nil {
+			return false
+		}
+
+		code := SendMessage(focus, WM_GETDLGCODE, msg.WParam, uintptr(unsafe.Pointer(msg)))
+		if code&(DLGC_WANTARROWS|DLGC_WANTALLKEYS) != 0 {
+			return false
+		}
+
+		previous := msg.WParam == VK_LEFT || msg.WParam == VK_UP
+
+		if next := GetNextDlgTabItem(group.hWnd, focus, previous); next != 0 {
+			SetFocus(next)
+			group.navigationGroupFocus = next
+		}
+
+		return true
+
+	case VK_TAB:
+		return handleNavigationGroupTab(focus, GetKeyState(VK_SHIFT) < 0)
+	}
+
+	return false
+}
+
+func handleNavigationGroupTab(focus HWND, previous bool) bool {
+	group := navigationGroupOf(focus)
+
+	var tabRoot HWND
+	if widget := focusedWidget(); widget != nil {
+		if scope := focusScopeOf(widget); scope != nil {
+			tabRoot = scope.hWnd
+		} else {
+			tabRoot = rootWidget(widget).BaseWidget().hWnd
+		}
+	}
+	if tabRoot == 0 {
+		return false
+	}
+
+	// Skip the other tab stops of the group the focus is in. The tab order
+	// is cyclic, but focus may not be part of it, so we limit the search.
+	next := focus
+	for i := 0; i < maxNavigationGroupTabStops; i++ {
+		next = GetNextDlgTabItem(tabRoot, next, previous)
+		if next == 0 || next == focus || group == nil || !IsChild(group.hWnd, next) {
+			break
+		}
+	}
+	if next == 0 || next == focus {
+		return group != nil
+	}
+
+	target := navigationGroupOf(next)
+	if group == nil && target == nil {
+		// Nothing special, the dialog manager does the same.
+		return false
+	}
+
+	if group != nil {
+		group.navigationGroupFocus = focus
+	}
+
+	if target != nil && target != group {
+		next = target.focusTarget(next)
+	}
+
+	SetFocus(next)
+
+	return true
+}
@@ -51,7 +51,7 @@ func notifyIconWndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) (result ui
 
 		actionId := uint16(TrackPopupMenuEx(
 			ni.contextMenu.hMenu,
-			TPM_NOANIMATION|TPM_RETURNCMD,
+			TPM_NOANIMATION|TPM_RETURNCMD|ni.contextMenu.popupLayoutFlags(),
 			p.X,
 			p.Y,
 			hwnd,
@@ -113,14 +113,14 @@ func NewNotifyIcon() (*NotifyIcon, error) {
 	nid.CbSize = uint32(unsafe.Sizeof(nid))
 
 	if !Shell_NotifyIcon(NIM_ADD, &nid) {
-		return nil, newError("Shell_NotifyIcon")
+		return nil, newAPIError("Shell_NotifyIcon")
 	}
 
 	// We want XP-compatible message behavior.
 	nid.UVersion = NOTIFYICON_VERSION
 
 	if !Shell_NotifyIcon(NIM_SETVERSION, &nid) {
-		return nil, newError("Shell_NotifyIcon")
+		return nil, newAPIError("Shell_NotifyIcon")
 	}
 
 	// Create and initialize the NotifyIcon already.
@@ -163,7 +163,7 @@ func (ni *NotifyIcon) Dispose() error {
 	nid := ni.notifyIconData()
 
 	if !Shell_NotifyIcon(NIM_DELETE, nid) {
-		return newError("Shell_NotifyIcon")
+		return newAPIError("Shell_NotifyIcon")
 	}
 
 	if !DestroyWindow(ni.hWnd) {
@@ -182,7 +182,7 @@ func (ni *NotifyIcon) showMessage(title, info string, iconType uint32) error {
 	copy(nid.SzInfo[:], syscall.StringToUTF16(info))
 
 	if !Shell_NotifyIcon(NIM_MODIFY, nid) {
-		return newError("Shell_NotifyIcon")
+		return newAPIError("Shell_NotifyIcon")
 	}
 
 	return nil
@@ -248,7 +248,7 @@ func (ni *NotifyIcon) SetIcon(icon *Icon) error {
 	}
 
 	if !Shell_NotifyIcon(NIM_MODIFY, nid) {
-		return newError("Shell_NotifyIcon")
+		return newAPIError("Shell_NotifyIcon")
 	}
 
 	ni.icon = icon
@@ -272,7 +272,7 @@ func (ni *NotifyIcon) SetToolTip(toolTip string) error {
 	copy(nid.SzTip[:], syscall.StringToUTF16(toolTip))
 
 	if !Shell_NotifyIcon(NIM_MODIFY, nid) {
-		return newError("Shell_NotifyIcon")
+		return newAPIError("Shell_NotifyIcon")
 	}
 
 	ni.toolTip = toolTip
@@ -299,7 +299,7 @@ func (ni *NotifyIcon) SetVisible(visible bool) error {
 	}
 
 	if !Shell_NotifyIcon(NIM_MODIFY, nid) {
-		return newError("Shell_NotifyIcon")
+		return newAPIError("Shell_NotifyIcon")
 	}
 
 	ni.visible = visible
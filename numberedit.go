@@ -7,6 +7,7 @@ package walk
 import (
 	"math"
 	"strconv"
+	"strings"
 	"syscall"
 	"unsafe"
 )
@@ -19,6 +20,12 @@ func init() {
 	MustRegisterWindowClass(numberEditWindowClass)
 }
 
+// NumberEdit is a widget to enter a number, which can be stepped by the
+// increment with its up-down buttons, the arrow keys or the mouse wheel.
+//
+// The Value property can be bound to float64 and integer fields. Once SetRange
+// was called, it is validated against the range, unless another Validator was
+// set.
 type NumberEdit struct {
 	WidgetBase
 	edit                  *LineEdit
@@ -28,6 +35,8 @@ type NumberEdit struct {
 	maxValue              float64
 	increment             float64
 	oldValue              float64
+	thousandSeparators    bool
+	wheelDelta            int
 	valueChangedPublisher EventPublisher
 }
 
@@ -78,7 +87,15 @@ func NewNumberEdit(parent Container) (*NumberEdit, error) {
 			return ne.Value()
 		},
 		func(v interface{}) error {
-			return ne.SetValue(v.(float64))
+			switch v := v.(type) {
+			case float64:
+				return ne.SetValue(v)
+
+			case int:
+				return ne.SetValue(float64(v))
+			}
+
+			return newError("invalid value type")
 		},
 		ne.valueChangedPublisher.Event()))
 
@@ -128,10 +145,13 @@ func (ne *NumberEdit) SizeHint() Size {
 	return Size{s.Width, maxi(s.Height, 22)}
 }
 
+// Decimals returns the number of decimal places of the value.
 func (ne *NumberEdit) Decimals() int {
 	return ne.decimals
 }
 
+// SetDecimals sets the number of decimal places of the value. With 0, it is
+// an integer.
 func (ne *NumberEdit) SetDecimals(value int) error {
 	if value < 0 {
 		return newError("invalid value")
@@ -142,11 +162,17 @@ func (ne *NumberEdit) SetDecimals(value int) error {
 	return ne.SetValue(ne.oldValue)
 }
 
+// Increment returns the amount, by which a step changes the value.
 func (ne *NumberEdit) Increment() float64 {
 	return ne.increment
 }
 
+// SetIncrement sets the amount, by which a step changes the value.
 func (ne *NumberEdit) SetIncrement(value float64) error {
+	if value <= 0 {
+		return newError("invalid value")
+	}
+
 	ne.increment = value
 
 	return nil
@@ -160,6 +186,8 @@ func (ne *NumberEdit) MaxValue() float64 {
 	return ne.maxValue
 }
 
+// SetRange sets the range of the value. Stepping stays within it and the
+// Value property is validated against it, unless another Validator was set.
 func (ne *NumberEdit) SetRange(min, max float64) error {
 	if min > max {
 		return newError("invalid range")
@@ -168,7 +196,36 @@ func (ne *NumberEdit) SetRange(min, max float64) error {
 	ne.minValue = min
 	ne.maxValue = max
 
-	return nil
+	prop := ne.Property("Value")
+	if _, ok := prop.Validator().(*RangeValidator); !ok && prop.Validator() != nil {
+		return nil
+	}
+
+	var validator Validator
+	if min < max {
+		rv, err := NewRangeValidator(min, max)
+		if err != nil {
+			return wrapError(err)
+		}
+
+		validator = rv
+	}
+
+	return prop.SetValidator(validator)
+}
+
+// ThousandSeparators returns if the digits of the value are grouped by the
+// thousand separator of the locale of the user.
+func (ne *NumberEdit) ThousandSeparators() bool {
+	return ne.thousandSeparators
+}
+
+// SetThousandSeparators sets if the digits of the value are grouped by the
+// thousand separator of the locale of the user.
+func (ne *NumberEdit) SetThousandSeparators(value bool) error {
+	ne.thousandSeparators = value
+
+	return ne.SetValue(ne.Value())
 }
 
 func (ne *NumberEdit) Value() float64 {
@@ -176,23 +233,51 @@ func (ne *NumberEdit) Value() float64 {
 	return val
 }
 
-func (ne *NumberEdit) SetValue(value float64) (err error) {
-	var text string
+func (ne *NumberEdit) SetValue(value float64) error {
+	return ne.edit.SetText(ne.formatValue(value))
+}
 
-	if ne.decimals == 0 {
-		text = strconv.Itoa(int(value))
-	} else {
-		text, err = FormatFloat(value, ne.decimals)
-		if err != nil {
-			return
+// formatValue formats value with the decimal separator and, if enabled, the
+// thousand separator of the locale of the user.
+func (ne *NumberEdit) formatValue(value float64) string {
+	s := strconv.FormatFloat(value, 'f', ne.decimals, 64)
+
+	decimal, thousand := localeNumberSeparators()
+
+	var sign, frac string
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	if i := strings.Index(s, "."); i > -1 {
+		s, frac = s[:i], s[i+1:]
+	}
+
+	if ne.thousandSeparators && thousand != "" {
+		var groups []string
+		for len(s) > 3 {
+			groups = append([]string{s[len(s)-3:]}, groups...)
+			s = s[:len(s)-3]
 		}
+
+		s = strings.Join(append([]string{s}, groups...), thousand)
 	}
 
-	if err = ne.edit.SetText(text); err != nil {
-		return
+	if frac != "" {
+		if decimal == "" {
+			decimal = "."
+		}
+
+		s += decimal + frac
 	}
 
-	return
+	return sign + s
+}
+
+// StepBy changes the value by steps times the increment, within the range.
+func (ne *NumberEdit) StepBy(steps int) error {
+	value := ne.Value() + float64(steps)*ne.increment
+
+	return ne.SetValue(math.Max(ne.minValue, math.Min(ne.maxValue, value)))
 }
 
 func (ne *NumberEdit) ValueChanged() *Event {
@@ -235,9 +320,20 @@ func (ne *NumberEdit) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uin
 			switch ((*NMHDR)(unsafe.Pointer(lParam))).Code {
 			case UDN_DELTAPOS:
 				nmud := (*NMUPDOWN)(unsafe.Pointer(lParam))
-				val := ne.Value()
-				val -= float64(nmud.IDelta) * ne.increment
-				ne.SetValue(val)
+				ne.StepBy(-int(nmud.IDelta))
+			}
+
+		case WM_MOUSEWHEEL:
+			// The edit passes the wheel messages it does not handle on to us.
+			if GetFocus() == ne.edit.hWnd {
+				// High-resolution wheels send fractions of WHEEL_DELTA, so
+				// the remainder is kept for the next message.
+				ne.wheelDelta += int(int16(HIWORD(uint32(wParam))))
+				if steps := ne.wheelDelta / WHEEL_DELTA; steps != 0 {
+					ne.wheelDelta -= steps * WHEEL_DELTA
+					ne.StepBy(steps)
+				}
+				return 0
 			}
 
 		case WM_SIZE, WM_SIZING:
@@ -0,0 +1,326 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+var (
+	procSaveDC            = libgdi32.NewProc("SaveDC")
+	procRestoreDC         = libgdi32.NewProc("RestoreDC")
+	procIntersectClipRect = libgdi32.NewProc("IntersectClipRect")
+	procSetViewportOrgEx  = libgdi32.NewProc("SetViewportOrgEx")
+)
+
+// OverlayHitTest specifies how an *Overlay treats the mouse.
+type OverlayHitTest int
+
+const (
+	// OverlayHitTestTransparent passes the mouse on to the widget, as if the
+	// *Overlay was not there, e.g. for progress overlays or validation
+	// marks.
+	OverlayHitTestTransparent OverlayHitTest = iota
+
+	// OverlayHitTestOpaque keeps the mouse from the widget within the bounds
+	// of the *Overlay, which publishes the mouse events itself, e.g. for an
+	// inline clear button.
+	OverlayHitTestOpaque
+)
+
+// Overlay is drawn on top of a widget, after the widget painted itself, so
+// native controls like LineEdit or TableView can be adorned without owner
+// drawing them.
+//
+// Native controls sometimes draw outside of WM_PAINT, e.g. an edit while the
+// user types. The overlays are drawn again after the messages causing that,
+// but they should not cover content the user works with. For a LineEdit,
+// reserve room for an inline icon by setting the margins of the edit.
+type Overlay struct {
+	wb                 *WidgetBase
+	paint              PaintFunc
+	bounds             Rectangle
+	visible            bool
+	hitTest            OverlayHitTest
+	cursor             Cursor
+	mouseDownPublisher MouseEventPublisher
+	mouseUpPublisher   MouseEventPublisher
+	mouseMovePublisher MouseEventPublisher
+}
+
+// AddOverlay adds a new *Overlay to the *WidgetBase, that covers bounds and
+// is painted by paint. A zero bounds covers the whole client area, however
+// large it is.
+//
+// The Canvas passed to paint has its origin at the top left corner of the
+// *Overlay and is clipped to its bounds.
+func (wb *WidgetBase) AddOverlay(bounds Rectangle, paint PaintFunc) (*Overlay, error) {
+	if paint == nil {
+		return nil, newError("paint must not be nil")
+	}
+
+	o := &Overlay{
+		wb:      wb,
+		paint:   paint,
+		bounds:  bounds,
+		visible: true,
+	}
+
+	wb.overlays = append(wb.overlays, o)
+
+	o.Invalidate()
+
+	return o, nil
+}
+
+// Overlays returns the overlays of the *WidgetBase, bottom first.
+func (wb *WidgetBase) Overlays() []*Overlay {
+	return append([]*Overlay(nil), wb.overlays...)
+}
+
+// Bounds returns the bounds of the *Overlay, relative to the client area of
+// its widget. A zero value covers the whole client area.
+func (o *Overlay) Bounds() Rectangle {
+	return o.bounds
+}
+
+// SetBounds sets the bounds of the *Overlay, relative to the client area of
+// its widget. A zero value covers the whole client area.
+func (o *Overlay) SetBounds(value Rectangle) {
+	if value == o.bounds {
+		return
+	}
+
+	o.Invalidate()
+
+	o.bounds = value
+
+	o.Invalidate()
+}
+
+func (o *Overlay) effectiveBounds() Rectangle {
+	if o.bounds == (Rectangle{}) {
+		return o.wb.ClientBounds()
+	}
+
+	return o.bounds
+}
+
+// Visible returns if the *Overlay is shown.
+func (o *Overlay) Visible() bool {
+	return o.visible
+}
+
+// SetVisible sets if the *Overlay is shown.
+func (o *Overlay) SetVisible(value bool) {
+	if value == o.visible {
+		return
+	}
+
+	o.visible = value
+
+	o.Invalidate()
+}
+
+// HitTest returns how the *Overlay treats the mouse.
+func (o *Overlay) HitTest() OverlayHitTest {
+	return o.hitTest
+}
+
+// SetHitTest sets how the *Overlay treats the mouse. The default is
+// OverlayHitTestTransparent.
+func (o *Overlay) SetHitTest(value OverlayHitTest) {
+	o.hitTest = value
+}
+
+// Cursor returns the cursor shown over an opaque *Overlay, or nil for the
+// cursor of its widget.
+func (o *Overlay) Cursor() Cursor {
+	return o.cursor
+}
+
+// SetCursor sets the cursor shown over an opaque *Overlay.
+func (o *Overlay) SetCursor(value Cursor) {
+	o.cursor = value
+}
+
+// MouseDown returns the event that an opaque *Overlay publishes, when a mouse
+// button is pressed within its bounds. The coordinates are relative to the
+// client area of its widget.
+func (o *Overlay) MouseDown() *MouseEvent {
+	return o.mouseDownPublisher.Event()
+}
+
+// MouseUp returns the event that an opaque *Overlay publishes, when a mouse
+// button is released within its bounds.
+func (o *Overlay) MouseUp() *MouseEvent {
+	return o.mouseUpPublisher.Event()
+}
+
+// MouseMove returns the event that an opaque *Overlay publishes, when the
+// mouse moves within its bounds.
+func (o *Overlay) MouseMove() *MouseEvent {
+	return o.mouseMovePublisher.Event()
+}
+
+// Invalidate schedules a repaint of the widget below the *Overlay and of the
+// *Overlay itself.
+func (o *Overlay) Invalidate() {
+	if o.wb.hWnd == 0 {
+		return
+	}
+
+	rect := o.effectiveBounds().toRECT()
+	InvalidateRect(o.wb.hWnd, &rect, true)
+}
+
+// Dispose removes the *Overlay from its widget.
+func (o *Overlay) Dispose() {
+	wb := o.wb
+
+	for i, overlay := range wb.overlays {
+		if overlay == o {
+			wb.overlays = append(wb.overlays[:i], wb.overlays[i+1:]...)
+			o.Invalidate()
+			break
+		}
+	}
+}
+
+// overlayAt returns the topmost visible opaque *Overlay at the point, in
+// client coordinates, or nil if there is none.
+func (wb *WidgetBase) overlayAt(x, y int) *Overlay {
+	for i := len(wb.overlays) - 1; i >= 0; i-- {
+		o := wb.overlays[i]
+		if !o.visible || o.hitTest != OverlayHitTestOpaque {
+			continue
+		}
+
+		b := o.effectiveBounds()
+		if x >= b.X && x < b.X+b.Width && y >= b.Y && y < b.Y+b.Height {
+			return o
+		}
+	}
+
+	return nil
+}
+
+// overlayWndProc routes the mouse messages within opaque overlays to them.
+func (wb *WidgetBase) overlayWndProc(msg uint32, wParam, lParam uintptr) (result uintptr, handled bool) {
+	switch msg {
+	case WM_LBUTTONDOWN, WM_MBUTTONDOWN, WM_RBUTTONDOWN, WM_LBUTTONDBLCLK, WM_MBUTTONDBLCLK, WM_RBUTTONDBLCLK,
+		WM_LBUTTONUP, WM_MBUTTONUP, WM_RBUTTONUP, WM_MOUSEMOVE:
+
+		o := wb.overlayAt(int(GET_X_LPARAM(lParam)), int(GET_Y_LPARAM(lParam)))
+		if o == nil {
+			return 0, false
+		}
+
+		switch msg {
+		case WM_LBUTTONUP, WM_MBUTTONUP, WM_RBUTTONUP:
+			var button MouseButton
+			switch msg {
+			case WM_MBUTTONUP:
+				button = MiddleButton
+
+			case WM_RBUTTONUP:
+				button = RightButton
+			}
+
+			o.mouseUpPublisher.Publish(int(GET_X_LPARAM(lParam)), int(GET_Y_LPARAM(lParam)), button)
+
+		case WM_MOUSEMOVE:
+			wb.publishMouseEvent(&o.mouseMovePublisher, wParam, lParam)
+
+		default:
+			wb.publishMouseEvent(&o.mouseDownPublisher, wParam, lParam)
+		}
+
+		return 0, true
+
+	case WM_SETCURSOR:
+		if LOWORD(uint32(lParam)) != HTCLIENT {
+			return 0, false
+		}
+
+		var pt POINT
+		if !GetCursorPos(&pt) || !ScreenToClient(wb.hWnd, &pt) {
+			return 0, false
+		}
+
+		if o := wb.overlayAt(int(pt.X), int(pt.Y)); o != nil && o.cursor != nil {
+			SetCursor(o.cursor.handle())
+			return 1, true
+		}
+	}
+
+	return 0, false
+}
+
+// overlaysNeedPaint returns if the native control may have painted over the
+// overlays outside of WM_PAINT, while it processed msg.
+func overlaysNeedPaint(msg uint32) bool {
+	switch msg {
+	case WM_CHAR, WM_KEYDOWN, WM_KEYUP, WM_SETFOCUS, WM_KILLFOCUS, WM_SETTEXT,
+		WM_LBUTTONDOWN, WM_LBUTTONUP, WM_LBUTTONDBLCLK, WM_MOUSEMOVE, WM_MOUSEWHEEL,
+		WM_HSCROLL, WM_VSCROLL, WM_TIMER, WM_ENABLE:
+
+		return true
+	}
+
+	return false
+}
+
+// invalidateOverlays schedules a repaint of the visible overlays, so they are
+// painted again after the widget, instead of while it may still be painting.
+func (wb *WidgetBase) invalidateOverlays() {
+	for _, o := range wb.overlays {
+		if !o.visible {
+			continue
+		}
+
+		rect := o.effectiveBounds().toRECT()
+		InvalidateRect(wb.hWnd, &rect, false)
+	}
+}
+
+// paintOverlays paints the visible overlays on top of what the widget
+// painted. It must only be called after WM_PAINT was processed.
+func (wb *WidgetBase) paintOverlays() {
+	hdc := GetDC(wb.hWnd)
+	if hdc == 0 {
+		return
+	}
+	defer ReleaseDC(wb.hWnd, hdc)
+
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	for _, o := range wb.overlays {
+		if !o.visible {
+			continue
+		}
+
+		b := o.effectiveBounds()
+
+		saved, _, _ := procSaveDC.Call(uintptr(hdc))
+
+		procIntersectClipRect.Call(uintptr(hdc), uintptr(b.X), uintptr(b.Y), uintptr(b.X+b.Width), uintptr(b.Y+b.Height))
+
+		var old POINT
+		procSetViewportOrgEx.Call(uintptr(hdc), uintptr(b.X), uintptr(b.Y), uintptr(unsafe.Pointer(&old)))
+
+		if err := o.paint(canvas, Rectangle{0, 0, b.Width, b.Height}); err != nil {
+			newError("painting overlay failed")
+		}
+
+		procRestoreDC.Call(uintptr(hdc), saved)
+	}
+}
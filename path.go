@@ -12,7 +12,7 @@ func knownFolderPath(id CSIDL) (string, error) {
 	var buf [MAX_PATH]uint16
 
 	if !SHGetSpecialFolderPath(0, &buf[0], id, false) {
-		return "", newError("SHGetSpecialFolderPath failed")
+		return "", newAPIError("SHGetSpecialFolderPath")
 	}
 
 	return syscall.UTF16ToString(buf[0:]), nil
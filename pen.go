@@ -96,7 +96,7 @@ func NewCosmeticPen(style PenStyle, color Color) (*CosmeticPen, error) {
 
 	hPen := ExtCreatePen(uint32(style), 1, lb, 0, nil)
 	if hPen == 0 {
-		return nil, newError("ExtCreatePen failed")
+		return nil, newAPIError("ExtCreatePen")
 	}
 
 	return &CosmeticPen{hPen: hPen, style: style, color: color}, nil
@@ -142,7 +142,7 @@ func NewGeometricPen(style PenStyle, width int, brush Brush) (*GeometricPen, err
 
 	hPen := ExtCreatePen(uint32(style), uint32(width), brush.logbrush(), 0, nil)
 	if hPen == 0 {
-		return nil, newError("ExtCreatePen failed")
+		return nil, newAPIError("ExtCreatePen")
 	}
 
 	return &GeometricPen{
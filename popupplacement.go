@@ -0,0 +1,141 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// PopupPlacement specifies on which side of an anchor a popup is placed.
+type PopupPlacement int
+
+const (
+	PopupBelow PopupPlacement = iota
+	PopupAbove
+	PopupRight
+	PopupLeft
+)
+
+// opposite returns the PopupPlacement on the other side of the anchor.
+func (pp PopupPlacement) opposite() PopupPlacement {
+	switch pp {
+	case PopupBelow:
+		return PopupAbove
+
+	case PopupAbove:
+		return PopupBelow
+
+	case PopupRight:
+		return PopupLeft
+	}
+
+	return PopupRight
+}
+
+// PopupBounds returns the bounds, in screen coordinates, of a popup of size,
+// placed next to anchor, which is in screen coordinates as well.
+//
+// The popup stays within the work area of the monitor showing anchor. If
+// there is not enough room on the side specified by placement, the popup is
+// flipped to the opposite side, if there is more room there. If it still
+// doesn't fit, it is shifted along the anchor, and finally clipped.
+//
+// Walk places tool tips and anchored menus this way, so custom popups, like
+// flyouts, should use it as well.
+func PopupBounds(anchor Rectangle, size Size, placement PopupPlacement) Rectangle {
+	workArea := workAreaForRectangle(anchor)
+
+	if room := popupRoom(anchor, workArea, placement); room < popupExtent(size, placement) {
+		if popupRoom(anchor, workArea, placement.opposite()) > room {
+			placement = placement.opposite()
+		}
+	}
+
+	b := Rectangle{Width: size.Width, Height: size.Height}
+
+	switch placement {
+	case PopupBelow:
+		b.X, b.Y = anchor.X, anchor.Y+anchor.Height
+
+	case PopupAbove:
+		b.X, b.Y = anchor.X, anchor.Y-size.Height
+
+	case PopupRight:
+		b.X, b.Y = anchor.X+anchor.Width, anchor.Y
+
+	case PopupLeft:
+		b.X, b.Y = anchor.X-size.Width, anchor.Y
+	}
+
+	return constrainToRectangle(b, workArea)
+}
+
+// popupRoom returns the space between anchor and the border of workArea on
+// the side specified by placement.
+func popupRoom(anchor, workArea Rectangle, placement PopupPlacement) int {
+	switch placement {
+	case PopupBelow:
+		return workArea.Y + workArea.Height - (anchor.Y + anchor.Height)
+
+	case PopupAbove:
+		return anchor.Y - workArea.Y
+
+	case PopupRight:
+		return workArea.X + workArea.Width - (anchor.X + anchor.Width)
+	}
+
+	return anchor.X - workArea.X
+}
+
+func popupExtent(size Size, placement PopupPlacement) int {
+	if placement == PopupBelow || placement == PopupAbove {
+		return size.Height
+	}
+
+	return size.Width
+}
+
+// constrainToRectangle shifts and, if it is too large, shrinks b to make it
+// lie within area.
+func constrainToRectangle(b, area Rectangle) Rectangle {
+	b.Width = mini(b.Width, area.Width)
+	b.Height = mini(b.Height, area.Height)
+
+	b.X = maxi(area.X, mini(b.X, area.X+area.Width-b.Width))
+	b.Y = maxi(area.Y, mini(b.Y, area.Y+area.Height-b.Height))
+
+	return b
+}
+
+// workAreaForRectangle returns the work area of the monitor, that has the
+// largest intersection with r, or is nearest to it.
+func workAreaForRectangle(r Rectangle) Rectangle {
+	rect := r.toRECT()
+
+	var mi MONITORINFO
+	mi.CbSize = uint32(unsafe.Sizeof(mi))
+
+	if !GetMonitorInfo(MonitorFromRect(&rect, MONITOR_DEFAULTTONEAREST), &mi) {
+		newAPIError("GetMonitorInfo")
+		return r
+	}
+
+	return rectangleFromRECT(mi.RcWork)
+}
+
+// ScreenBounds returns the outer bounding box of the *WidgetBase in screen
+// coordinates, e.g. as anchor for PopupBounds.
+func (wb *WidgetBase) ScreenBounds() Rectangle {
+	var r RECT
+
+	if !GetWindowRect(wb.hWnd, &r) {
+		lastError("GetWindowRect")
+		return Rectangle{}
+	}
+
+	return rectangleFromRECT(r)
+}
@@ -0,0 +1,209 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	pbtAPMPowerStatusChange = 0x000A
+	pbtAPMResumeAutomatic   = 0x0012
+	pbtAPMSuspend           = 0x0004
+	pbtPowerSettingChange   = 0x8013
+
+	deviceNotifyWindowHandle = 0
+)
+
+// guidConsoleDisplayState identifies the power setting, that reports if the
+// display is on, off or dimmed.
+var guidConsoleDisplayState = GUID{0x6FE69556, 0x704A, 0x47A0, [8]byte{0x8F, 0x24, 0xC2, 0x8D, 0x93, 0x6F, 0xDA, 0x47}}
+
+var (
+	procRegisterPowerSettingNotification   = libuser32.NewProc("RegisterPowerSettingNotification")
+	procUnregisterPowerSettingNotification = libuser32.NewProc("UnregisterPowerSettingNotification")
+	procGetSystemPowerStatus               = libkernel32.NewProc("GetSystemPowerStatus")
+)
+
+type powerBroadcastSetting struct {
+	powerSetting GUID
+	dataLength   uint32
+	data         [1]byte
+}
+
+type systemPowerStatus struct {
+	acLineStatus        byte
+	batteryFlag         byte
+	batteryLifePercent  byte
+	systemStatusFlag    byte
+	batteryLifeTime     uint32
+	batteryFullLifeTime uint32
+}
+
+// PowerSource specifies where the system gets its power from.
+type PowerSource int
+
+const (
+	PowerSourceUnknown PowerSource = iota
+	PowerSourceAC
+	PowerSourceBattery
+)
+
+// DisplayState specifies the power state of the display.
+type DisplayState int
+
+const (
+	DisplayOn DisplayState = iota
+	DisplayOff
+	DisplayDimmed
+)
+
+var (
+	// powerWindow is the top-level window handling power notifications, which
+	// are broadcast to all of them.
+	powerWindow       *TopLevelWindow
+	powerNotification uintptr
+	displayState      DisplayState
+)
+
+// Suspending returns the event that is published, before the system goes to
+// sleep or hibernates. Handlers should return quickly.
+func (app *Application) Suspending() *Event {
+	return app.suspendingPublisher.Event()
+}
+
+// Resumed returns the event that is published, when the system resumed from
+// sleep or hibernation, e.g. to reconnect to servers.
+func (app *Application) Resumed() *Event {
+	return app.resumedPublisher.Event()
+}
+
+// PowerSourceChanged returns the event that is published, when the system
+// switches between AC and battery power, or the battery level changes.
+func (app *Application) PowerSourceChanged() *Event {
+	return app.powerSourceChangedPublisher.Event()
+}
+
+// DisplayStateChanged returns the event that is published, when the display
+// is turned on or off, or dimmed.
+func (app *Application) DisplayStateChanged() *Event {
+	return app.displayStateChangedPublisher.Event()
+}
+
+// PowerSource returns where the system currently gets its power from.
+func (app *Application) PowerSource() PowerSource {
+	var sps systemPowerStatus
+	if ret, _, _ := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&sps))); ret == 0 {
+		return PowerSourceUnknown
+	}
+
+	switch sps.acLineStatus {
+	case 0:
+		return PowerSourceBattery
+
+	case 1:
+		return PowerSourceAC
+	}
+
+	return PowerSourceUnknown
+}
+
+// BatteryLifePercent returns the remaining battery charge in percent, if it
+// is known.
+func (app *Application) BatteryLifePercent() (int, bool) {
+	var sps systemPowerStatus
+	if ret, _, _ := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&sps))); ret == 0 || sps.batteryLifePercent == 255 {
+		return 0, false
+	}
+
+	return int(sps.batteryLifePercent), true
+}
+
+// DisplayState returns the current power state of the display.
+func (app *Application) DisplayState() DisplayState {
+	return displayState
+}
+
+// updatePowerWindow makes sure, that one of the top-level windows handles
+// power notifications, after tlw was created or destroyed.
+func updatePowerWindow(tlw *TopLevelWindow, destroyed bool) {
+	if destroyed {
+		if tlw != powerWindow {
+			return
+		}
+
+		if powerNotification != 0 {
+			procUnregisterPowerSettingNotification.Call(powerNotification)
+			powerNotification = 0
+		}
+
+		powerWindow = nil
+
+		for w := range topLevelWindows {
+			if w != tlw {
+				updatePowerWindow(w, false)
+				break
+			}
+		}
+
+		return
+	}
+
+	if powerWindow != nil {
+		return
+	}
+
+	powerWindow = tlw
+
+	if procRegisterPowerSettingNotification.Find() == nil {
+		// Since Windows Vista.
+		powerNotification, _, _ = procRegisterPowerSettingNotification.Call(
+			uintptr(tlw.hWnd),
+			uintptr(unsafe.Pointer(&guidConsoleDisplayState)),
+			deviceNotifyWindowHandle)
+	}
+}
+
+// handlePowerBroadcast handles WM_POWERBROADCAST for the top-level window
+// tlw.
+func handlePowerBroadcast(tlw *TopLevelWindow, wParam, lParam uintptr) {
+	if tlw != powerWindow {
+		return
+	}
+
+	app := appSingleton
+
+	switch wParam {
+	case pbtAPMSuspend:
+		app.suspendingPublisher.Publish()
+
+	case pbtAPMResumeAutomatic:
+		app.resumedPublisher.Publish()
+
+	case pbtAPMPowerStatusChange:
+		app.powerSourceChangedPublisher.Publish()
+
+	case pbtPowerSettingChange:
+		pbs := (*powerBroadcastSetting)(unsafe.Pointer(lParam))
+		if pbs.powerSetting != guidConsoleDisplayState || pbs.dataLength < 1 {
+			break
+		}
+
+		switch pbs.data[0] {
+		case 0:
+			displayState = DisplayOff
+
+		case 2:
+			displayState = DisplayDimmed
+
+		default:
+			displayState = DisplayOn
+		}
+
+		app.displayStateChangedPublisher.Publish()
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// PowerRequestKind specifies what a *PowerRequest keeps from turning off.
+type PowerRequestKind int
+
+const (
+	// PowerRequestSystem keeps the system from going to sleep.
+	PowerRequestSystem PowerRequestKind = iota
+
+	// PowerRequestDisplay keeps the system from going to sleep and the
+	// display from turning off.
+	PowerRequestDisplay
+)
+
+const (
+	powerRequestContextVersion      = 0
+	powerRequestContextSimpleString = 0x1
+
+	powerRequestDisplayRequired = 0
+	powerRequestSystemRequired  = 1
+
+	esSystemRequired  = 0x00000001
+	esDisplayRequired = 0x00000002
+	esContinuous      = 0x80000000
+)
+
+var (
+	procPowerCreateRequest      = libkernel32.NewProc("PowerCreateRequest")
+	procPowerSetRequest         = libkernel32.NewProc("PowerSetRequest")
+	procPowerClearRequest       = libkernel32.NewProc("PowerClearRequest")
+	procSetThreadExecutionState = libkernel32.NewProc("SetThreadExecutionState")
+)
+
+type reasonContext struct {
+	version            uint32
+	flags              uint32
+	simpleReasonString *uint16
+}
+
+// executionStateRequests counts the active requests per kind, for systems
+// without PowerCreateRequest.
+var executionStateRequests struct {
+	m      sync.Mutex
+	counts [2]int
+}
+
+// PowerRequest keeps the system awake, while a long operation, like a
+// download or a backup, is running.
+type PowerRequest struct {
+	kind   PowerRequestKind
+	handle uintptr
+	once   sync.Once
+}
+
+// NewPowerRequest creates and activates a new *PowerRequest of kind.
+//
+// reason is shown to the user by tools like "powercfg /requests". Call
+// Release when the operation completed or was canceled, or use
+// BackgroundWorker.SetPowerRequest to tie it to a BackgroundWorker. PowerRequests may be
+// created and released on any goroutine.
+func NewPowerRequest(kind PowerRequestKind, reason string) (*PowerRequest, error) {
+	pr := &PowerRequest{kind: kind}
+
+	if procPowerCreateRequest.Find() != nil {
+		// Before Windows 7.
+		pr.updateExecutionState(1)
+		return pr, nil
+	}
+
+	rc := reasonContext{
+		version:            powerRequestContextVersion,
+		flags:              powerRequestContextSimpleString,
+		simpleReasonString: syscall.StringToUTF16Ptr(reason),
+	}
+
+	handle, _, err := procPowerCreateRequest.Call(uintptr(unsafe.Pointer(&rc)))
+	if syscall.Handle(handle) == syscall.InvalidHandle {
+		return nil, callError("PowerCreateRequest", err)
+	}
+
+	pr.handle = handle
+
+	for _, requestType := range pr.requestTypes() {
+		if ret, _, err := procPowerSetRequest.Call(handle, requestType); ret == 0 {
+			pr.Release()
+			return nil, callError("PowerSetRequest", err)
+		}
+	}
+
+	return pr, nil
+}
+
+// RunWithPowerRequest calls f, while a *PowerRequest of kind is active. The
+// *PowerRequest is released, when f returns, whether it completed, failed or
+// was canceled.
+func RunWithPowerRequest(kind PowerRequestKind, reason string, f func() error) error {
+	pr, err := NewPowerRequest(kind, reason)
+	if err != nil {
+		return err
+	}
+	defer pr.Release()
+
+	return f()
+}
+
+// Kind returns what the *PowerRequest keeps from turning off.
+func (pr *PowerRequest) Kind() PowerRequestKind {
+	return pr.kind
+}
+
+// Release deactivates the *PowerRequest. Calling it more than once is
+// harmless.
+func (pr *PowerRequest) Release() {
+	pr.once.Do(func() {
+		if pr.handle == 0 {
+			pr.updateExecutionState(-1)
+			return
+		}
+
+		for _, requestType := range pr.requestTypes() {
+			procPowerClearRequest.Call(pr.handle, requestType)
+		}
+
+		syscall.CloseHandle(syscall.Handle(pr.handle))
+		pr.handle = 0
+	})
+}
+
+func (pr *PowerRequest) requestTypes() []uintptr {
+	if pr.kind == PowerRequestDisplay {
+		return []uintptr{powerRequestSystemRequired, powerRequestDisplayRequired}
+	}
+
+	return []uintptr{powerRequestSystemRequired}
+}
+
+// updateExecutionState adjusts the request count for the kind of the
+// *PowerRequest by delta and applies the resulting execution state.
+//
+// The execution state belongs to a thread, while goroutines may move between
+// threads, so it is set on the UI thread.
+func (pr *PowerRequest) updateExecutionState(delta int) {
+	ers := &executionStateRequests
+
+	ers.m.Lock()
+	ers.counts[pr.kind] += delta
+
+	state := uintptr(esContinuous)
+	if ers.counts[PowerRequestSystem] > 0 || ers.counts[PowerRequestDisplay] > 0 {
+		state |= esSystemRequired
+	}
+	if ers.counts[PowerRequestDisplay] > 0 {
+		state |= esDisplayRequired
+	}
+	ers.m.Unlock()
+
+	postSynchronized(func() {
+		procSetThreadExecutionState.Call(state)
+	})
+}
@@ -6,12 +6,42 @@ package walk
 
 import . "github.com/lxn/go-winapi"
 
+const (
+	pbsMarquee = 0x08
+
+	pbmSetMarquee = WM_USER + 10
+	pbmSetState   = WM_USER + 16
+	pbmGetState   = WM_USER + 17
+)
+
+// ProgressBarState specifies the color of a *ProgressBar, which tells the user
+// if the operation is proceeding.
+type ProgressBarState int
+
+const (
+	// ProgressBarNormal shows the progress in the normal color, usually
+	// green.
+	ProgressBarNormal ProgressBarState = 1
+
+	// ProgressBarError shows the progress in red, when the operation failed.
+	ProgressBarError ProgressBarState = 2
+
+	// ProgressBarPaused shows the progress in yellow, when the operation is
+	// paused.
+	ProgressBarPaused ProgressBarState = 3
+)
+
+// ProgressBar shows the progress of an operation, either as a value within a
+// range or, in marquee mode, as an animation for operations of unknown
+// duration.
 type ProgressBar struct {
 	WidgetBase
+	marqueeInterval       int
+	valueChangedPublisher EventPublisher
 }
 
 func NewProgressBar(parent Container) (*ProgressBar, error) {
-	pb := &ProgressBar{}
+	pb := &ProgressBar{marqueeInterval: 30}
 
 	if err := InitChildWidget(
 		pb,
@@ -22,6 +52,26 @@ func NewProgressBar(parent Container) (*ProgressBar, error) {
 		return nil, err
 	}
 
+	pb.MustRegisterProperty("Value", NewProperty(
+		func() interface{} {
+			return pb.Value()
+		},
+		func(v interface{}) error {
+			switch v := v.(type) {
+			case int:
+				pb.SetValue(v)
+
+			case float64:
+				pb.SetValue(int(v))
+
+			default:
+				return newError("invalid value type")
+			}
+
+			return nil
+		},
+		pb.valueChangedPublisher.Event()))
+
 	return pb, nil
 }
 
@@ -54,5 +104,75 @@ func (pb *ProgressBar) Value() int {
 }
 
 func (pb *ProgressBar) SetValue(value int) {
-	pb.SendMessage(PBM_SETPOS, uintptr(value), 0)
+	if old := int(pb.SendMessage(PBM_SETPOS, uintptr(value), 0)); old != pb.Value() {
+		pb.valueChangedPublisher.Publish()
+	}
+}
+
+// ValueChanged returns the event that is published, when the value changed.
+func (pb *ProgressBar) ValueChanged() *Event {
+	return pb.valueChangedPublisher.Event()
+}
+
+// MarqueeMode returns if the *ProgressBar shows an animation instead of a
+// value, for operations of unknown duration.
+func (pb *ProgressBar) MarqueeMode() bool {
+	return pb.hasStyleBits(pbsMarquee)
+}
+
+// SetMarqueeMode sets if the *ProgressBar shows an animation instead of a
+// value, for operations of unknown duration.
+func (pb *ProgressBar) SetMarqueeMode(marquee bool) error {
+	if marquee == pb.MarqueeMode() {
+		return nil
+	}
+
+	if err := pb.ensureStyleBits(pbsMarquee, marquee); err != nil {
+		return err
+	}
+
+	pb.SendMessage(pbmSetMarquee, uintptr(boolToInt(marquee)), uintptr(pb.marqueeInterval))
+
+	return nil
+}
+
+// MarqueeInterval returns the time between the animation steps in marquee
+// mode, in milliseconds.
+func (pb *ProgressBar) MarqueeInterval() int {
+	return pb.marqueeInterval
+}
+
+// SetMarqueeInterval sets the time between the animation steps in marquee
+// mode, in milliseconds. The default is 30.
+func (pb *ProgressBar) SetMarqueeInterval(interval int) error {
+	if interval <= 0 {
+		return newError("invalid interval")
+	}
+
+	pb.marqueeInterval = interval
+
+	if pb.MarqueeMode() {
+		pb.SendMessage(pbmSetMarquee, 1, uintptr(interval))
+	}
+
+	return nil
+}
+
+// State returns the state of the *ProgressBar.
+func (pb *ProgressBar) State() ProgressBarState {
+	return ProgressBarState(pb.SendMessage(pbmGetState, 0, 0))
+}
+
+// SetState sets the state of the *ProgressBar, which requires visual styles.
+// While paused or failed, the value should not change.
+func (pb *ProgressBar) SetState(state ProgressBarState) error {
+	if state < ProgressBarNormal || state > ProgressBarPaused {
+		return newError("invalid state")
+	}
+
+	if 0 == pb.SendMessage(pbmSetState, uintptr(state), 0) {
+		return newAPIError("SendMessage(PBM_SETSTATE)")
+	}
+
+	return nil
 }
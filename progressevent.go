@@ -0,0 +1,43 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+type ProgressEventHandler func(percent int, status string)
+
+type ProgressEvent struct {
+	handlers []ProgressEventHandler
+}
+
+func (e *ProgressEvent) Attach(handler ProgressEventHandler) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	return len(e.handlers) - 1
+}
+
+func (e *ProgressEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type ProgressEventPublisher struct {
+	event ProgressEvent
+}
+
+func (p *ProgressEventPublisher) Event() *ProgressEvent {
+	return &p.event
+}
+
+func (p *ProgressEventPublisher) Publish(percent int, status string) {
+	for _, handler := range p.event.handlers {
+		if handler != nil {
+			handler(percent, status)
+		}
+	}
+}
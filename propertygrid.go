@@ -0,0 +1,678 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const propertyGridWindowClass = `\o/ Walk_PropertyGrid_Class \o/`
+
+func init() {
+	MustRegisterWindowClass(propertyGridWindowClass)
+}
+
+var (
+	colorType           = reflect.TypeOf(Color(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	timePointerType     = reflect.TypeOf((*time.Time)(nil))
+	propertyGridEditors = []string{"text", "combo", "color", "file", "checkbox", "number", "date"}
+)
+
+// PropertyGrid shows the exported fields of a struct as rows of names and
+// editors, grouped by category, like the property inspectors of designers.
+//
+// The editor of a field depends on its type: a CheckBox for bool, a
+// NumberEdit for numbers, a DateEdit for time.Time, a color picker for Color
+// and a LineEdit for string. Struct tags customize the fields:
+//
+//	category:"Layout"           groups the field under a heading
+//	label:"Font Size"           is shown instead of the field name
+//	description:"..."           is shown below the rows, when the editor is used
+//	editor:"combo"              is one of text, combo, color, file, checkbox,
+//	                            number and date, or - to leave the field out
+//	options:"Left,Center,Right" are the choices of a combo editor
+//	filter:"Images|*.png"       is the filter of a file editor
+//	range:"0,100"               is the range of a number editor
+//	decimals:"2"                are the decimal places of a number editor
+//	regexp:"^[a-z]+$"           validates a text editor
+//
+// The editors are bound to the fields by a DataBinder, so the validators of
+// their properties apply. A change is written back to the struct, when all
+// editors are valid.
+type PropertyGrid struct {
+	WidgetBase
+	composite             *Composite
+	description           *Label
+	dataSource            interface{}
+	dataBinder            *DataBinder
+	rows                  []*propertyGridRow
+	categoryFont          *Font
+	valueChangedPublisher StringEventPublisher
+}
+
+type propertyGridRow struct {
+	field       string
+	description string
+	label       *Label
+	editor      Widget
+}
+
+// propertyGridOptionsModel is the model of combo editors.
+type propertyGridOptionsModel struct {
+	ListModelBase
+	options []string
+}
+
+func (m *propertyGridOptionsModel) ItemCount() int {
+	return len(m.options)
+}
+
+func (m *propertyGridOptionsModel) Value(index int) interface{} {
+	return m.options[index]
+}
+
+func (m *propertyGridOptionsModel) BindingValue(index int) interface{} {
+	return m.options[index]
+}
+
+// NewPropertyGrid returns a new, empty *PropertyGrid.
+func NewPropertyGrid(parent Container) (*PropertyGrid, error) {
+	pg := new(PropertyGrid)
+
+	if err := InitChildWidget(
+		pg,
+		parent,
+		propertyGridWindowClass,
+		WS_VISIBLE,
+		WS_EX_CONTROLPARENT); err != nil {
+		return nil, err
+	}
+
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			pg.Dispose()
+		}
+	}()
+
+	if err := pg.SetDataSource(nil); err != nil {
+		return nil, err
+	}
+
+	succeeded = true
+
+	return pg, nil
+}
+
+// Dispose releases the operating system resources, associated with the
+// *PropertyGrid.
+func (pg *PropertyGrid) Dispose() {
+	pg.WidgetBase.Dispose()
+
+	if pg.categoryFont != nil {
+		pg.categoryFont.Dispose()
+		pg.categoryFont = nil
+	}
+}
+
+func (*PropertyGrid) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | GrowableHorz | GreedyHorz | GrowableVert
+}
+
+func (pg *PropertyGrid) MinSizeHint() Size {
+	if pg.composite == nil {
+		return Size{}
+	}
+
+	return pg.composite.Layout().MinSize()
+}
+
+func (pg *PropertyGrid) SizeHint() Size {
+	return pg.MinSizeHint()
+}
+
+// DataSource returns the pointer to the struct, whose fields the
+// *PropertyGrid shows.
+func (pg *PropertyGrid) DataSource() interface{} {
+	return pg.dataSource
+}
+
+// SetDataSource sets the pointer to the struct, whose fields the
+// *PropertyGrid shows, or nil to show nothing.
+func (pg *PropertyGrid) SetDataSource(dataSource interface{}) error {
+	var st reflect.Type
+	if dataSource != nil {
+		t := reflect.TypeOf(dataSource)
+		if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+			return newError("DataSource must be a pointer to a struct.")
+		}
+
+		st = t.Elem()
+	}
+
+	if pg.composite != nil {
+		pg.composite.Dispose()
+		pg.composite = nil
+	}
+	if pg.dataBinder != nil {
+		pg.dataBinder.SetBoundWidgets(nil)
+	}
+
+	pg.dataSource = dataSource
+	pg.dataBinder = nil
+	pg.rows = nil
+
+	var err error
+	if pg.composite, err = newCompositeWithStyle(pg, 0); err != nil {
+		return err
+	}
+
+	layout := NewGridLayout()
+	if err := pg.composite.SetLayout(layout); err != nil {
+		return err
+	}
+	if err := layout.SetColumnStretchFactor(1, 2); err != nil {
+		return err
+	}
+
+	var row int
+
+	if st != nil {
+		if row, err = pg.createRows(layout, st); err != nil {
+			return err
+		}
+	}
+
+	if pg.description, err = NewLabel(pg.composite); err != nil {
+		return err
+	}
+	if err := layout.SetRange(pg.description, Rectangle{0, row, 2, 1}); err != nil {
+		return err
+	}
+
+	if dataSource != nil {
+		if err := pg.bind(); err != nil {
+			return err
+		}
+	}
+
+	pg.composite.SetBounds(pg.ClientBounds())
+
+	return pg.updateParentLayout()
+}
+
+// createRows creates the rows for the fields of st, category by category, and
+// returns the number of grid rows used.
+func (pg *PropertyGrid) createRows(layout *GridLayout, st reflect.Type) (int, error) {
+	var categories []string
+	category2Fields := make(map[string][]reflect.StructField)
+
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if sf.PkgPath != "" || sf.Anonymous || sf.Tag.Get("editor") == "-" {
+			continue
+		}
+
+		category := sf.Tag.Get("category")
+		if _, ok := category2Fields[category]; !ok {
+			categories = append(categories, category)
+		}
+		category2Fields[category] = append(category2Fields[category], sf)
+	}
+
+	var row int
+
+	for _, category := range categories {
+		if category != "" {
+			heading, err := NewLabel(pg.composite)
+			if err != nil {
+				return 0, err
+			}
+			if err := heading.SetText(category); err != nil {
+				return 0, err
+			}
+			if pg.categoryFont == nil {
+				font := pg.Font()
+				if bold, err := NewFont(font.Family(), font.PointSize(), FontBold); err == nil {
+					pg.categoryFont = bold
+				}
+			}
+			if pg.categoryFont != nil {
+				heading.SetFont(pg.categoryFont)
+			}
+			if err := layout.SetRange(heading, Rectangle{0, row, 2, 1}); err != nil {
+				return 0, err
+			}
+			row++
+		}
+
+		for _, sf := range category2Fields[category] {
+			r, err := pg.createRow(sf)
+			if err != nil {
+				return 0, err
+			}
+			if r == nil {
+				// Fields of unsupported types are left out.
+				continue
+			}
+
+			if err := layout.SetRange(r.label, Rectangle{0, row, 1, 1}); err != nil {
+				return 0, err
+			}
+			if err := layout.SetRange(r.editor, Rectangle{1, row, 1, 1}); err != nil {
+				return 0, err
+			}
+			row++
+
+			pg.rows = append(pg.rows, r)
+		}
+	}
+
+	return row, nil
+}
+
+func (pg *PropertyGrid) createRow(sf reflect.StructField) (*propertyGridRow, error) {
+	editor, prop, err := pg.createEditor(sf)
+	if err != nil || editor == nil {
+		return nil, err
+	}
+
+	if err := prop.SetSource(sf.Name); err != nil {
+		return nil, err
+	}
+
+	r := &propertyGridRow{
+		field:       sf.Name,
+		description: sf.Tag.Get("description"),
+		editor:      editor,
+	}
+
+	if r.label, err = NewLabel(pg.composite); err != nil {
+		return nil, err
+	}
+	text := sf.Tag.Get("label")
+	if text == "" {
+		text = sf.Name
+	}
+	if err := r.label.SetText(text); err != nil {
+		return nil, err
+	}
+
+	if r.description != "" {
+		r.label.SetToolTipText(r.description)
+		editor.SetToolTipText(r.description)
+	}
+
+	showDescription := func() {
+		pg.description.SetText(r.description)
+	}
+	r.label.MouseDown().Attach(func(x, y int, button MouseButton) {
+		showDescription()
+	})
+	editor.MouseDown().Attach(func(x, y int, button MouseButton) {
+		showDescription()
+	})
+	editor.KeyDown().Attach(func(key int) {
+		showDescription()
+	})
+
+	return r, nil
+}
+
+// editorKind returns the kind of editor for sf, or "" if the type of sf is not
+// supported.
+func editorKind(sf reflect.StructField) string {
+	if kind := sf.Tag.Get("editor"); kind != "" {
+		return kind
+	}
+
+	switch t := sf.Type; {
+	case t == colorType:
+		return "color"
+
+	case t == timeType, t == timePointerType:
+		return "date"
+	}
+
+	switch sf.Type.Kind() {
+	case reflect.Bool:
+		return "checkbox"
+
+	case reflect.String:
+		return "text"
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+
+		return "number"
+	}
+
+	return ""
+}
+
+// createEditor creates the editor for sf and returns it with its property,
+// that is bound to sf.
+func (pg *PropertyGrid) createEditor(sf reflect.StructField) (Widget, Property, error) {
+	parent := pg.composite
+
+	switch kind := editorKind(sf); kind {
+	case "":
+		return nil, nil, nil
+
+	case "text":
+		le, err := NewLineEdit(parent)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		prop := le.Property("Text")
+		if pattern := sf.Tag.Get("regexp"); pattern != "" {
+			validator, err := NewRegexpValidator(pattern)
+			if err != nil {
+				return nil, nil, wrapError(err)
+			}
+			if err := prop.SetValidator(validator); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		return le, prop, nil
+
+	case "combo":
+		cb, err := NewComboBox(parent)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var options []string
+		if s := sf.Tag.Get("options"); s != "" {
+			options = strings.Split(s, ",")
+		}
+		if err := cb.SetModel(&propertyGridOptionsModel{options: options}); err != nil {
+			return nil, nil, err
+		}
+
+		return cb, cb.Property("Value"), nil
+
+	case "checkbox":
+		cb, err := NewCheckBox(parent)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return cb, cb.Property("Checked"), nil
+
+	case "number":
+		return newPropertyGridNumberEditor(parent, sf)
+
+	case "date":
+		de, err := NewDateEdit(parent)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return de, de.Property("Date"), nil
+
+	case "color":
+		return newPropertyGridColorEditor(parent)
+
+	case "file":
+		return newPropertyGridFileEditor(parent, sf.Tag.Get("filter"))
+
+	default:
+		return nil, nil, newError(fmt.Sprintf("Field '%s': unknown editor '%s', must be one of %s", sf.Name, kind, strings.Join(propertyGridEditors, ", ")))
+	}
+}
+
+func newPropertyGridNumberEditor(parent Container, sf reflect.StructField) (Widget, Property, error) {
+	ne, err := NewNumberEdit(parent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	min, max := -math.MaxFloat64, math.MaxFloat64
+	decimals := 2
+	switch sf.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		decimals = 0
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		min = 0
+		decimals = 0
+	}
+	if err := ne.SetDecimals(decimals); err != nil {
+		return nil, nil, err
+	}
+
+	if s := sf.Tag.Get("range"); s != "" {
+		parts := strings.Split(s, ",")
+		if len(parts) != 2 {
+			return nil, nil, newError(fmt.Sprintf("Field '%s': invalid range '%s'", sf.Name, s))
+		}
+
+		if min, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64); err != nil {
+			return nil, nil, wrapError(err)
+		}
+		if max, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64); err != nil {
+			return nil, nil, wrapError(err)
+		}
+	}
+	if err := ne.SetRange(min, max); err != nil {
+		return nil, nil, err
+	}
+
+	if s := sf.Tag.Get("decimals"); s != "" {
+		decimals, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, nil, wrapError(err)
+		}
+		if err := ne.SetDecimals(decimals); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return ne, ne.Property("Value"), nil
+}
+
+// newPropertyGridButtonEditor returns a composite with a LineEdit and a
+// button, that runs a dialog to change the value. If createLeading is not
+// nil, it is called to create widgets in front of the LineEdit.
+func newPropertyGridButtonEditor(parent Container, createLeading func(c *Composite) error) (*Composite, *LineEdit, *PushButton, error) {
+	c, err := NewComposite(parent)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	layout := NewHBoxLayout()
+	if err := layout.SetMargins(Margins{}); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := c.SetLayout(layout); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if createLeading != nil {
+		if err := createLeading(c); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	le, err := NewLineEdit(c)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	pb, err := NewPushButton(c)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := pb.SetText("..."); err != nil {
+		return nil, nil, nil, err
+	}
+	pb.SetMinMaxSize(Size{}, Size{pb.dialogBaseUnitsToPixels(Size{16, 0}).Width, 0})
+
+	return c, le, pb, nil
+}
+
+func newPropertyGridColorEditor(parent Container) (Widget, Property, error) {
+	var color Color
+	var changedPublisher EventPublisher
+	var swatch *CustomWidget
+
+	c, le, pb, err := newPropertyGridButtonEditor(parent, func(c *Composite) (err error) {
+		swatch, err = NewCustomWidget(c, 0, func(canvas *Canvas, updateBounds Rectangle) error {
+			brush, err := NewSolidColorBrush(color)
+			if err != nil {
+				return err
+			}
+			defer brush.Dispose()
+
+			return canvas.FillRectangle(brush, canvas.Bounds())
+		})
+		return
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	size := le.MinSizeHint().Height
+	swatch.SetMinMaxSize(Size{size, size}, Size{size, size})
+
+	le.SetReadOnly(true)
+
+	setColor := func(value Color) {
+		color = value
+		le.SetText(fmt.Sprintf("#%02X%02X%02X", value.R(), value.G(), value.B()))
+		swatch.Invalidate()
+
+		changedPublisher.Publish()
+	}
+
+	pb.Clicked().Attach(func() {
+		dlg := &ColorDialog{Color: color}
+		if ok, _ := dlg.Show(pb.RootWidget()); ok {
+			setColor(dlg.Color)
+		}
+	})
+
+	c.MustRegisterProperty("Value", NewProperty(
+		func() interface{} {
+			return color
+		},
+		func(v interface{}) error {
+			setColor(v.(Color))
+			return nil
+		},
+		changedPublisher.Event()))
+
+	return c, c.Property("Value"), nil
+}
+
+func newPropertyGridFileEditor(parent Container, filter string) (Widget, Property, error) {
+	c, le, pb, err := newPropertyGridButtonEditor(parent, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pb.Clicked().Attach(func() {
+		dlg := &FileDialog{
+			Title:    tr("Select File", "walk"),
+			FilePath: le.Text(),
+			Filter:   filter,
+		}
+		if ok, _ := dlg.ShowOpen(pb.RootWidget()); ok {
+			le.SetText(dlg.FilePath)
+		}
+	})
+
+	c.MustRegisterProperty("Value", NewProperty(
+		func() interface{} {
+			return le.Text()
+		},
+		func(v interface{}) error {
+			return le.SetText(v.(string))
+		},
+		le.TextChanged()))
+
+	return c, c.Property("Value"), nil
+}
+
+// bind binds the editors to the fields of the data source and writes changes
+// back, as long as all editors are valid.
+func (pg *PropertyGrid) bind() error {
+	db := NewDataBinder()
+	db.SetDataSource(pg.dataSource)
+
+	editors := make([]Widget, len(pg.rows))
+	for i, r := range pg.rows {
+		editors[i] = r.editor
+	}
+	db.SetBoundWidgets(editors)
+
+	if err := db.Reset(); err != nil {
+		return err
+	}
+
+	for _, r := range pg.rows {
+		r := r
+
+		for _, prop := range r.editor.BaseWidget().name2Property {
+			if prop.Source() == nil {
+				continue
+			}
+
+			prop.Changed().Attach(func() {
+				if !db.CanSubmit() {
+					return
+				}
+
+				if err := db.Submit(); err != nil {
+					return
+				}
+
+				pg.valueChangedPublisher.Publish(r.field)
+			})
+		}
+	}
+
+	pg.dataBinder = db
+
+	return nil
+}
+
+// Reset updates the editors from the fields of the data source, e.g. after
+// the struct was changed elsewhere.
+func (pg *PropertyGrid) Reset() error {
+	if pg.dataBinder == nil {
+		return nil
+	}
+
+	return pg.dataBinder.Reset()
+}
+
+// ValueChanged returns the event that is published with the name of a field,
+// after the value of its editor was written to it.
+func (pg *PropertyGrid) ValueChanged() *StringEvent {
+	return pg.valueChangedPublisher.Event()
+}
+
+func (pg *PropertyGrid) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_SIZE, WM_SIZING:
+		if pg.composite != nil {
+			pg.composite.SetBounds(pg.ClientBounds())
+		}
+	}
+
+	return pg.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
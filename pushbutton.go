@@ -55,17 +55,7 @@ func (pb *PushButton) ensureProperDialogDefaultButton(hwndFocus HWND) {
 		return
 	}
 
-	root := rootWidget(pb)
-	if root == nil {
-		return
-	}
-
-	dlg, ok := root.(dialogish)
-	if !ok {
-		return
-	}
-
-	defBtn := dlg.DefaultButton()
+	defBtn := defaultButtonFor(widget)
 	if defBtn == nil {
 		return
 	}
@@ -84,18 +74,7 @@ func (pb *PushButton) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uin
 	case WM_GETDLGCODE:
 		hwndFocus := GetFocus()
 		if hwndFocus == pb.hWnd {
-			root := rootWidget(pb)
-			if root == nil {
-				break
-			}
-
-			dlg, ok := root.(dialogish)
-			if !ok {
-				break
-			}
-
-			defBtn := dlg.DefaultButton()
-			if defBtn == pb {
+			if defaultButtonFor(pb) == pb {
 				pb.setAndClearStyleBits(BS_DEFPUSHBUTTON, BS_PUSHBUTTON)
 				return DLGC_BUTTON | DLGC_DEFPUSHBUTTON
 			}
@@ -0,0 +1,381 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import . "github.com/lxn/go-winapi"
+
+const rangeSliderWindowClass = `\o/ Walk_RangeSlider_Class \o/`
+
+const (
+	rangeSliderThumbSize   = 12
+	rangeSliderTrackHeight = 4
+)
+
+func init() {
+	MustRegisterWindowClass(rangeSliderWindowClass)
+}
+
+// RangeSlider is a horizontal slider with two thumbs, that select a range of
+// values, e.g. for filtering by price.
+//
+// With the keyboard, Tab switches between the thumbs and the arrow keys,
+// Page Up, Page Down, Home and End move the focused one.
+type RangeSlider struct {
+	WidgetBase
+	minValue              int
+	maxValue              int
+	low                   int
+	high                  int
+	pageSize              int
+	activeThumb           int
+	dragging              bool
+	focused               bool
+	rangeChangedPublisher EventPublisher
+}
+
+func NewRangeSlider(parent Container) (*RangeSlider, error) {
+	rs := &RangeSlider{maxValue: 100, high: 100, pageSize: 10}
+
+	if err := InitChildWidget(
+		rs,
+		parent,
+		rangeSliderWindowClass,
+		WS_TABSTOP|WS_VISIBLE,
+		0); err != nil {
+		return nil, err
+	}
+
+	rs.MustRegisterProperty("Low", NewProperty(
+		func() interface{} {
+			return rs.Low()
+		},
+		func(v interface{}) error {
+			return rs.SetValues(v.(int), rs.high)
+		},
+		rs.rangeChangedPublisher.Event()))
+
+	rs.MustRegisterProperty("High", NewProperty(
+		func() interface{} {
+			return rs.High()
+		},
+		func(v interface{}) error {
+			return rs.SetValues(rs.low, v.(int))
+		},
+		rs.rangeChangedPublisher.Event()))
+
+	return rs, nil
+}
+
+func (*RangeSlider) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | GrowableHorz | GreedyHorz
+}
+
+func (rs *RangeSlider) MinSizeHint() Size {
+	return Size{4 * rs.thumbSize(), rs.thumbSize() + 8}
+}
+
+func (rs *RangeSlider) SizeHint() Size {
+	return Size{rs.dialogBaseUnitsToPixels(Size{100, 0}).Width, rs.thumbSize() + 8}
+}
+
+func (rs *RangeSlider) thumbSize() int {
+	return rs.IntFromDIP(rangeSliderThumbSize)
+}
+
+// MinValue returns the smallest value the *RangeSlider can select.
+func (rs *RangeSlider) MinValue() int {
+	return rs.minValue
+}
+
+// MaxValue returns the largest value the *RangeSlider can select.
+func (rs *RangeSlider) MaxValue() int {
+	return rs.maxValue
+}
+
+// SetRange sets the values the *RangeSlider can select. The selected values
+// are clamped to it.
+func (rs *RangeSlider) SetRange(min, max int) error {
+	if max < min {
+		return newError("invalid range")
+	}
+
+	rs.minValue, rs.maxValue = min, max
+
+	return rs.SetValues(rs.low, rs.high)
+}
+
+// Low returns the lower end of the selected range.
+func (rs *RangeSlider) Low() int {
+	return rs.low
+}
+
+// High returns the upper end of the selected range.
+func (rs *RangeSlider) High() int {
+	return rs.high
+}
+
+// SetValues sets the selected range. The values are clamped to the range of
+// the *RangeSlider and swapped, if low is greater than high.
+func (rs *RangeSlider) SetValues(low, high int) error {
+	if low > high {
+		low, high = high, low
+	}
+
+	low = rs.clamp(low)
+	high = rs.clamp(high)
+
+	if low == rs.low && high == rs.high {
+		return nil
+	}
+
+	rs.low, rs.high = low, high
+
+	rs.Invalidate()
+
+	rs.rangeChangedPublisher.Publish()
+
+	return nil
+}
+
+// PageSize returns the amount, by which Page Up and Page Down move a thumb.
+func (rs *RangeSlider) PageSize() int {
+	return rs.pageSize
+}
+
+// SetPageSize sets the amount, by which Page Up and Page Down move a thumb.
+func (rs *RangeSlider) SetPageSize(value int) {
+	rs.pageSize = value
+}
+
+// RangeChanged returns the *Event that is published, when the selected range
+// changed.
+func (rs *RangeSlider) RangeChanged() *Event {
+	return rs.rangeChangedPublisher.Event()
+}
+
+func (rs *RangeSlider) clamp(value int) int {
+	if value < rs.minValue {
+		return rs.minValue
+	}
+	if value > rs.maxValue {
+		return rs.maxValue
+	}
+
+	return value
+}
+
+// track returns the horizontal extent, within which the thumb centers move.
+func (rs *RangeSlider) track() (left, width int) {
+	half := rs.thumbSize() / 2
+
+	return half, maxi(rs.ClientBounds().Width-2*half, 1)
+}
+
+func (rs *RangeSlider) xFromValue(value int) int {
+	left, width := rs.track()
+
+	if rs.maxValue == rs.minValue {
+		return left
+	}
+
+	return left + int(MulDiv(int32(value-rs.minValue), int32(width), int32(rs.maxValue-rs.minValue)))
+}
+
+func (rs *RangeSlider) valueFromX(x int) int {
+	left, width := rs.track()
+
+	return rs.clamp(rs.minValue + int(MulDiv(int32(x-left), int32(rs.maxValue-rs.minValue), int32(width))))
+}
+
+// moveThumb moves the thumb with index thumb, 0 for low and 1 for high, to
+// value, without passing the other one.
+func (rs *RangeSlider) moveThumb(thumb, value int) {
+	if thumb == 0 {
+		rs.SetValues(mini(value, rs.high), rs.high)
+	} else {
+		rs.SetValues(rs.low, maxi(value, rs.low))
+	}
+}
+
+func (rs *RangeSlider) thumbValue(thumb int) int {
+	if thumb == 0 {
+		return rs.low
+	}
+
+	return rs.high
+}
+
+func (rs *RangeSlider) paint(canvas *Canvas) error {
+	palette := appSingleton.Palette()
+
+	cb := rs.ClientBounds()
+
+	background, err := NewSolidColorBrush(palette.Background)
+	if err != nil {
+		return err
+	}
+	defer background.Dispose()
+
+	track, err := NewSolidColorBrush(palette.Border)
+	if err != nil {
+		return err
+	}
+	defer track.Dispose()
+
+	selection, err := NewSolidColorBrush(palette.Highlight)
+	if err != nil {
+		return err
+	}
+	defer selection.Dispose()
+
+	thumbBorder, err := NewCosmeticPen(PenSolid, palette.Border)
+	if err != nil {
+		return err
+	}
+	defer thumbBorder.Dispose()
+
+	if err := canvas.FillRectangle(background, cb); err != nil {
+		return err
+	}
+
+	left, width := rs.track()
+	trackHeight := rs.IntFromDIP(rangeSliderTrackHeight)
+	y := (cb.Height - trackHeight) / 2
+
+	if err := canvas.FillRectangle(track, Rectangle{left, y, width, trackHeight}); err != nil {
+		return err
+	}
+
+	lowX, highX := rs.xFromValue(rs.low), rs.xFromValue(rs.high)
+
+	if err := canvas.FillRectangle(selection, Rectangle{lowX, y, highX - lowX, trackHeight}); err != nil {
+		return err
+	}
+
+	size := rs.thumbSize()
+
+	for thumb, x := range []int{lowX, highX} {
+		bounds := Rectangle{x - size/2, (cb.Height - size) / 2, size, size}
+
+		brush := background
+		if rs.focused && thumb == rs.activeThumb {
+			brush = selection
+		}
+
+		if err := canvas.FillEllipse(brush, bounds); err != nil {
+			return err
+		}
+		if err := canvas.DrawEllipse(thumbBorder, bounds); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (rs *RangeSlider) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_PAINT:
+		var ps PAINTSTRUCT
+
+		hdc := BeginPaint(hwnd, &ps)
+		if hdc == 0 {
+			newAPIError("BeginPaint")
+			break
+		}
+		defer EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			break
+		}
+		defer canvas.Dispose()
+
+		rs.paint(canvas)
+
+		return 0
+
+	case WM_ERASEBKGND:
+		return 1
+
+	case WM_SIZE, WM_SIZING:
+		rs.Invalidate()
+
+	case WM_SETFOCUS, WM_KILLFOCUS:
+		rs.focused = msg == WM_SETFOCUS
+		if rs.focused {
+			// Shift+Tab enters at the high thumb.
+			rs.activeThumb = 0
+			if GetKeyState(VK_SHIFT) < 0 {
+				rs.activeThumb = 1
+			}
+		}
+		rs.Invalidate()
+
+	case WM_GETDLGCODE:
+		code := uintptr(DLGC_WANTARROWS)
+		if wParam == VK_TAB && GetKeyState(VK_SHIFT) >= 0 && rs.activeThumb == 0 ||
+			wParam == VK_TAB && GetKeyState(VK_SHIFT) < 0 && rs.activeThumb == 1 {
+
+			// Tab moves between the thumbs first.
+			code |= DLGC_WANTTAB
+		}
+		return code
+
+	case WM_LBUTTONDOWN:
+		SetFocus(hwnd)
+
+		x := int(int16(LOWORD(uint32(lParam))))
+
+		// Pick the closer thumb, or the one in the direction of the click,
+		// if both are at the same position.
+		lowX, highX := rs.xFromValue(rs.low), rs.xFromValue(rs.high)
+		if absi(x-lowX) < absi(x-highX) || lowX == highX && x < lowX {
+			rs.activeThumb = 0
+		} else {
+			rs.activeThumb = 1
+		}
+
+		rs.dragging = true
+		rs.moveThumb(rs.activeThumb, rs.valueFromX(x))
+
+	case WM_MOUSEMOVE:
+		if rs.dragging {
+			rs.moveThumb(rs.activeThumb, rs.valueFromX(int(int16(LOWORD(uint32(lParam))))))
+		}
+
+	case WM_LBUTTONUP, WM_CAPTURECHANGED:
+		rs.dragging = false
+
+	case WM_KEYDOWN:
+		value := rs.thumbValue(rs.activeThumb)
+
+		switch wParam {
+		case VK_TAB:
+			rs.activeThumb = 1 - rs.activeThumb
+			rs.Invalidate()
+
+		case VK_LEFT, VK_DOWN:
+			rs.moveThumb(rs.activeThumb, value-1)
+
+		case VK_RIGHT, VK_UP:
+			rs.moveThumb(rs.activeThumb, value+1)
+
+		case VK_PRIOR:
+			rs.moveThumb(rs.activeThumb, value+rs.pageSize)
+
+		case VK_NEXT:
+			rs.moveThumb(rs.activeThumb, value-rs.pageSize)
+
+		case VK_HOME:
+			rs.moveThumb(rs.activeThumb, rs.minValue)
+
+		case VK_END:
+			rs.moveThumb(rs.activeThumb, rs.maxValue)
+		}
+	}
+
+	return rs.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
@@ -0,0 +1,557 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	ccsNoParentAlign = 0x0008
+
+	rbsVarHeight    = 0x0200
+	rbsBandBorders  = 0x0400
+	rbsAutoSize     = 0x2000
+	rbsDblClkToggle = 0x8000
+
+	rbbsBreak          = 0x0001
+	rbbsChildEdge      = 0x0004
+	rbbsGripperAlways  = 0x0080
+	rbbsUseChevron     = 0x0200
+	rebarDefaultStyles = rbbsChildEdge | rbbsGripperAlways | rbbsUseChevron
+
+	rbbimStyle     = 0x0001
+	rbbimText      = 0x0004
+	rbbimChild     = 0x0010
+	rbbimChildSize = 0x0020
+	rbbimSize      = 0x0040
+	rbbimID        = 0x0100
+	rbbimIdealSize = 0x0200
+
+	rbDeleteBand   = WM_USER + 2
+	rbInsertBand   = WM_USER + 10
+	rbSetBandInfo  = WM_USER + 11
+	rbGetBandCount = WM_USER + 12
+	rbIDToIndex    = WM_USER + 16
+	rbGetBarHeight = WM_USER + 27
+	rbGetBandInfo  = WM_USER + 28
+	rbMoveBand     = WM_USER + 39
+
+	rbnFirst         = -831
+	rbnHeightChange  = rbnFirst - 0
+	rbnChevronPushed = rbnFirst - 10
+
+	tbGetMaxSize = WM_USER + 83
+)
+
+type rebarBandInfo struct {
+	CbSize            uint32
+	FMask             uint32
+	FStyle            uint32
+	ClrFore           COLORREF
+	ClrBack           COLORREF
+	LpText            *uint16
+	Cch               uint32
+	IImage            int32
+	HwndChild         HWND
+	CxMinChild        uint32
+	CyMinChild        uint32
+	Cx                uint32
+	HbmBack           HBITMAP
+	WID               uint32
+	CyChild           uint32
+	CyMaxChild        uint32
+	CyIntegral        uint32
+	CxIdeal           uint32
+	LParam            uintptr
+	CxHeader          uint32
+	RcChevronLocation RECT
+	UChevronState     uint32
+}
+
+type nmRebarChevron struct {
+	Hdr      NMHDR
+	UBand    uint32
+	WID      uint32
+	LParam   uintptr
+	Rc       RECT
+	LParamNM uintptr
+}
+
+// rebarBand is the band of a child of a Rebar.
+type rebarBand struct {
+	widget Widget
+	id     uint32
+	title  string
+}
+
+// Rebar arranges its children in bands, which the user can drag around by
+// their grippers, like the tool bars of many applications.
+//
+// Each child becomes a band. A ToolBar, that does not fit into its band,
+// shows a chevron, which opens a menu with the actions that are cut off.
+//
+// The order, width and rows of the bands are saved to and restored from the
+// Settings of the application, if the *Rebar is persistent.
+type Rebar struct {
+	ContainerBase
+	bands      []*rebarBand
+	nextBandId uint32
+}
+
+// NewRebar returns a new, empty *Rebar.
+func NewRebar(parent Container) (*Rebar, error) {
+	rb := new(Rebar)
+	rb.children = newWidgetList(rb)
+	rb.SetPersistent(true)
+
+	if err := InitChildWidget(
+		rb,
+		parent,
+		"ReBarWindow32",
+		WS_VISIBLE|WS_CLIPCHILDREN|CCS_NODIVIDER|CCS_NORESIZE|ccsNoParentAlign|rbsVarHeight|rbsBandBorders|rbsAutoSize|rbsDblClkToggle,
+		WS_EX_CONTROLPARENT); err != nil {
+		return nil, err
+	}
+
+	rb.layout = &rebarLayout{rb}
+
+	return rb, nil
+}
+
+func (*Rebar) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | GrowableHorz | GreedyHorz
+}
+
+func (rb *Rebar) MinSizeHint() Size {
+	return rb.SizeHint()
+}
+
+func (rb *Rebar) SizeHint() Size {
+	return Size{0, int(rb.SendMessage(rbGetBarHeight, 0, 0))}
+}
+
+// SetLayout returns an error, because a *Rebar arranges its children in
+// bands.
+func (rb *Rebar) SetLayout(value Layout) error {
+	return newError("Rebar does not support layouts")
+}
+
+// BandTitle returns the title shown in front of the band of child.
+func (rb *Rebar) BandTitle(child Widget) string {
+	if band := rb.band(child); band != nil {
+		return band.title
+	}
+
+	return ""
+}
+
+// SetBandTitle sets the title shown in front of the band of child.
+func (rb *Rebar) SetBandTitle(child Widget, title string) error {
+	band := rb.band(child)
+	if band == nil {
+		return newError("child not in Rebar")
+	}
+
+	band.title = title
+
+	rbbi := rebarBandInfo{
+		FMask:  rbbimText,
+		LpText: syscall.StringToUTF16Ptr(title),
+	}
+
+	return rb.setBandInfo(band, &rbbi)
+}
+
+// BandBreak returns if the band of child starts a new row.
+func (rb *Rebar) BandBreak(child Widget) bool {
+	band := rb.band(child)
+	if band == nil {
+		return false
+	}
+
+	rbbi := rebarBandInfo{FMask: rbbimStyle}
+	rb.getBandInfo(band, &rbbi)
+
+	return rbbi.FStyle&rbbsBreak != 0
+}
+
+// SetBandBreak sets if the band of child starts a new row.
+func (rb *Rebar) SetBandBreak(child Widget, value bool) error {
+	band := rb.band(child)
+	if band == nil {
+		return newError("child not in Rebar")
+	}
+
+	rbbi := rebarBandInfo{FMask: rbbimStyle, FStyle: rebarDefaultStyles}
+	if value {
+		rbbi.FStyle |= rbbsBreak
+	}
+
+	return rb.setBandInfo(band, &rbbi)
+}
+
+func (rb *Rebar) band(child Widget) *rebarBand {
+	for _, band := range rb.bands {
+		if band.widget == child {
+			return band
+		}
+	}
+
+	return nil
+}
+
+func (rb *Rebar) bandById(id uint32) *rebarBand {
+	for _, band := range rb.bands {
+		if band.id == id {
+			return band
+		}
+	}
+
+	return nil
+}
+
+func (rb *Rebar) bandIndex(band *rebarBand) int {
+	return int(int32(rb.SendMessage(rbIDToIndex, uintptr(band.id), 0)))
+}
+
+func (rb *Rebar) getBandInfo(band *rebarBand, rbbi *rebarBandInfo) error {
+	rbbi.CbSize = uint32(unsafe.Sizeof(*rbbi))
+
+	if 0 == rb.SendMessage(rbGetBandInfo, uintptr(rb.bandIndex(band)), uintptr(unsafe.Pointer(rbbi))) {
+		return newAPIError("SendMessage(RB_GETBANDINFO)")
+	}
+
+	return nil
+}
+
+func (rb *Rebar) setBandInfo(band *rebarBand, rbbi *rebarBandInfo) error {
+	rbbi.CbSize = uint32(unsafe.Sizeof(*rbbi))
+
+	if 0 == rb.SendMessage(rbSetBandInfo, uintptr(rb.bandIndex(band)), uintptr(unsafe.Pointer(rbbi))) {
+		return newAPIError("SendMessage(RB_SETBANDINFO)")
+	}
+
+	return nil
+}
+
+// bandChildSize returns the size, that the band of widget is to provide.
+func bandChildSize(widget Widget) (min, ideal Size) {
+	if tb, ok := widget.(*ToolBar); ok {
+		// The band may be narrower than all buttons, then it shows a chevron.
+		var s SIZE
+		tb.SendMessage(tbGetMaxSize, 0, uintptr(unsafe.Pointer(&s)))
+
+		ideal = Size{int(s.CX), int(s.CY)}
+		min = Size{mini(ideal.Width, tb.SizeHint().Width), ideal.Height}
+
+		return
+	}
+
+	return widget.MinSizeHint(), widget.SizeHint()
+}
+
+// updateBand updates the band to the size of its child.
+func (rb *Rebar) updateBand(band *rebarBand) error {
+	min, ideal := bandChildSize(band.widget)
+
+	rbbi := rebarBandInfo{
+		FMask:      rbbimChildSize | rbbimIdealSize,
+		CxMinChild: uint32(min.Width),
+		CyMinChild: uint32(maxi(min.Height, ideal.Height)),
+		CxIdeal:    uint32(ideal.Width),
+	}
+
+	return rb.setBandInfo(band, &rbbi)
+}
+
+func (rb *Rebar) onInsertingWidget(index int, widget Widget) error {
+	return nil
+}
+
+func (rb *Rebar) onInsertedWidget(index int, widget Widget) error {
+	if parent := widget.Parent(); parent == nil || parent.BaseWidget().hWnd != rb.hWnd {
+		if err := widget.SetParent(rb); err != nil {
+			return err
+		}
+	}
+
+	if tb, ok := widget.(*ToolBar); ok {
+		// The band sizes and positions the ToolBar, which must not wrap its
+		// buttons, so the chevron can take over.
+		if err := tb.ensureStyleBits(TBSTYLE_WRAPABLE, false); err != nil {
+			return err
+		}
+		if err := tb.ensureStyleBits(CCS_NORESIZE|ccsNoParentAlign|TBSTYLE_FLAT|TBSTYLE_TRANSPARENT, true); err != nil {
+			return err
+		}
+	}
+
+	rb.nextBandId++
+	band := &rebarBand{widget: widget, id: rb.nextBandId}
+
+	min, ideal := bandChildSize(widget)
+
+	rbbi := rebarBandInfo{
+		FMask:      rbbimStyle | rbbimChild | rbbimChildSize | rbbimIdealSize | rbbimSize | rbbimID,
+		FStyle:     rebarDefaultStyles,
+		HwndChild:  widget.BaseWidget().hWnd,
+		CxMinChild: uint32(min.Width),
+		CyMinChild: uint32(maxi(min.Height, ideal.Height)),
+		CxIdeal:    uint32(ideal.Width),
+		Cx:         uint32(ideal.Width),
+		WID:        band.id,
+	}
+	rbbi.CbSize = uint32(unsafe.Sizeof(rbbi))
+
+	if 0 == rb.SendMessage(rbInsertBand, uintptr(index), uintptr(unsafe.Pointer(&rbbi))) {
+		return newAPIError("SendMessage(RB_INSERTBAND)")
+	}
+
+	rb.bands = append(rb.bands, band)
+
+	return rb.updateParentLayout()
+}
+
+func (rb *Rebar) onRemovingWidget(index int, widget Widget) error {
+	band := rb.band(widget)
+	if band == nil {
+		return nil
+	}
+
+	if 0 == rb.SendMessage(rbDeleteBand, uintptr(rb.bandIndex(band)), 0) {
+		return newAPIError("SendMessage(RB_DELETEBAND)")
+	}
+
+	for i, b := range rb.bands {
+		if b == band {
+			rb.bands = append(rb.bands[:i], rb.bands[i+1:]...)
+			break
+		}
+	}
+
+	if widget.Parent() != nil && widget.Parent().BaseWidget().hWnd == rb.hWnd {
+		return widget.SetParent(nil)
+	}
+
+	return nil
+}
+
+func (rb *Rebar) onRemovedWidget(index int, widget Widget) error {
+	return rb.updateParentLayout()
+}
+
+func (rb *Rebar) onClearingWidgets() error {
+	for _, widget := range rb.children.items {
+		if err := rb.onRemovingWidget(0, widget); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (rb *Rebar) onClearedWidgets() error {
+	return rb.updateParentLayout()
+}
+
+// SaveState saves the order, widths and rows of the bands and the state of
+// the children.
+func (rb *Rebar) SaveState() error {
+	count := int(rb.SendMessage(rbGetBandCount, 0, 0))
+
+	entries := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		rbbi := rebarBandInfo{FMask: rbbimID | rbbimSize | rbbimStyle}
+		rbbi.CbSize = uint32(unsafe.Sizeof(rbbi))
+
+		if 0 == rb.SendMessage(rbGetBandInfo, uintptr(i), uintptr(unsafe.Pointer(&rbbi))) {
+			return newAPIError("SendMessage(RB_GETBANDINFO)")
+		}
+
+		entries = append(entries, fmt.Sprintf("%d,%d,%d", rbbi.WID, rbbi.Cx, rbbi.FStyle&rbbsBreak))
+	}
+
+	if err := rb.putState(strings.Join(entries, ";")); err != nil {
+		return err
+	}
+
+	return rb.ContainerBase.SaveState()
+}
+
+// RestoreState restores the order, widths and rows of the bands and the state
+// of the children.
+func (rb *Rebar) RestoreState() error {
+	state, err := rb.getState()
+	if err != nil {
+		return err
+	}
+
+	if state != "" {
+		for i, entry := range strings.Split(state, ";") {
+			parts := strings.Split(entry, ",")
+			if len(parts) != 3 {
+				continue
+			}
+
+			var values [3]uint64
+			for j, part := range parts {
+				if values[j], err = strconv.ParseUint(part, 10, 32); err != nil {
+					return wrapError(err)
+				}
+			}
+
+			band := rb.bandById(uint32(values[0]))
+			if band == nil {
+				// The bands changed since the state was saved.
+				continue
+			}
+
+			rb.SendMessage(rbMoveBand, uintptr(rb.bandIndex(band)), uintptr(i))
+
+			rbbi := rebarBandInfo{
+				FMask:  rbbimSize | rbbimStyle,
+				Cx:     uint32(values[1]),
+				FStyle: rebarDefaultStyles | uint32(values[2])&rbbsBreak,
+			}
+			if err := rb.setBandInfo(band, &rbbi); err != nil {
+				return err
+			}
+		}
+	}
+
+	return rb.ContainerBase.RestoreState()
+}
+
+// showChevronMenu shows the actions of the ToolBar of band, that are cut off,
+// in a menu below the chevron.
+func (rb *Rebar) showChevronMenu(band *rebarBand, chevron RECT) {
+	tb, ok := band.widget.(*ToolBar)
+	if !ok {
+		return
+	}
+
+	var cr RECT
+	if !GetClientRect(tb.hWnd, &cr) {
+		return
+	}
+
+	menu, err := NewMenu()
+	if err != nil {
+		return
+	}
+	defer menu.Dispose()
+	defer menu.Actions().Clear()
+
+	for _, action := range tb.actions.actions {
+		if !tb.actionVisible(action) || tb.actionText(action) == "-" {
+			continue
+		}
+
+		var r RECT
+		tb.SendMessage(tbGetRect, uintptr(action.id), uintptr(unsafe.Pointer(&r)))
+
+		if r.Right > cr.Right {
+			if err := menu.Actions().Add(action); err != nil {
+				return
+			}
+		}
+	}
+
+	if menu.Actions().Len() == 0 {
+		return
+	}
+
+	pt := POINT{chevron.Left, chevron.Top}
+	if !ClientToScreen(rb.hWnd, &pt) {
+		return
+	}
+
+	anchor := Rectangle{int(pt.X), int(pt.Y), int(chevron.Right - chevron.Left), int(chevron.Bottom - chevron.Top)}
+
+	if chosen, _ := menu.ExecAnchored(anchor, PopupBelow); chosen != nil {
+		chosen.raiseTriggered()
+	}
+}
+
+func (rb *Rebar) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_NOTIFY:
+		nmh := (*NMHDR)(unsafe.Pointer(lParam))
+		if nmh.HwndFrom != rb.hWnd {
+			// Notifications of the children.
+			break
+		}
+
+		switch int32(nmh.Code) {
+		case rbnHeightChange:
+			rb.updateParentLayout()
+
+		case rbnChevronPushed:
+			nmrc := (*nmRebarChevron)(unsafe.Pointer(lParam))
+
+			if band := rb.bandById(nmrc.WID); band != nil {
+				rb.showChevronMenu(band, nmrc.Rc)
+			}
+		}
+
+		return 0
+	}
+
+	return rb.ContainerBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+// rebarLayout updates the bands of a Rebar, when its children ask their
+// parent for layout, e.g. when a ToolBar got another button.
+type rebarLayout struct {
+	rb *Rebar
+}
+
+func (l *rebarLayout) Container() Container {
+	return l.rb
+}
+
+func (l *rebarLayout) SetContainer(value Container) {
+}
+
+func (l *rebarLayout) Margins() Margins {
+	return Margins{}
+}
+
+func (l *rebarLayout) SetMargins(value Margins) error {
+	return newError("not supported")
+}
+
+func (l *rebarLayout) Spacing() int {
+	return 0
+}
+
+func (l *rebarLayout) SetSpacing(value int) error {
+	return newError("not supported")
+}
+
+func (l *rebarLayout) LayoutFlags() LayoutFlags {
+	return l.rb.LayoutFlags()
+}
+
+func (l *rebarLayout) MinSize() Size {
+	return l.rb.MinSizeHint()
+}
+
+func (l *rebarLayout) Update(reset bool) error {
+	for _, band := range l.rb.bands {
+		if err := l.rb.updateBand(band); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
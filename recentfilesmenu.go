@@ -0,0 +1,233 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	recentFilesSeparator     = "|"
+	recentFilesMaxTextLength = 50
+)
+
+// RecentFilesMenu maintains a list of most recently used files inside a *Menu.
+//
+// The list is persisted through App().Settings(), using the settings key
+// passed to NewRecentFilesMenu.
+type RecentFilesMenu struct {
+	menu                  *Menu
+	settingsKey           string
+	filePaths             []string
+	maxCount              int
+	fileSelectedPublisher StringEventPublisher
+}
+
+// NewRecentFilesMenu creates a new *RecentFilesMenu that manages the Actions of
+// menu and restores the list of files from App().Settings().
+func NewRecentFilesMenu(menu *Menu, settingsKey string) (*RecentFilesMenu, error) {
+	if menu == nil {
+		return nil, newError("menu must not be nil")
+	}
+	if settingsKey == "" {
+		return nil, newError("settingsKey must not be empty")
+	}
+
+	rfm := &RecentFilesMenu{
+		menu:        menu,
+		settingsKey: settingsKey,
+		maxCount:    8,
+	}
+
+	if settings := appSingleton.settings; settings != nil {
+		if s, ok := settings.Get(settingsKey); ok && s != "" {
+			rfm.filePaths = strings.Split(s, recentFilesSeparator)
+		}
+	}
+
+	if len(rfm.filePaths) > rfm.maxCount {
+		rfm.filePaths = rfm.filePaths[:rfm.maxCount]
+	}
+
+	if err := rfm.rebuild(); err != nil {
+		return nil, err
+	}
+
+	return rfm, nil
+}
+
+// Menu returns the *Menu managed by the *RecentFilesMenu.
+func (rfm *RecentFilesMenu) Menu() *Menu {
+	return rfm.menu
+}
+
+// FilePaths returns the file paths, most recently used first.
+func (rfm *RecentFilesMenu) FilePaths() []string {
+	return append([]string(nil), rfm.filePaths...)
+}
+
+// MaxCount returns the maximum number of files in the list.
+func (rfm *RecentFilesMenu) MaxCount() int {
+	return rfm.maxCount
+}
+
+// SetMaxCount sets the maximum number of files in the list.
+//
+// The default is 8.
+func (rfm *RecentFilesMenu) SetMaxCount(value int) error {
+	if value < 1 {
+		return newError("value must be > 0")
+	}
+
+	rfm.maxCount = value
+
+	if len(rfm.filePaths) > value {
+		rfm.filePaths = rfm.filePaths[:value]
+
+		return rfm.update()
+	}
+
+	return nil
+}
+
+// Add moves filePath to the top of the list, adding it if necessary.
+func (rfm *RecentFilesMenu) Add(filePath string) error {
+	if filePath == "" || strings.Contains(filePath, recentFilesSeparator) {
+		return newError("invalid file path")
+	}
+
+	rfm.removeFilePath(filePath)
+
+	rfm.filePaths = append([]string{filePath}, rfm.filePaths...)
+
+	if len(rfm.filePaths) > rfm.maxCount {
+		rfm.filePaths = rfm.filePaths[:rfm.maxCount]
+	}
+
+	return rfm.update()
+}
+
+// Remove removes filePath from the list, e.g. because the file no longer
+// exists.
+func (rfm *RecentFilesMenu) Remove(filePath string) error {
+	if !rfm.removeFilePath(filePath) {
+		return nil
+	}
+
+	return rfm.update()
+}
+
+// Clear removes all files from the list.
+func (rfm *RecentFilesMenu) Clear() error {
+	rfm.filePaths = nil
+
+	return rfm.update()
+}
+
+// FileSelected returns a *StringEvent that is published with the file path,
+// when the user selects a file from the menu.
+func (rfm *RecentFilesMenu) FileSelected() *StringEvent {
+	return rfm.fileSelectedPublisher.Event()
+}
+
+func (rfm *RecentFilesMenu) removeFilePath(filePath string) bool {
+	for i, fp := range rfm.filePaths {
+		if strings.EqualFold(fp, filePath) {
+			rfm.filePaths = append(rfm.filePaths[:i], rfm.filePaths[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (rfm *RecentFilesMenu) update() error {
+	if err := rfm.rebuild(); err != nil {
+		return err
+	}
+
+	if settings := appSingleton.settings; settings != nil {
+		return settings.Put(rfm.settingsKey, strings.Join(rfm.filePaths, recentFilesSeparator))
+	}
+
+	return nil
+}
+
+func (rfm *RecentFilesMenu) rebuild() error {
+	actions := rfm.menu.Actions()
+
+	if err := actions.Clear(); err != nil {
+		return err
+	}
+
+	for i, filePath := range rfm.filePaths {
+		filePath := filePath
+
+		action := NewAction()
+
+		text := ellipsizePath(filePath, recentFilesMaxTextLength)
+		text = strings.Replace(text, "&", "&&", -1)
+		if i < 9 {
+			text = fmt.Sprintf("&%d %s", i+1, text)
+		} else {
+			text = fmt.Sprintf("%d %s", i+1, text)
+		}
+
+		if err := action.SetText(text); err != nil {
+			return err
+		}
+		if err := action.SetToolTip(filePath); err != nil {
+			return err
+		}
+
+		action.Triggered().Attach(func() {
+			rfm.fileSelectedPublisher.Publish(filePath)
+		})
+
+		if err := actions.Add(action); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ellipsizePath shortens filePath to at most maxLen runes by replacing
+// directories in the middle with "...", keeping the volume and file name.
+func ellipsizePath(filePath string, maxLen int) string {
+	if len([]rune(filePath)) <= maxLen {
+		return filePath
+	}
+
+	volume := filepath.VolumeName(filePath)
+	dir, file := filepath.Split(filePath[len(volume):])
+
+	const ellipsis = "..."
+	sep := string(filepath.Separator)
+
+	dirs := strings.Split(strings.Trim(dir, sep), sep)
+
+	for len(dirs) > 0 {
+		dirs = dirs[1:]
+
+		s := volume + sep + ellipsis + sep
+		if len(dirs) > 0 {
+			s += strings.Join(dirs, sep) + sep
+		}
+		s += file
+
+		if len([]rune(s)) <= maxLen {
+			return s
+		}
+	}
+
+	if runes := []rune(file); len(runes) > maxLen {
+		return ellipsis + string(runes[len(runes)-maxLen+len(ellipsis):])
+	}
+
+	return file
+}
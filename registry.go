@@ -29,14 +29,14 @@ func LocalMachineKey() *RegistryKey {
 
 func RegistryKeyString(rootKey *RegistryKey, subKeyPath, valueName string) (value string, err error) {
 	var hKey HKEY
-	if RegOpenKeyEx(
+	if ret := RegOpenKeyEx(
 		rootKey.hKey,
 		syscall.StringToUTF16Ptr(subKeyPath),
 		0,
 		KEY_READ,
-		&hKey) != ERROR_SUCCESS {
+		&hKey); ret != ERROR_SUCCESS {
 
-		return "", newError("RegistryKeyString: Failed to open subkey.")
+		return "", callError("RegOpenKeyEx", syscall.Errno(ret))
 	}
 	defer RegCloseKey(hKey)
 
@@ -44,28 +44,28 @@ func RegistryKeyString(rootKey *RegistryKey, subKeyPath, valueName string) (valu
 	var data []uint16
 	var bufSize uint32
 
-	if ERROR_SUCCESS != RegQueryValueEx(
+	if ret := RegQueryValueEx(
 		hKey,
 		syscall.StringToUTF16Ptr(valueName),
 		nil,
 		&typ,
 		nil,
-		&bufSize) {
+		&bufSize); ret != ERROR_SUCCESS {
 
-		return "", newError("RegQueryValueEx #1")
+		return "", callError("RegQueryValueEx", syscall.Errno(ret))
 	}
 
 	data = make([]uint16, bufSize/2+1)
 
-	if ERROR_SUCCESS != RegQueryValueEx(
+	if ret := RegQueryValueEx(
 		hKey,
 		syscall.StringToUTF16Ptr(valueName),
 		nil,
 		&typ,
 		(*byte)(unsafe.Pointer(&data[0])),
-		&bufSize) {
+		&bufSize); ret != ERROR_SUCCESS {
 
-		return "", newError("RegQueryValueEx #2")
+		return "", callError("RegQueryValueEx", syscall.Errno(ret))
 	}
 
 	return syscall.UTF16ToString(data), nil
@@ -73,28 +73,28 @@ func RegistryKeyString(rootKey *RegistryKey, subKeyPath, valueName string) (valu
 
 func RegistryKeyUint32(rootKey *RegistryKey, subKeyPath, valueName string) (value uint32, err error) {
 	var hKey HKEY
-	if RegOpenKeyEx(
+	if ret := RegOpenKeyEx(
 		rootKey.hKey,
 		syscall.StringToUTF16Ptr(subKeyPath),
 		0,
 		KEY_READ,
-		&hKey) != ERROR_SUCCESS {
+		&hKey); ret != ERROR_SUCCESS {
 
-		return 0, newError("RegistryKeyUint32: Failed to open subkey.")
+		return 0, callError("RegOpenKeyEx", syscall.Errno(ret))
 	}
 	defer RegCloseKey(hKey)
 
 	bufSize := uint32(4)
 
-	if ERROR_SUCCESS != RegQueryValueEx(
+	if ret := RegQueryValueEx(
 		hKey,
 		syscall.StringToUTF16Ptr(valueName),
 		nil,
 		nil,
 		(*byte)(unsafe.Pointer(&value)),
-		&bufSize) {
+		&bufSize); ret != ERROR_SUCCESS {
 
-		return 0, newError("RegQueryValueEx")
+		return 0, callError("RegQueryValueEx", syscall.Errno(ret))
 	}
 
 	return
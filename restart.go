@@ -0,0 +1,213 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// restartStateArg prefixes the command line argument, that carries the state
+// token from one instance of the application to the next.
+const restartStateArg = "--walk-restart-state="
+
+// restartedByOSArg marks instances started by Windows, because the
+// application was registered using RegisterRestart.
+const restartedByOSArg = "--walk-restarted-by-os"
+
+// maxRestartCommandLine is the maximum length of the command line accepted
+// by RegisterApplicationRestart.
+const maxRestartCommandLine = 1024
+
+// RestartPolicy is a combination of flags, that exclude situations in which
+// Windows restarts an application registered using RegisterRestart.
+type RestartPolicy uint32
+
+const (
+	RestartNoCrash  RestartPolicy = 0x1
+	RestartNoHang   RestartPolicy = 0x2
+	RestartNoPatch  RestartPolicy = 0x4
+	RestartNoReboot RestartPolicy = 0x8
+)
+
+var (
+	procRegisterApplicationRestart   = libkernel32.NewProc("RegisterApplicationRestart")
+	procUnregisterApplicationRestart = libkernel32.NewProc("UnregisterApplicationRestart")
+)
+
+var (
+	restartState       string
+	restartStateSet    bool
+	restartedByOS      bool
+	restartedPublished bool
+)
+
+func init() {
+	// The arguments are removed, so applications parsing os.Args don't have
+	// to know about them.
+	args := os.Args[:1]
+
+	for _, arg := range os.Args[1:] {
+		switch {
+		case strings.HasPrefix(arg, restartStateArg):
+			restartState = strings.TrimPrefix(arg, restartStateArg)
+			restartStateSet = true
+
+		case arg == restartedByOSArg:
+			restartedByOS = true
+
+		default:
+			args = append(args, arg)
+		}
+	}
+
+	os.Args = args
+}
+
+// RestartState returns the state token, that the previous instance of the
+// application passed to RestartWithState or RegisterRestart, if this instance
+// was started that way.
+func (app *Application) RestartState() (state string, ok bool) {
+	return restartState, restartStateSet
+}
+
+// Restart closes all top-level windows, starts a new instance of the
+// application with args and exits, e.g. to apply settings that can't be
+// changed at runtime.
+//
+// The application is shut down like using Shutdown. If that is canceled,
+// Restart returns an error and the application keeps running.
+func (app *Application) Restart(args ...string) error {
+	return app.restart(args, "", false)
+}
+
+// RestartWithState is like Restart, but passes state to the new instance,
+// where RestartState returns it.
+func (app *Application) RestartWithState(state string, args ...string) error {
+	return app.restart(args, state, true)
+}
+
+func (app *Application) restart(args []string, state string, withState bool) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if withState {
+		args = append(append([]string(nil), args...), restartStateArg+state)
+	}
+
+	if !app.shutdown() {
+		return newError("restart canceled")
+	}
+
+	// Otherwise the new instance would forward its arguments to this one.
+	app.releaseSingleInstance()
+
+	if _, err := os.StartProcess(exe, append([]string{exe}, args...), &os.ProcAttr{
+		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr},
+	}); err != nil {
+		return wrapError(err)
+	}
+
+	app.Exit(0)
+
+	return nil
+}
+
+// RestartedByOS returns if Windows started this instance of the application,
+// because the previous one was registered using RegisterRestart.
+func (app *Application) RestartedByOS() bool {
+	return restartedByOS
+}
+
+// Restarted returns the *StringEvent that is published with the state token
+// passed to RegisterRestart, when the message loop of the first top-level
+// window starts in an instance started by Windows.
+//
+// Handlers typically restore the documents and windows, that were open when
+// the previous instance ended. The bounds of windows, that were snapped, e.g.
+// as part of a Windows 11 snap group, are kept by SaveState and RestoreState,
+// so persistent windows reappear in their snapped arrangement.
+func (app *Application) Restarted() *StringEvent {
+	return app.restartedPublisher.Event()
+}
+
+// RegisterRestart registers the application with Windows, to be started again
+// with args after it crashed, hung or was closed to install an update or
+// reboot, as long as it ran for at least 60 seconds. policy excludes some of
+// these situations.
+//
+// If state is not empty, RestartState of the new instance returns it. To
+// keep the state current, call RegisterRestart again when it changes, e.g. in
+// a SessionEnding handler. The command line built from args and state must
+// not be longer than 1024 characters.
+func (app *Application) RegisterRestart(policy RestartPolicy, state string, args ...string) error {
+	if err := procRegisterApplicationRestart.Find(); err != nil {
+		// Before Windows Vista.
+		return nil
+	}
+
+	args = append(append([]string(nil), args...), restartedByOSArg)
+
+	if state != "" {
+		args = append(args, restartStateArg+state)
+	}
+
+	escaped := make([]string, len(args))
+	for i, arg := range args {
+		escaped[i] = syscall.EscapeArg(arg)
+	}
+
+	commandLine := strings.Join(escaped, " ")
+	if len(commandLine) > maxRestartCommandLine {
+		return newError("restart command line too long")
+	}
+
+	if hr, _, _ := procRegisterApplicationRestart.Call(
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(commandLine))),
+		uintptr(policy)); hr != 0 {
+
+		return errorFromHRESULT("RegisterApplicationRestart", HRESULT(hr))
+	}
+
+	return nil
+}
+
+// RegisterRestartWithCurrentArgs is like RegisterRestart, but restarts the
+// application with the command line arguments of this instance.
+func (app *Application) RegisterRestartWithCurrentArgs(policy RestartPolicy, state string) error {
+	return app.RegisterRestart(policy, state, os.Args[1:]...)
+}
+
+// UnregisterRestart undoes RegisterRestart, e.g. when the user chose to quit
+// the application.
+func (app *Application) UnregisterRestart() error {
+	if err := procUnregisterApplicationRestart.Find(); err != nil {
+		return nil
+	}
+
+	if hr, _, _ := procUnregisterApplicationRestart.Call(); hr != 0 {
+		return errorFromHRESULT("UnregisterApplicationRestart", HRESULT(hr))
+	}
+
+	return nil
+}
+
+// publishRestarted publishes the Restarted event once, if this instance was
+// started by Windows.
+func publishRestarted() {
+	if !restartedByOS || restartedPublished {
+		return
+	}
+
+	restartedPublished = true
+
+	appSingleton.restartedPublisher.Publish(restartState)
+}
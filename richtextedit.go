@@ -0,0 +1,622 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+var libmsftedit = syscall.NewLazyDLL("Msftedit.dll")
+
+const (
+	emExLimitText    = WM_USER + 53
+	emExGetSel       = WM_USER + 52
+	emExSetSel       = WM_USER + 55
+	emGetCharFormat  = WM_USER + 58
+	emGetParaFormat  = WM_USER + 61
+	emSetCharFormat  = WM_USER + 68
+	emSetEventMask   = WM_USER + 69
+	emSetParaFormat  = WM_USER + 71
+	emStreamIn       = WM_USER + 73
+	emStreamOut      = WM_USER + 74
+	emGetTextRange   = WM_USER + 75
+	emAutoURLDetect  = WM_USER + 91
+	emGetAutoURLDet  = WM_USER + 92
+	enmChange        = 0x00000001
+	enmLink          = 0x04000000
+	enLink           = 0x070B
+	scfSelection     = 0x0001
+	sfText           = 0x0001
+	sfRTF            = 0x0002
+	sfUseCodePage    = 0x0020
+	sffSelection     = 0x8000
+	cpUTF8           = 65001
+	cfmBold          = 0x00000001
+	cfmItalic        = 0x00000002
+	cfmUnderline     = 0x00000004
+	cfmStrikeOut     = 0x00000008
+	cfmBackColor     = 0x04000000
+	cfmFace          = 0x20000000
+	cfmColor         = 0x40000000
+	cfmSize          = 0x80000000
+	pfmStartIndent   = 0x00000001
+	pfmRightIndent   = 0x00000002
+	pfmOffset        = 0x00000004
+	pfmAlignment     = 0x00000008
+	pfmNumbering     = 0x00000020
+	pfaLeft          = 1
+	pfaRight         = 2
+	pfaCenter        = 3
+	pfnBullet        = 1
+	twipsPerPoint    = 20
+	richTextMaxChars = 0x7FFFFFFE
+)
+
+type charRange struct {
+	cpMin int32
+	cpMax int32
+}
+
+type textRange struct {
+	chrg      charRange
+	lpstrText *uint16
+}
+
+type charFormat2 struct {
+	cbSize          uint32
+	dwMask          uint32
+	dwEffects       uint32
+	yHeight         int32
+	yOffset         int32
+	crTextColor     uint32
+	bCharSet        byte
+	bPitchAndFamily byte
+	szFaceName      [32]uint16
+	wWeight         uint16
+	sSpacing        int16
+	crBackColor     uint32
+	lcid            uint32
+	dwReserved      uint32
+	sStyle          int16
+	wKerning        uint16
+	bUnderlineType  byte
+	bAnimation      byte
+	bRevAuthor      byte
+	bUnderlineColor byte
+}
+
+type paraFormat2 struct {
+	cbSize           uint32
+	dwMask           uint32
+	wNumbering       uint16
+	wEffects         uint16
+	dxStartIndent    int32
+	dxRightIndent    int32
+	dxOffset         int32
+	wAlignment       uint16
+	cTabCount        int16
+	rgxTabs          [32]int32
+	dySpaceBefore    int32
+	dySpaceAfter     int32
+	dyLineSpacing    int32
+	sStyle           int16
+	bLineSpacingRule byte
+	bOutlineLevel    byte
+	wShadingWeight   uint16
+	wShadingStyle    uint16
+	wNumberingStart  uint16
+	wNumberingStyle  uint16
+	wNumberingTab    uint16
+	wBorderSpace     uint16
+	wBorderWidth     uint16
+	wBorders         uint16
+}
+
+type editStream struct {
+	dwCookie    uintptr
+	dwError     uint32
+	pfnCallback uintptr
+}
+
+type enLinkNotification struct {
+	nmhdr  NMHDR
+	msg    uint32
+	wParam uintptr
+	lParam uintptr
+	chrg   charRange
+}
+
+// RichTextFormat specifies the format of a document read or written by a
+// *RichTextEdit.
+type RichTextFormat int
+
+const (
+	// RichTextFormatRTF is the Rich Text Format.
+	RichTextFormatRTF RichTextFormat = iota
+
+	// RichTextFormatText is UTF-8 encoded plain text.
+	RichTextFormatText
+)
+
+func (format RichTextFormat) streamFlags() uintptr {
+	if format == RichTextFormatText {
+		return cpUTF8<<16 | sfUseCodePage | sfText
+	}
+
+	return sfRTF
+}
+
+// richTextStream is the state of an EM_STREAMIN or EM_STREAMOUT, which the
+// callback finds by the cookie.
+type richTextStream struct {
+	r   io.Reader
+	w   io.Writer
+	err error
+}
+
+var (
+	richTextStreams           = make(map[uintptr]*richTextStream)
+	richTextStreamCookie      uintptr
+	richTextStreamCallbackPtr = syscall.NewCallback(richTextStreamCallback)
+)
+
+func richTextStreamCallback(cookie, buf, cb, pcb uintptr) uintptr {
+	s := richTextStreams[cookie]
+	p := (*[1 << 30]byte)(unsafe.Pointer(buf))[:int32(cb):int32(cb)]
+	n := (*int32)(unsafe.Pointer(pcb))
+
+	var count int
+	if s.r != nil {
+		count, s.err = io.ReadFull(s.r, p)
+		if s.err == io.EOF || s.err == io.ErrUnexpectedEOF {
+			s.err = nil
+		}
+	} else {
+		count, s.err = s.w.Write(p)
+	}
+
+	*n = int32(count)
+
+	if s.err != nil {
+		return 1
+	}
+
+	return 0
+}
+
+// RichTextEdit is a multi-line text widget with character and paragraph
+// formatting, images and hyperlinks, based on the RichEdit control.
+//
+// Documents are loaded and saved as RTF or plain text by streaming, so large
+// documents need not be held in memory as a whole.
+type RichTextEdit struct {
+	WidgetBase
+	readOnlyChangedPublisher EventPublisher
+	textChangedPublisher     EventPublisher
+	linkClickedPublisher     StringEventPublisher
+}
+
+func NewRichTextEdit(parent Container) (*RichTextEdit, error) {
+	if err := libmsftedit.Load(); err != nil {
+		return nil, wrapError(err)
+	}
+
+	rte := new(RichTextEdit)
+
+	if err := InitChildWidget(
+		rte,
+		parent,
+		"RICHEDIT50W",
+		WS_TABSTOP|WS_VISIBLE|WS_VSCROLL|ES_MULTILINE|ES_WANTRETURN|ES_AUTOVSCROLL,
+		WS_EX_CLIENTEDGE); err != nil {
+		return nil, err
+	}
+
+	rte.SendMessage(emExLimitText, 0, richTextMaxChars)
+	rte.SendMessage(emSetEventMask, 0, enmChange|enmLink)
+
+	rte.MustRegisterProperty("ReadOnly", NewProperty(
+		func() interface{} {
+			return rte.ReadOnly()
+		},
+		func(v interface{}) error {
+			return rte.SetReadOnly(v.(bool))
+		},
+		rte.readOnlyChangedPublisher.Event()))
+
+	rte.MustRegisterProperty("Text", NewProperty(
+		func() interface{} {
+			return rte.Text()
+		},
+		func(v interface{}) error {
+			return rte.SetText(v.(string))
+		},
+		rte.textChangedPublisher.Event()))
+
+	return rte, nil
+}
+
+func (*RichTextEdit) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | ShrinkableVert | GrowableHorz | GrowableVert | GreedyHorz | GreedyVert
+}
+
+func (rte *RichTextEdit) MinSizeHint() Size {
+	return rte.dialogBaseUnitsToPixels(Size{20, 12})
+}
+
+func (rte *RichTextEdit) SizeHint() Size {
+	return Size{100, 100}
+}
+
+// Text returns the plain text of the document.
+func (rte *RichTextEdit) Text() string {
+	return widgetText(rte.hWnd)
+}
+
+// SetText replaces the document by plain text.
+func (rte *RichTextEdit) SetText(value string) error {
+	return setWidgetText(rte.hWnd, value)
+}
+
+func (rte *RichTextEdit) TextChanged() *Event {
+	return rte.textChangedPublisher.Event()
+}
+
+func (rte *RichTextEdit) ReadOnly() bool {
+	return rte.hasStyleBits(ES_READONLY)
+}
+
+func (rte *RichTextEdit) SetReadOnly(readOnly bool) error {
+	if 0 == rte.SendMessage(EM_SETREADONLY, uintptr(BoolToBOOL(readOnly)), 0) {
+		return newAPIError("SendMessage(EM_SETREADONLY)")
+	}
+
+	rte.readOnlyChangedPublisher.Publish()
+
+	return nil
+}
+
+func (rte *RichTextEdit) TextSelection() (start, end int) {
+	var cr charRange
+	rte.SendMessage(emExGetSel, 0, uintptr(unsafe.Pointer(&cr)))
+
+	return int(cr.cpMin), int(cr.cpMax)
+}
+
+// SetTextSelection selects the text from start to end. With start 0 and end
+// -1, all text is selected.
+func (rte *RichTextEdit) SetTextSelection(start, end int) {
+	cr := charRange{int32(start), int32(end)}
+	rte.SendMessage(emExSetSel, 0, uintptr(unsafe.Pointer(&cr)))
+}
+
+func (rte *RichTextEdit) ReplaceSelectedText(text string, canUndo bool) {
+	rte.SendMessage(EM_REPLACESEL,
+		uintptr(BoolToBOOL(canUndo)),
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(text))))
+}
+
+// RTF returns the document in the Rich Text Format.
+func (rte *RichTextEdit) RTF() (string, error) {
+	var buf bytes.Buffer
+	if err := rte.Save(&buf, RichTextFormatRTF); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// SetRTF replaces the document by rtf.
+func (rte *RichTextEdit) SetRTF(rtf string) error {
+	return rte.Load(strings.NewReader(rtf), RichTextFormatRTF)
+}
+
+// Load replaces the document by the one read from r in format.
+func (rte *RichTextEdit) Load(r io.Reader, format RichTextFormat) error {
+	return rte.stream(emStreamIn, format.streamFlags(), &richTextStream{r: r})
+}
+
+// Save writes the document to w in format.
+func (rte *RichTextEdit) Save(w io.Writer, format RichTextFormat) error {
+	return rte.stream(emStreamOut, format.streamFlags(), &richTextStream{w: w})
+}
+
+// InsertRTF replaces the selection by rtf.
+func (rte *RichTextEdit) InsertRTF(rtf string) error {
+	return rte.stream(emStreamIn, sfRTF|sffSelection, &richTextStream{r: strings.NewReader(rtf)})
+}
+
+func (rte *RichTextEdit) stream(msg uint32, flags uintptr, s *richTextStream) error {
+	richTextStreamCookie++
+	cookie := richTextStreamCookie
+
+	richTextStreams[cookie] = s
+	defer delete(richTextStreams, cookie)
+
+	es := editStream{dwCookie: cookie, pfnCallback: richTextStreamCallbackPtr}
+
+	rte.SendMessage(msg, flags, uintptr(unsafe.Pointer(&es)))
+
+	if s.err != nil {
+		return wrapError(s.err)
+	}
+	if es.dwError != 0 {
+		return newError(fmt.Sprintf("streaming failed: error %d", es.dwError))
+	}
+
+	return nil
+}
+
+// InsertImage replaces the selection by img, which is embedded in the
+// document as PNG.
+func (rte *RichTextEdit) InsertImage(img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return wrapError(err)
+	}
+
+	size := img.Bounds().Size()
+	dpi := rte.DPI()
+
+	return rte.InsertRTF(fmt.Sprintf(
+		`{\rtf1{\pict\pngblip\picw%d\pich%d\picwgoal%d\pichgoal%d %x}}`,
+		size.X,
+		size.Y,
+		size.X*1440/dpi,
+		size.Y*1440/dpi,
+		buf.Bytes()))
+}
+
+// InsertLink replaces the selection by text, which links to url. Clicking it
+// publishes LinkClicked.
+func (rte *RichTextEdit) InsertLink(text, url string) error {
+	return rte.InsertRTF(fmt.Sprintf(
+		`{\rtf1{\field{\*\fldinst{HYPERLINK "%s"}}{\fldrslt{%s}}}}`,
+		rtfEscape(url),
+		rtfEscape(text)))
+}
+
+// rtfEscape escapes the characters of s, that have a special meaning in RTF
+// or are not ASCII.
+func rtfEscape(s string) string {
+	var buf bytes.Buffer
+
+	for _, c := range syscall.StringToUTF16(s) {
+		switch {
+		case c == 0:
+
+		case c == '\\' || c == '{' || c == '}':
+			buf.WriteByte('\\')
+			buf.WriteByte(byte(c))
+
+		case c > 0x7F:
+			fmt.Fprintf(&buf, `\u%d?`, int16(c))
+
+		default:
+			buf.WriteByte(byte(c))
+		}
+	}
+
+	return buf.String()
+}
+
+// AutoURLDetection returns if URLs in the text are shown as links.
+func (rte *RichTextEdit) AutoURLDetection() bool {
+	return rte.SendMessage(emGetAutoURLDet, 0, 0) != 0
+}
+
+// SetAutoURLDetection sets if URLs in the text are shown as links. Clicking
+// one publishes LinkClicked.
+func (rte *RichTextEdit) SetAutoURLDetection(enabled bool) error {
+	if 0 != rte.SendMessage(emAutoURLDetect, uintptr(boolToInt(enabled)), 0) {
+		return newAPIError("SendMessage(EM_AUTOURLDETECT)")
+	}
+
+	return nil
+}
+
+// LinkClicked returns the event that is published with the URL, when a link
+// is clicked.
+func (rte *RichTextEdit) LinkClicked() *StringEvent {
+	return rte.linkClickedPublisher.Event()
+}
+
+func (rte *RichTextEdit) textRange(start, end int) string {
+	buf := make([]uint16, end-start+1)
+
+	tr := textRange{charRange{int32(start), int32(end)}, &buf[0]}
+	rte.SendMessage(emGetTextRange, 0, uintptr(unsafe.Pointer(&tr)))
+
+	return syscall.UTF16ToString(buf)
+}
+
+// linkURL returns the URL of the link covering the text range. The text of a
+// link inserted by InsertLink is preceded by its hidden field instruction.
+func (rte *RichTextEdit) linkURL(start, end int) string {
+	text := rte.textRange(start, end)
+
+	const prefix = `HYPERLINK "`
+	if strings.HasPrefix(text, prefix) {
+		text = text[len(prefix):]
+		if i := strings.Index(text, `"`); i > -1 {
+			text = text[:i]
+		}
+	}
+
+	return text
+}
+
+// SelectionFont returns the font of the selected text, or of its start, if
+// it is mixed.
+func (rte *RichTextEdit) SelectionFont() (*Font, error) {
+	cf := charFormat2{dwMask: cfmFace | cfmSize | cfmBold | cfmItalic | cfmUnderline | cfmStrikeOut}
+	cf.cbSize = uint32(unsafe.Sizeof(cf))
+	rte.SendMessage(emGetCharFormat, scfSelection, uintptr(unsafe.Pointer(&cf)))
+
+	// The font styles have the same bits as the effects.
+	style := FontStyle(cf.dwEffects) & (FontBold | FontItalic | FontUnderline | FontStrikeOut)
+
+	return NewFont(syscall.UTF16ToString(cf.szFaceName[:]), int(cf.yHeight/twipsPerPoint), style)
+}
+
+// SetSelectionFont sets the font of the selected text or, without a
+// selection, of the text typed next.
+func (rte *RichTextEdit) SetSelectionFont(font *Font) error {
+	if font == nil {
+		return newError("font must not be nil")
+	}
+
+	cf := charFormat2{
+		dwMask:    cfmFace | cfmSize | cfmBold | cfmItalic | cfmUnderline | cfmStrikeOut,
+		dwEffects: uint32(font.Style()),
+		yHeight:   int32(font.PointSize() * twipsPerPoint),
+	}
+	copy(cf.szFaceName[:len(cf.szFaceName)-1], syscall.StringToUTF16(font.Family()))
+
+	return rte.setSelectionCharFormat(&cf)
+}
+
+// SetSelectionFontStyle sets or clears style for the selected text, leaving
+// the other styles as they are, e.g. for the Bold button of a toolbar.
+func (rte *RichTextEdit) SetSelectionFontStyle(style FontStyle, set bool) error {
+	cf := charFormat2{dwMask: uint32(style) & (cfmBold | cfmItalic | cfmUnderline | cfmStrikeOut)}
+	if set {
+		cf.dwEffects = cf.dwMask
+	}
+
+	return rte.setSelectionCharFormat(&cf)
+}
+
+// SetSelectionTextColor sets the color of the selected text.
+func (rte *RichTextEdit) SetSelectionTextColor(color Color) error {
+	return rte.setSelectionCharFormat(&charFormat2{dwMask: cfmColor, crTextColor: uint32(color)})
+}
+
+// SetSelectionBackgroundColor sets the background color of the selected
+// text, e.g. to highlight it.
+func (rte *RichTextEdit) SetSelectionBackgroundColor(color Color) error {
+	return rte.setSelectionCharFormat(&charFormat2{dwMask: cfmBackColor, crBackColor: uint32(color)})
+}
+
+func (rte *RichTextEdit) setSelectionCharFormat(cf *charFormat2) error {
+	cf.cbSize = uint32(unsafe.Sizeof(*cf))
+
+	if 0 == rte.SendMessage(emSetCharFormat, scfSelection, uintptr(unsafe.Pointer(cf))) {
+		return newAPIError("SendMessage(EM_SETCHARFORMAT)")
+	}
+
+	return nil
+}
+
+// ParagraphAlignment returns the alignment of the paragraph with the caret.
+func (rte *RichTextEdit) ParagraphAlignment() Alignment1D {
+	pf := paraFormat2{dwMask: pfmAlignment}
+	pf.cbSize = uint32(unsafe.Sizeof(pf))
+	rte.SendMessage(emGetParaFormat, 0, uintptr(unsafe.Pointer(&pf)))
+
+	switch pf.wAlignment {
+	case pfaCenter:
+		return AlignCenter
+
+	case pfaRight:
+		return AlignFar
+	}
+
+	return AlignNear
+}
+
+// SetParagraphAlignment sets the alignment of the selected paragraphs.
+func (rte *RichTextEdit) SetParagraphAlignment(alignment Alignment1D) error {
+	pf := paraFormat2{dwMask: pfmAlignment, wAlignment: pfaLeft}
+
+	switch alignment {
+	case AlignCenter:
+		pf.wAlignment = pfaCenter
+
+	case AlignFar:
+		pf.wAlignment = pfaRight
+	}
+
+	return rte.setParaFormat(&pf)
+}
+
+// SetParagraphBullets sets if the selected paragraphs are bulleted.
+func (rte *RichTextEdit) SetParagraphBullets(bullets bool) error {
+	pf := paraFormat2{dwMask: pfmNumbering}
+	if bullets {
+		pf.wNumbering = pfnBullet
+	}
+
+	return rte.setParaFormat(&pf)
+}
+
+// SetParagraphIndents sets the indents of the selected paragraphs, in points.
+// firstLine is the indent of the first line relative to start, which can be
+// negative for a hanging indent.
+func (rte *RichTextEdit) SetParagraphIndents(start, end, firstLine int) error {
+	pf := paraFormat2{
+		dwMask:        pfmStartIndent | pfmRightIndent | pfmOffset,
+		dxStartIndent: int32((start + firstLine) * twipsPerPoint),
+		dxRightIndent: int32(end * twipsPerPoint),
+		dxOffset:      int32(-firstLine * twipsPerPoint),
+	}
+
+	return rte.setParaFormat(&pf)
+}
+
+func (rte *RichTextEdit) setParaFormat(pf *paraFormat2) error {
+	pf.cbSize = uint32(unsafe.Sizeof(*pf))
+
+	if 0 == rte.SendMessage(emSetParaFormat, 0, uintptr(unsafe.Pointer(pf))) {
+		return newAPIError("SendMessage(EM_SETPARAFORMAT)")
+	}
+
+	return nil
+}
+
+func (rte *RichTextEdit) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_COMMAND:
+		switch HIWORD(uint32(wParam)) {
+		case EN_CHANGE:
+			rte.textChangedPublisher.Publish()
+		}
+
+	case WM_NOTIFY:
+		switch ((*NMHDR)(unsafe.Pointer(lParam))).Code {
+		case enLink:
+			enl := (*enLinkNotification)(unsafe.Pointer(lParam))
+
+			if enl.msg == WM_LBUTTONUP {
+				start, end := rte.TextSelection()
+
+				// Clicks that end a selection are no link clicks.
+				if start == end {
+					rte.linkClickedPublisher.Publish(rte.linkURL(int(enl.chrg.cpMin), int(enl.chrg.cpMax)))
+				}
+			}
+
+			return 0
+		}
+
+	case WM_GETDLGCODE:
+		if wParam == VK_RETURN {
+			return DLGC_WANTALLKEYS
+		}
+
+		return DLGC_HASSETSEL | DLGC_WANTARROWS | DLGC_WANTCHARS
+	}
+
+	return rte.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
@@ -0,0 +1,217 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"reflect"
+	"time"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// RunOptions customizes the message loop run by Application.Run, so walk can
+// coexist with libraries that need to be serviced on the UI thread.
+type RunOptions struct {
+	// Window is the top-level window, as long as which the message loop
+	// runs. Without one, the message loop runs until Exit is called.
+	Window RootWidget
+
+	// Dispatch, if not nil, is offered each message before walk processes
+	// it. If it returns true, the message is neither translated nor
+	// dispatched by walk.
+	Dispatch func(msg *MSG) bool
+
+	// Iteration, if not nil, is called after each message was processed,
+	// also by the message loops of modal dialogs.
+	Iteration func()
+
+	// Interval, if greater than 0, makes the message loop call Iteration at
+	// least at this interval, even without other messages.
+	Interval time.Duration
+
+	// Channels are external event sources, whose values are passed to the UI
+	// thread.
+	Channels []RunChannel
+}
+
+// RunChannel connects a channel to the message loop run by Application.Run.
+type RunChannel struct {
+	// Chan is the channel to receive from. It must be a channel that allows
+	// receiving, e.g. a chan string or a <-chan time.Time.
+	Chan interface{}
+
+	// Receive is called on the UI thread with each value received from Chan.
+	// After Chan was closed, Receive is called once more with ok false.
+	Receive func(value interface{}, ok bool)
+}
+
+// runOptions holds the RunOptions passed to Application.Run, while it runs.
+var runOptions *RunOptions
+
+// iteratedByTimer is set, when the Interval timer called Iteration while the
+// current message was dispatched, so it is not called twice.
+var iteratedByTimer bool
+
+func runIteration() {
+	if runOptions != nil && runOptions.Iteration != nil {
+		runOptions.Iteration()
+	}
+}
+
+// Run runs the message loop of the application like TopLevelWindow.Run, but
+// customized by opts, and returns the exit code.
+func (app *Application) Run(opts RunOptions) int {
+	for _, rc := range opts.Channels {
+		if v := reflect.ValueOf(rc.Chan); v.Kind() != reflect.Chan || v.Type().ChanDir()&reflect.RecvDir == 0 {
+			panic("RunChannel.Chan must be a channel that allows receiving")
+		}
+	}
+
+	runOptions = &opts
+	defer func() {
+		runOptions = nil
+	}()
+
+	if opts.Window == nil {
+		// The goroutine receiving from the channels needs the UI thread to
+		// synchronize with.
+		uiThreadId = GetCurrentThreadId()
+		initUIThread()
+	}
+
+	if opts.Interval > 0 {
+		// Timer messages are also dispatched by the modal loops of menus and
+		// message boxes, which don't call Iteration otherwise.
+		defer stopTimer(startTimer(opts.Interval, func() {
+			runIteration()
+			iteratedByTimer = true
+		}))
+	}
+
+	if len(opts.Channels) > 0 {
+		defer receiveRunChannels(opts.Channels)()
+	}
+
+	if opts.Window != nil {
+		return opts.Window.Run()
+	}
+
+	endStartupTrace()
+
+	publishRestarted()
+
+	publishActivation()
+
+	defer app.saveSettingsOnExit()
+
+	defer recoverCrash()
+
+	return runMessageLoop(nil)
+}
+
+// receiveRunChannels starts receiving from the channels in another goroutine
+// and returns a func, that stops it.
+func receiveRunChannels(channels []RunChannel) (stop func()) {
+	quit := make(chan struct{})
+
+	cases := []reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(quit)}}
+	receivers := []func(value interface{}, ok bool){nil}
+
+	for _, rc := range channels {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(rc.Chan)})
+		receivers = append(receivers, rc.Receive)
+	}
+
+	go func() {
+		for len(cases) > 1 {
+			chosen, v, ok := reflect.Select(cases)
+			if chosen == 0 {
+				return
+			}
+
+			receive := receivers[chosen]
+
+			var value interface{}
+			if ok {
+				value = v.Interface()
+			} else {
+				cases = append(cases[:chosen], cases[chosen+1:]...)
+				receivers = append(receivers[:chosen], receivers[chosen+1:]...)
+			}
+
+			postSynchronized(func() {
+				receive(value, ok)
+			})
+		}
+	}()
+
+	return func() {
+		close(quit)
+	}
+}
+
+// messageLoopWindows holds the windows, as long as which the running message
+// loops run, the outermost first. It is nil for a windowless message loop.
+var messageLoopWindows []*TopLevelWindow
+
+// runMessageLoop runs the message loop, until tlw is disposed of or, if tlw is
+// nil, until WM_QUIT is received.
+func runMessageLoop(tlw *TopLevelWindow) int {
+	messageLoopWindows = append(messageLoopWindows, tlw)
+	defer func() {
+		messageLoopWindows = messageLoopWindows[:len(messageLoopWindows)-1]
+	}()
+
+	var msg MSG
+
+	for tlw == nil || tlw.hWnd != 0 {
+		switch GetMessage(&msg, 0, 0, 0) {
+		case 0:
+			appSingleton.cancelContext()
+			return int(msg.WParam)
+
+		case -1:
+			return -1
+		}
+
+		handled := runOptions != nil && runOptions.Dispatch != nil && runOptions.Dispatch(&msg)
+
+		if !handled && !filterMessage(&msg) && !handleGlobalHotkey(&msg) && !handleNavigationGroupKey(&msg) && !handleFocusScopeTab(&msg) && !isDialogMessage(tlw, &msg) {
+			TranslateMessage(&msg)
+			DispatchMessage(&msg)
+		}
+
+		runSynchronized()
+
+		runIdle()
+
+		if iteratedByTimer {
+			iteratedByTimer = false
+		} else {
+			runIteration()
+		}
+	}
+
+	// tlw may be disposed of before WM_QUIT is received, e.g. by Shutdown,
+	// so the exit code passed to Exit would be lost otherwise.
+	return appSingleton.exitCode
+}
+
+// isDialogMessage processes dialog navigation keys for tlw or, if tlw is nil,
+// for the top-level window the message is meant for.
+func isDialogMessage(tlw *TopLevelWindow, msg *MSG) bool {
+	hwnd := msg.HWnd
+	if tlw != nil {
+		hwnd = tlw.hWnd
+	} else if hwnd != 0 {
+		hwnd = GetAncestor(hwnd, GA_ROOT)
+	}
+
+	if hwnd == 0 {
+		return false
+	}
+
+	return IsDialogMessage(hwnd, msg)
+}
@@ -0,0 +1,294 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	emSetMargins  = 0x00D3
+	ecLeftMargin  = 0x0001
+	ecRightMargin = 0x0002
+
+	searchBoxIconSize     = 16
+	searchBoxPadding      = 4
+	searchBoxSearchTimer  = 1
+	searchBoxDefaultDelay = 300
+)
+
+// SearchBox is a single-line edit for search terms, with a magnifier in front
+// and, as soon as there is text, a button to clear it.
+//
+// Instead of reacting to TextChanged, which is published on every keystroke,
+// use SearchTextChanged, which is published once the user paused typing for
+// the search delay, when Return is pressed and when the text is cleared.
+// Pressing Escape clears the text.
+type SearchBox struct {
+	LineEdit
+	searchDelay                int
+	searchTextChangedPublisher StringEventPublisher
+}
+
+// NewSearchBox returns a new, empty *SearchBox.
+func NewSearchBox(parent Container) (*SearchBox, error) {
+	sb := &SearchBox{searchDelay: searchBoxDefaultDelay}
+
+	if err := InitChildWidget(
+		sb,
+		parent,
+		"EDIT",
+		WS_TABSTOP|WS_VISIBLE|ES_AUTOHSCROLL,
+		WS_EX_CLIENTEDGE); err != nil {
+		return nil, err
+	}
+
+	var succeeded bool
+	defer func() {
+		if !succeeded {
+			sb.Dispose()
+		}
+	}()
+
+	sb.MustRegisterProperty("ReadOnly", NewProperty(
+		func() interface{} {
+			return sb.ReadOnly()
+		},
+		func(v interface{}) error {
+			return sb.SetReadOnly(v.(bool))
+		},
+		sb.readOnlyChangedPublisher.Event()))
+
+	sb.MustRegisterProperty("Text", NewProperty(
+		func() interface{} {
+			return sb.Text()
+		},
+		func(v interface{}) error {
+			return sb.SetText(v.(string))
+		},
+		sb.textChangedPublisher.Event()))
+
+	if err := sb.SetCueBanner(tr("Search…", "walk")); err != nil {
+		return nil, err
+	}
+
+	sb.updateMargins()
+
+	succeeded = true
+
+	return sb, nil
+}
+
+// SearchDelay returns the time in milliseconds, that the user has to pause
+// typing, before SearchTextChanged is published.
+func (sb *SearchBox) SearchDelay() int {
+	return sb.searchDelay
+}
+
+// SetSearchDelay sets the time in milliseconds, that the user has to pause
+// typing, before SearchTextChanged is published. The default is 300.
+func (sb *SearchBox) SetSearchDelay(value int) error {
+	if value < 0 {
+		return newError("value must not be negative")
+	}
+
+	sb.searchDelay = value
+
+	return nil
+}
+
+// Clear removes the text and publishes SearchTextChanged right away.
+func (sb *SearchBox) Clear() error {
+	if err := sb.SetText(""); err != nil {
+		return err
+	}
+
+	sb.publishSearchTextChanged()
+
+	return nil
+}
+
+// SearchTextChanged returns the event that is published with the text of the
+// *SearchBox, after the user changed it.
+func (sb *SearchBox) SearchTextChanged() *StringEvent {
+	return sb.searchTextChangedPublisher.Event()
+}
+
+func (sb *SearchBox) publishSearchTextChanged() {
+	KillTimer(sb.hWnd, searchBoxSearchTimer)
+
+	sb.searchTextChangedPublisher.Publish(sb.Text())
+}
+
+// updateMargins makes room for the magnifier and the clear button.
+func (sb *SearchBox) updateMargins() {
+	margin := sb.IntFromDIP(searchBoxIconSize + 2*searchBoxPadding)
+
+	sb.SendMessage(emSetMargins, ecLeftMargin|ecRightMargin, uintptr(MAKELONG(uint16(margin), uint16(margin))))
+}
+
+func (sb *SearchBox) iconBounds() Rectangle {
+	cb := sb.ClientBounds()
+	size := sb.IntFromDIP(searchBoxIconSize)
+
+	return Rectangle{sb.IntFromDIP(searchBoxPadding), (cb.Height - size) / 2, size, size}
+}
+
+func (sb *SearchBox) clearButtonBounds() Rectangle {
+	cb := sb.ClientBounds()
+	size := sb.IntFromDIP(searchBoxIconSize)
+
+	return Rectangle{cb.Width - size - sb.IntFromDIP(searchBoxPadding), (cb.Height - size) / 2, size, size}
+}
+
+func (sb *SearchBox) clearButtonVisible() bool {
+	return !sb.ReadOnly() && sb.Text() != ""
+}
+
+func (sb *SearchBox) clearButtonAt(x, y int) bool {
+	if !sb.clearButtonVisible() {
+		return false
+	}
+
+	r := sb.clearButtonBounds()
+
+	return x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height
+}
+
+// paintDecorations draws the magnifier and the clear button over the margins
+// of the edit control.
+func (sb *SearchBox) paintDecorations() error {
+	canvas, err := sb.CreateCanvas()
+	if err != nil {
+		return err
+	}
+	defer canvas.Dispose()
+
+	palette := appSingleton.Palette()
+
+	color := palette.DisabledText
+	if sb.Enabled() && sb.Text() != "" {
+		color = palette.Text
+	}
+
+	brush, err := NewSolidColorBrush(color)
+	if err != nil {
+		return err
+	}
+	defer brush.Dispose()
+
+	pen, err := NewGeometricPen(PenSolid|PenCapRound, sb.IntFromDIP(2), brush)
+	if err != nil {
+		return err
+	}
+	defer pen.Dispose()
+
+	// The magnifier is a circle with a handle to the lower right.
+	r := sb.iconBounds()
+	d := r.Width * 5 / 8
+
+	if err := canvas.DrawEllipse(pen, Rectangle{r.X + 1, r.Y + 1, d, d}); err != nil {
+		return err
+	}
+	if err := canvas.DrawLine(pen, Point{r.X + d, r.Y + d}, Point{r.X + r.Width - 2, r.Y + r.Height - 2}); err != nil {
+		return err
+	}
+
+	if !sb.clearButtonVisible() {
+		return nil
+	}
+
+	r = sb.clearButtonBounds()
+	inset := r.Width / 4
+
+	if err := canvas.DrawLine(pen, Point{r.X + inset, r.Y + inset}, Point{r.X + r.Width - inset, r.Y + r.Height - inset}); err != nil {
+		return err
+	}
+
+	return canvas.DrawLine(pen, Point{r.X + r.Width - inset, r.Y + inset}, Point{r.X + inset, r.Y + r.Height - inset})
+}
+
+func (sb *SearchBox) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_PAINT:
+		result := sb.LineEdit.WndProc(hwnd, msg, wParam, lParam)
+
+		sb.paintDecorations()
+
+		return result
+
+	case WM_COMMAND:
+		if HIWORD(uint32(wParam)) == EN_CHANGE {
+			// The clear button appears or disappears.
+			sb.Invalidate()
+
+			if sb.searchDelay == 0 {
+				sb.publishSearchTextChanged()
+			} else if 0 == SetTimer(hwnd, searchBoxSearchTimer, uint32(sb.searchDelay), 0) {
+				lastError("SetTimer")
+				sb.publishSearchTextChanged()
+			}
+		}
+
+	case WM_TIMER:
+		if wParam == searchBoxSearchTimer {
+			sb.publishSearchTextChanged()
+			return 0
+		}
+
+	case WM_GETDLGCODE:
+		if wParam == VK_ESCAPE && sb.Text() != "" && !sb.ReadOnly() {
+			// Escape clears the text, instead of closing the dialog.
+			return DLGC_WANTALLKEYS
+		}
+
+	case WM_KEYDOWN:
+		switch wParam {
+		case VK_ESCAPE:
+			if sb.Text() != "" && !sb.ReadOnly() {
+				sb.Clear()
+				return 0
+			}
+
+		case VK_RETURN:
+			sb.publishSearchTextChanged()
+		}
+
+	case WM_CHAR:
+		if wParam == VK_ESCAPE {
+			// Avoid the beep of the edit control.
+			return 0
+		}
+
+	case WM_LBUTTONDOWN:
+		if sb.clearButtonAt(int(GET_X_LPARAM(lParam)), int(GET_Y_LPARAM(lParam))) {
+			sb.Clear()
+			sb.SetFocus()
+			return 0
+		}
+
+	case WM_SETCURSOR:
+		var pt POINT
+		if !GetCursorPos(&pt) {
+			break
+		}
+		ScreenToClient(hwnd, &pt)
+
+		if sb.clearButtonAt(int(pt.X), int(pt.Y)) {
+			SetCursor(CursorArrow().handle())
+			return 1
+		}
+
+	case WM_SIZE, WM_SETFONT:
+		result := sb.LineEdit.WndProc(hwnd, msg, wParam, lParam)
+
+		sb.updateMargins()
+
+		return result
+
+	case WM_DESTROY:
+		KillTimer(hwnd, searchBoxSearchTimer)
+	}
+
+	return sb.LineEdit.WndProc(hwnd, msg, wParam, lParam)
+}
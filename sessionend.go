@@ -0,0 +1,105 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// SessionEndReason is a combination of flags describing why the user session
+// ends.
+type SessionEndReason uint32
+
+const (
+	// SessionEndCloseApp means the application is asked to close, e.g. by an
+	// installer or Windows Update, which will restart it, if it registered
+	// for that.
+	SessionEndCloseApp SessionEndReason = 0x00000001
+
+	// SessionEndCritical means the application is forced to close.
+	SessionEndCritical SessionEndReason = 0x40000000
+
+	// SessionEndLogoff means the user is logging off.
+	SessionEndLogoff SessionEndReason = 0x80000000
+)
+
+var (
+	procShutdownBlockReasonCreate  = libuser32.NewProc("ShutdownBlockReasonCreate")
+	procShutdownBlockReasonDestroy = libuser32.NewProc("ShutdownBlockReasonDestroy")
+)
+
+// sessionEndQuery holds the outcome of the current WM_QUERYENDSESSION
+// broadcast, which each top-level window receives.
+var sessionEndQuery *struct {
+	canceled bool
+}
+
+// SessionEnding returns the event that is published, when the user logs off
+// or the machine shuts down.
+//
+// Handlers may set canceled to true to ask for the session end to be
+// postponed, e.g. because there are unsaved documents. Windows shows the
+// reason set by TopLevelWindow.SetShutdownBlockReason to the user, who
+// decides whether to end the session anyway. With SessionEndCritical in
+// reason, the session ends regardless.
+func (app *Application) SessionEnding() *SessionEndEvent {
+	return app.sessionEndingPublisher.Event()
+}
+
+// SessionEnded returns the event that is published, when the session ends
+// indeed. The process may be terminated any time after handlers returned.
+func (app *Application) SessionEnded() *Event {
+	return app.sessionEndedPublisher.Event()
+}
+
+// SetShutdownBlockReason sets the reason, why the *TopLevelWindow blocks
+// shutting down, that is shown to the user, e.g. "Unsaved documents". An
+// empty reason removes it.
+func (tlw *TopLevelWindow) SetShutdownBlockReason(reason string) error {
+	if err := procShutdownBlockReasonCreate.Find(); err != nil {
+		// Before Windows Vista.
+		return nil
+	}
+
+	if reason == "" {
+		procShutdownBlockReasonDestroy.Call(uintptr(tlw.hWnd))
+		return nil
+	}
+
+	if ret, _, err := procShutdownBlockReasonCreate.Call(
+		uintptr(tlw.hWnd),
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(reason)))); ret == 0 {
+
+		return callError("ShutdownBlockReasonCreate", err)
+	}
+
+	return nil
+}
+
+// handleQueryEndSession handles WM_QUERYENDSESSION and returns if the session
+// may end.
+func handleQueryEndSession(lParam uintptr) bool {
+	if sessionEndQuery == nil {
+		sessionEndQuery = new(struct{ canceled bool })
+
+		appSingleton.sessionEndingPublisher.Publish(&sessionEndQuery.canceled, SessionEndReason(lParam))
+	}
+
+	return !sessionEndQuery.canceled
+}
+
+// handleEndSession handles WM_ENDSESSION.
+func handleEndSession(wParam uintptr) {
+	if sessionEndQuery == nil {
+		return
+	}
+
+	sessionEndQuery = nil
+
+	if wParam != 0 {
+		appSingleton.sessionEndedPublisher.Publish()
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+type SessionEndEventHandler func(canceled *bool, reason SessionEndReason)
+
+type SessionEndEvent struct {
+	handlers []SessionEndEventHandler
+}
+
+func (e *SessionEndEvent) Attach(handler SessionEndEventHandler) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	return len(e.handlers) - 1
+}
+
+func (e *SessionEndEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type SessionEndEventPublisher struct {
+	event SessionEndEvent
+}
+
+func (p *SessionEndEventPublisher) Event() *SessionEndEvent {
+	return &p.event
+}
+
+func (p *SessionEndEventPublisher) Publish(canceled *bool, reason SessionEndReason) {
+	for _, handler := range p.event.handlers {
+		if handler != nil {
+			handler(canceled, reason)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+// SettingsChangeNotifier is implemented by Settings that publish the keys of
+// values that changed, by Put or by Load.
+//
+// If the Settings of the application implement SettingsChangeNotifier,
+// persistent widgets restore their state immediately, when their value
+// changes.
+type SettingsChangeNotifier interface {
+	Changed() *StringEvent
+}
+
+// puttingState is true while a widget stores its state, so it isn't restored
+// from the value it just stored.
+var puttingState bool
+
+// attachSettingsChanged attaches the rebinding of persistent widgets to the
+// Changed event of the Settings of the application, if possible.
+func (app *Application) attachSettingsChanged() {
+	if notifier, ok := app.settings.(SettingsChangeNotifier); ok {
+		app.settingsChangedHandle = notifier.Changed().Attach(restoreWidgetStateForKey)
+	}
+}
+
+func (app *Application) detachSettingsChanged() {
+	if notifier, ok := app.settings.(SettingsChangeNotifier); ok {
+		notifier.Changed().Detach(app.settingsChangedHandle)
+	}
+}
+
+// restoreWidgetStateForKey restores the state of the persistent widget, whose
+// state is stored at key.
+func restoreWidgetStateForKey(key string) {
+	if puttingState || layoutStates != nil {
+		return
+	}
+
+	for tlw := range topLevelWindows {
+		if widget := widgetForStateKey(tlw.widget, key); widget != nil {
+			if p, ok := widget.(Persistable); ok && p.Persistent() {
+				p.RestoreState()
+			}
+
+			return
+		}
+	}
+}
+
+// widgetForStateKey returns widget or one of its descendants, if its state is
+// stored at key.
+func widgetForStateKey(widget Widget, key string) Widget {
+	if widget.BaseWidget().path() == key {
+		return widget
+	}
+
+	switch w := widget.(type) {
+	case *TabWidget:
+		pages := w.Pages()
+		for i := 0; i < pages.Len(); i++ {
+			if found := widgetForStateKey(pages.At(i), key); found != nil {
+				return found
+			}
+		}
+
+	case Container:
+		children := w.Children()
+		for i := 0; i < children.Len(); i++ {
+			if found := widgetForStateKey(children.At(i), key); found != nil {
+				return found
+			}
+		}
+	}
+
+	return nil
+}
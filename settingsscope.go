@@ -0,0 +1,147 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SettingsScope is a view of Settings, that prefixes all keys with a scope,
+// e.g. "MainWindow/LeftSplitter", so values of different windows or
+// components can use the same keys without colliding.
+//
+// Persistent widgets store their state the same way: The key consists of the
+// settings scope of the nearest container that has one and the names of the
+// widgets below it. Top-level windows always have a scope, which is their name
+// or, without one, the name of their type, e.g. "MainWindow", unless
+// Application.SetUnscopedWindows is used.
+type SettingsScope struct {
+	settings Settings
+	scope    string
+}
+
+// NewSettingsScope returns a new *SettingsScope for scope in settings. If
+// settings is a *SettingsScope itself, the scopes are nested.
+func NewSettingsScope(settings Settings, scope string) *SettingsScope {
+	scope = strings.Trim(scope, "/")
+
+	if parent, ok := settings.(*SettingsScope); ok {
+		return &SettingsScope{parent.settings, parent.Key(scope)}
+	}
+
+	return &SettingsScope{settings, scope}
+}
+
+// Prefix returns the scope, which all keys are prefixed with.
+func (ss *SettingsScope) Prefix() string {
+	return ss.scope
+}
+
+// Settings returns the unscoped Settings.
+func (ss *SettingsScope) Settings() Settings {
+	return ss.settings
+}
+
+// Key returns the key, under which the value for key is stored in the
+// unscoped Settings.
+func (ss *SettingsScope) Key(key string) string {
+	if ss.scope == "" {
+		return key
+	}
+	if key == "" {
+		return ss.scope
+	}
+
+	return ss.scope + "/" + key
+}
+
+// Scope returns a *SettingsScope for scope nested in the *SettingsScope.
+func (ss *SettingsScope) Scope(scope string) *SettingsScope {
+	return NewSettingsScope(ss, scope)
+}
+
+func (ss *SettingsScope) Get(key string) (string, bool) {
+	return ss.settings.Get(ss.Key(key))
+}
+
+func (ss *SettingsScope) Put(key, value string) error {
+	return ss.settings.Put(ss.Key(key), value)
+}
+
+func (ss *SettingsScope) Load() error {
+	return ss.settings.Load()
+}
+
+func (ss *SettingsScope) Save() error {
+	return ss.settings.Save()
+}
+
+// Scope returns a *SettingsScope for scope in the *IniFileSettings.
+func (ifs *IniFileSettings) Scope(scope string) *SettingsScope {
+	return NewSettingsScope(ifs, scope)
+}
+
+// Scope returns a *SettingsScope for scope in the *JSONSettings.
+func (js *JSONSettings) Scope(scope string) *SettingsScope {
+	return NewSettingsScope(js, scope)
+}
+
+// Scope returns a *SettingsScope for scope in the *EncryptedSettings.
+func (es *EncryptedSettings) Scope(scope string) *SettingsScope {
+	return NewSettingsScope(es, scope)
+}
+
+// SettingsScope returns the scope of the keys, under which the state of the
+// persistent descendants of the *ContainerBase is stored, if it starts one.
+func (cb *ContainerBase) SettingsScope() string {
+	return cb.settingsScope
+}
+
+// SetSettingsScope makes the *ContainerBase start a settings scope, so the
+// keys of its persistent descendants no longer depend on its ancestors, e.g.
+// for a composite that is reused in several windows.
+//
+// The keys are relative to the root of the Settings, so nested scopes are
+// passed like "MainWindow/LeftSplitter". An empty value ends the scope.
+func (cb *ContainerBase) SetSettingsScope(value string) {
+	cb.settingsScope = strings.Trim(value, "/")
+}
+
+// StateSettings returns the App().Settings() scoped like the state of the
+// persistent descendants of the *ContainerBase, so an application can store
+// own values next to them, or nil without Settings.
+func (cb *ContainerBase) StateSettings() *SettingsScope {
+	settings := appSingleton.settings
+	if settings == nil {
+		return nil
+	}
+
+	return NewSettingsScope(settings, cb.path())
+}
+
+// defaultSettingsScope returns the settings scope of an unnamed top-level
+// window, which is the name of its type.
+func defaultSettingsScope(widget Widget) string {
+	t := reflect.TypeOf(widget)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
+
+// UnscopedWindows returns if the state of unnamed top-level windows is stored
+// without a settings scope.
+func (app *Application) UnscopedWindows() bool {
+	return app.unscopedWindows
+}
+
+// SetUnscopedWindows sets if the state of unnamed top-level windows is stored
+// without a settings scope, like before they were scoped by the name of their
+// type, so applications keep reading the keys they already stored.
+func (app *Application) SetUnscopedWindows(value bool) {
+	app.unscopedWindows = value
+}
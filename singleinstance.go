@@ -0,0 +1,186 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const singleInstanceWindowClass = `\o/ Walk_SingleInstance_Class \o/`
+
+// singleInstanceMagic identifies WM_COPYDATA messages carrying forwarded
+// command line arguments.
+const singleInstanceMagic = 0x57414c4b
+
+const errorAlreadyExists = 183
+
+var (
+	libkernel32                  = syscall.NewLazyDLL("kernel32.dll")
+	procCreateMutex              = libkernel32.NewProc("CreateMutexW")
+	procAllowSetForegroundWindow = libuser32.NewProc("AllowSetForegroundWindow")
+)
+
+type copyDataStruct struct {
+	dwData uintptr
+	cbData uint32
+	lpData uintptr
+}
+
+var singleInstanceHWnd HWND
+
+func init() {
+	MustRegisterWindowClass(singleInstanceWindowClass)
+}
+
+// SetSingleInstance makes sure that only one instance of the application,
+// identified by id, runs per user session.
+//
+// If another instance is running already, SetSingleInstance forwards the
+// command line arguments, without the program name, to it and exits the
+// process. The running instance publishes them with its ActivatedWithArgs
+// event, where it will typically open documents and bring its main window to
+// the foreground. Arguments for a document or URI are published with the
+// Activated event as well.
+//
+// SetSingleInstance should be called early in main, before creating any
+// windows.
+func (app *Application) SetSingleInstance(id string) error {
+	if app.singleInstanceMutex != 0 {
+		return newError("single instance already set")
+	}
+
+	name := syscall.StringToUTF16Ptr(`Local\Walk_SingleInstance_` + id)
+
+	mutex, _, err := procCreateMutex.Call(0, 0, uintptr(unsafe.Pointer(name)))
+	if mutex == 0 {
+		return callError("CreateMutex", err)
+	}
+
+	if errno, ok := err.(syscall.Errno); ok && errno == errorAlreadyExists {
+		CloseHandle(HANDLE(mutex))
+
+		if err := forwardArgs(id, absolutePathArgs(os.Args[1:])); err != nil {
+			return err
+		}
+
+		os.Exit(0)
+	}
+
+	hWnd := CreateWindowEx(
+		0,
+		syscall.StringToUTF16Ptr(singleInstanceWindowClass),
+		syscall.StringToUTF16Ptr(id),
+		0,
+		0,
+		0,
+		0,
+		0,
+		HWND_MESSAGE,
+		0,
+		0,
+		nil)
+	if hWnd == 0 {
+		CloseHandle(HANDLE(mutex))
+		return lastError("CreateWindowEx")
+	}
+
+	app.singleInstanceMutex = HANDLE(mutex)
+	singleInstanceHWnd = hWnd
+
+	return nil
+}
+
+// releaseSingleInstance makes way for another instance, e.g. when restarting.
+func (app *Application) releaseSingleInstance() {
+	if app.singleInstanceMutex == 0 {
+		return
+	}
+
+	DestroyWindow(singleInstanceHWnd)
+	singleInstanceHWnd = 0
+
+	CloseHandle(app.singleInstanceMutex)
+	app.singleInstanceMutex = 0
+}
+
+// ActivatedWithArgs returns the event that is published, when another instance
+// of the application forwarded its command line arguments.
+//
+// See SetSingleInstance.
+func (app *Application) ActivatedWithArgs() *ArgsEvent {
+	return app.activatedWithArgsPublisher.Event()
+}
+
+// forwardArgs sends args to the message window of the running instance
+// identified by id.
+func forwardArgs(id string, args []string) error {
+	className := syscall.StringToUTF16Ptr(singleInstanceWindowClass)
+	windowName := syscall.StringToUTF16Ptr(id)
+
+	// The running instance may have just created its mutex, but not its
+	// message window yet.
+	var hWnd HWND
+	for i := 0; i < 50; i++ {
+		if hWnd = FindWindowEx(HWND_MESSAGE, 0, className, windowName); hWnd != 0 {
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+	if hWnd == 0 {
+		return newError("running instance not found")
+	}
+
+	// Let the running instance bring its windows to the foreground.
+	var processId uint32
+	GetWindowThreadProcessId(hWnd, &processId)
+	procAllowSetForegroundWindow.Call(uintptr(processId))
+
+	// The arguments are separated by NUL characters, which can't be part of
+	// them, so we can't use syscall.StringToUTF16 here.
+	data := append(utf16.Encode([]rune(strings.Join(args, "\x00"))), 0)
+
+	cds := copyDataStruct{
+		dwData: singleInstanceMagic,
+		cbData: uint32(len(data) * 2),
+		lpData: uintptr(unsafe.Pointer(&data[0])),
+	}
+
+	if SendMessage(hWnd, WM_COPYDATA, 0, uintptr(unsafe.Pointer(&cds))) == 0 {
+		return newError("forwarding arguments failed")
+	}
+
+	return nil
+}
+
+func singleInstanceWndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	cds := (*copyDataStruct)(unsafe.Pointer(lParam))
+	if cds.dwData != singleInstanceMagic {
+		return DefWindowProc(hwnd, msg, wParam, lParam)
+	}
+
+	var args []string
+	if n := cds.cbData / 2; n > 1 {
+		data := (*[1 << 29]uint16)(unsafe.Pointer(cds.lpData))[:n-1]
+
+		args = strings.Split(string(utf16.Decode(data)), "\x00")
+	}
+
+	// Don't block the sending instance while handlers run.
+	postSynchronized(func() {
+		appSingleton.activatedWithArgsPublisher.Publish(args)
+
+		publishForwardedActivation(args)
+	})
+
+	return 1
+}
@@ -0,0 +1,257 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	tbsAutoTicks = 0x0001
+	tbsVert      = 0x0002
+	tbsNoTicks   = 0x0010
+	tbsToolTips  = 0x0100
+
+	tbmGetPos      = WM_USER
+	tbmGetRangeMin = WM_USER + 1
+	tbmGetRangeMax = WM_USER + 2
+	tbmSetPos      = WM_USER + 5
+	tbmSetRangeMin = WM_USER + 7
+	tbmSetRangeMax = WM_USER + 8
+	tbmSetTicFreq  = WM_USER + 20
+	tbmSetPageSize = WM_USER + 21
+	tbmGetPageSize = WM_USER + 22
+	tbmSetLineSize = WM_USER + 23
+	tbmGetLineSize = WM_USER + 24
+	tbmSetToolTips = WM_USER + 29
+	tbmGetToolTips = WM_USER + 30
+	tbmSetTipSide  = WM_USER + 31
+	tbtsTop        = 0
+	tbtsLeft       = 1
+)
+
+// Slider wraps the trackbar control, which selects a value from a range by
+// dragging a thumb, e.g. for a volume or a zoom factor.
+//
+// While the thumb is dragged, a tool tip shows the current value, unless
+// SetToolTipsShown(false) was called.
+type Slider struct {
+	WidgetBase
+	hWndToolTips          HWND
+	tickFrequency         int
+	oldValue              int
+	valueChangedPublisher EventPublisher
+}
+
+// NewSlider returns a new horizontal *Slider.
+func NewSlider(parent Container) (*Slider, error) {
+	return NewSliderWithOrientation(parent, Horizontal)
+}
+
+// NewSliderWithOrientation returns a new *Slider with the specified
+// orientation.
+func NewSliderWithOrientation(parent Container, orientation Orientation) (*Slider, error) {
+	s := &Slider{tickFrequency: 1}
+
+	var style uint32 = WS_TABSTOP | WS_VISIBLE | tbsAutoTicks | tbsToolTips
+	if orientation == Vertical {
+		style |= tbsVert
+	}
+
+	if err := InitChildWidget(
+		s,
+		parent,
+		"msctls_trackbar32",
+		style,
+		0); err != nil {
+		return nil, err
+	}
+
+	s.hWndToolTips = HWND(s.SendMessage(tbmGetToolTips, 0, 0))
+	if orientation == Vertical {
+		s.SendMessage(tbmSetTipSide, tbtsLeft, 0)
+	} else {
+		s.SendMessage(tbmSetTipSide, tbtsTop, 0)
+	}
+
+	s.SetRange(0, 100)
+
+	s.MustRegisterProperty("Value", NewProperty(
+		func() interface{} {
+			return s.Value()
+		},
+		func(v interface{}) error {
+			switch v := v.(type) {
+			case int:
+				s.SetValue(v)
+
+			case float64:
+				s.SetValue(int(v))
+
+			default:
+				return newError("invalid value type")
+			}
+
+			return nil
+		},
+		s.valueChangedPublisher.Event()))
+
+	return s, nil
+}
+
+func (s *Slider) LayoutFlags() LayoutFlags {
+	if s.Orientation() == Vertical {
+		return ShrinkableVert | GrowableVert | GreedyVert
+	}
+
+	return ShrinkableHorz | GrowableHorz | GreedyHorz
+}
+
+func (s *Slider) MinSizeHint() Size {
+	return s.orientedSize(s.dialogBaseUnitsToPixels(Size{20, 20}))
+}
+
+func (s *Slider) SizeHint() Size {
+	return s.orientedSize(s.dialogBaseUnitsToPixels(Size{100, 20}))
+}
+
+func (s *Slider) orientedSize(size Size) Size {
+	if s.Orientation() == Vertical {
+		return Size{size.Height, size.Width}
+	}
+
+	return size
+}
+
+// Orientation returns the orientation of the *Slider.
+func (s *Slider) Orientation() Orientation {
+	if s.hasStyleBits(tbsVert) {
+		return Vertical
+	}
+
+	return Horizontal
+}
+
+func (s *Slider) MinValue() int {
+	return int(s.SendMessage(tbmGetRangeMin, 0, 0))
+}
+
+func (s *Slider) MaxValue() int {
+	return int(s.SendMessage(tbmGetRangeMax, 0, 0))
+}
+
+// SetRange sets the range of the value. A value outside of it is clamped.
+func (s *Slider) SetRange(min, max int) error {
+	if min > max {
+		return newError("invalid range")
+	}
+
+	s.SendMessage(tbmSetRangeMin, 0, uintptr(min))
+	s.SendMessage(tbmSetRangeMax, 1, uintptr(max))
+
+	s.publishValueChangedIfChanged()
+
+	return nil
+}
+
+func (s *Slider) Value() int {
+	return int(s.SendMessage(tbmGetPos, 0, 0))
+}
+
+func (s *Slider) SetValue(value int) {
+	s.SendMessage(tbmSetPos, 1, uintptr(value))
+
+	s.publishValueChangedIfChanged()
+}
+
+// ValueChanged returns the event that is published, when the value changed,
+// also while the thumb is dragged.
+func (s *Slider) ValueChanged() *Event {
+	return s.valueChangedPublisher.Event()
+}
+
+func (s *Slider) publishValueChangedIfChanged() {
+	if value := s.Value(); value != s.oldValue {
+		s.oldValue = value
+
+		s.valueChangedPublisher.Publish()
+	}
+}
+
+// PageSize returns the amount, by which Page Up and Page Down or clicking the
+// track change the value.
+func (s *Slider) PageSize() int {
+	return int(s.SendMessage(tbmGetPageSize, 0, 0))
+}
+
+func (s *Slider) SetPageSize(value int) {
+	s.SendMessage(tbmSetPageSize, 0, uintptr(value))
+}
+
+// LineSize returns the amount, by which the arrow keys change the value.
+func (s *Slider) LineSize() int {
+	return int(s.SendMessage(tbmGetLineSize, 0, 0))
+}
+
+func (s *Slider) SetLineSize(value int) {
+	s.SendMessage(tbmSetLineSize, 0, uintptr(value))
+}
+
+// TicksVisible returns if tick marks are shown.
+func (s *Slider) TicksVisible() bool {
+	return !s.hasStyleBits(tbsNoTicks)
+}
+
+func (s *Slider) SetTicksVisible(visible bool) error {
+	return s.ensureStyleBits(tbsNoTicks, !visible)
+}
+
+// TickFrequency returns the distance of the tick marks, in values.
+func (s *Slider) TickFrequency() int {
+	return s.tickFrequency
+}
+
+func (s *Slider) SetTickFrequency(value int) error {
+	if value < 1 {
+		return newError("invalid value")
+	}
+
+	s.tickFrequency = value
+
+	s.SendMessage(tbmSetTicFreq, uintptr(value), 0)
+
+	return nil
+}
+
+// ToolTipsShown returns if a tool tip shows the value, while the thumb is
+// dragged.
+func (s *Slider) ToolTipsShown() bool {
+	return HWND(s.SendMessage(tbmGetToolTips, 0, 0)) != 0
+}
+
+func (s *Slider) SetToolTipsShown(shown bool) {
+	var hwnd HWND
+	if shown {
+		hwnd = s.hWndToolTips
+	}
+
+	s.SendMessage(tbmSetToolTips, uintptr(hwnd), 0)
+}
+
+func (s *Slider) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_HSCROLL, WM_VSCROLL:
+		// Sent to the parent, which passes it on to us.
+		s.publishValueChangedIfChanged()
+		return 0
+
+	case WM_DESTROY:
+		if s.hWndToolTips != 0 && !s.ToolTipsShown() {
+			// The trackbar only destroys the tool tip control it uses.
+			DestroyWindow(s.hWndToolTips)
+			s.hWndToolTips = 0
+		}
+	}
+
+	return s.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
@@ -0,0 +1,66 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// snappedBounds returns the bounds of the window hwnd in screen coordinates,
+// if it is snapped, e.g. to a half of the screen or as part of a snap group.
+//
+// Windows doesn't report snapping, but the normal position of wp stays at the
+// bounds the window had before it was snapped, so a window with normal show
+// state that is somewhere else is considered snapped.
+func snappedBounds(hwnd HWND, wp *WINDOWPLACEMENT) (RECT, bool) {
+	var r RECT
+
+	if wp.ShowCmd != SW_SHOWNORMAL {
+		return r, false
+	}
+
+	if !GetWindowRect(hwnd, &r) {
+		lastError("GetWindowRect")
+		return r, false
+	}
+
+	var mi MONITORINFO
+	mi.CbSize = uint32(unsafe.Sizeof(mi))
+
+	if !GetMonitorInfo(MonitorFromWindow(hwnd, MONITOR_DEFAULTTONEAREST), &mi) {
+		newAPIError("GetMonitorInfo")
+		return r, false
+	}
+
+	// The normal position is in workspace coordinates, which are relative to
+	// the work area.
+	normal := wp.RcNormalPosition
+	dx := mi.RcWork.Left - mi.RcMonitor.Left
+	dy := mi.RcWork.Top - mi.RcMonitor.Top
+
+	if r.Left-dx == normal.Left && r.Top-dy == normal.Top &&
+		r.Right-dx == normal.Right && r.Bottom-dy == normal.Bottom {
+
+		return r, false
+	}
+
+	return r, true
+}
+
+// restoreSnappedBounds moves the window hwnd to r, after its placement was
+// restored, so it appears in the arrangement it was snapped to.
+func restoreSnappedBounds(hwnd HWND, r RECT) error {
+	if !SetWindowPos(
+		hwnd, 0,
+		r.Left, r.Top, r.Right-r.Left, r.Bottom-r.Top,
+		SWP_NOZORDER|SWP_NOOWNERZORDER|SWP_NOACTIVATE) {
+
+		return lastError("SetWindowPos")
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	spfAsync            = 0x01
+	spfPurgeBeforeSpeak = 0x02
+	spfIsNotXML         = 0x10
+)
+
+// Vtable indexes of the ISpVoice methods used.
+const (
+	vtblSpVoiceSpeak         = 20
+	vtblSpVoiceSetRate       = 28
+	vtblSpVoiceSetVolume     = 30
+	vtblSpVoiceWaitUntilDone = 32
+)
+
+var (
+	clsidSpVoice = CLSID{0x96749377, 0x3391, 0x11D2, [8]byte{0x9E, 0xE3, 0x00, 0xC0, 0x4F, 0x79, 0x73, 0x96}}
+	iidISpVoice  = IID{0x6C44DF74, 0x72B9, 0x4992, [8]byte{0xA1, 0xEC, 0xEF, 0x99, 0x6E, 0x04, 0x22, 0xD4}}
+)
+
+// Speech speaks text with the default voice of the Speech API, e.g. to
+// announce status changes in accessibility-focused applications or kiosks.
+//
+// Speaking is asynchronous. Texts passed to Speak while another one is being
+// spoken are queued.
+type Speech struct {
+	voice unsafe.Pointer
+}
+
+// NewSpeech returns a new *Speech. Call Dispose, when it is no longer needed.
+func NewSpeech() (*Speech, error) {
+	if hr := OleInitialize(); hr != S_OK && hr != S_FALSE {
+		return nil, newError(fmt.Sprint("OleInitialize Error: ", hr))
+	}
+
+	var classFactoryPtr unsafe.Pointer
+	if hr := CoGetClassObject(&clsidSpVoice, CLSCTX_ALL, nil, &IID_IClassFactory, &classFactoryPtr); FAILED(hr) {
+		OleUninitialize()
+		return nil, errorFromHRESULT("CoGetClassObject", hr)
+	}
+	classFactory := (*IClassFactory)(classFactoryPtr)
+	defer classFactory.Release()
+
+	var voicePtr unsafe.Pointer
+	if hr := classFactory.CreateInstance(nil, &iidISpVoice, &voicePtr); FAILED(hr) {
+		OleUninitialize()
+		return nil, errorFromHRESULT("IClassFactory.CreateInstance", hr)
+	}
+
+	return &Speech{voice: voicePtr}, nil
+}
+
+// Dispose stops speaking and releases the voice.
+func (s *Speech) Dispose() {
+	if s.voice == nil {
+		return
+	}
+
+	s.Stop()
+
+	comRelease(s.voice)
+	s.voice = nil
+
+	OleUninitialize()
+}
+
+// Speak queues text to be spoken after the texts passed before.
+func (s *Speech) Speak(text string) error {
+	return s.speak(text, spfAsync|spfIsNotXML)
+}
+
+// SpeakNow stops speaking and discards the queued texts, before it speaks
+// text, e.g. for announcements that make the pending ones obsolete.
+func (s *Speech) SpeakNow(text string) error {
+	return s.speak(text, spfAsync|spfIsNotXML|spfPurgeBeforeSpeak)
+}
+
+// Stop stops speaking and discards the queued texts.
+func (s *Speech) Stop() error {
+	if hr := comCall(s.voice, vtblSpVoiceSpeak, 0, spfAsync|spfPurgeBeforeSpeak, 0); FAILED(hr) {
+		return errorFromHRESULT("ISpVoice.Speak", hr)
+	}
+
+	return nil
+}
+
+func (s *Speech) speak(text string, flags uintptr) error {
+	if hr := comCall(s.voice,
+		vtblSpVoiceSpeak,
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(text))),
+		flags,
+		0); FAILED(hr) {
+
+		return errorFromHRESULT("ISpVoice.Speak", hr)
+	}
+
+	return nil
+}
+
+// Speaking returns if the *Speech is speaking or has texts queued.
+func (s *Speech) Speaking() bool {
+	// S_FALSE means the timeout elapsed before speaking was done.
+	return comCall(s.voice, vtblSpVoiceWaitUntilDone, 0) == S_FALSE
+}
+
+// SetRate sets the speaking rate, from -10 for the slowest to 10 for the
+// fastest. 0 is the normal rate.
+func (s *Speech) SetRate(value int) error {
+	if value < -10 || value > 10 {
+		return newError("value out of range")
+	}
+
+	if hr := comCall(s.voice, vtblSpVoiceSetRate, uintptr(int32(value))); FAILED(hr) {
+		return errorFromHRESULT("ISpVoice.SetRate", hr)
+	}
+
+	return nil
+}
+
+// SetVolume sets the volume, from 0 to 100.
+func (s *Speech) SetVolume(value int) error {
+	if value < 0 || value > 100 {
+		return newError("value out of range")
+	}
+
+	if hr := comCall(s.voice, vtblSpVoiceSetVolume, uintptr(uint16(value))); FAILED(hr) {
+		return errorFromHRESULT("ISpVoice.SetVolume", hr)
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"time"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const splashScreenWindowClass = `\o/ Walk_SplashScreen_Class \o/`
+
+const (
+	wsExLayered = 0x00080000
+	lwaAlpha    = 0x00000002
+
+	splashScreenFadeDuration = 200 * time.Millisecond
+	splashScreenFadeInterval = 15 * time.Millisecond
+)
+
+var procSetLayeredWindowAttributes = libuser32.NewProc("SetLayeredWindowAttributes")
+
+var splashScreensByHWnd = make(map[HWND]*SplashScreen)
+
+func init() {
+	MustRegisterWindowClass(splashScreenWindowClass)
+}
+
+// SplashScreen is a borderless window showing an image, and optionally a
+// progress text, while the application starts up.
+//
+// A SplashScreen paints itself immediately, so it can be shown before the
+// message loop runs.
+type SplashScreen struct {
+	hWnd           HWND
+	bitmap         *Bitmap
+	text           string
+	textColor      Color
+	fadeTimerId    uintptr
+	startingHandle int
+	startingWindow *TopLevelWindow
+}
+
+// NewSplashScreen creates and shows a new *SplashScreen with bitmap,
+// centered on the primary monitor.
+func NewSplashScreen(bitmap *Bitmap) (*SplashScreen, error) {
+	size := bitmap.Size()
+
+	x := (int(GetSystemMetrics(SM_CXSCREEN)) - size.Width) / 2
+	y := (int(GetSystemMetrics(SM_CYSCREEN)) - size.Height) / 2
+
+	hWnd := CreateWindowEx(
+		WS_EX_TOOLWINDOW|WS_EX_TOPMOST|wsExLayered,
+		syscall.StringToUTF16Ptr(splashScreenWindowClass),
+		nil,
+		WS_POPUP,
+		int32(x),
+		int32(y),
+		int32(size.Width),
+		int32(size.Height),
+		0,
+		0,
+		0,
+		nil)
+	if hWnd == 0 {
+		return nil, lastError("CreateWindowEx")
+	}
+
+	ss := &SplashScreen{hWnd: hWnd, bitmap: bitmap, textColor: RGB(255, 255, 255)}
+	splashScreensByHWnd[hWnd] = ss
+
+	ss.setAlpha(255)
+
+	ShowWindow(hWnd, SW_SHOWNOACTIVATE)
+	UpdateWindow(hWnd)
+
+	return ss, nil
+}
+
+// Text returns the progress text of the *SplashScreen.
+func (ss *SplashScreen) Text() string {
+	return ss.text
+}
+
+// SetText sets the progress text, that is shown at the bottom of the
+// *SplashScreen, e.g. "Loading plugins…".
+//
+// The *SplashScreen is repainted right away, so SetText may be called while
+// initializing, before the message loop runs.
+func (ss *SplashScreen) SetText(value string) {
+	ss.text = value
+
+	ss.repaint()
+}
+
+// TextColor returns the color of the progress text.
+func (ss *SplashScreen) TextColor() Color {
+	return ss.textColor
+}
+
+// SetTextColor sets the color of the progress text, white by default.
+func (ss *SplashScreen) SetTextColor(value Color) {
+	ss.textColor = value
+
+	ss.repaint()
+}
+
+// CloseWhenShown makes the *SplashScreen fade out, when the message loop of
+// window starts, i.e. when the main window of the application is shown.
+func (ss *SplashScreen) CloseWhenShown(window *TopLevelWindow) {
+	ss.detachStarting()
+
+	ss.startingWindow = window
+	ss.startingHandle = window.Starting().Attach(func() {
+		ss.Close()
+	})
+}
+
+// Close fades out and then destroys the *SplashScreen.
+//
+// Fading out requires a running message loop. Use Dispose to destroy the
+// *SplashScreen immediately.
+func (ss *SplashScreen) Close() {
+	ss.detachStarting()
+
+	if ss.hWnd == 0 || ss.fadeTimerId != 0 {
+		return
+	}
+
+	start := time.Now()
+
+	ss.fadeTimerId = startTimer(splashScreenFadeInterval, func() {
+		elapsed := time.Since(start)
+		if elapsed >= splashScreenFadeDuration {
+			ss.Dispose()
+			return
+		}
+
+		ss.setAlpha(byte(255 - 255*elapsed/splashScreenFadeDuration))
+	})
+
+	if ss.fadeTimerId == 0 {
+		ss.Dispose()
+	}
+}
+
+// Dispose destroys the *SplashScreen immediately.
+//
+// The bitmap of the *SplashScreen is not disposed of.
+func (ss *SplashScreen) Dispose() {
+	ss.detachStarting()
+
+	stopTimer(ss.fadeTimerId)
+	ss.fadeTimerId = 0
+
+	if ss.hWnd == 0 {
+		return
+	}
+
+	delete(splashScreensByHWnd, ss.hWnd)
+
+	DestroyWindow(ss.hWnd)
+	ss.hWnd = 0
+}
+
+func (ss *SplashScreen) detachStarting() {
+	if ss.startingWindow == nil {
+		return
+	}
+
+	ss.startingWindow.Starting().Detach(ss.startingHandle)
+	ss.startingWindow = nil
+}
+
+func (ss *SplashScreen) setAlpha(alpha byte) {
+	procSetLayeredWindowAttributes.Call(uintptr(ss.hWnd), 0, uintptr(alpha), lwaAlpha)
+}
+
+func (ss *SplashScreen) repaint() {
+	if ss.hWnd == 0 {
+		return
+	}
+
+	InvalidateRect(ss.hWnd, nil, false)
+	UpdateWindow(ss.hWnd)
+}
+
+func (ss *SplashScreen) paint(hdc HDC) error {
+	if err := ss.bitmap.draw(hdc, Point{}); err != nil {
+		return err
+	}
+
+	if ss.text == "" {
+		return nil
+	}
+
+	canvas, err := newCanvasFromHDC(hdc)
+	if err != nil {
+		return err
+	}
+	defer canvas.Dispose()
+
+	size := ss.bitmap.Size()
+	margin := 8
+	bounds := Rectangle{margin, 0, size.Width - 2*margin, size.Height - margin}
+
+	return canvas.DrawText(
+		ss.text,
+		defaultFont,
+		ss.textColor,
+		bounds,
+		TextBottom|TextCenter|TextSingleLine|TextEndEllipsis)
+}
+
+func splashScreenWndProc(ss *SplashScreen, hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_PAINT:
+		var ps PAINTSTRUCT
+
+		hdc := BeginPaint(hwnd, &ps)
+		if hdc == 0 {
+			newAPIError("BeginPaint")
+			break
+		}
+		defer EndPaint(hwnd, &ps)
+
+		ss.paint(hdc)
+
+		return 0
+
+	case WM_ERASEBKGND:
+		return 1
+	}
+
+	return DefWindowProc(hwnd, msg, wParam, lParam)
+}
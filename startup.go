@@ -0,0 +1,145 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// commonControlsCategories maps the window classes of common controls to the
+// InitCommonControlsEx category, that registers them.
+var commonControlsCategories = map[string]uint32{
+	"SysListView32":      ICC_LISTVIEW_CLASSES,
+	"SysHeader32":        ICC_LISTVIEW_CLASSES,
+	"SysTreeView32":      ICC_TREEVIEW_CLASSES,
+	"SysTabControl32":    ICC_TAB_CLASSES,
+	"tooltips_class32":   ICC_TAB_CLASSES,
+	"ToolbarWindow32":    ICC_BAR_CLASSES,
+	"msctls_statusbar32": ICC_BAR_CLASSES,
+	"msctls_trackbar32":  ICC_BAR_CLASSES,
+	"msctls_updown32":    ICC_UPDOWN_CLASS,
+	"msctls_progress32":  ICC_PROGRESS_CLASS,
+	"msctls_hotkey32":    ICC_HOTKEY_CLASS,
+	"SysDateTimePick32":  ICC_DATE_CLASSES,
+	"SysMonthCal32":      ICC_DATE_CLASSES,
+	"ReBarWindow32":      ICC_COOL_CLASSES,
+	"SysIPAddress32":     ICC_INTERNET_CLASSES,
+	"SysLink":            ICC_LINK_CLASS,
+}
+
+var initializedCommonControls uint32
+
+// StartupTraceEntry is the time spent initializing a subsystem.
+type StartupTraceEntry struct {
+	Subsystem string
+	Duration  time.Duration
+}
+
+// StartupTrace reports where time was spent, before the message loop ran for
+// the first time.
+type StartupTrace struct {
+	// Total is the time from initialization of the walk package until the
+	// first message loop was entered.
+	Total time.Duration
+
+	// Subsystems are the initialization steps performed by walk, in the
+	// order they first occurred.
+	Subsystems []StartupTraceEntry
+}
+
+var (
+	startupBegin     = time.Now()
+	startupEnd       time.Time
+	startupTraceDone bool
+	startupTrace     []StartupTraceEntry
+
+	prewarmOnce sync.Once
+)
+
+// StartupTrace returns where time was spent during startup, e.g. to find out
+// why the main window takes long to appear.
+//
+// The trace is complete, once a message loop was entered.
+func (app *Application) StartupTrace() StartupTrace {
+	end := startupEnd
+	if !startupTraceDone {
+		end = time.Now()
+	}
+
+	return StartupTrace{
+		Total:      end.Sub(startupBegin),
+		Subsystems: append([]StartupTraceEntry(nil), startupTrace...),
+	}
+}
+
+// Prewarm loads system libraries, that walk only needs for some features, on
+// a background goroutine, so using the features for the first time doesn't
+// block the UI thread.
+//
+// Prewarm only does work, that is safe outside of the UI thread. It returns
+// immediately.
+func (app *Application) Prewarm() {
+	prewarmOnce.Do(func() {
+		go func() {
+			for _, dll := range []*syscall.LazyDLL{libuxtheme, libdwmapi, libgdi32, libwinmm} {
+				dll.Load()
+			}
+		}()
+	})
+}
+
+// traceStartup adds the time elapsed since start to the time spent
+// initializing subsystem, unless startup is complete.
+func traceStartup(subsystem string, start time.Time) {
+	if startupTraceDone {
+		return
+	}
+
+	d := time.Since(start)
+
+	for i := range startupTrace {
+		if startupTrace[i].Subsystem == subsystem {
+			startupTrace[i].Duration += d
+			return
+		}
+	}
+
+	startupTrace = append(startupTrace, StartupTraceEntry{subsystem, d})
+}
+
+// endStartupTrace completes the startup trace, when a message loop is
+// entered.
+func endStartupTrace() {
+	if startupTraceDone {
+		return
+	}
+
+	startupEnd = time.Now()
+	startupTraceDone = true
+}
+
+// initCommonControlsForClass registers the category of common controls,
+// that className belongs to, when the first widget of it is created.
+func initCommonControlsForClass(className string) {
+	icc, ok := commonControlsCategories[className]
+	if !ok || initializedCommonControls&icc != 0 {
+		return
+	}
+
+	defer traceStartup("CommonControls", time.Now())
+
+	var iccex INITCOMMONCONTROLSEX
+	iccex.DwSize = uint32(unsafe.Sizeof(iccex))
+	iccex.DwICC = icc
+
+	if InitCommonControlsEx(&iccex) {
+		initializedCommonControls |= icc
+	}
+}
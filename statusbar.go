@@ -0,0 +1,581 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	sbarsSizeGrip = 0x0100
+	sbarsTooltips = 0x0800
+
+	sbSetText    = WM_USER + 11
+	sbSetParts   = WM_USER + 4
+	sbGetRect    = WM_USER + 10
+	sbSetIcon    = WM_USER + 15
+	sbSetTipText = WM_USER + 17
+
+	sbtOwnerDraw = 0x1000
+)
+
+// StatusBarItemDrawFunc draws an owner-drawn StatusBarItem into bounds.
+type StatusBarItemDrawFunc func(item *StatusBarItem, canvas *Canvas, bounds Rectangle) error
+
+// StatusBar is the bar at the bottom of a MainWindow, that shows the items of
+// its Items list side by side. Each item is a part with a text and an icon,
+// that may also show a progress bar or be drawn by a callback.
+//
+// The *StatusBar is visible as long as it has items.
+type StatusBar struct {
+	WidgetBase
+	items *StatusBarItemList
+}
+
+// NewStatusBar returns a new *StatusBar without items.
+//
+// MainWindow has a *StatusBar already, see MainWindow.StatusBar.
+func NewStatusBar(parent Container) (*StatusBar, error) {
+	sb := new(StatusBar)
+	sb.items = newStatusBarItemList(sb)
+
+	if err := InitChildWidget(
+		sb,
+		parent,
+		"msctls_statusbar32",
+		sbarsSizeGrip|sbarsTooltips|WS_VISIBLE,
+		0); err != nil {
+		return nil, err
+	}
+
+	return sb, nil
+}
+
+func (*StatusBar) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | GrowableHorz | GreedyHorz
+}
+
+func (sb *StatusBar) MinSizeHint() Size {
+	return sb.SizeHint()
+}
+
+func (sb *StatusBar) SizeHint() Size {
+	return Size{0, sb.Height()}
+}
+
+// Items returns the list of items of the *StatusBar.
+func (sb *StatusBar) Items() *StatusBarItemList {
+	return sb.items
+}
+
+// SizeGripVisible returns if the *StatusBar shows a size grip at its right
+// end.
+func (sb *StatusBar) SizeGripVisible() bool {
+	return sb.hasStyleBits(sbarsSizeGrip)
+}
+
+// SetSizeGripVisible sets if the *StatusBar shows a size grip at its right
+// end.
+func (sb *StatusBar) SetSizeGripVisible(visible bool) error {
+	if err := sb.ensureStyleBits(sbarsSizeGrip, visible); err != nil {
+		return err
+	}
+
+	sb.SendMessage(WM_SIZE, 0, 0)
+
+	return sb.update()
+}
+
+// update sets the parts from the widths of the items and updates the bounds
+// of the progress bars.
+//
+// The remaining width is shared by the items with a width of 0.
+func (sb *StatusBar) update() error {
+	count := sb.items.Len()
+	if count == 0 {
+		// A single part without text, that spans the whole *StatusBar.
+		rightEdge := int32(-1)
+		sb.SendMessage(sbSetParts, 1, uintptr(unsafe.Pointer(&rightEdge)))
+		return setWidgetText(sb.hWnd, "")
+	}
+
+	width := sb.ClientBounds().Width
+
+	fixedWidth := 0
+	stretchCount := 0
+	for _, item := range sb.items.items {
+		if item.width > 0 {
+			fixedWidth += item.width
+		} else {
+			stretchCount++
+		}
+	}
+
+	var stretchWidth int
+	if stretchCount > 0 {
+		stretchWidth = maxi(0, width-fixedWidth) / stretchCount
+	}
+
+	rightEdges := make([]int32, count)
+	var x int
+	for i, item := range sb.items.items {
+		if item.width > 0 {
+			x += item.width
+		} else {
+			x += stretchWidth
+		}
+		rightEdges[i] = int32(x)
+	}
+	rightEdges[count-1] = -1
+
+	if FALSE == sb.SendMessage(sbSetParts, uintptr(count), uintptr(unsafe.Pointer(&rightEdges[0]))) {
+		return newAPIError("SendMessage(SB_SETPARTS)")
+	}
+
+	for _, item := range sb.items.items {
+		if err := item.update(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (sb *StatusBar) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_NOTIFY:
+		nmm := (*NMMOUSE)(unsafe.Pointer(lParam))
+
+		index := int(nmm.DwItemSpec)
+		if index < 0 || index >= sb.items.Len() {
+			break
+		}
+
+		switch int32(nmm.Hdr.Code) {
+		case NM_CLICK:
+			sb.items.At(index).clickedPublisher.Publish()
+
+		case NM_DBLCLK:
+			sb.items.At(index).doubleClickedPublisher.Publish()
+		}
+
+	case WM_DRAWITEM:
+		dis := (*DRAWITEMSTRUCT)(unsafe.Pointer(lParam))
+
+		index := int(int32(dis.ItemID))
+		if index < 0 || index >= sb.items.Len() {
+			break
+		}
+
+		if item := sb.items.At(index); item.drawFunc != nil {
+			item.draw(dis)
+			return 1
+		}
+
+	case WM_SIZE:
+		result := sb.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+
+		sb.update()
+
+		return result
+	}
+
+	return sb.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+// StatusBarItem is a part of a StatusBar.
+type StatusBarItem struct {
+	sb                     *StatusBar
+	text                   string
+	toolTipText            string
+	icon                   *Icon
+	width                  int
+	hWndProgress           HWND
+	progressValue          int
+	drawFunc               StatusBarItemDrawFunc
+	clickedPublisher       EventPublisher
+	doubleClickedPublisher EventPublisher
+}
+
+// NewStatusBarItem returns a new *StatusBarItem, that shares the width left
+// by the other items.
+func NewStatusBarItem() *StatusBarItem {
+	return new(StatusBarItem)
+}
+
+// Text returns the text of the *StatusBarItem.
+func (sbi *StatusBarItem) Text() string {
+	return sbi.text
+}
+
+// SetText sets the text of the *StatusBarItem.
+func (sbi *StatusBarItem) SetText(text string) error {
+	if text == sbi.text {
+		return nil
+	}
+
+	old := sbi.text
+	sbi.text = text
+
+	if err := sbi.update(); err != nil {
+		sbi.text = old
+		return err
+	}
+
+	return nil
+}
+
+// ToolTipText returns the text, that is shown as tool tip, when the text of
+// the *StatusBarItem does not fit or the item shows only an icon.
+func (sbi *StatusBarItem) ToolTipText() string {
+	return sbi.toolTipText
+}
+
+// SetToolTipText sets the text, that is shown as tool tip, when the text of
+// the *StatusBarItem does not fit or the item shows only an icon.
+func (sbi *StatusBarItem) SetToolTipText(toolTipText string) error {
+	if toolTipText == sbi.toolTipText {
+		return nil
+	}
+
+	old := sbi.toolTipText
+	sbi.toolTipText = toolTipText
+
+	if err := sbi.update(); err != nil {
+		sbi.toolTipText = old
+		return err
+	}
+
+	return nil
+}
+
+// Icon returns the icon shown in front of the text of the *StatusBarItem.
+func (sbi *StatusBarItem) Icon() *Icon {
+	return sbi.icon
+}
+
+// SetIcon sets the icon shown in front of the text of the *StatusBarItem.
+func (sbi *StatusBarItem) SetIcon(icon *Icon) error {
+	if icon == sbi.icon {
+		return nil
+	}
+
+	old := sbi.icon
+	sbi.icon = icon
+
+	if err := sbi.update(); err != nil {
+		sbi.icon = old
+		return err
+	}
+
+	return nil
+}
+
+// Width returns the width of the *StatusBarItem in pixels. A width of 0 means,
+// that the item shares the width left by the other items.
+func (sbi *StatusBarItem) Width() int {
+	return sbi.width
+}
+
+// SetWidth sets the width of the *StatusBarItem in pixels. A width of 0 means,
+// that the item shares the width left by the other items.
+func (sbi *StatusBarItem) SetWidth(width int) error {
+	if width < 0 {
+		return newError("width must not be negative")
+	}
+
+	if width == sbi.width {
+		return nil
+	}
+
+	sbi.width = width
+
+	if sbi.sb == nil {
+		return nil
+	}
+
+	return sbi.sb.update()
+}
+
+// ProgressVisible returns if the *StatusBarItem shows a progress bar instead
+// of its text.
+func (sbi *StatusBarItem) ProgressVisible() bool {
+	return sbi.hWndProgress != 0
+}
+
+// SetProgressVisible sets if the *StatusBarItem shows a progress bar instead
+// of its text.
+func (sbi *StatusBarItem) SetProgressVisible(visible bool) error {
+	if visible == sbi.ProgressVisible() {
+		return nil
+	}
+
+	if !visible {
+		sbi.destroyProgress()
+		return sbi.update()
+	}
+
+	if sbi.sb == nil {
+		return newError("item not in a StatusBar")
+	}
+
+	sbi.hWndProgress = CreateWindowEx(
+		0, syscall.StringToUTF16Ptr("msctls_progress32"), nil,
+		WS_CHILD|WS_VISIBLE,
+		0, 0, 0, 0, sbi.sb.hWnd, 0, 0, nil)
+	if sbi.hWndProgress == 0 {
+		return lastError("CreateWindowEx(msctls_progress32)")
+	}
+
+	return sbi.update()
+}
+
+// ProgressValue returns the value of the progress bar of the *StatusBarItem,
+// from 0 to 100.
+func (sbi *StatusBarItem) ProgressValue() int {
+	return sbi.progressValue
+}
+
+// SetProgressValue sets the value of the progress bar of the *StatusBarItem,
+// from 0 to 100.
+func (sbi *StatusBarItem) SetProgressValue(value int) {
+	sbi.progressValue = mini(100, maxi(0, value))
+
+	if sbi.hWndProgress != 0 {
+		SendMessage(sbi.hWndProgress, PBM_SETPOS, uintptr(sbi.progressValue), 0)
+	}
+}
+
+// OwnerDrawn returns if the *StatusBarItem is drawn by a callback.
+func (sbi *StatusBarItem) OwnerDrawn() bool {
+	return sbi.drawFunc != nil
+}
+
+// SetOwnerDraw sets the callback, that draws the *StatusBarItem instead of its
+// text and icon, e.g. to show a colored indicator. Pass nil to restore
+// default rendering. A progress bar is still shown above the drawing.
+func (sbi *StatusBarItem) SetOwnerDraw(draw StatusBarItemDrawFunc) error {
+	old := sbi.drawFunc
+	sbi.drawFunc = draw
+
+	if err := sbi.update(); err != nil {
+		sbi.drawFunc = old
+		return err
+	}
+
+	return nil
+}
+
+// Invalidate schedules a full repaint of the *StatusBarItem, e.g. after the
+// state shown by an owner-drawn item changed.
+func (sbi *StatusBarItem) Invalidate() error {
+	if sbi.sb == nil {
+		return nil
+	}
+
+	var r RECT
+	if FALSE == sbi.sb.SendMessage(sbGetRect, uintptr(sbi.index()), uintptr(unsafe.Pointer(&r))) {
+		return newAPIError("SendMessage(SB_GETRECT)")
+	}
+
+	if !InvalidateRect(sbi.sb.hWnd, &r, true) {
+		return newAPIError("InvalidateRect")
+	}
+
+	return nil
+}
+
+// Clicked returns the event that is published, when the user clicks the
+// *StatusBarItem.
+func (sbi *StatusBarItem) Clicked() *Event {
+	return sbi.clickedPublisher.Event()
+}
+
+// DoubleClicked returns the event that is published, when the user double
+// clicks the *StatusBarItem.
+func (sbi *StatusBarItem) DoubleClicked() *Event {
+	return sbi.doubleClickedPublisher.Event()
+}
+
+func (sbi *StatusBarItem) index() int {
+	return sbi.sb.items.Index(sbi)
+}
+
+func (sbi *StatusBarItem) update() error {
+	if sbi.sb == nil {
+		return nil
+	}
+
+	index := sbi.index()
+	if index == -1 {
+		return nil
+	}
+
+	text := sbi.text
+	if sbi.hWndProgress != 0 {
+		text = ""
+	}
+
+	if sbi.drawFunc != nil {
+		// The text is not shown, so the control does not need it.
+		if FALSE == sbi.sb.SendMessage(sbSetText, uintptr(index)|sbtOwnerDraw, 0) {
+			return newAPIError("SendMessage(SB_SETTEXT)")
+		}
+	} else if FALSE == sbi.sb.SendMessage(sbSetText, uintptr(index), uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(text)))) {
+		return newAPIError("SendMessage(SB_SETTEXT)")
+	}
+
+	sbi.sb.SendMessage(sbSetTipText, uintptr(index), uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(sbi.toolTipText))))
+
+	var hIcon HICON
+	if sbi.icon != nil && sbi.drawFunc == nil {
+		hIcon = sbi.icon.hIcon
+	}
+	if FALSE == sbi.sb.SendMessage(sbSetIcon, uintptr(index), uintptr(hIcon)) {
+		return newAPIError("SendMessage(SB_SETICON)")
+	}
+
+	if sbi.hWndProgress != 0 {
+		var r RECT
+		sbi.sb.SendMessage(sbGetRect, uintptr(index), uintptr(unsafe.Pointer(&r)))
+
+		if !MoveWindow(sbi.hWndProgress, r.Left+1, r.Top+1, r.Right-r.Left-2, r.Bottom-r.Top-2, true) {
+			return lastError("MoveWindow")
+		}
+
+		SendMessage(sbi.hWndProgress, PBM_SETPOS, uintptr(sbi.progressValue), 0)
+	}
+
+	return nil
+}
+
+func (sbi *StatusBarItem) draw(dis *DRAWITEMSTRUCT) {
+	canvas, err := newCanvasFromHDC(dis.HDC)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	sbi.drawFunc(sbi, canvas, rectangleFromRECT(dis.RcItem))
+}
+
+func (sbi *StatusBarItem) destroyProgress() {
+	if sbi.hWndProgress != 0 {
+		DestroyWindow(sbi.hWndProgress)
+		sbi.hWndProgress = 0
+	}
+}
+
+type StatusBarItemList struct {
+	sb    *StatusBar
+	items []*StatusBarItem
+}
+
+func newStatusBarItemList(sb *StatusBar) *StatusBarItemList {
+	return &StatusBarItemList{sb: sb}
+}
+
+// Add adds a StatusBarItem to the end of the list.
+func (l *StatusBarItemList) Add(item *StatusBarItem) error {
+	return l.Insert(len(l.items), item)
+}
+
+// At returns the StatusBarItem as the specified index.
+//
+// Bounds are not checked.
+func (l *StatusBarItemList) At(index int) *StatusBarItem {
+	return l.items[index]
+}
+
+// Clear removes all StatusBarItems from the list.
+func (l *StatusBarItemList) Clear() error {
+	for _, item := range l.items {
+		item.destroyProgress()
+		item.sb = nil
+	}
+
+	l.items = nil
+
+	l.sb.SetVisible(false)
+
+	return l.sb.update()
+}
+
+// Index returns the index of the specified StatusBarItem or -1 if it is not
+// found.
+func (l *StatusBarItemList) Index(item *StatusBarItem) int {
+	for i, sbi := range l.items {
+		if sbi == item {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Contains returns whether the specified StatusBarItem is found in the list.
+func (l *StatusBarItemList) Contains(item *StatusBarItem) bool {
+	return l.Index(item) > -1
+}
+
+// Insert inserts StatusBarItem item at position index.
+//
+// A StatusBarItem cannot be contained in multiple StatusBarItemLists at the
+// same time.
+func (l *StatusBarItemList) Insert(index int, item *StatusBarItem) error {
+	if item.sb != nil {
+		return newError("duplicate insert")
+	}
+
+	item.sb = l.sb
+
+	l.items = append(l.items, nil)
+	copy(l.items[index+1:], l.items[index:])
+	l.items[index] = item
+
+	if err := l.sb.update(); err != nil {
+		l.items = append(l.items[:index], l.items[index+1:]...)
+		item.sb = nil
+		return err
+	}
+
+	l.sb.SetVisible(true)
+
+	return nil
+}
+
+// Len returns the number of StatusBarItems in the list.
+func (l *StatusBarItemList) Len() int {
+	return len(l.items)
+}
+
+// Remove removes the specified StatusBarItem from the list.
+func (l *StatusBarItemList) Remove(item *StatusBarItem) error {
+	index := l.Index(item)
+	if index == -1 {
+		return nil
+	}
+
+	return l.RemoveAt(index)
+}
+
+// RemoveAt removes the StatusBarItem at position index.
+func (l *StatusBarItemList) RemoveAt(index int) error {
+	item := l.items[index]
+
+	item.destroyProgress()
+	item.sb = nil
+
+	l.items = append(l.items[:index], l.items[index+1:]...)
+
+	if len(l.items) == 0 {
+		l.sb.SetVisible(false)
+	}
+
+	return l.sb.update()
+}
@@ -0,0 +1,99 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const syncWindowClass = `\o/ Walk_Sync_Class \o/`
+
+// syncHWnd is the message-only window of the UI thread, that runs the
+// synchronized funcs. Unlike thread messages, its messages are also
+// dispatched by modal loops, e.g. of menus and message boxes.
+var syncHWnd HWND
+
+func init() {
+	MustRegisterWindowClass(syncWindowClass)
+}
+
+// initUIThread makes the calling thread the UI thread, if there is none yet.
+func initUIThread() {
+	if uiThreadId == 0 {
+		uiThreadId = GetCurrentThreadId()
+	}
+
+	if syncHWnd != 0 || GetCurrentThreadId() != uiThreadId {
+		return
+	}
+
+	syncHWnd = CreateWindowEx(
+		0,
+		syscall.StringToUTF16Ptr(syncWindowClass),
+		nil,
+		0,
+		0,
+		0,
+		0,
+		0,
+		HWND_MESSAGE,
+		0,
+		0,
+		nil)
+	if syncHWnd == 0 {
+		lastError("CreateWindowEx")
+	}
+}
+
+// Synchronize enqueues func f to be called some time later on the UI thread,
+// from inside its message loop.
+//
+// Widgets must only be accessed on the UI thread, so goroutines use
+// Synchronize to update them. Unlike WidgetBase.Synchronize, no widget is
+// needed.
+func (app *Application) Synchronize(f func()) {
+	postSynchronized(f)
+}
+
+// SynchronizeResult calls func f on the UI thread, like Synchronize, and
+// blocks until it returned, passing on its results.
+//
+// If called on the UI thread, f is called directly, to avoid a deadlock. If
+// there is no UI thread yet, an error is returned, as nothing would call f.
+func (app *Application) SynchronizeResult(f func() (interface{}, error)) (interface{}, error) {
+	if app.IsUIThread() {
+		return f()
+	}
+
+	if syncHWnd == 0 {
+		return nil, newError("no message loop")
+	}
+
+	type result struct {
+		value interface{}
+		err   error
+	}
+
+	done := make(chan result, 1)
+
+	postSynchronized(func() {
+		value, err := f()
+		done <- result{value, err}
+	})
+
+	r := <-done
+
+	return r.value, r.err
+}
+
+// IsUIThread returns if the calling goroutine runs on the UI thread, i.e. the
+// thread that created the first top-level window and runs the message loop.
+//
+// This is useful for assertions in code that touches widgets.
+func (app *Application) IsUIThread() bool {
+	return uiThreadId != 0 && GetCurrentThreadId() == uiThreadId
+}
@@ -0,0 +1,293 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// immersiveColorSet is the WM_SETTINGCHANGE area, that is sent when the user
+// switches between light and dark mode.
+const immersiveColorSet = "ImmersiveColorSet"
+
+const wmDPIChanged = 0x02E0
+
+const personalizeKeyPath = `Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`
+
+var (
+	systemColorsChangePending bool
+	themeChangePending        bool
+	colorSchemeChangePending  bool
+	highContrastChangePending bool
+	settingChangeAreas        []string
+	systemChangePosted        bool
+
+	darkModeKnown bool
+	darkMode      bool
+)
+
+// SystemColorsChanged returns the *Event that is published after the user
+// changed the system colors.
+//
+// Cached brushes of the system colors have been refreshed and all windows
+// have been invalidated, when the *Event is published.
+func (app *Application) SystemColorsChanged() *Event {
+	return app.systemColorsChangedPublisher.Event()
+}
+
+// ThemeChanged returns the *Event that is published after the visual style,
+// light or dark mode, or system metrics like the menu font changed.
+//
+// Cached fonts and metrics have been refreshed and all windows have been
+// invalidated, when the *Event is published.
+func (app *Application) ThemeChanged() *Event {
+	return app.themeChangedPublisher.Event()
+}
+
+// SystemSettingsChanged returns the *StringEvent that is published after a
+// system wide setting changed, e.g. "intl" for the regional settings or
+// "Environment" for the environment variables. The string is empty, if the
+// sender did not specify which setting changed.
+func (app *Application) SystemSettingsChanged() *StringEvent {
+	return app.systemSettingsChangedPublisher.Event()
+}
+
+// ColorSchemeChanged returns the *Event that is published after the user
+// switched between light and dark mode for apps. It is published before
+// ThemeChanged.
+func (app *Application) ColorSchemeChanged() *Event {
+	return app.colorSchemeChangedPublisher.Event()
+}
+
+// DPIChanged returns the *DPIChangedEvent that is published after a
+// top-level window was moved to a monitor with a different DPI, or the
+// scaling factor of its monitor changed.
+//
+// This only happens if the application declares itself per-monitor DPI aware
+// in its manifest. The window has been resized and invalidated already, when
+// the *DPIChangedEvent is published.
+func (app *Application) DPIChanged() *DPIChangedEvent {
+	return app.dpiChangedPublisher.Event()
+}
+
+// DarkMode returns if the user chose the dark color scheme for apps.
+func (app *Application) DarkMode() bool {
+	if !darkModeKnown {
+		darkMode = appsUseDarkMode()
+		darkModeKnown = true
+	}
+
+	return darkMode
+}
+
+func appsUseDarkMode() bool {
+	var hKey HKEY
+	if RegOpenKeyEx(
+		HKEY_CURRENT_USER,
+		syscall.StringToUTF16Ptr(personalizeKeyPath),
+		0,
+		KEY_READ,
+		&hKey) != ERROR_SUCCESS {
+
+		return false
+	}
+	defer RegCloseKey(hKey)
+
+	var value uint32
+	bufSize := uint32(unsafe.Sizeof(value))
+
+	if RegQueryValueEx(
+		hKey,
+		syscall.StringToUTF16Ptr("AppsUseLightTheme"),
+		nil,
+		nil,
+		(*byte)(unsafe.Pointer(&value)),
+		&bufSize) != ERROR_SUCCESS {
+
+		return false
+	}
+
+	return value == 0
+}
+
+// handleSystemChange records a system change notification received by a
+// top-level window.
+//
+// These messages are broadcast to all top-level windows, so the changes are
+// processed once, after the notifications have been received.
+func handleSystemChange(msg uint32, wParam, lParam uintptr) {
+	switch msg {
+	case WM_SYSCOLORCHANGE:
+		systemColorsChangePending = true
+
+	case WM_THEMECHANGED:
+		themeChangePending = true
+
+	case WM_SETTINGCHANGE:
+		var area string
+		if lParam != 0 {
+			area = syscall.UTF16ToString((*[1 << 16]uint16)(unsafe.Pointer(lParam))[:])
+		}
+
+		addSettingChangeArea(area)
+
+		if area == immersiveColorSet {
+			if dark := appsUseDarkMode(); !darkModeKnown || dark != darkMode {
+				darkMode, darkModeKnown = dark, true
+				colorSchemeChangePending = true
+			}
+		}
+
+		if wParam == spiSetHighContrast && updateHighContrast() {
+			highContrastChangePending = true
+		}
+
+		if area == immersiveColorSet || wParam == SPI_SETNONCLIENTMETRICS || highContrastChangePending {
+			themeChangePending = true
+		}
+
+	default:
+		return
+	}
+
+	if !systemChangePosted {
+		systemChangePosted = true
+
+		postSynchronized(processSystemChanges)
+	}
+}
+
+// addSettingChangeArea records area, unless it is pending already, since
+// each top-level window receives WM_SETTINGCHANGE.
+func addSettingChangeArea(area string) {
+	for _, a := range settingChangeAreas {
+		if a == area {
+			return
+		}
+	}
+
+	settingChangeAreas = append(settingChangeAreas, area)
+}
+
+func processSystemChanges() {
+	systemChangePosted = false
+
+	colors, theme, colorScheme := systemColorsChangePending, themeChangePending, colorSchemeChangePending
+	systemColorsChangePending, themeChangePending, colorSchemeChangePending = false, false, false
+
+	highContrast := highContrastChangePending
+	highContrastChangePending = false
+
+	areas := settingChangeAreas
+	settingChangeAreas = nil
+
+	app := appSingleton
+
+	// High contrast overrides dark colors, so they are applied again.
+	if colorScheme && app.colorScheme == ColorSchemeSystem || highContrast && app.colorScheme != ColorSchemeLight {
+		app.applyColorScheme()
+	}
+
+	if colors || theme {
+		resetCachedSystemResources()
+
+		for tlw := range topLevelWindows {
+			refreshWidget(tlw.widget, colors)
+		}
+
+		for _, m := range menusByHandle {
+			m.applyTheme()
+		}
+
+		if colors {
+			app.systemColorsChangedPublisher.Publish()
+		}
+
+		if highContrast {
+			app.highContrastChangedPublisher.Publish()
+		}
+
+		if colorScheme {
+			app.colorSchemeChangedPublisher.Publish()
+		}
+
+		if theme {
+			app.themeChangedPublisher.Publish()
+		}
+	}
+
+	for _, area := range areas {
+		app.systemSettingsChangedPublisher.Publish(area)
+	}
+}
+
+// handleDPIChanged handles WM_DPICHANGED for the top-level window tlw.
+func handleDPIChanged(tlw *TopLevelWindow, wParam, lParam uintptr) {
+	// The new DPI is the same for both axes.
+	dpi := int(wParam & 0xFFFF)
+
+	// Windows suggests bounds, that keep the window at the same physical
+	// size on the new monitor.
+	r := (*RECT)(unsafe.Pointer(lParam))
+	SetWindowPos(
+		tlw.hWnd,
+		0,
+		r.Left,
+		r.Top,
+		r.Right-r.Left,
+		r.Bottom-r.Top,
+		SWP_NOZORDER|SWP_NOACTIVATE)
+
+	refreshWidget(tlw.widget, false)
+
+	appSingleton.dpiChangedPublisher.Publish(tlw, dpi)
+}
+
+// resetCachedSystemResources releases the brushes and fonts derived from
+// system colors and metrics, so they are recreated on next use.
+func resetCachedSystemResources() {
+	// SystemColorBrushes are owned by the system, so they are not disposed
+	// of.
+	menuBackgroundBrush, menuSelectedBrush = nil, nil
+
+	if menuFontSingleton != nil && menuFontSingleton != defaultFont {
+		menuFontSingleton.Dispose()
+	}
+	menuFontSingleton = nil
+}
+
+// refreshWidget invalidates widget and its descendants. If forwardColors is
+// true, WM_SYSCOLORCHANGE is forwarded to them, as common controls require.
+func refreshWidget(widget Widget, forwardColors bool) {
+	hwnd := widget.Handle()
+	if hwnd == 0 {
+		return
+	}
+
+	if forwardColors {
+		if _, ok := widget.(RootWidget); !ok {
+			SendMessage(hwnd, WM_SYSCOLORCHANGE, 0, 0)
+		}
+	}
+
+	InvalidateRect(hwnd, nil, true)
+
+	switch w := widget.(type) {
+	case *TabWidget:
+		pages := w.Pages()
+		for i := 0; i < pages.Len(); i++ {
+			refreshWidget(pages.At(i), forwardColors)
+		}
+
+	case Container:
+		children := w.Children()
+		for i := 0; i < children.Len(); i++ {
+			refreshWidget(children.At(i), forwardColors)
+		}
+	}
+}
@@ -29,6 +29,13 @@ const (
 //
 // TableView is implemented as a virtual mode list view to support quite large
 // amounts of data.
+//
+// Screen readers and UI Automation clients see the rows and cells through the
+// accessibility proxy, that Windows provides for list view controls. It
+// offers the Grid, Table and SelectionItem patterns and reads names and values
+// through LVN_GETDISPINFO, so walk implements no UI Automation providers of
+// its own. It only notifies clients of changes of the virtual rows, which the
+// control can't detect itself.
 type TableView struct {
 	WidgetBase
 	columns                          *TableViewColumnList
@@ -44,18 +51,23 @@ type TableView struct {
 	rowChangedHandlerHandle          int
 	sortChangedHandlerHandle         int
 	currentIndex                     int
+	currentColumn                    int
 	currentIndexChangedPublisher     EventPublisher
 	selectedIndexes                  *IndexList
 	selectedIndexesChangedPublisher  EventPublisher
 	itemActivatedPublisher           EventPublisher
 	columnClickedPublisher           IntEventPublisher
+	pasteFailedPublisher             ErrorEventPublisher
 	columnsOrderableChangedPublisher EventPublisher
 	columnsSizableChangedPublisher   EventPublisher
+	columnChooserEnabled             bool
 	lastColumnStretched              bool
 	inEraseBkgnd                     bool
 	persistent                       bool
 	itemStateChangedEventDelay       int
 	alternatingRowBGColor            Color
+	itemActivationMode               ItemActivationMode
+	dragAutoScroll                   dragAutoScroller
 }
 
 // NewTableView creates and returns a *TableView as child of the specified
@@ -97,6 +109,7 @@ func NewTableView(parent Container) (*TableView, error) {
 	}
 
 	tv.currentIndex = -1
+	tv.currentColumn = -1
 
 	tv.MustRegisterProperty("ColumnsOrderable", NewBoolProperty(
 		func() bool {
@@ -231,6 +244,21 @@ func (tv *TableView) SetAlternatingRowBGColor(c Color) {
 	tv.Invalidate()
 }
 
+// DragAutoScroll returns how the *TableView scrolls, while rows are dragged
+// near its edges.
+func (tv *TableView) DragAutoScroll() DragAutoScrollOptions {
+	return tv.dragAutoScroll.options
+}
+
+// SetDragAutoScroll sets how the *TableView scrolls, while rows are dragged
+// near its edges.
+//
+// Scrolling starts, when the list view reports the begin of a drag operation,
+// and ends when the mouse button is released.
+func (tv *TableView) SetDragAutoScroll(options DragAutoScrollOptions) {
+	tv.dragAutoScroll.setOptions(options)
+}
+
 // Columns returns the list of columns.
 func (tv *TableView) Columns() *TableViewColumnList {
 	return tv.columns
@@ -243,7 +271,7 @@ func (tv *TableView) VisibleColumnsInDisplayOrder() []*TableViewColumn {
 	indices := make([]int32, len(visibleCols))
 
 	if FALSE == tv.SendMessage(LVM_GETCOLUMNORDERARRAY, uintptr(len(indices)), uintptr(unsafe.Pointer(&indices[0]))) {
-		newError("LVM_GETCOLUMNORDERARRAY")
+		newAPIError("SendMessage(LVM_GETCOLUMNORDERARRAY)")
 		return nil
 	}
 
@@ -273,7 +301,7 @@ func (tv *TableView) UpdateItem(index int) error {
 		return tv.Invalidate()
 	} else {
 		if FALSE == tv.SendMessage(LVM_UPDATE, uintptr(index), 0) {
-			return newError("LVM_UPDATE")
+			return newAPIError("SendMessage(LVM_UPDATE)")
 		}
 	}
 
@@ -285,10 +313,14 @@ func (tv *TableView) attachModel() {
 		tv.setItemCount()
 
 		tv.SetCurrentIndex(-1)
+
+		notifyWinEvent(eventObjectReorder, tv.hWnd, childIdSelf)
 	})
 
 	tv.rowChangedHandlerHandle = tv.model.RowChanged().Attach(func(row int) {
 		tv.UpdateItem(row)
+
+		tv.notifyRowChanged(row)
 	})
 
 	if sorter, ok := tv.model.(Sorter); ok {
@@ -297,10 +329,23 @@ func (tv *TableView) attachModel() {
 			tv.setSelectedColumnIndex(col)
 			tv.setSortIcon(col, sorter.SortOrder())
 			tv.Invalidate()
+
+			notifyWinEvent(eventObjectReorder, tv.hWnd, childIdSelf)
 		})
 	}
 }
 
+// notifyRowChanged tells accessibility clients, that the values of row
+// changed.
+//
+// The rows of a *TableView are virtual, i.e. only the model knows their
+// values, so the list view control can't detect changes itself. Screen readers
+// and UI Automation clients retrieve the new values through LVN_GETDISPINFO.
+func (tv *TableView) notifyRowChanged(row int) {
+	notifyWinEvent(eventObjectNameChange, tv.hWnd, int32(row+1))
+	notifyWinEvent(eventObjectValueChange, tv.hWnd, int32(row+1))
+}
+
 func (tv *TableView) detachModel() {
 	tv.model.RowsReset().Detach(tv.rowsResetHandlerHandle)
 	tv.model.RowChanged().Detach(tv.rowChangedHandlerHandle)
@@ -380,7 +425,7 @@ func (tv *TableView) setItemCount() error {
 	}
 
 	if 0 == tv.SendMessage(LVM_SETITEMCOUNT, uintptr(count), 0) {
-		return newError("SendMessage(LVM_SETITEMCOUNT)")
+		return newAPIError("SendMessage(LVM_SETITEMCOUNT)")
 	}
 
 	return nil
@@ -413,7 +458,7 @@ func (tv *TableView) SetCheckBoxes(value bool) {
 	}
 
 	if FALSE == tv.SendMessage(LVM_SETCALLBACKMASK, mask, 0) {
-		newError("SendMessage(LVM_SETCALLBACKMASK)")
+		newAPIError("SendMessage(LVM_SETCALLBACKMASK)")
 	}
 }
 
@@ -495,7 +540,7 @@ func (tv *TableView) setSortIcon(index int, order SortOrder) error {
 		itemPtr := uintptr(unsafe.Pointer(&item))
 
 		if SendMessage(headerHwnd, HDM_GETITEM, iPtr, itemPtr) == 0 {
-			return newError("SendMessage(HDM_GETITEM)")
+			return newAPIError("SendMessage(HDM_GETITEM)")
 		}
 
 		if i == idx {
@@ -513,7 +558,7 @@ func (tv *TableView) setSortIcon(index int, order SortOrder) error {
 		}
 
 		if SendMessage(headerHwnd, HDM_SETITEM, iPtr, itemPtr) == 0 {
-			return newError("SendMessage(HDM_SETITEM)")
+			return newAPIError("SendMessage(HDM_SETITEM)")
 		}
 	}
 
@@ -529,12 +574,40 @@ func (tv *TableView) ColumnClicked() *IntEvent {
 // ItemActivated returns the event that is published after an item was
 // activated.
 //
-// An item is activated when it is double clicked or the enter key is pressed
-// when the item is selected.
+// An item is activated when it is clicked as specified by the
+// ItemActivationMode or the enter key is pressed when the item is selected.
 func (tv *TableView) ItemActivated() *Event {
 	return tv.itemActivatedPublisher.Event()
 }
 
+// ItemActivationMode returns how the user activates items.
+func (tv *TableView) ItemActivationMode() ItemActivationMode {
+	return tv.itemActivationMode
+}
+
+// SetItemActivationMode sets how the user activates items.
+func (tv *TableView) SetItemActivationMode(mode ItemActivationMode) error {
+	if !mode.valid() {
+		return newError("invalid mode")
+	}
+
+	exStyle := tv.SendMessage(LVM_GETEXTENDEDLISTVIEWSTYLE, 0, 0) &^ lvsExItemActivationAll
+
+	switch mode {
+	case ItemActivationSingleClick:
+		exStyle |= lvsExOneClickActivate
+
+	case ItemActivationHover:
+		exStyle |= lvsExOneClickActivate | lvsExTrackSelect | lvsExUnderlineHot
+	}
+
+	tv.SendMessage(LVM_SETEXTENDEDLISTVIEWSTYLE, 0, exStyle)
+
+	tv.itemActivationMode = mode
+
+	return nil
+}
+
 // CurrentIndex returns the index of the current item, or -1 if there is no
 // current item.
 func (tv *TableView) CurrentIndex() int {
@@ -553,12 +626,12 @@ func (tv *TableView) SetCurrentIndex(value int) error {
 	}
 
 	if FALSE == tv.SendMessage(LVM_SETITEMSTATE, uintptr(value), uintptr(unsafe.Pointer(&lvi))) {
-		return newError("SendMessage(LVM_SETITEMSTATE)")
+		return newAPIError("SendMessage(LVM_SETITEMSTATE)")
 	}
 
 	if value != -1 {
 		if FALSE == tv.SendMessage(LVM_ENSUREVISIBLE, uintptr(value), uintptr(0)) {
-			return newError("SendMessage(LVM_ENSUREVISIBLE)")
+			return newAPIError("SendMessage(LVM_ENSUREVISIBLE)")
 		}
 	}
 
@@ -697,7 +770,7 @@ func (tv *TableView) StretchLastColumn() error {
 	}
 
 	if 0 == tv.SendMessage(LVM_SETCOLUMNWIDTH, uintptr(colCount-1), LVSCW_AUTOSIZE_USEHEADER) {
-		return newError("LVM_SETCOLUMNWIDTH failed")
+		return newAPIError("LVM_SETCOLUMNWIDTH")
 	}
 
 	return nil
@@ -742,7 +815,7 @@ func (tv *TableView) SaveState() error {
 		lParam := uintptr(unsafe.Pointer(&indices[0]))
 
 		if 0 == tv.SendMessage(LVM_GETCOLUMNORDERARRAY, uintptr(visibleCount), lParam) {
-			return newError("LVM_GETCOLUMNORDERARRAY")
+			return newAPIError("SendMessage(LVM_GETCOLUMNORDERARRAY)")
 		}
 
 		for i, idx := range indices {
@@ -854,7 +927,7 @@ func (tv *TableView) RestoreState() error {
 			wParam := uintptr(len(indices))
 			lParam := uintptr(unsafe.Pointer(&indices[0]))
 			if 0 == tv.SendMessage(LVM_SETCOLUMNORDERARRAY, wParam, lParam) {
-				return newError("LVM_SETCOLUMNORDERARRAY")
+				return newAPIError("SendMessage(LVM_SETCOLUMNORDERARRAY)")
 			}
 		}
 	}
@@ -899,9 +972,11 @@ func (tv *TableView) toggleItemChecked(index int) error {
 	}
 
 	if FALSE == tv.SendMessage(LVM_UPDATE, uintptr(index), 0) {
-		return newError("SendMessage(LVM_UPDATE)")
+		return newAPIError("SendMessage(LVM_UPDATE)")
 	}
 
+	notifyWinEvent(eventObjectStateChange, tv.hWnd, int32(index+1))
+
 	return nil
 }
 
@@ -956,6 +1031,13 @@ func (tv *TableView) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uint
 
 		switch msg {
 		case WM_LBUTTONDOWN, WM_RBUTTONDOWN:
+			if hti.Flags&LVHT_ONITEM != 0 {
+				shti := LVHITTESTINFO{Pt: hti.Pt}
+				if -1 != int32(tv.SendMessage(LVM_SUBITEMHITTEST, 0, uintptr(unsafe.Pointer(&shti)))) {
+					tv.currentColumn = tv.fromLVColIdx(shti.ISubItem)
+				}
+			}
+
 			if hti.Flags == LVHT_ONITEMSTATEICON &&
 				tv.itemChecker != nil &&
 				tv.CheckBoxes() {
@@ -964,6 +1046,19 @@ func (tv *TableView) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uint
 			}
 		}
 
+	case WM_CONTEXTMENU:
+		if tv.columnChooserEnabled && HWND(wParam) == HWND(tv.SendMessage(LVM_GETHEADER, 0, 0)) {
+			x, y := GET_X_LPARAM(lParam), GET_Y_LPARAM(lParam)
+			if x == -1 && y == -1 {
+				p := POINT{}
+				ClientToScreen(tv.hWnd, &p)
+				x, y = p.X, p.Y
+			}
+
+			tv.showHeaderContextMenu(int(x), int(y))
+			return 0
+		}
+
 	case WM_KEYDOWN:
 		if wParam == VK_SPACE &&
 			tv.currentIndex > -1 &&
@@ -973,6 +1068,17 @@ func (tv *TableView) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uint
 			tv.toggleItemChecked(tv.currentIndex)
 		}
 
+		if wParam == 'V' &&
+			GetKeyState(VK_CONTROL) < 0 &&
+			tv.Editable() &&
+			clipboardHasText() {
+
+			if err := tv.Paste(); err != nil {
+				tv.pasteFailedPublisher.Publish(err)
+			}
+			return 0
+		}
+
 	case WM_NOTIFY:
 		switch int32(((*NMHDR)(unsafe.Pointer(lParam))).Code) {
 		case LVN_GETDISPINFO:
@@ -1048,7 +1154,7 @@ func (tv *TableView) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uint
 			}
 
 		case NM_CUSTOMDRAW:
-			if tv.alternatingRowBGColor != defaultTVRowBGColor {
+			if tv.alternatingRowBGColor != defaultTVRowBGColor && !appSingleton.HighContrast() {
 				nmlvcd := (*NMLVCUSTOMDRAW)(unsafe.Pointer(lParam))
 
 				switch nmlvcd.Nmcd.DwDrawStage {
@@ -1106,6 +1212,12 @@ func (tv *TableView) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uint
 
 		case LVN_ITEMACTIVATE:
 			tv.itemActivatedPublisher.Publish()
+
+		case LVN_BEGINDRAG:
+			tv.dragAutoScroll.start(&tv.WidgetBase, VK_LBUTTON)
+
+		case LVN_BEGINRDRAG:
+			tv.dragAutoScroll.start(&tv.WidgetBase, VK_RBUTTON)
 		}
 
 	case WM_TIMER:
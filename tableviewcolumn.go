@@ -291,7 +291,7 @@ func (tvc *TableViewColumn) create() error {
 
 func (tvc *TableViewColumn) destroy() error {
 	if FALSE == tvc.tv.SendMessage(LVM_DELETECOLUMN, uintptr(tvc.indexInListView()), 0) {
-		return newError("LVM_DELETECOLUMN")
+		return newAPIError("SendMessage(LVM_DELETECOLUMN)")
 	}
 
 	return nil
@@ -305,7 +305,7 @@ func (tvc *TableViewColumn) update() error {
 	lvc := tvc.getLVCOLUMN()
 
 	if FALSE == tvc.tv.SendMessage(LVM_SETCOLUMN, uintptr(tvc.indexInListView()), uintptr(unsafe.Pointer(lvc))) {
-		return newError("LVM_SETCOLUMN")
+		return newAPIError("SendMessage(LVM_SETCOLUMN)")
 	}
 
 	return nil
@@ -0,0 +1,383 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// columnChooserModel lists the columns of a TableView in the column chooser
+// dialog, with a check box for their visibility.
+type columnChooserModel struct {
+	TableModelBase
+	columns        []*TableViewColumn
+	visible        []bool
+	checkedChanged func()
+}
+
+func (m *columnChooserModel) RowCount() int {
+	return len(m.columns)
+}
+
+func (m *columnChooserModel) Value(row, col int) interface{} {
+	return m.columns[row].TitleEffective()
+}
+
+func (m *columnChooserModel) Checked(index int) bool {
+	return m.visible[index]
+}
+
+func (m *columnChooserModel) SetChecked(index int, checked bool) error {
+	m.visible[index] = checked
+
+	m.checkedChanged()
+
+	return nil
+}
+
+func (m *columnChooserModel) anyVisible() bool {
+	for _, v := range m.visible {
+		if v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *columnChooserModel) move(index, delta int) bool {
+	other := index + delta
+	if index < 0 || other < 0 || other >= len(m.columns) {
+		return false
+	}
+
+	m.columns[index], m.columns[other] = m.columns[other], m.columns[index]
+	m.visible[index], m.visible[other] = m.visible[other], m.visible[index]
+
+	m.PublishRowsReset()
+
+	return true
+}
+
+// ColumnChooserEnabled returns if the context menu of the header of the
+// *TableView offers to show or hide columns and to open the column chooser
+// dialog.
+func (tv *TableView) ColumnChooserEnabled() bool {
+	return tv.columnChooserEnabled
+}
+
+// SetColumnChooserEnabled sets if the context menu of the header of the
+// *TableView offers to show or hide columns and to open the column chooser
+// dialog.
+func (tv *TableView) SetColumnChooserEnabled(enabled bool) {
+	tv.columnChooserEnabled = enabled
+}
+
+// RunColumnChooser runs a dialog, which lets the user choose the visible
+// columns of the *TableView and their order, and returns if the user accepted
+// it.
+//
+// If the *TableView is persistent, its state is saved right away.
+func (tv *TableView) RunColumnChooser() (accepted bool, err error) {
+	model := new(columnChooserModel)
+
+	for _, tvc := range tv.orderedColumns() {
+		model.columns = append(model.columns, tvc)
+		model.visible = append(model.visible, tvc.Visible())
+	}
+
+	dlg, err := NewDialog(tv.RootWidget())
+	if err != nil {
+		return false, err
+	}
+	defer dlg.Dispose()
+
+	if err := dlg.SetTitle(tr("Choose Columns", "walk")); err != nil {
+		return false, err
+	}
+
+	layout := NewGridLayout()
+	if err := dlg.SetLayout(layout); err != nil {
+		return false, err
+	}
+
+	label, err := NewLabel(dlg)
+	if err != nil {
+		return false, err
+	}
+	if err := label.SetText(tr("Select the columns to show and their order:", "walk")); err != nil {
+		return false, err
+	}
+
+	list, err := NewTableView(dlg)
+	if err != nil {
+		return false, err
+	}
+	list.SetCheckBoxes(true)
+	if err := list.SetSingleItemSelection(true); err != nil {
+		return false, err
+	}
+
+	col := NewTableViewColumn()
+	if err := col.SetTitle(tr("Column", "walk")); err != nil {
+		return false, err
+	}
+	if err := list.Columns().Add(col); err != nil {
+		return false, err
+	}
+	if err := list.SetLastColumnStretched(true); err != nil {
+		return false, err
+	}
+	if err := list.SetModel(model); err != nil {
+		return false, err
+	}
+
+	upPB, err := NewPushButton(dlg)
+	if err != nil {
+		return false, err
+	}
+	if err := upPB.SetText(tr("Move &Up", "walk")); err != nil {
+		return false, err
+	}
+
+	downPB, err := NewPushButton(dlg)
+	if err != nil {
+		return false, err
+	}
+	if err := downPB.SetText(tr("Move &Down", "walk")); err != nil {
+		return false, err
+	}
+
+	vSpacer, err := NewVSpacer(dlg)
+	if err != nil {
+		return false, err
+	}
+
+	buttons, err := NewComposite(dlg)
+	if err != nil {
+		return false, err
+	}
+	hbox := NewHBoxLayout()
+	if err := buttons.SetLayout(hbox); err != nil {
+		return false, err
+	}
+	if err := hbox.SetMargins(Margins{}); err != nil {
+		return false, err
+	}
+
+	if _, err := NewHSpacer(buttons); err != nil {
+		return false, err
+	}
+
+	okPB, err := NewPushButton(buttons)
+	if err != nil {
+		return false, err
+	}
+	if err := okPB.SetText(tr("OK", "walk")); err != nil {
+		return false, err
+	}
+
+	cancelPB, err := NewPushButton(buttons)
+	if err != nil {
+		return false, err
+	}
+	if err := cancelPB.SetText(tr("Cancel", "walk")); err != nil {
+		return false, err
+	}
+
+	for w, r := range map[Widget]Rectangle{
+		label:   {0, 0, 2, 1},
+		list:    {0, 1, 1, 3},
+		upPB:    {1, 1, 1, 1},
+		downPB:  {1, 2, 1, 1},
+		vSpacer: {1, 3, 1, 1},
+		buttons: {0, 4, 2, 1},
+	} {
+		if err := layout.SetRange(w, r); err != nil {
+			return false, err
+		}
+	}
+
+	updateButtons := func() {
+		index := list.CurrentIndex()
+
+		upPB.SetEnabled(index > 0)
+		downPB.SetEnabled(index > -1 && index < len(model.columns)-1)
+		okPB.SetEnabled(model.anyVisible())
+	}
+	model.checkedChanged = updateButtons
+	list.CurrentIndexChanged().Attach(updateButtons)
+
+	move := func(delta int) {
+		index := list.CurrentIndex()
+
+		if model.move(index, delta) {
+			list.SetCurrentIndex(index + delta)
+		}
+
+		updateButtons()
+	}
+	upPB.Clicked().Attach(func() {
+		move(-1)
+	})
+	downPB.Clicked().Attach(func() {
+		move(1)
+	})
+
+	okPB.Clicked().Attach(func() {
+		dlg.Accept()
+	})
+	cancelPB.Clicked().Attach(func() {
+		dlg.Cancel()
+	})
+
+	if err := dlg.SetDefaultButton(okPB); err != nil {
+		return false, err
+	}
+	if err := dlg.SetCancelButton(cancelPB); err != nil {
+		return false, err
+	}
+
+	updateButtons()
+
+	if dlg.Run() != DlgCmdOK {
+		return false, nil
+	}
+
+	if err := tv.applyColumnChoice(model.columns, model.visible); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// orderedColumns returns the visible columns in display order, followed by
+// the hidden ones.
+func (tv *TableView) orderedColumns() []*TableViewColumn {
+	var cols []*TableViewColumn
+
+	if tv.visibleColumnCount() > 0 {
+		cols = tv.VisibleColumnsInDisplayOrder()
+	}
+
+	for _, tvc := range tv.columns.items {
+		if !tvc.visible {
+			cols = append(cols, tvc)
+		}
+	}
+
+	return cols
+}
+
+// applyColumnChoice sets the visibility of the columns and orders the visible
+// ones like cols.
+func (tv *TableView) applyColumnChoice(cols []*TableViewColumn, visible []bool) error {
+	tv.SetSuspended(true)
+	defer tv.SetSuspended(false)
+
+	for i, tvc := range cols {
+		if err := tvc.SetVisible(visible[i]); err != nil {
+			return err
+		}
+	}
+
+	var indices []int32
+	for i, tvc := range cols {
+		if visible[i] {
+			indices = append(indices, tvc.indexInListView())
+		}
+	}
+
+	if len(indices) > 0 {
+		if 0 == tv.SendMessage(LVM_SETCOLUMNORDERARRAY, uintptr(len(indices)), uintptr(unsafe.Pointer(&indices[0]))) {
+			return newAPIError("SendMessage(LVM_SETCOLUMNORDERARRAY)")
+		}
+	}
+
+	if tv.persistent {
+		return tv.SaveState()
+	}
+
+	return nil
+}
+
+// showHeaderContextMenu shows the context menu of the header, which toggles
+// the visibility of the columns and opens the column chooser dialog.
+func (tv *TableView) showHeaderContextMenu(x, y int) error {
+	menu, err := NewMenu()
+	if err != nil {
+		return err
+	}
+	defer menu.Dispose()
+
+	action2Column := make(map[*Action]*TableViewColumn)
+
+	for _, tvc := range tv.orderedColumns() {
+		action := NewAction()
+		if err := action.SetText(tvc.TitleEffective()); err != nil {
+			return err
+		}
+		if err := action.SetCheckable(true); err != nil {
+			return err
+		}
+		if err := action.SetChecked(tvc.Visible()); err != nil {
+			return err
+		}
+		if tvc.Visible() && tv.visibleColumnCount() == 1 {
+			// The last visible column can not be hidden.
+			if err := action.SetEnabled(false); err != nil {
+				return err
+			}
+		}
+		if err := menu.Actions().Add(action); err != nil {
+			return err
+		}
+
+		action2Column[action] = tvc
+	}
+
+	separator := NewAction()
+	if err := separator.SetText("-"); err != nil {
+		return err
+	}
+	if err := menu.Actions().Add(separator); err != nil {
+		return err
+	}
+
+	chooserAction := NewAction()
+	if err := chooserAction.SetText(tr("Choose Columns...", "walk")); err != nil {
+		return err
+	}
+	if err := menu.Actions().Add(chooserAction); err != nil {
+		return err
+	}
+
+	action, err := menu.Exec(x, y)
+	if err != nil || action == nil {
+		return err
+	}
+
+	if action == chooserAction {
+		_, err := tv.RunColumnChooser()
+		return err
+	}
+
+	tvc := action2Column[action]
+	if tvc == nil {
+		return nil
+	}
+
+	if err := tvc.SetVisible(!tvc.Visible()); err != nil {
+		return err
+	}
+
+	if tv.persistent {
+		return tv.SaveState()
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// CellError reports that a value could not be stored in a cell of a
+// TableView.
+type CellError struct {
+	Row  int
+	Col  int
+	Text string
+	Err  error
+}
+
+func (ce *CellError) Error() string {
+	return fmt.Sprintf("row %d, column %d: %q: %s", ce.Row, ce.Col, ce.Text, ce.Err)
+}
+
+// CellErrors is the error returned by TableView.Paste and TableView.PasteText,
+// if some of the values could not be stored. The other values were stored.
+type CellErrors []*CellError
+
+func (ce CellErrors) Error() string {
+	if len(ce) == 1 {
+		return ce[0].Error()
+	}
+
+	return fmt.Sprintf("%s (and %d more errors)", ce[0].Error(), len(ce)-1)
+}
+
+// Editable returns if the model of the *TableView implements CellValueSetter,
+// so values can be pasted.
+func (tv *TableView) Editable() bool {
+	return tv.cellValueSetter() != nil
+}
+
+func (tv *TableView) cellValueSetter() CellValueSetter {
+	if cvs, ok := tv.providedModel.(CellValueSetter); ok {
+		return cvs
+	}
+
+	cvs, _ := tv.model.(CellValueSetter)
+
+	return cvs
+}
+
+func (tv *TableView) rowAppender() RowAppender {
+	if ra, ok := tv.providedModel.(RowAppender); ok {
+		return ra
+	}
+
+	ra, _ := tv.model.(RowAppender)
+
+	return ra
+}
+
+// CurrentColumn returns the index of the column of the current cell, which
+// is the one the user clicked last, or -1 if there is none.
+func (tv *TableView) CurrentColumn() int {
+	return tv.currentColumn
+}
+
+// Paste stores the tab or comma separated values on the clipboard in the
+// cells starting at the current cell, like spreadsheet applications do.
+//
+// The values are converted by the CellValueSetter of the model. Rows are added
+// via its RowAppender, if it implements one, values beyond the last row are
+// ignored otherwise. Values beyond the last visible column are ignored.
+//
+// If some values could not be converted, the others are stored anyway and the
+// returned error is a CellErrors.
+func (tv *TableView) Paste() error {
+	text, err := clipboardText()
+	if err != nil {
+		return err
+	}
+
+	return tv.PasteText(text)
+}
+
+// PasteText stores the tab or comma separated values of text in the cells
+// starting at the current cell. See Paste for details.
+func (tv *TableView) PasteText(text string) error {
+	row := tv.currentIndex
+	if row < 0 {
+		row = 0
+	}
+
+	col := tv.currentColumn
+	if col < 0 || col >= len(tv.columns.items) || !tv.columns.items[col].visible {
+		col = -1
+	}
+
+	return tv.PasteTextAt(text, row, col)
+}
+
+// PasteTextAt stores the tab or comma separated values of text in the cells
+// starting at row and column col. If col is -1, the values start at the first
+// visible column. See Paste for details.
+func (tv *TableView) PasteTextAt(text string, row, col int) error {
+	setter := tv.cellValueSetter()
+	if setter == nil {
+		return newError("model does not implement CellValueSetter")
+	}
+
+	if row < 0 || row > tv.model.RowCount() {
+		return newError("invalid row")
+	}
+
+	records, err := parseTabularText(text)
+	if err != nil {
+		return wrapError(err)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	cols := tv.pasteColumns(col)
+	if cols == nil {
+		return newError("invalid column")
+	}
+
+	if rowCount := tv.model.RowCount(); row+len(records) > rowCount {
+		if appender := tv.rowAppender(); appender != nil {
+			if err := appender.AppendRows(row + len(records) - rowCount); err != nil {
+				return wrapError(err)
+			}
+
+			if err := tv.setItemCount(); err != nil {
+				return err
+			}
+		}
+
+		if rowCount = tv.model.RowCount(); row+len(records) > rowCount {
+			records = records[:rowCount-row]
+		}
+	}
+
+	var cellErrs CellErrors
+
+	for i, record := range records {
+		for j, text := range record {
+			if j == len(cols) {
+				break
+			}
+
+			if err := setter.SetCellValue(row+i, cols[j], text); err != nil {
+				cellErrs = append(cellErrs, &CellError{row + i, cols[j], text, err})
+			}
+		}
+	}
+
+	if len(records) > 0 {
+		if FALSE == tv.SendMessage(LVM_REDRAWITEMS, uintptr(row), uintptr(row+len(records)-1)) {
+			return newAPIError("SendMessage(LVM_REDRAWITEMS)")
+		}
+	}
+
+	if cellErrs != nil {
+		return cellErrs
+	}
+
+	return nil
+}
+
+// PasteFailed returns the event that is published, when pasting with Ctrl+V
+// failed. If only some values could not be stored, the error is a CellErrors.
+func (tv *TableView) PasteFailed() *ErrorEvent {
+	return tv.pasteFailedPublisher.Event()
+}
+
+// pasteColumns returns the model indexes of the visible columns in display
+// order, starting at the column with model index col, or at the first visible
+// one if col is -1.
+func (tv *TableView) pasteColumns(col int) []int {
+	var cols []int
+
+	for _, tvc := range tv.VisibleColumnsInDisplayOrder() {
+		idx := tv.columns.Index(tvc)
+
+		if idx == col {
+			cols = cols[:0]
+			col = -1
+		}
+
+		cols = append(cols, idx)
+	}
+
+	if col != -1 {
+		return nil
+	}
+
+	return cols
+}
+
+// parseTabularText parses the rows of tab separated values, as copied from
+// spreadsheet applications, or, if text contains no tabs, of comma separated
+// values.
+func parseTabularText(text string) ([][]string, error) {
+	text = strings.TrimRight(text, "\r\n")
+	if text == "" {
+		return nil, nil
+	}
+
+	r := csv.NewReader(bytes.NewBufferString(text))
+	if strings.Contains(text, "\t") {
+		r.Comma = '\t'
+	}
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	return r.ReadAll()
+}
@@ -21,12 +21,14 @@ func init() {
 type TabPage struct {
 	ContainerBase
 	title                 string
+	image                 Image
+	closable              bool
 	tabWidget             *TabWidget
 	titleChangedPublisher EventPublisher
 }
 
 func NewTabPage() (*TabPage, error) {
-	tp := &TabPage{}
+	tp := &TabPage{closable: true}
 
 	if err := InitWidget(
 		tp,
@@ -87,6 +89,42 @@ func (tp *TabPage) SetTitle(value string) error {
 	return tp.tabWidget.onPageChanged(tp)
 }
 
+// Image returns the image shown in the tab of the *TabPage, left of its
+// title.
+func (tp *TabPage) Image() Image {
+	return tp.image
+}
+
+// SetImage sets the image shown in the tab of the *TabPage, left of its
+// title. It is scaled to the size of a small icon.
+func (tp *TabPage) SetImage(value Image) error {
+	tp.image = value
+
+	if tp.tabWidget == nil {
+		return nil
+	}
+
+	tp.tabWidget.updateTabStyle()
+
+	return nil
+}
+
+// Closable returns if the tab of the *TabPage shows a close button, when the
+// TabWidget has closable tabs. The default is true.
+func (tp *TabPage) Closable() bool {
+	return tp.closable
+}
+
+// SetClosable sets if the tab of the *TabPage shows a close button, when the
+// TabWidget has closable tabs, e.g. to keep a start page open.
+func (tp *TabPage) SetClosable(value bool) {
+	tp.closable = value
+
+	if tp.tabWidget != nil {
+		InvalidateRect(tp.tabWidget.hWndTab, nil, true)
+	}
+}
+
 func (tp *TabPage) tcItem() *TCITEM {
 	text := syscall.StringToUTF16(tp.Title())
 
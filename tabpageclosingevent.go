@@ -0,0 +1,59 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+type TabPageClosingEventHandler func(page *TabPage, canceled *bool)
+
+type TabPageClosingEvent struct {
+	handlers   []TabPageClosingEventHandler
+	priorities []int
+}
+
+func (e *TabPageClosingEvent) Attach(handler TabPageClosingEventHandler) int {
+	return e.AttachWithPriority(handler, 0)
+}
+
+// AttachWithPriority attaches handler, so that it is called before all
+// handlers with a lower priority.
+//
+// Handlers of equal priority are called in the same order as with Attach. As
+// soon as a handler cancels, the remaining handlers are not called.
+func (e *TabPageClosingEvent) AttachWithPriority(handler TabPageClosingEventHandler, priority int) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			e.priorities[i] = priority
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	e.priorities = append(e.priorities, priority)
+	return len(e.handlers) - 1
+}
+
+func (e *TabPageClosingEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type TabPageClosingEventPublisher struct {
+	event TabPageClosingEvent
+}
+
+func (p *TabPageClosingEventPublisher) Event() *TabPageClosingEvent {
+	return &p.event
+}
+
+func (p *TabPageClosingEventPublisher) Publish(page *TabPage, canceled *bool) {
+	for _, i := range handlerIndicesByPriority(p.event.priorities) {
+		if handler := p.event.handlers[i]; handler != nil {
+			handler(page, canceled)
+
+			if *canceled {
+				return
+			}
+		}
+	}
+}
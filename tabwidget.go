@@ -21,14 +21,23 @@ func init() {
 type TabWidget struct {
 	WidgetBase
 	hWndTab                      HWND
+	hWndNewTab                   HWND
+	origTabWndProcPtr            uintptr
 	pages                        *TabPageList
 	currentIndex                 int
 	currentIndexChangedPublisher EventPublisher
+	pageClosingPublisher         TabPageClosingEventPublisher
+	newTabRequestedPublisher     EventPublisher
 	persistent                   bool
+	tabsClosable                 bool
+	pressedCloseIndex            int
+	dragIndex                    int
+	dragStart                    POINT
+	dragging                     bool
 }
 
 func NewTabWidget(parent Container) (*TabWidget, error) {
-	tw := &TabWidget{currentIndex: -1}
+	tw := &TabWidget{currentIndex: -1, pressedCloseIndex: -1, dragIndex: -1}
 	tw.pages = newTabPageList(tw)
 
 	if err := InitChildWidget(
@@ -58,6 +67,9 @@ func NewTabWidget(parent Container) (*TabWidget, error) {
 	}
 	SendMessage(tw.hWndTab, WM_SETFONT, uintptr(defaultFont.handleForDPI(0)), 1)
 
+	tw.origTabWndProcPtr = SetWindowLongPtr(tw.hWndTab, GWLP_WNDPROC, tabWidgetTabWndProcPtr)
+	tabWidgetsByTabHWnd[tw.hWndTab] = tw
+
 	tw.MustRegisterProperty("HasCurrentPage", NewReadOnlyBoolProperty(
 		func() bool {
 			return tw.CurrentIndex() != -1
@@ -120,6 +132,10 @@ func (tw *TabWidget) SetFont(f *Font) {
 	tw.WidgetBase.SetFont(f)
 
 	setDescendantsFont(tw, f)
+
+	if tw.hWndNewTab != 0 {
+		setWidgetFont(tw.hWndNewTab, tw.Font())
+	}
 }
 
 func (tw *TabWidget) CurrentIndex() int {
@@ -137,7 +153,7 @@ func (tw *TabWidget) SetCurrentIndex(index int) error {
 
 	ret := int(SendMessage(tw.hWndTab, TCM_SETCURSEL, uintptr(index), 0))
 	if ret == -1 {
-		return newError("SendMessage(TCM_SETCURSEL) failed")
+		return newAPIError("SendMessage(TCM_SETCURSEL)")
 	}
 
 	// FIXME: The SendMessage(TCM_SETCURSEL) call above doesn't cause a
@@ -147,6 +163,20 @@ func (tw *TabWidget) SetCurrentIndex(index int) error {
 	return nil
 }
 
+func (tw *TabWidget) revealChild(child Widget) error {
+	page, ok := child.(*TabPage)
+	if !ok {
+		return nil
+	}
+
+	index := tw.pages.Index(page)
+	if index == -1 {
+		return nil
+	}
+
+	return tw.SetCurrentIndex(index)
+}
+
 func (tw *TabWidget) CurrentIndexChanged() *Event {
 	return tw.currentIndexChangedPublisher.Event()
 }
@@ -215,7 +245,7 @@ func (tw *TabWidget) resizePages() {
 		r.Top,
 	}
 	if !ScreenToClient(tw.hWnd, &p) {
-		newError("ScreenToClient failed")
+		newAPIError("ScreenToClient")
 		return
 	}
 
@@ -249,6 +279,7 @@ func (tw *TabWidget) onResize(lParam uintptr) {
 	}
 
 	tw.resizePages()
+	tw.updateNewTabButtonBounds()
 }
 
 func (tw *TabWidget) onSelChange() {
@@ -283,6 +314,19 @@ func (tw *TabWidget) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uint
 			case TCN_SELCHANGE:
 				tw.onSelChange()
 			}
+
+		case WM_DRAWITEM:
+			dis := (*DRAWITEMSTRUCT)(unsafe.Pointer(lParam))
+			if dis.HwndItem == tw.hWndTab {
+				tw.drawTab(dis)
+				return 1
+			}
+
+		case WM_COMMAND:
+			if tw.hWndNewTab != 0 && HWND(lParam) == tw.hWndNewTab && HIWORD(uint32(wParam)) == BN_CLICKED {
+				tw.newTabRequestedPublisher.Publish()
+				return 0
+			}
 		}
 	}
 
@@ -294,7 +338,7 @@ func (tw *TabWidget) onPageChanged(page *TabPage) (err error) {
 	item := page.tcItem()
 
 	if 0 == SendMessage(tw.hWndTab, TCM_SETITEM, uintptr(index), uintptr(unsafe.Pointer(item))) {
-		return newError("SendMessage(TCM_SETITEM) failed")
+		return newAPIError("SendMessage(TCM_SETITEM)")
 	}
 
 	return nil
@@ -308,7 +352,7 @@ func (tw *TabWidget) onInsertedPage(index int, page *TabPage) (err error) {
 	item := page.tcItem()
 
 	if idx := int(SendMessage(tw.hWndTab, TCM_INSERTITEM, uintptr(index), uintptr(unsafe.Pointer(item)))); idx == -1 {
-		return newError("SendMessage(TCM_INSERTITEM) failed")
+		return newAPIError("SendMessage(TCM_INSERTITEM)")
 	}
 
 	page.SetVisible(false)
@@ -335,10 +379,10 @@ func (tw *TabWidget) onInsertedPage(index int, page *TabPage) (err error) {
 		tw.SetCurrentIndex(0)
 	}
 
-	tw.resizePages()
-
 	page.tabWidget = tw
 
+	tw.updateTabStyle()
+
 	return
 }
 
@@ -375,6 +419,8 @@ func (tw *TabWidget) onRemovedPage(index int, page *TabPage) (err error) {
 
 	SendMessage(tw.hWndTab, TCM_DELETEITEM, uintptr(index), 0)
 
+	tw.updateTabStyle()
+
 	if tw.pages.Len() > 0 {
 		tw.currentIndex = 0
 		SendMessage(tw.hWndTab, TCM_SETCURSEL, uintptr(tw.currentIndex), 0)
@@ -416,5 +462,8 @@ func (tw *TabWidget) onClearedPages(pages []*TabPage) (err error) {
 		tw.removePage(page)
 	}
 	tw.currentIndex = -1
+
+	tw.updateTabStyle()
+
 	return nil
 }
@@ -0,0 +1,444 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	tcsOwnerDrawFixed = 0x2000
+
+	tcmFirst       = 0x1300
+	tcmGetItemRect = tcmFirst + 10
+	tcmHitTest     = tcmFirst + 13
+	tcmSetPadding  = tcmFirst + 43
+)
+
+const (
+	tabWidgetCloseButtonSize = 14
+	tabWidgetImageSize       = 16
+	tabWidgetItemSpacing     = 4
+	tabWidgetDefaultPadding  = 6
+)
+
+type tcHitTestInfo struct {
+	Pt    POINT
+	Flags uint32
+}
+
+var (
+	tabWidgetTabWndProcPtr = syscall.NewCallback(tabWidgetTabWndProc)
+	tabWidgetsByTabHWnd    = make(map[HWND]*TabWidget)
+)
+
+// TabsClosable returns if the tabs of the *TabWidget show close buttons.
+func (tw *TabWidget) TabsClosable() bool {
+	return tw.tabsClosable
+}
+
+// SetTabsClosable sets if the tabs of the *TabWidget show close buttons. Pages
+// can opt out with TabPage.SetClosable.
+//
+// Clicking the close button or clicking a tab with the middle mouse button
+// closes the page, unless a PageClosing handler cancels.
+func (tw *TabWidget) SetTabsClosable(value bool) {
+	if value == tw.tabsClosable {
+		return
+	}
+
+	tw.tabsClosable = value
+
+	tw.updateTabStyle()
+}
+
+// PageClosing returns the event that is published, before the user closes a
+// page of the *TabWidget. Handlers may cancel closing, e.g. to ask whether to
+// save changes first.
+func (tw *TabWidget) PageClosing() *TabPageClosingEvent {
+	return tw.pageClosingPublisher.Event()
+}
+
+// ClosePage closes page like its close button does: PageClosing is published
+// and, if no handler cancels, page is removed and disposed. The page to the
+// right becomes current, or to the left, if page was the rightmost one.
+func (tw *TabWidget) ClosePage(page *TabPage) (closed bool, err error) {
+	index := tw.pages.Index(page)
+	if index == -1 {
+		return false, newError("page not found")
+	}
+
+	var canceled bool
+	tw.pageClosingPublisher.Publish(page, &canceled)
+	if canceled {
+		return false, nil
+	}
+
+	wasCurrent := index == tw.currentIndex
+
+	if err := tw.pages.RemoveAt(index); err != nil {
+		return false, err
+	}
+
+	page.Dispose()
+
+	if wasCurrent && tw.pages.Len() > 0 {
+		if err := tw.SetCurrentIndex(mini(index, tw.pages.Len()-1)); err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+// MovePage moves the page at index from to index to, like dragging its tab
+// does.
+func (tw *TabWidget) MovePage(from, to int) error {
+	count := tw.pages.Len()
+	if from < 0 || from >= count || to < 0 || to >= count {
+		return newError("invalid index")
+	}
+	if from == to {
+		return nil
+	}
+
+	var current *TabPage
+	if tw.currentIndex > -1 {
+		current = tw.pages.items[tw.currentIndex]
+	}
+
+	page := tw.pages.items[from]
+	items := append(tw.pages.items[:from], tw.pages.items[from+1:]...)
+	tw.pages.items = items
+	tw.pages.insertIntoSlice(to, page)
+
+	SendMessage(tw.hWndTab, TCM_DELETEITEM, uintptr(from), 0)
+	if idx := int(SendMessage(tw.hWndTab, TCM_INSERTITEM, uintptr(to), uintptr(unsafe.Pointer(page.tcItem())))); idx == -1 {
+		return newAPIError("SendMessage(TCM_INSERTITEM)")
+	}
+
+	if current != nil {
+		// This does not cause TCN_SELCHANGE and the current page stays the same.
+		tw.currentIndex = tw.pages.Index(current)
+		SendMessage(tw.hWndTab, TCM_SETCURSEL, uintptr(tw.currentIndex), 0)
+	}
+
+	tw.updateNewTabButtonBounds()
+
+	return nil
+}
+
+// NewTabButtonVisible returns if a button for adding a tab is shown right of
+// the last tab.
+func (tw *TabWidget) NewTabButtonVisible() bool {
+	return tw.hWndNewTab != 0
+}
+
+// SetNewTabButtonVisible sets if a button for adding a tab is shown right of
+// the last tab. Clicking it publishes the NewTabRequested event, the
+// *TabWidget does not add a page itself.
+func (tw *TabWidget) SetNewTabButtonVisible(value bool) error {
+	if value == tw.NewTabButtonVisible() {
+		return nil
+	}
+
+	if !value {
+		if !DestroyWindow(tw.hWndNewTab) {
+			return lastError("DestroyWindow")
+		}
+		tw.hWndNewTab = 0
+
+		return nil
+	}
+
+	tw.hWndNewTab = CreateWindowEx(
+		0, syscall.StringToUTF16Ptr("BUTTON"), syscall.StringToUTF16Ptr("+"),
+		WS_CHILD|WS_VISIBLE|BS_PUSHBUTTON|BS_FLAT,
+		0, 0, 0, 0, tw.hWnd, 0, 0, nil)
+	if tw.hWndNewTab == 0 {
+		return lastError("CreateWindowEx(BUTTON)")
+	}
+	setWidgetFont(tw.hWndNewTab, tw.Font())
+
+	// The button must be above the tab control, which clips it.
+	SetWindowPos(tw.hWndNewTab, HWND_TOP, 0, 0, 0, 0, SWP_NOMOVE|SWP_NOSIZE)
+
+	tw.updateNewTabButtonBounds()
+
+	return nil
+}
+
+// NewTabRequested returns the event that is published, when the user clicks
+// the new tab button.
+func (tw *TabWidget) NewTabRequested() *Event {
+	return tw.newTabRequestedPublisher.Event()
+}
+
+// updateNewTabButtonBounds places the new tab button right of the last tab.
+func (tw *TabWidget) updateNewTabButtonBounds() {
+	if tw.hWndNewTab == 0 {
+		return
+	}
+
+	var r RECT
+	if count := tw.pages.Len(); count > 0 {
+		SendMessage(tw.hWndTab, tcmGetItemRect, uintptr(count-1), uintptr(unsafe.Pointer(&r)))
+	} else {
+		r.Bottom = int32(tw.dialogBaseUnitsToPixels(Size{0, 12}).Height)
+	}
+
+	size := r.Bottom - r.Top
+	x := r.Right + int32(tw.IntFromDIP(tabWidgetItemSpacing))
+	if width := int32(tw.ClientBounds().Width); x+size > width {
+		x = width - size
+	}
+
+	if !MoveWindow(tw.hWndNewTab, x, r.Top, size, size, true) {
+		lastError("MoveWindow")
+	}
+}
+
+// ownerDrawsTabs returns if the tabs need to be drawn by the *TabWidget,
+// because they have close buttons or images.
+func (tw *TabWidget) ownerDrawsTabs() bool {
+	if tw.tabsClosable {
+		return true
+	}
+
+	for _, page := range tw.pages.items {
+		if page.image != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// updateTabStyle switches owner drawing of the tabs on or off and makes room
+// for the close buttons and images.
+func (tw *TabWidget) updateTabStyle() {
+	if tw.hWndTab == 0 {
+		return
+	}
+
+	ownerDraw := tw.ownerDrawsTabs()
+
+	style := uint32(GetWindowLong(tw.hWndTab, GWL_STYLE))
+	if ownerDraw {
+		style |= tcsOwnerDrawFixed
+	} else {
+		style &^= tcsOwnerDrawFixed
+	}
+	SetWindowLong(tw.hWndTab, GWL_STYLE, int32(style))
+
+	// The padding is added at both sides of the title, so it is as wide as
+	// the wider of the image and the close button.
+	var extra int
+	if ownerDraw {
+		if tw.tabsClosable {
+			extra = tabWidgetCloseButtonSize + tabWidgetItemSpacing
+		}
+		for _, page := range tw.pages.items {
+			if page.image != nil {
+				extra = maxi(extra, tabWidgetImageSize+tabWidgetItemSpacing)
+				break
+			}
+		}
+	}
+
+	padding := tw.IntFromDIP(tabWidgetDefaultPadding + extra)
+	SendMessage(tw.hWndTab, tcmSetPadding, 0, uintptr(MAKELONG(uint16(padding), uint16(tw.IntFromDIP(3)))))
+
+	// The tab control measures the tabs again, when their items change.
+	for i, page := range tw.pages.items {
+		SendMessage(tw.hWndTab, TCM_SETITEM, uintptr(i), uintptr(unsafe.Pointer(page.tcItem())))
+	}
+
+	InvalidateRect(tw.hWndTab, nil, true)
+
+	tw.resizePages()
+	tw.updateNewTabButtonBounds()
+}
+
+// pageClosable returns if the tab at index shows a close button.
+func (tw *TabWidget) pageClosable(index int) bool {
+	return tw.tabsClosable && index > -1 && index < tw.pages.Len() && tw.pages.items[index].closable
+}
+
+// tabAt returns the index of the tab at the point in client coordinates of
+// the tab control, or -1 if there is none.
+func (tw *TabWidget) tabAt(x, y int32) int {
+	hti := tcHitTestInfo{Pt: POINT{x, y}}
+
+	return int(int32(SendMessage(tw.hWndTab, tcmHitTest, 0, uintptr(unsafe.Pointer(&hti)))))
+}
+
+// closeButtonBounds returns the bounds of the close button within the tab
+// bounds r.
+func (tw *TabWidget) closeButtonBounds(r RECT) Rectangle {
+	size := tw.IntFromDIP(tabWidgetCloseButtonSize)
+
+	return Rectangle{
+		int(r.Right) - tw.IntFromDIP(tabWidgetDefaultPadding) - size,
+		int(r.Top+r.Bottom)/2 - size/2,
+		size,
+		size,
+	}
+}
+
+// closeButtonAt returns the index of the tab, whose close button is at the
+// point in client coordinates of the tab control, or -1 if there is none.
+func (tw *TabWidget) closeButtonAt(x, y int32) int {
+	index := tw.tabAt(x, y)
+	if !tw.pageClosable(index) {
+		return -1
+	}
+
+	var r RECT
+	SendMessage(tw.hWndTab, tcmGetItemRect, uintptr(index), uintptr(unsafe.Pointer(&r)))
+
+	b := tw.closeButtonBounds(r)
+	if int(x) < b.X || int(x) >= b.X+b.Width || int(y) < b.Y || int(y) >= b.Y+b.Height {
+		return -1
+	}
+
+	return index
+}
+
+func (tw *TabWidget) drawTab(dis *DRAWITEMSTRUCT) {
+	index := int(int32(dis.ItemID))
+	if index < 0 || index >= tw.pages.Len() {
+		return
+	}
+	page := tw.pages.items[index]
+
+	canvas, err := newCanvasFromHDC(dis.HDC)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	palette := appSingleton.Palette()
+
+	color := palette.ControlText
+	if dis.ItemState&ODS_DISABLED != 0 || !page.Enabled() {
+		color = palette.DisabledText
+	}
+
+	bounds := rectangleFromRECT(dis.RcItem)
+	padding := tw.IntFromDIP(tabWidgetDefaultPadding)
+	spacing := tw.IntFromDIP(tabWidgetItemSpacing)
+
+	bounds.X += padding
+	bounds.Width -= 2 * padding
+
+	if page.image != nil {
+		size := tw.IntFromDIP(tabWidgetImageSize)
+
+		canvas.DrawImageStretched(page.image, Rectangle{bounds.X, bounds.Y + (bounds.Height-size)/2, size, size})
+
+		bounds.X += size + spacing
+		bounds.Width -= size + spacing
+	}
+
+	if tw.pageClosable(index) {
+		cbb := tw.closeButtonBounds(dis.RcItem)
+
+		pen, err := NewCosmeticPen(PenSolid, color)
+		if err == nil {
+			defer pen.Dispose()
+
+			inset := cbb.Width / 4
+			canvas.DrawLine(pen, Point{cbb.X + inset, cbb.Y + inset}, Point{cbb.X + cbb.Width - inset, cbb.Y + cbb.Height - inset})
+			canvas.DrawLine(pen, Point{cbb.X + cbb.Width - inset, cbb.Y + inset}, Point{cbb.X + inset, cbb.Y + cbb.Height - inset})
+		}
+
+		bounds.Width = cbb.X - spacing - bounds.X
+	}
+
+	canvas.DrawText(page.Title(), tw.Font(), color, bounds, TextSingleLine|TextVCenter|TextCenter|TextEndEllipsis|TextNoPrefix)
+}
+
+// tabWidgetTabWndProc handles close buttons and dragging of tabs in the tab
+// control of a *TabWidget.
+func tabWidgetTabWndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	tw := tabWidgetsByTabHWnd[hwnd]
+	if tw == nil {
+		return DefWindowProc(hwnd, msg, wParam, lParam)
+	}
+
+	x, y := GET_X_LPARAM(lParam), GET_Y_LPARAM(lParam)
+
+	switch msg {
+	case WM_LBUTTONDOWN:
+		if index := tw.closeButtonAt(x, y); index != -1 {
+			tw.pressedCloseIndex = index
+			SetCapture(hwnd)
+			return 0
+		}
+
+		tw.dragIndex = tw.tabAt(x, y)
+		tw.dragStart = POINT{x, y}
+		tw.dragging = false
+
+	case WM_MOUSEMOVE:
+		if tw.dragIndex == -1 || wParam&MK_LBUTTON == 0 {
+			break
+		}
+
+		if !tw.dragging {
+			if absi(int(x-tw.dragStart.X)) < int(GetSystemMetrics(SM_CXDRAG)) {
+				break
+			}
+
+			tw.dragging = true
+			SetCapture(hwnd)
+		}
+
+		if target := tw.tabAt(x, tw.dragStart.Y); target != -1 && target != tw.dragIndex {
+			if err := tw.MovePage(tw.dragIndex, target); err == nil {
+				tw.dragIndex = target
+			}
+		}
+		return 0
+
+	case WM_LBUTTONUP:
+		pressed := tw.pressedCloseIndex
+		tw.pressedCloseIndex = -1
+		tw.dragIndex = -1
+
+		if tw.dragging || pressed != -1 {
+			tw.dragging = false
+			ReleaseCapture()
+		}
+
+		if pressed != -1 && tw.closeButtonAt(x, y) == pressed {
+			tw.ClosePage(tw.pages.items[pressed])
+			return 0
+		}
+
+	case WM_MBUTTONUP:
+		if index := tw.tabAt(x, y); tw.pageClosable(index) {
+			tw.ClosePage(tw.pages.items[index])
+			return 0
+		}
+
+	case WM_CAPTURECHANGED:
+		tw.pressedCloseIndex = -1
+		tw.dragIndex = -1
+		tw.dragging = false
+
+	case WM_NCDESTROY:
+		origWndProcPtr := tw.origTabWndProcPtr
+		delete(tabWidgetsByTabHWnd, hwnd)
+
+		return CallWindowProc(origWndProcPtr, hwnd, msg, wParam, lParam)
+	}
+
+	return CallWindowProc(tw.origTabWndProcPtr, hwnd, msg, wParam, lParam)
+}
@@ -0,0 +1,581 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const tagEditWindowClass = `\o/ Walk_TagEdit_Class \o/`
+
+const (
+	tagEditMargin       = 2
+	tagEditChipPadding  = 4
+	tagEditMinEditWidth = 60
+	tagEditCloseGlyph   = "×"
+)
+
+func init() {
+	MustRegisterWindowClass(tagEditWindowClass)
+}
+
+// TagEdit is an edit, where typed entries become tags, shown as chips with a
+// button to remove them, e.g. for recipients or labels.
+//
+// Return, comma and semicolon turn the text typed into a tag. Backspace or
+// Left at the beginning of the text select the last chip. While a chip is
+// selected, Left and Right move the selection, Delete and Backspace remove
+// the chip and Escape returns to the text.
+type TagEdit struct {
+	WidgetBase
+	edit                 *tagEditInput
+	tags                 []string
+	selectedIndex        int
+	completionModel      ListModel
+	validator            Validator
+	prevTextLen          int
+	completing           bool
+	tagsChangedPublisher EventPublisher
+	tagRejectedPublisher ErrorEventPublisher
+}
+
+// tagEditInput is the edit of a TagEdit, where tags are typed.
+type tagEditInput struct {
+	LineEdit
+	te *TagEdit
+}
+
+func NewTagEdit(parent Container) (*TagEdit, error) {
+	te := &TagEdit{selectedIndex: -1}
+
+	if err := InitChildWidget(
+		te,
+		parent,
+		tagEditWindowClass,
+		WS_VISIBLE,
+		WS_EX_CONTROLPARENT|WS_EX_CLIENTEDGE); err != nil {
+		return nil, err
+	}
+
+	var succeeded bool
+	defer func() {
+		if !succeeded {
+			te.Dispose()
+		}
+	}()
+
+	te.edit = &tagEditInput{te: te}
+	if err := InitWidget(
+		te.edit,
+		te,
+		"EDIT",
+		WS_CHILD|WS_TABSTOP|WS_VISIBLE|ES_AUTOHSCROLL,
+		0); err != nil {
+		return nil, err
+	}
+
+	te.MustRegisterProperty("Tags", NewProperty(
+		func() interface{} {
+			return te.Tags()
+		},
+		func(v interface{}) error {
+			tags, _ := v.([]string)
+			return te.SetTags(tags)
+		},
+		te.tagsChangedPublisher.Event()))
+
+	succeeded = true
+
+	return te, nil
+}
+
+func (*TagEdit) LayoutFlags() LayoutFlags {
+	return ShrinkableHorz | GrowableHorz | GreedyHorz
+}
+
+func (te *TagEdit) MinSizeHint() Size {
+	return Size{
+		te.dialogBaseUnitsToPixels(Size{50, 0}).Width,
+		te.lineHeight() + 2*tagEditMargin + te.borderHeight(),
+	}
+}
+
+func (te *TagEdit) SizeHint() Size {
+	width := te.ClientBounds().Width
+	if width <= 0 {
+		width = te.dialogBaseUnitsToPixels(Size{100, 0}).Width
+	}
+
+	_, _, height := te.chipLayout(width)
+
+	return Size{width, height + te.borderHeight()}
+}
+
+func (te *TagEdit) borderHeight() int {
+	return te.Bounds().Height - te.ClientBounds().Height
+}
+
+func (te *TagEdit) lineHeight() int {
+	return te.dialogBaseUnitsToPixels(Size{0, 10}).Height
+}
+
+func (te *TagEdit) SetEnabled(value bool) {
+	te.edit.SetEnabled(value)
+	te.WidgetBase.SetEnabled(value)
+}
+
+func (te *TagEdit) SetFont(value *Font) {
+	te.WidgetBase.SetFont(value)
+	te.edit.SetFont(value)
+
+	te.updateChildren()
+}
+
+func (te *TagEdit) SetFocus() error {
+	return te.edit.SetFocus()
+}
+
+// Tags returns the tags of the *TagEdit.
+func (te *TagEdit) Tags() []string {
+	return append([]string(nil), te.tags...)
+}
+
+// SetTags sets the tags of the *TagEdit. They are not validated.
+func (te *TagEdit) SetTags(tags []string) error {
+	te.tags = append(te.tags[:0], tags...)
+	te.selectedIndex = -1
+
+	te.tagsChanged()
+
+	return nil
+}
+
+// AddTag validates tag and adds it, unless the *TagEdit has it already.
+func (te *TagEdit) AddTag(tag string) error {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return nil
+	}
+
+	if te.validator != nil {
+		if err := te.validator.Validate(tag); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range te.tags {
+		if t == tag {
+			return nil
+		}
+	}
+
+	te.tags = append(te.tags, tag)
+
+	te.tagsChanged()
+
+	return nil
+}
+
+// RemoveTagAt removes the tag at index.
+func (te *TagEdit) RemoveTagAt(index int) error {
+	if index < 0 || index >= len(te.tags) {
+		return newError("invalid index")
+	}
+
+	te.tags = append(te.tags[:index], te.tags[index+1:]...)
+
+	if te.selectedIndex >= len(te.tags) {
+		te.selectedIndex = len(te.tags) - 1
+	}
+
+	te.tagsChanged()
+
+	return nil
+}
+
+func (te *TagEdit) tagsChanged() {
+	te.updateChildren()
+	te.updateParentLayout()
+
+	te.tagsChangedPublisher.Publish()
+}
+
+// TagsChanged returns the *Event that is published, when tags were added or
+// removed.
+func (te *TagEdit) TagsChanged() *Event {
+	return te.tagsChangedPublisher.Event()
+}
+
+// TagRejected returns the *ErrorEvent that is published with the error of
+// the Validator, when the user typed an invalid tag. The text stays in the
+// edit, so the user can correct it.
+func (te *TagEdit) TagRejected() *ErrorEvent {
+	return te.tagRejectedPublisher.Event()
+}
+
+// Validator returns the Validator, that tags typed by the user must pass.
+func (te *TagEdit) Validator() Validator {
+	return te.validator
+}
+
+// SetValidator sets the Validator, that tags typed by the user must pass. It
+// is called with the tag as string.
+func (te *TagEdit) SetValidator(validator Validator) {
+	te.validator = validator
+}
+
+// CompletionModel returns the ListModel, that provides completions for the
+// text typed.
+func (te *TagEdit) CompletionModel() ListModel {
+	return te.completionModel
+}
+
+// SetCompletionModel sets the ListModel, that provides completions for the
+// text typed. The first item starting with the text, ignoring case, that is
+// not a tag yet, completes it inline.
+func (te *TagEdit) SetCompletionModel(model ListModel) {
+	te.completionModel = model
+}
+
+// commitText turns the text typed into a tag.
+func (te *TagEdit) commitText() {
+	text := te.edit.Text()
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	if err := te.AddTag(text); err != nil {
+		te.edit.SetTextSelection(0, -1)
+		te.tagRejectedPublisher.Publish(err)
+		return
+	}
+
+	te.edit.SetText("")
+}
+
+// complete completes the text typed inline with the first matching item of
+// the completion model, selecting the completed part.
+func (te *TagEdit) complete() {
+	text := te.edit.Text()
+
+	grew := len(text) > te.prevTextLen
+	te.prevTextLen = len(text)
+
+	if te.completing || !grew || te.completionModel == nil || text == "" {
+		return
+	}
+
+	count := te.completionModel.ItemCount()
+	for i := 0; i < count; i++ {
+		item := fmt.Sprint(te.completionModel.Value(i))
+		if len(item) <= len(text) || !strings.EqualFold(item[:len(text)], text) || te.hasTag(item) {
+			continue
+		}
+
+		te.completing = true
+		te.edit.SetText(text + item[len(text):])
+		te.completing = false
+
+		te.prevTextLen = len(text)
+		te.edit.SetTextSelection(len(syscall.StringToUTF16(text))-1, -1)
+		return
+	}
+}
+
+func (te *TagEdit) hasTag(tag string) bool {
+	for _, t := range te.tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SelectedIndex returns the index of the selected chip or -1.
+func (te *TagEdit) SelectedIndex() int {
+	return te.selectedIndex
+}
+
+func (te *TagEdit) setSelectedIndex(index int) {
+	if index >= len(te.tags) {
+		index = -1
+	}
+
+	te.selectedIndex = index
+
+	if index == -1 {
+		te.edit.SetFocus()
+	} else {
+		SetFocus(te.hWnd)
+	}
+
+	te.Invalidate()
+}
+
+func (te *TagEdit) chipWidth(tag string) int {
+	return te.calculateTextSizeImpl(tag).Width +
+		te.calculateTextSizeImpl(tagEditCloseGlyph).Width +
+		3*tagEditChipPadding
+}
+
+// chipLayout returns the bounds of the chips and the edit and the height
+// needed, for a client area of width.
+func (te *TagEdit) chipLayout(width int) (chips []Rectangle, edit Rectangle, height int) {
+	line := te.lineHeight()
+
+	x, y := tagEditMargin, tagEditMargin
+
+	for _, tag := range te.tags {
+		w := te.chipWidth(tag)
+		if x > tagEditMargin && x+w > width-tagEditMargin {
+			x = tagEditMargin
+			y += line + tagEditMargin
+		}
+
+		chips = append(chips, Rectangle{x, y, w, line})
+
+		x += w + tagEditMargin
+	}
+
+	if x > tagEditMargin && width-tagEditMargin-x < tagEditMinEditWidth {
+		x = tagEditMargin
+		y += line + tagEditMargin
+	}
+
+	edit = Rectangle{x, y, maxi(width-tagEditMargin-x, 0), line}
+
+	return chips, edit, y + line + tagEditMargin
+}
+
+// closeBounds returns the bounds of the remove button of the chip at chip.
+func (te *TagEdit) closeBounds(chip Rectangle) Rectangle {
+	w := te.calculateTextSizeImpl(tagEditCloseGlyph).Width + tagEditChipPadding
+
+	return Rectangle{chip.X + chip.Width - w - tagEditChipPadding/2, chip.Y, w, chip.Height}
+}
+
+func (te *TagEdit) updateChildren() {
+	if te.edit == nil {
+		return
+	}
+
+	_, edit, _ := te.chipLayout(te.ClientBounds().Width)
+	te.edit.SetBounds(edit)
+
+	te.Invalidate()
+}
+
+func (te *TagEdit) chipAt(x, y int) (index int, onClose bool) {
+	chips, _, _ := te.chipLayout(te.ClientBounds().Width)
+
+	for i, chip := range chips {
+		if x >= chip.X && x < chip.X+chip.Width && y >= chip.Y && y < chip.Y+chip.Height {
+			cb := te.closeBounds(chip)
+			return i, x >= cb.X
+		}
+	}
+
+	return -1, false
+}
+
+func (te *TagEdit) paint(canvas *Canvas) error {
+	palette := appSingleton.Palette()
+
+	background, err := NewSolidColorBrush(palette.ControlBackground)
+	if err != nil {
+		return err
+	}
+	defer background.Dispose()
+
+	chipBackground, err := NewSolidColorBrush(palette.Background)
+	if err != nil {
+		return err
+	}
+	defer chipBackground.Dispose()
+
+	highlight, err := NewSolidColorBrush(palette.Highlight)
+	if err != nil {
+		return err
+	}
+	defer highlight.Dispose()
+
+	border, err := NewCosmeticPen(PenSolid, palette.Border)
+	if err != nil {
+		return err
+	}
+	defer border.Dispose()
+
+	cb := te.ClientBounds()
+	if err := canvas.FillRectangle(background, cb); err != nil {
+		return err
+	}
+
+	font := te.Font()
+	format := TextSingleLine | TextVCenter | TextNoPrefix
+
+	chips, _, _ := te.chipLayout(cb.Width)
+
+	for i, chip := range chips {
+		brush, color := Brush(chipBackground), palette.Text
+		if i == te.selectedIndex {
+			brush, color = highlight, palette.HighlightText
+		}
+
+		if err := canvas.FillRectangle(brush, chip); err != nil {
+			return err
+		}
+		if err := canvas.DrawRectangle(border, chip); err != nil {
+			return err
+		}
+
+		text := Rectangle{chip.X + tagEditChipPadding, chip.Y, chip.Width - 2*tagEditChipPadding, chip.Height}
+		if err := canvas.DrawText(te.tags[i], font, color, text, format|TextLeft); err != nil {
+			return err
+		}
+
+		if err := canvas.DrawText(tagEditCloseGlyph, font, color, te.closeBounds(chip), format|TextCenter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (te *TagEdit) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_PAINT:
+		var ps PAINTSTRUCT
+
+		hdc := BeginPaint(hwnd, &ps)
+		if hdc == 0 {
+			newAPIError("BeginPaint")
+			break
+		}
+		defer EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			break
+		}
+		defer canvas.Dispose()
+
+		te.paint(canvas)
+
+		return 0
+
+	case WM_ERASEBKGND:
+		return 1
+
+	case WM_COMMAND:
+		switch HIWORD(uint32(wParam)) {
+		case EN_CHANGE:
+			te.complete()
+		}
+
+	case WM_LBUTTONDOWN:
+		index, onClose := te.chipAt(int(int16(LOWORD(uint32(lParam)))), int(int16(HIWORD(uint32(lParam)))))
+
+		switch {
+		case index == -1:
+			te.setSelectedIndex(-1)
+
+		case onClose:
+			te.RemoveTagAt(index)
+			te.setSelectedIndex(-1)
+
+		default:
+			te.setSelectedIndex(index)
+		}
+
+	case WM_GETDLGCODE:
+		if te.selectedIndex != -1 {
+			return DLGC_WANTARROWS
+		}
+
+	case WM_KEYDOWN:
+		switch wParam {
+		case VK_LEFT:
+			if te.selectedIndex > 0 {
+				te.setSelectedIndex(te.selectedIndex - 1)
+			}
+
+		case VK_RIGHT, VK_ESCAPE:
+			if wParam == VK_RIGHT && te.selectedIndex != -1 && te.selectedIndex < len(te.tags)-1 {
+				te.setSelectedIndex(te.selectedIndex + 1)
+			} else {
+				te.setSelectedIndex(-1)
+			}
+
+		case VK_HOME:
+			te.setSelectedIndex(0)
+
+		case VK_END:
+			te.setSelectedIndex(-1)
+
+		case VK_DELETE, VK_BACK:
+			if index := te.selectedIndex; index != -1 {
+				te.RemoveTagAt(index)
+
+				if wParam == VK_BACK && index > 0 {
+					te.setSelectedIndex(index - 1)
+				} else {
+					te.setSelectedIndex(te.selectedIndex)
+				}
+			}
+		}
+
+	case WM_KILLFOCUS:
+		if te.selectedIndex != -1 && HWND(wParam) != te.edit.hWnd {
+			te.selectedIndex = -1
+			te.Invalidate()
+		}
+
+	case WM_SIZE, WM_SIZING:
+		te.updateChildren()
+	}
+
+	return te.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+func (tei *tagEditInput) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	te := tei.te
+
+	switch msg {
+	case WM_GETDLGCODE:
+		if wParam == VK_RETURN && tei.Text() != "" {
+			return DLGC_WANTALLKEYS
+		}
+
+	case WM_CHAR:
+		switch wParam {
+		case '\r', ',', ';':
+			te.commitText()
+			return 0
+		}
+
+	case WM_KEYDOWN:
+		start, end := tei.TextSelection()
+
+		switch wParam {
+		case VK_BACK, VK_LEFT:
+			if start == 0 && end == 0 && len(te.tags) > 0 {
+				te.setSelectedIndex(len(te.tags) - 1)
+				return 0
+			}
+		}
+
+	case WM_KILLFOCUS:
+		if HWND(wParam) != te.hWnd {
+			te.commitText()
+		}
+	}
+
+	return tei.LineEdit.WndProc(hwnd, msg, wParam, lParam)
+}
@@ -0,0 +1,98 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"strings"
+	"time"
+)
+
+// TelemetryKind specifies what kind of user interaction a TelemetryRecord
+// describes.
+type TelemetryKind int
+
+const (
+	// TelemetryActionTriggered is recorded after the handlers of an Action's
+	// Triggered event have run, e.g. as the result of a menu command.
+	TelemetryActionTriggered TelemetryKind = iota
+
+	// TelemetryDialogOpened is recorded when a *Dialog starts running.
+	TelemetryDialogOpened
+
+	// TelemetryDialogClosed is recorded when a *Dialog stopped running.
+	TelemetryDialogClosed
+)
+
+// TelemetryRecord describes a single user interaction.
+type TelemetryRecord struct {
+	Kind TelemetryKind
+
+	// Name identifies the source of the interaction, e.g. the name or text of
+	// an Action or the name or title of a Dialog.
+	Name string
+
+	// Start is the time the interaction started.
+	Start time.Time
+
+	// Duration is the time it took to run the Action handlers resp. the time
+	// a Dialog was open. It is 0 for TelemetryDialogOpened.
+	Duration time.Duration
+
+	// Result holds the result of a Dialog for TelemetryDialogClosed.
+	Result int
+}
+
+// TelemetryHandler is called for each TelemetryRecord, if set via
+// Application.SetTelemetryHandler.
+type TelemetryHandler func(record *TelemetryRecord)
+
+// TelemetryHandler returns the handler that receives usage records.
+func (app *Application) TelemetryHandler() TelemetryHandler {
+	return app.telemetryHandler
+}
+
+// SetTelemetryHandler sets the handler that receives usage records.
+//
+// The handler is called on the UI thread, so it should return quickly.
+// Pass nil to disable telemetry, which is the default.
+func (app *Application) SetTelemetryHandler(handler TelemetryHandler) {
+	app.telemetryHandler = handler
+}
+
+func recordTelemetry(kind TelemetryKind, name string, start time.Time, result int) {
+	handler := appSingleton.telemetryHandler
+	if handler == nil {
+		return
+	}
+
+	record := &TelemetryRecord{
+		Kind:   kind,
+		Name:   name,
+		Start:  start,
+		Result: result,
+	}
+
+	if kind != TelemetryDialogOpened {
+		record.Duration = time.Since(start)
+	}
+
+	handler(record)
+}
+
+func actionTelemetryName(action *Action) string {
+	if action.name != "" {
+		return action.name
+	}
+
+	return strings.Replace(action.text, "&", "", -1)
+}
+
+func dialogTelemetryName(dlg *Dialog) string {
+	if name := dlg.Name(); name != "" {
+		return name
+	}
+
+	return dlg.Title()
+}
@@ -0,0 +1,196 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"bytes"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// TextDocument provides the text shown by a *TextEdit line by line, on
+// demand.
+//
+// Implementations can serve text from memory-mapped files, like
+// *MappedTextDocument does, or stream it from any other source, without
+// loading the whole text into the *TextEdit.
+type TextDocument interface {
+	// LineCount returns the number of lines of the TextDocument.
+	LineCount() int
+
+	// Lines returns up to count lines, starting at line first, without line
+	// terminators.
+	Lines(first, count int) ([]string, error)
+}
+
+// mappedLineIndexInterval is the number of lines between two entries of the
+// line index of a *MappedTextDocument. Lines in between are found by
+// scanning, which keeps the index small for files with millions of lines.
+const mappedLineIndexInterval = 256
+
+// maxMappedTextDocumentSize is the size of the largest file a
+// *MappedTextDocument can map, which works for 32-bit processes as well.
+const maxMappedTextDocumentSize = 1 << 30
+
+// MappedTextDocument is a read-only, UTF-8 encoded TextDocument backed by a
+// memory-mapped file.
+type MappedTextDocument struct {
+	file      *os.File
+	mapping   syscall.Handle
+	data      []byte
+	lineCount int
+	index     []int
+}
+
+// NewMappedTextDocument maps the file at filePath into memory and returns a
+// new *MappedTextDocument for it.
+//
+// The file must not be truncated while it is mapped. Call Dispose to unmap it.
+func NewMappedTextDocument(filePath string) (*MappedTextDocument, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, wrapError(err)
+	}
+
+	doc := &MappedTextDocument{file: file}
+
+	// Empty files can't be mapped.
+	if size := info.Size(); size > 0 {
+		if size > maxMappedTextDocumentSize {
+			file.Close()
+			return nil, newError("file too large to be mapped")
+		}
+
+		mapping, err := syscall.CreateFileMapping(syscall.Handle(file.Fd()), nil, syscall.PAGE_READONLY, 0, 0, nil)
+		if err != nil {
+			file.Close()
+			return nil, callError("CreateFileMapping", err)
+		}
+
+		addr, err := syscall.MapViewOfFile(mapping, syscall.FILE_MAP_READ, 0, 0, 0)
+		if err != nil {
+			syscall.CloseHandle(mapping)
+			file.Close()
+			return nil, callError("MapViewOfFile", err)
+		}
+
+		doc.mapping = mapping
+		doc.data = (*[maxMappedTextDocumentSize]byte)(unsafe.Pointer(addr))[:size:size]
+	}
+
+	doc.buildIndex()
+
+	return doc, nil
+}
+
+// Dispose unmaps the file of the *MappedTextDocument and closes it.
+func (doc *MappedTextDocument) Dispose() {
+	if doc.data != nil {
+		syscall.UnmapViewOfFile(uintptr(unsafe.Pointer(&doc.data[0])))
+		doc.data = nil
+	}
+
+	if doc.mapping != 0 {
+		syscall.CloseHandle(doc.mapping)
+		doc.mapping = 0
+	}
+
+	if doc.file != nil {
+		doc.file.Close()
+		doc.file = nil
+	}
+
+	doc.lineCount = 0
+	doc.index = nil
+}
+
+// LineCount returns the number of lines of the *MappedTextDocument.
+func (doc *MappedTextDocument) LineCount() int {
+	return doc.lineCount
+}
+
+// Lines returns up to count lines, starting at line first.
+func (doc *MappedTextDocument) Lines(first, count int) ([]string, error) {
+	if first < 0 || count < 0 {
+		return nil, newError("invalid line range")
+	}
+
+	if first >= doc.lineCount {
+		return nil, nil
+	}
+
+	if first+count > doc.lineCount {
+		count = doc.lineCount - first
+	}
+
+	offset := doc.lineOffset(first)
+
+	lines := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		end := bytes.IndexByte(doc.data[offset:], '\n')
+		if end == -1 {
+			end = len(doc.data) - offset
+		}
+
+		line := doc.data[offset : offset+end]
+		if n := len(line); n > 0 && line[n-1] == '\r' {
+			line = line[:n-1]
+		}
+
+		lines = append(lines, string(line))
+
+		offset += end + 1
+	}
+
+	return lines, nil
+}
+
+func (doc *MappedTextDocument) buildIndex() {
+	doc.index = []int{0}
+	doc.lineCount = 0
+
+	if len(doc.data) == 0 {
+		return
+	}
+
+	offset := 0
+	for {
+		end := bytes.IndexByte(doc.data[offset:], '\n')
+		if end == -1 {
+			break
+		}
+
+		offset += end + 1
+		doc.lineCount++
+
+		if doc.lineCount%mappedLineIndexInterval == 0 {
+			doc.index = append(doc.index, offset)
+		}
+	}
+
+	// A last line without terminator is a line, too.
+	if offset < len(doc.data) {
+		doc.lineCount++
+	}
+}
+
+// lineOffset returns the offset of line in the data of the
+// *MappedTextDocument.
+func (doc *MappedTextDocument) lineOffset(line int) int {
+	offset := doc.index[line/mappedLineIndexInterval]
+
+	for i := line % mappedLineIndexInterval; i > 0; i-- {
+		offset += bytes.IndexByte(doc.data[offset:], '\n') + 1
+	}
+
+	return offset
+}
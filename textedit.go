@@ -5,6 +5,7 @@
 package walk
 
 import (
+	"strings"
 	"syscall"
 	"unsafe"
 )
@@ -13,14 +14,26 @@ import (
 	. "github.com/lxn/go-winapi"
 )
 
+// defaultTabWidth is the tab stop width of the EDIT control, in characters.
+const defaultTabWidth = 8
+
+// whitespaceColor is used to draw the glyphs for whitespace characters.
+var whitespaceColor = RGB(0xA0, 0xA0, 0xA0)
+
 type TextEdit struct {
 	WidgetBase
 	readOnlyChangedPublisher EventPublisher
 	textChangedPublisher     EventPublisher
+	document                 TextDocument
+	firstLine                int
+	tabWidth                 int
+	acceptsTab               bool
+	convertsTabsToSpaces     bool
+	showsWhitespace          bool
 }
 
 func NewTextEdit(parent Container) (*TextEdit, error) {
-	te := &TextEdit{}
+	te := &TextEdit{tabWidth: defaultTabWidth}
 
 	if err := InitChildWidget(
 		te,
@@ -105,7 +118,7 @@ func (te *TextEdit) ReadOnly() bool {
 
 func (te *TextEdit) SetReadOnly(readOnly bool) error {
 	if 0 == te.SendMessage(EM_SETREADONLY, uintptr(BoolToBOOL(readOnly)), 0) {
-		return newError("SendMessage(EM_SETREADONLY)")
+		return newAPIError("SendMessage(EM_SETREADONLY)")
 	}
 
 	te.readOnlyChangedPublisher.Publish()
@@ -117,12 +130,373 @@ func (te *TextEdit) TextChanged() *Event {
 	return te.textChangedPublisher.Event()
 }
 
+// TabWidth returns the distance between tab stops, in characters.
+func (te *TextEdit) TabWidth() int {
+	return te.tabWidth
+}
+
+// SetTabWidth sets the distance between tab stops, in characters.
+func (te *TextEdit) SetTabWidth(value int) error {
+	if value < 1 {
+		return newError("invalid tab width")
+	}
+
+	// Tab stops are measured in dialog template units, with the average
+	// character being 4 units wide.
+	dlus := uint32(value * 4)
+	if 0 == te.SendMessage(EM_SETTABSTOPS, 1, uintptr(unsafe.Pointer(&dlus))) {
+		return newAPIError("SendMessage(EM_SETTABSTOPS)")
+	}
+
+	te.tabWidth = value
+
+	te.Invalidate()
+
+	return nil
+}
+
+// AcceptsTab returns if pressing the tab key inserts a tab, instead of moving
+// the focus to the next widget.
+func (te *TextEdit) AcceptsTab() bool {
+	return te.acceptsTab
+}
+
+// SetAcceptsTab sets if pressing the tab key inserts a tab, instead of moving
+// the focus to the next widget.
+func (te *TextEdit) SetAcceptsTab(value bool) {
+	te.acceptsTab = value
+}
+
+// ConvertsTabsToSpaces returns if typed tabs are replaced with spaces up to
+// the next tab stop.
+func (te *TextEdit) ConvertsTabsToSpaces() bool {
+	return te.convertsTabsToSpaces
+}
+
+// SetConvertsTabsToSpaces sets if typed tabs are replaced with spaces up to
+// the next tab stop.
+func (te *TextEdit) SetConvertsTabsToSpaces(value bool) {
+	te.convertsTabsToSpaces = value
+}
+
+// ShowsWhitespace returns if spaces and tabs are made visible by glyphs.
+func (te *TextEdit) ShowsWhitespace() bool {
+	return te.showsWhitespace
+}
+
+// SetShowsWhitespace sets if spaces and tabs are made visible by glyphs.
+func (te *TextEdit) SetShowsWhitespace(value bool) {
+	if value == te.showsWhitespace {
+		return
+	}
+
+	te.showsWhitespace = value
+
+	te.Invalidate()
+}
+
+// TrimTrailingWhitespace removes spaces and tabs at the end of all lines.
+//
+// It is meant to be called by code that saves the text of the *TextEdit,
+// before retrieving it. The change can be undone.
+func (te *TextEdit) TrimTrailingWhitespace() {
+	text := te.Text()
+
+	lines := strings.Split(text, "\r\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	trimmed := strings.Join(lines, "\r\n")
+	if trimmed == text {
+		return
+	}
+
+	te.SetTextSelection(0, -1)
+	te.ReplaceSelectedText(trimmed, true)
+}
+
+// insertTabAsSpaces inserts as many spaces, as are needed to reach the next
+// tab stop from the caret.
+func (te *TextEdit) insertTabAsSpaces() {
+	start, _ := te.TextSelection()
+
+	line := te.SendMessage(EM_LINEFROMCHAR, uintptr(start), 0)
+	column := start - int(te.SendMessage(EM_LINEINDEX, line, 0))
+
+	te.ReplaceSelectedText(strings.Repeat(" ", te.tabWidth-column%te.tabWidth), true)
+}
+
+// drawWhitespace draws glyphs over the visible spaces and tabs.
+func (te *TextEdit) drawWhitespace() {
+	textLength := te.TextLength()
+	buf := make([]uint16, textLength+1)
+	te.SendMessage(WM_GETTEXT, uintptr(textLength+1), uintptr(unsafe.Pointer(&buf[0])))
+
+	canvas, err := newCanvasFromHWND(te.hWnd)
+	if err != nil {
+		return
+	}
+	defer canvas.Dispose()
+
+	font := te.Font()
+	lineHeight := te.dialogBaseUnits().Height
+	clientHeight := te.ClientBounds().Height
+
+	color := whitespaceColor
+	if appSingleton.HighContrast() {
+		color = Color(GetSysColor(COLOR_GRAYTEXT))
+	}
+
+	HideCaret(te.hWnd)
+	defer ShowCaret(te.hWnd)
+
+	firstLine := te.SendMessage(EM_GETFIRSTVISIBLELINE, 0, 0)
+	for i := int(te.SendMessage(EM_LINEINDEX, firstLine, 0)); i < textLength; i++ {
+		var glyph string
+		switch buf[i] {
+		case ' ':
+			glyph = "\u00B7"
+
+		case '\t':
+			glyph = "\u2192"
+
+		default:
+			continue
+		}
+
+		pos := te.SendMessage(EM_POSFROMCHAR, uintptr(i), 0)
+		x, y := int(int16(LOWORD(uint32(pos)))), int(int16(HIWORD(uint32(pos))))
+		if y >= clientHeight {
+			break
+		}
+
+		// The width of the character is the distance to the next one on the
+		// same line, if any.
+		width := lineHeight / 2
+		if i+1 < textLength {
+			next := te.SendMessage(EM_POSFROMCHAR, uintptr(i+1), 0)
+			if int(int16(HIWORD(uint32(next)))) == y {
+				width = int(int16(LOWORD(uint32(next)))) - x
+			}
+		}
+
+		bounds := Rectangle{x, y, width, lineHeight}
+		if buf[i] == '\t' {
+			bounds.Width = lineHeight
+		}
+
+		canvas.DrawText(glyph, font, color, bounds, TextCenter|TextVCenter|TextSingleLine|TextNoClip)
+	}
+}
+
+// Document returns the TextDocument shown by the *TextEdit, if any.
+func (te *TextEdit) Document() TextDocument {
+	return te.document
+}
+
+// SetDocument makes the *TextEdit show the lines of document, which may be
+// much larger than the text the control could hold.
+//
+// Only the lines that are currently visible are requested from document and
+// put into the control, so the *TextEdit is made read-only and it scrolls the
+// document itself. Selections therefore are limited to the visible lines.
+//
+// Pass nil to show regular text again.
+func (te *TextEdit) SetDocument(document TextDocument) error {
+	te.document = document
+	te.firstLine = 0
+
+	if document == nil {
+		return te.SetText("")
+	}
+
+	if err := te.SetReadOnly(true); err != nil {
+		return err
+	}
+
+	return te.loadDocumentLines()
+}
+
+// FirstVisibleLine returns the index of the line of the TextDocument, that is
+// shown at the top of the *TextEdit.
+func (te *TextEdit) FirstVisibleLine() int {
+	return te.firstLine
+}
+
+// ScrollToLine scrolls the TextDocument of the *TextEdit, so that line is
+// shown at the top, if possible.
+func (te *TextEdit) ScrollToLine(line int) error {
+	if te.document == nil {
+		return newError("no document")
+	}
+
+	maxFirstLine := te.document.LineCount() - te.visibleLineCount()
+	if line > maxFirstLine {
+		line = maxFirstLine
+	}
+	if line < 0 {
+		line = 0
+	}
+
+	if line == te.firstLine {
+		return nil
+	}
+
+	te.firstLine = line
+
+	return te.loadDocumentLines()
+}
+
+func (te *TextEdit) visibleLineCount() int {
+	lineHeight := te.dialogBaseUnits().Height
+	if lineHeight <= 0 {
+		return 1
+	}
+
+	return maxi(1, te.ClientBounds().Height/lineHeight)
+}
+
+// loadDocumentLines puts the visible lines of the TextDocument into the
+// control and updates the scroll bar.
+func (te *TextEdit) loadDocumentLines() error {
+	visible := te.visibleLineCount()
+
+	lines, err := te.document.Lines(te.firstLine, visible)
+	if err != nil {
+		return err
+	}
+
+	if err := setWidgetText(te.hWnd, strings.Join(lines, "\r\n")); err != nil {
+		return err
+	}
+
+	// The control resets its scroll bar when its text changes.
+	si := SCROLLINFO{
+		FMask: SIF_PAGE | SIF_POS | SIF_RANGE | SIF_DISABLENOSCROLL,
+		NMax:  int32(maxi(0, te.document.LineCount()-1)),
+		NPage: uint32(visible),
+		NPos:  int32(te.firstLine),
+	}
+	si.CbSize = uint32(unsafe.Sizeof(si))
+
+	SetScrollInfo(te.hWnd, SB_VERT, &si, true)
+
+	return nil
+}
+
+// documentWndProc handles the messages that scroll the TextDocument of the
+// *TextEdit.
+func (te *TextEdit) documentWndProc(msg uint32, wParam, lParam uintptr) (result uintptr, handled bool) {
+	page := te.visibleLineCount()
+	line := te.firstLine
+
+	switch msg {
+	case WM_VSCROLL:
+		switch LOWORD(uint32(wParam)) {
+		case SB_LINEUP:
+			line--
+
+		case SB_LINEDOWN:
+			line++
+
+		case SB_PAGEUP:
+			line -= page
+
+		case SB_PAGEDOWN:
+			line += page
+
+		case SB_TOP:
+			line = 0
+
+		case SB_BOTTOM:
+			line = te.document.LineCount()
+
+		case SB_THUMBTRACK, SB_THUMBPOSITION:
+			// The position in wParam has 16 bits only.
+			si := SCROLLINFO{FMask: SIF_TRACKPOS}
+			si.CbSize = uint32(unsafe.Sizeof(si))
+
+			if GetScrollInfo(te.hWnd, SB_VERT, &si) {
+				line = int(si.NTrackPos)
+			}
+		}
+
+	case WM_MOUSEWHEEL:
+		line -= int(int16(HIWORD(uint32(wParam)))) / WHEEL_DELTA * 3
+
+	case WM_KEYDOWN:
+		switch wParam {
+		case VK_UP:
+			line--
+
+		case VK_DOWN:
+			line++
+
+		case VK_PRIOR:
+			line -= page
+
+		case VK_NEXT:
+			line += page
+
+		case VK_HOME:
+			line = 0
+
+		case VK_END:
+			line = te.document.LineCount()
+
+		default:
+			return 0, false
+		}
+
+	case WM_SIZE:
+		// The number of visible lines may have changed.
+		te.loadDocumentLines()
+		return 0, false
+
+	default:
+		return 0, false
+	}
+
+	te.ScrollToLine(line)
+
+	return 0, true
+}
+
 func (te *TextEdit) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	if te.document != nil {
+		if result, handled := te.documentWndProc(msg, wParam, lParam); handled {
+			return result
+		}
+	}
+
 	switch msg {
 	case WM_COMMAND:
 		switch HIWORD(uint32(wParam)) {
 		case EN_CHANGE:
-			te.textChangedPublisher.Publish()
+			// With a TextDocument, the text changes only by scrolling.
+			if te.document == nil {
+				te.textChangedPublisher.Publish()
+			}
+
+			// The control redraws changed text without WM_PAINT, which
+			// clears our glyphs.
+			if te.showsWhitespace {
+				te.Invalidate()
+			}
+		}
+
+	case WM_CHAR:
+		if wParam == '\t' && te.convertsTabsToSpaces && !te.ReadOnly() {
+			te.insertTabAsSpaces()
+			return 0
+		}
+
+	case WM_PAINT:
+		if te.showsWhitespace {
+			result := te.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+			te.drawWhitespace()
+			return result
 		}
 
 	case WM_GETDLGCODE:
@@ -130,6 +504,10 @@ func (te *TextEdit) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintp
 			return DLGC_WANTALLKEYS
 		}
 
+		if te.acceptsTab {
+			return DLGC_HASSETSEL | DLGC_WANTARROWS | DLGC_WANTCHARS | DLGC_WANTTAB
+		}
+
 		return DLGC_HASSETSEL | DLGC_WANTARROWS | DLGC_WANTCHARS
 	}
 
@@ -0,0 +1,402 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// Theme specifies the colors used for UI elements drawn by walk.
+type Theme byte
+
+const (
+	// ThemeDefault leaves drawing to the operating system.
+	ThemeDefault Theme = iota
+
+	// ThemeDark uses light text on a dark background.
+	ThemeDark
+)
+
+const (
+	uxthemeSetPreferredAppMode = 135
+	uxthemeFlushMenuThemes     = 136
+
+	appModeDefault   = 0
+	appModeForceDark = 2
+)
+
+var (
+	libuxtheme         = syscall.NewLazyDLL("uxtheme.dll")
+	procGetProcAddress = syscall.NewLazyDLL("kernel32.dll").NewProc("GetProcAddress")
+)
+
+var (
+	darkMenuBackgroundColor = RGB(43, 43, 43)
+	darkMenuSelectedColor   = RGB(65, 65, 65)
+	darkMenuTextColor       = RGB(255, 255, 255)
+	darkMenuDisabledColor   = RGB(109, 109, 109)
+
+	darkMenuBackgroundBrush Brush
+	darkMenuSelectedBrush   Brush
+	menuBackgroundBrush     Brush
+	menuSelectedBrush       Brush
+	menuFontSingleton       *Font
+)
+
+// Theme returns the Theme of the application.
+func (app *Application) Theme() Theme {
+	return app.theme
+}
+
+// SetTheme sets the Theme of the application.
+//
+// With ThemeDark, menus and menu bars are drawn dark. Where available, the
+// immersive dark mode of Windows is used for menu borders and scroll arrows,
+// while menu items are drawn by walk.
+func (app *Application) SetTheme(value Theme) error {
+	if value == app.theme {
+		return nil
+	}
+
+	app.theme = value
+
+	setPreferredAppMode(value == ThemeDark)
+
+	for _, m := range menusByHandle {
+		if err := m.applyTheme(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// uxthemeProc returns the address of a function exported by uxtheme.dll by
+// ordinal only, or 0 if it is not available.
+func uxthemeProc(ordinal uintptr) uintptr {
+	if err := libuxtheme.Load(); err != nil {
+		return 0
+	}
+
+	addr, _, _ := procGetProcAddress.Call(libuxtheme.Handle(), ordinal)
+
+	return addr
+}
+
+func setPreferredAppMode(dark bool) {
+	setPreferredAppMode := uxthemeProc(uxthemeSetPreferredAppMode)
+	flushMenuThemes := uxthemeProc(uxthemeFlushMenuThemes)
+	if setPreferredAppMode == 0 || flushMenuThemes == 0 {
+		return
+	}
+
+	mode := uintptr(appModeDefault)
+	if dark {
+		mode = appModeForceDark
+	}
+
+	syscall.Syscall(setPreferredAppMode, 1, mode, 0, 0)
+	syscall.Syscall(flushMenuThemes, 0, 0, 0, 0)
+}
+
+func themedMenus() bool {
+	return appSingleton.theme == ThemeDark && !appSingleton.HighContrast()
+}
+
+// applyTheme updates the background and the items of the *Menu, after the
+// Theme of the application changed.
+func (m *Menu) applyTheme() error {
+	var mi MENUINFO
+	mi.CbSize = uint32(unsafe.Sizeof(mi))
+	mi.FMask = MIM_BACKGROUND
+
+	if themedMenus() {
+		brush, err := darkMenuBrushes()
+		if err != nil {
+			return err
+		}
+
+		mi.HbrBack = brush.handle()
+	}
+
+	if !SetMenuInfo(m.hMenu, &mi) {
+		return lastError("SetMenuInfo")
+	}
+
+	return m.updateItems()
+}
+
+// darkMenuBrushes lazily creates the brushes for dark menus and returns the
+// background brush.
+func darkMenuBrushes() (Brush, error) {
+	if darkMenuBackgroundBrush == nil {
+		background, err := NewSolidColorBrush(darkMenuBackgroundColor)
+		if err != nil {
+			return nil, err
+		}
+
+		selected, err := NewSolidColorBrush(darkMenuSelectedColor)
+		if err != nil {
+			background.Dispose()
+			return nil, err
+		}
+
+		darkMenuBackgroundBrush, darkMenuSelectedBrush = background, selected
+	}
+
+	return darkMenuBackgroundBrush, nil
+}
+
+// menuFont returns the *Font the system uses for menus.
+func menuFont() *Font {
+	if menuFontSingleton == nil {
+		var ncm NONCLIENTMETRICS
+		ncm.CbSize = uint32(unsafe.Sizeof(ncm))
+
+		if SystemParametersInfo(SPI_GETNONCLIENTMETRICS, ncm.CbSize, unsafe.Pointer(&ncm), 0) {
+			menuFontSingleton, _ = newFontFromLOGFONT(&ncm.LfMenuFont, screenDPIY)
+		}
+
+		if menuFontSingleton == nil {
+			menuFontSingleton = defaultFont
+		}
+	}
+
+	return menuFontSingleton
+}
+
+const (
+	themedMenuItemPadding      = 4
+	themedMenuItemGutterWidth  = 24
+	themedMenuItemShortcutGap  = 24
+	themedMenuItemMinHeight    = 20
+	themedMenuItemTextFormat   = TextSingleLine | TextVCenter
+	themedMenuItemCheckMark    = "✓"
+	themedMenuItemSubmenuArrow = "›"
+
+	themedMenuItemSubmenuArrowRTL = "‹"
+)
+
+// splitMenuItemText returns the label and the shortcut part of a menu item
+// text, which are separated by a tab.
+func splitMenuItemText(text string) (label, shortcut string) {
+	if i := strings.Index(text, "\t"); i > -1 {
+		return text[:i], text[i+1:]
+	}
+
+	return text, ""
+}
+
+// menuItemPalette holds the brushes and colors used to draw a menu item.
+type menuItemPalette struct {
+	background   Brush
+	selected     Brush
+	text         Color
+	selectedText Color
+	disabledText Color
+}
+
+// currentMenuItemPalette returns the menuItemPalette for the Theme of the
+// application.
+func currentMenuItemPalette() (*menuItemPalette, error) {
+	if themedMenus() {
+		if _, err := darkMenuBrushes(); err != nil {
+			return nil, err
+		}
+
+		return &menuItemPalette{
+			background:   darkMenuBackgroundBrush,
+			selected:     darkMenuSelectedBrush,
+			text:         darkMenuTextColor,
+			selectedText: darkMenuTextColor,
+			disabledText: darkMenuDisabledColor,
+		}, nil
+	}
+
+	if menuBackgroundBrush == nil {
+		background, err := NewSystemColorBrush(COLOR_MENU)
+		if err != nil {
+			return nil, err
+		}
+
+		selected, err := NewSystemColorBrush(COLOR_HIGHLIGHT)
+		if err != nil {
+			background.Dispose()
+			return nil, err
+		}
+
+		menuBackgroundBrush, menuSelectedBrush = background, selected
+	}
+
+	return &menuItemPalette{
+		background:   menuBackgroundBrush,
+		selected:     menuSelectedBrush,
+		text:         Color(GetSysColor(COLOR_MENUTEXT)),
+		selectedText: Color(GetSysColor(COLOR_HIGHLIGHTTEXT)),
+		disabledText: Color(GetSysColor(COLOR_GRAYTEXT)),
+	}, nil
+}
+
+// drawsMenuItem returns if walk draws the menu item of action itself, either
+// because of the Theme of the application or the font or text color of action.
+func drawsMenuItem(action *Action) bool {
+	_, hasTextColor := menuItemTextColor(action)
+
+	return themedMenus() || action.font != nil || hasTextColor
+}
+
+// menuItemTextColor returns the text color of action, if it has one. While
+// HighContrast is on, the default one is always used.
+func menuItemTextColor(action *Action) (Color, bool) {
+	if appSingleton.HighContrast() || !action.hasTextColor {
+		return 0, false
+	}
+
+	return action.textColor, true
+}
+
+func menuItemFont(action *Action) *Font {
+	if action.font != nil {
+		return action.font
+	}
+
+	return menuFont()
+}
+
+// measureThemedMenuItem returns the Size of a menu item drawn by walk.
+func measureThemedMenuItem(action *Action, text string, canvas *Canvas, bar bool) (Size, error) {
+	font := menuItemFont(action)
+
+	label, shortcut := splitMenuItemText(text)
+
+	bounds, _, err := canvas.MeasureText(label, font, Rectangle{Width: 10000, Height: 10000}, themedMenuItemTextFormat)
+	if err != nil {
+		return Size{}, err
+	}
+
+	width := bounds.Width + 2*themedMenuItemPadding
+	height := bounds.Height + 2*themedMenuItemPadding
+
+	if !bar {
+		width += 2 * themedMenuItemGutterWidth
+
+		if shortcut != "" {
+			bounds, _, err := canvas.MeasureText(shortcut, font, Rectangle{Width: 10000, Height: 10000}, themedMenuItemTextFormat)
+			if err != nil {
+				return Size{}, err
+			}
+
+			width += themedMenuItemShortcutGap + bounds.Width
+		}
+
+		if height < themedMenuItemMinHeight {
+			height = themedMenuItemMinHeight
+		}
+	}
+
+	return Size{width, height}, nil
+}
+
+// drawThemedMenuItem draws the menu item of action in menu using the colors of
+// the Theme of the application and the font and text color of action.
+func drawThemedMenuItem(menu *Menu, action *Action, canvas *Canvas, bounds Rectangle, state MenuItemState) error {
+	bar, rightToLeft := menu.bar, menu.isRightToLeft()
+
+	palette, err := currentMenuItemPalette()
+	if err != nil {
+		return err
+	}
+
+	background := palette.background
+	color := palette.text
+	if textColor, ok := menuItemTextColor(action); ok {
+		color = textColor
+	}
+
+	if state&MenuItemSelected != 0 {
+		background = palette.selected
+
+		if !themedMenus() {
+			color = palette.selectedText
+		}
+	}
+	if state&MenuItemDisabled != 0 {
+		color = palette.disabledText
+	}
+
+	if err := canvas.FillRectangle(background, bounds); err != nil {
+		return err
+	}
+
+	font := menuItemFont(action)
+
+	format := themedMenuItemTextFormat
+	labelAlignment, shortcutAlignment := TextLeft, TextRight
+	if rightToLeft {
+		format |= TextRTLReading
+		labelAlignment, shortcutAlignment = TextRight, TextLeft
+	}
+
+	label, shortcut := splitMenuItemText(menu.actionText(action))
+	if bar {
+		return canvas.DrawText(label, font, color, bounds, format|TextCenter)
+	}
+
+	gutter := Rectangle{bounds.X, bounds.Y, themedMenuItemGutterWidth, bounds.Height}
+	arrow := Rectangle{bounds.X + bounds.Width - themedMenuItemGutterWidth, bounds.Y, themedMenuItemGutterWidth, bounds.Height}
+	if rightToLeft {
+		gutter, arrow = arrow, gutter
+	}
+
+	if state&MenuItemChecked != 0 {
+		if err := canvas.DrawText(themedMenuItemCheckMark, font, color, gutter, themedMenuItemTextFormat|TextCenter); err != nil {
+			return err
+		}
+	} else if image := menu.actionImage(action); image != nil {
+		size := image.Size()
+		location := Point{
+			gutter.X + (gutter.Width-size.Width)/2,
+			gutter.Y + (gutter.Height-size.Height)/2,
+		}
+
+		if err := canvas.DrawImage(image, location); err != nil {
+			return err
+		}
+	}
+
+	text := Rectangle{
+		bounds.X + themedMenuItemGutterWidth + themedMenuItemPadding,
+		bounds.Y,
+		bounds.Width - 2*(themedMenuItemGutterWidth+themedMenuItemPadding),
+		bounds.Height,
+	}
+
+	if err := canvas.DrawText(label, font, color, text, format|labelAlignment); err != nil {
+		return err
+	}
+
+	if shortcut != "" {
+		if err := canvas.DrawText(shortcut, font, color, text, format|shortcutAlignment|TextNoPrefix); err != nil {
+			return err
+		}
+	}
+
+	if action.menu != nil {
+		submenuArrow := themedMenuItemSubmenuArrow
+		if rightToLeft {
+			submenuArrow = themedMenuItemSubmenuArrowRTL
+		}
+
+		if err := canvas.DrawText(submenuArrow, font, color, arrow, format|TextCenter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
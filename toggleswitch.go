@@ -0,0 +1,362 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+var procDrawFocusRect = libuser32.NewProc("DrawFocusRect")
+
+const toggleSwitchWindowClass = `\o/ Walk_ToggleSwitch_Class \o/`
+
+const (
+	toggleSwitchWidth   = 40
+	toggleSwitchHeight  = 20
+	toggleSwitchMargin  = 3
+	toggleSwitchSpacing = 8
+
+	toggleSwitchAnimationTimerId    = 1
+	toggleSwitchAnimationIntervalMs = 15
+	toggleSwitchAnimationSteps      = 8
+)
+
+func init() {
+	MustRegisterWindowClass(toggleSwitchWindowClass)
+}
+
+// ToggleSwitch is an on/off switch, an alternative to a CheckBox for settings
+// that take effect right away. Its text is shown to the right of the switch.
+//
+// Clicking it or pressing Space toggles it, the knob slides to the new
+// position.
+type ToggleSwitch struct {
+	WidgetBase
+	checked                 bool
+	knobPos                 int // 0 is off, toggleSwitchAnimationSteps is on
+	focused                 bool
+	pressed                 bool
+	checkedChangedPublisher EventPublisher
+	textChangedPublisher    EventPublisher
+}
+
+// NewToggleSwitch returns a new *ToggleSwitch, that is off.
+func NewToggleSwitch(parent Container) (*ToggleSwitch, error) {
+	ts := new(ToggleSwitch)
+
+	if err := InitChildWidget(
+		ts,
+		parent,
+		toggleSwitchWindowClass,
+		WS_TABSTOP|WS_VISIBLE,
+		0); err != nil {
+		return nil, err
+	}
+
+	ts.MustRegisterProperty("Checked", NewBoolProperty(
+		func() bool {
+			return ts.Checked()
+		},
+		func(v bool) error {
+			ts.SetChecked(v)
+			return nil
+		},
+		ts.checkedChangedPublisher.Event()))
+
+	ts.MustRegisterProperty("Text", NewProperty(
+		func() interface{} {
+			return ts.Text()
+		},
+		func(v interface{}) error {
+			return ts.SetText(v.(string))
+		},
+		ts.textChangedPublisher.Event()))
+
+	return ts, nil
+}
+
+func (*ToggleSwitch) LayoutFlags() LayoutFlags {
+	return 0
+}
+
+func (ts *ToggleSwitch) MinSizeHint() Size {
+	s := ts.switchSize()
+
+	if text := ts.Text(); text != "" {
+		textSize := ts.calculateTextSizeImpl(text)
+
+		s.Width += ts.IntFromDIP(toggleSwitchSpacing) + textSize.Width
+		s.Height = maxi(s.Height, textSize.Height)
+	}
+
+	return s
+}
+
+func (ts *ToggleSwitch) SizeHint() Size {
+	return ts.MinSizeHint()
+}
+
+func (ts *ToggleSwitch) switchSize() Size {
+	return Size{ts.IntFromDIP(toggleSwitchWidth), ts.IntFromDIP(toggleSwitchHeight)}
+}
+
+// Text returns the text shown next to the switch.
+func (ts *ToggleSwitch) Text() string {
+	return widgetText(ts.hWnd)
+}
+
+// SetText sets the text shown next to the switch.
+func (ts *ToggleSwitch) SetText(value string) error {
+	if value == ts.Text() {
+		return nil
+	}
+
+	if err := setWidgetText(ts.hWnd, value); err != nil {
+		return err
+	}
+
+	ts.Invalidate()
+
+	ts.textChangedPublisher.Publish()
+
+	return ts.updateParentLayout()
+}
+
+// Checked returns if the *ToggleSwitch is on.
+func (ts *ToggleSwitch) Checked() bool {
+	return ts.checked
+}
+
+// SetChecked switches the *ToggleSwitch on or off. The knob moves without
+// animation, use Toggle for that.
+func (ts *ToggleSwitch) SetChecked(value bool) {
+	if value == ts.checked {
+		return
+	}
+
+	ts.checked = value
+
+	KillTimer(ts.hWnd, toggleSwitchAnimationTimerId)
+	ts.knobPos = ts.knobTarget()
+	ts.Invalidate()
+
+	ts.checkedChangedPublisher.Publish()
+}
+
+// Toggle switches the *ToggleSwitch over, with the knob sliding to the new
+// position.
+func (ts *ToggleSwitch) Toggle() {
+	ts.checked = !ts.checked
+
+	if 0 == SetTimer(ts.hWnd, toggleSwitchAnimationTimerId, toggleSwitchAnimationIntervalMs, 0) {
+		lastError("SetTimer")
+		ts.knobPos = ts.knobTarget()
+		ts.Invalidate()
+	}
+
+	ts.checkedChangedPublisher.Publish()
+}
+
+// CheckedChanged returns the event that is published, when the
+// *ToggleSwitch was switched on or off.
+func (ts *ToggleSwitch) CheckedChanged() *Event {
+	return ts.checkedChangedPublisher.Event()
+}
+
+func (ts *ToggleSwitch) knobTarget() int {
+	if ts.checked {
+		return toggleSwitchAnimationSteps
+	}
+
+	return 0
+}
+
+// animate moves the knob one step toward its target.
+func (ts *ToggleSwitch) animate() {
+	target := ts.knobTarget()
+
+	switch {
+	case ts.knobPos < target:
+		ts.knobPos++
+
+	case ts.knobPos > target:
+		ts.knobPos--
+	}
+
+	if ts.knobPos == target {
+		KillTimer(ts.hWnd, toggleSwitchAnimationTimerId)
+	}
+
+	ts.Invalidate()
+}
+
+func (ts *ToggleSwitch) paint(canvas *Canvas) error {
+	palette := appSingleton.Palette()
+
+	cb := ts.ClientBounds()
+	enabled := ts.Enabled()
+
+	background, err := NewSolidColorBrush(palette.Background)
+	if err != nil {
+		return err
+	}
+	defer background.Dispose()
+
+	if err := canvas.FillRectangle(background, cb); err != nil {
+		return err
+	}
+
+	trackColor := palette.Border
+	knobColor := palette.Background
+	if ts.checked {
+		trackColor = palette.Highlight
+		knobColor = palette.HighlightText
+	}
+	if !enabled {
+		trackColor = palette.DisabledText
+	}
+
+	track, err := NewSolidColorBrush(trackColor)
+	if err != nil {
+		return err
+	}
+	defer track.Dispose()
+
+	knob, err := NewSolidColorBrush(knobColor)
+	if err != nil {
+		return err
+	}
+	defer knob.Dispose()
+
+	// The track is a pill, made of two circles joined by a rectangle.
+	s := ts.switchSize()
+	bounds := Rectangle{0, (cb.Height - s.Height) / 2, s.Width, s.Height}
+	d := bounds.Height
+
+	if err := canvas.FillEllipse(track, Rectangle{bounds.X, bounds.Y, d, d}); err != nil {
+		return err
+	}
+	if err := canvas.FillEllipse(track, Rectangle{bounds.X + bounds.Width - d, bounds.Y, d, d}); err != nil {
+		return err
+	}
+	if err := canvas.FillRectangle(track, Rectangle{bounds.X + d/2, bounds.Y, bounds.Width - d, d}); err != nil {
+		return err
+	}
+
+	margin := ts.IntFromDIP(toggleSwitchMargin)
+	knobSize := d - 2*margin
+	if ts.pressed {
+		knobSize -= margin
+	}
+
+	travel := bounds.Width - d
+	x := bounds.X + travel*ts.knobPos/toggleSwitchAnimationSteps + (d-knobSize)/2
+	y := bounds.Y + (d-knobSize)/2
+
+	if err := canvas.FillEllipse(knob, Rectangle{x, y, knobSize, knobSize}); err != nil {
+		return err
+	}
+
+	if text := ts.Text(); text != "" {
+		textColor := palette.Text
+		if !enabled {
+			textColor = palette.DisabledText
+		}
+
+		left := s.Width + ts.IntFromDIP(toggleSwitchSpacing)
+		textBounds := Rectangle{left, 0, cb.Width - left, cb.Height}
+
+		if err := canvas.DrawText(text, ts.Font(), textColor, textBounds, TextLeft|TextVCenter|TextSingleLine|TextEndEllipsis); err != nil {
+			return err
+		}
+
+		if ts.focused {
+			rect := textBounds.toRECT()
+			procDrawFocusRect.Call(uintptr(canvas.hdc), uintptr(unsafe.Pointer(&rect)))
+		}
+	} else if ts.focused {
+		rect := bounds.toRECT()
+		procDrawFocusRect.Call(uintptr(canvas.hdc), uintptr(unsafe.Pointer(&rect)))
+	}
+
+	return nil
+}
+
+func (ts *ToggleSwitch) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_PAINT:
+		var ps PAINTSTRUCT
+
+		hdc := BeginPaint(hwnd, &ps)
+		if hdc == 0 {
+			newAPIError("BeginPaint")
+			break
+		}
+		defer EndPaint(hwnd, &ps)
+
+		canvas, err := newCanvasFromHDC(hdc)
+		if err != nil {
+			break
+		}
+		defer canvas.Dispose()
+
+		ts.paint(canvas)
+
+		return 0
+
+	case WM_ERASEBKGND:
+		return 1
+
+	case WM_SIZE, WM_SIZING, WM_ENABLE:
+		ts.Invalidate()
+
+	case WM_SETFOCUS, WM_KILLFOCUS:
+		ts.focused = msg == WM_SETFOCUS
+		ts.Invalidate()
+
+	case WM_TIMER:
+		if wParam == toggleSwitchAnimationTimerId {
+			ts.animate()
+			return 0
+		}
+
+	case WM_LBUTTONDOWN:
+		SetFocus(hwnd)
+		SetCapture(hwnd)
+
+		ts.pressed = true
+		ts.Invalidate()
+
+	case WM_LBUTTONUP:
+		if !ts.pressed {
+			break
+		}
+
+		ReleaseCapture()
+
+		x, y := int(GET_X_LPARAM(lParam)), int(GET_Y_LPARAM(lParam))
+		cb := ts.ClientBounds()
+		if x >= 0 && x < cb.Width && y >= 0 && y < cb.Height {
+			ts.Toggle()
+		}
+
+	case WM_CAPTURECHANGED:
+		ts.pressed = false
+		ts.Invalidate()
+
+	case WM_KEYDOWN:
+		// Ignore the auto repeat.
+		if wParam == VK_SPACE && lParam&(1<<30) == 0 {
+			ts.Toggle()
+		}
+
+	case WM_DESTROY:
+		KillTimer(hwnd, toggleSwitchAnimationTimerId)
+	}
+
+	return ts.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
+}
@@ -11,12 +11,63 @@ import (
 
 import . "github.com/lxn/go-winapi"
 
+const (
+	tbstyleList = 0x1000
+
+	tbstyleExDrawDDArrows = 0x0001
+
+	btnsDropDown      = 0x0008
+	btnsWholeDropDown = 0x0080
+
+	tbSetExtendedStyle = WM_USER + 84
+	tbGetRect          = WM_USER + 51
+
+	tbnDropDown = -700 - 10
+)
+
+type nmToolBar struct {
+	Hdr      NMHDR
+	IItem    int32
+	TbButton TBBUTTON
+	CchText  int32
+	PszText  *uint16
+	RcButton RECT
+}
+
+// ToolBarButtonStyle specifies how the buttons of a ToolBar show the text
+// and image of their actions.
+type ToolBarButtonStyle int
+
+const (
+	// ToolBarButtonImageAboveText shows the image above the text.
+	ToolBarButtonImageAboveText ToolBarButtonStyle = iota
+
+	// ToolBarButtonImageBeforeText shows the text beside the image.
+	ToolBarButtonImageBeforeText
+
+	// ToolBarButtonImageOnly shows only the image, if there is one.
+	ToolBarButtonImageOnly
+
+	// ToolBarButtonTextOnly shows only the text.
+	ToolBarButtonTextOnly
+)
+
+// ToolBar shows the actions of its Actions list as buttons.
+//
+// An action with the text "-" is shown as separator. An action with a menu,
+// e.g. one added by ActionList.AddMenu, is shown as drop-down button, that
+// opens the menu. If handlers are attached to the Triggered event of such an
+// action, when it is added, the button is split into the button itself, that
+// triggers the action, and an arrow, that opens the menu.
 type ToolBar struct {
 	WidgetBase
+	actionPresentations
 	imageList          *ImageList
 	actions            *ActionList
 	defaultButtonWidth int
 	maxTextRows        int
+	buttonStyle        ToolBarButtonStyle
+	buttonSize         Size
 }
 
 func newToolBar(parent Container, style uint32) (*ToolBar, error) {
@@ -32,6 +83,8 @@ func newToolBar(parent Container, style uint32) (*ToolBar, error) {
 		return nil, err
 	}
 
+	tb.SendMessage(tbSetExtendedStyle, 0, tbstyleExDrawDDArrows)
+
 	return tb, nil
 }
 
@@ -91,7 +144,7 @@ func (tb *ToolBar) applyDefaultButtonWidth() error {
 	lParam := uintptr(
 		MAKELONG(uint16(tb.defaultButtonWidth), uint16(tb.defaultButtonWidth)))
 	if 0 == tb.SendMessage(TB_SETBUTTONWIDTH, 0, lParam) {
-		return newError("SendMessage(TB_SETBUTTONWIDTH)")
+		return newAPIError("SendMessage(TB_SETBUTTONWIDTH)")
 	}
 
 	size := uint32(tb.SendMessage(TB_GETBUTTONSIZE, 0, 0))
@@ -99,7 +152,7 @@ func (tb *ToolBar) applyDefaultButtonWidth() error {
 
 	lParam = uintptr(MAKELONG(uint16(tb.defaultButtonWidth), height))
 	if FALSE == tb.SendMessage(TB_SETBUTTONSIZE, 0, lParam) {
-		return newError("SendMessage(TB_SETBUTTONSIZE)")
+		return newAPIError("SendMessage(TB_SETBUTTONSIZE)")
 	}
 
 	return nil
@@ -142,13 +195,90 @@ func (tb *ToolBar) SetDefaultButtonWidth(width int) error {
 	return tb.applyDefaultButtonWidth()
 }
 
+// ButtonStyle returns how the buttons of the ToolBar show the text and image
+// of their actions.
+func (tb *ToolBar) ButtonStyle() ToolBarButtonStyle {
+	return tb.buttonStyle
+}
+
+// SetButtonStyle sets how the buttons of the ToolBar show the text and image
+// of their actions.
+func (tb *ToolBar) SetButtonStyle(style ToolBarButtonStyle) error {
+	if style == tb.buttonStyle {
+		return nil
+	}
+
+	old := tb.buttonStyle
+
+	tb.buttonStyle = style
+
+	if err := tb.ensureStyleBits(tbstyleList, style == ToolBarButtonImageBeforeText); err != nil {
+		tb.buttonStyle = old
+
+		return err
+	}
+
+	for _, action := range tb.actions.actions {
+		if err := tb.onActionChanged(action); err != nil {
+			tb.buttonStyle = old
+
+			return err
+		}
+	}
+
+	tb.SendMessage(TB_AUTOSIZE, 0, 0)
+
+	return tb.updateParentLayout()
+}
+
+// ButtonSize returns the size of the buttons of the ToolBar set by
+// SetButtonSize.
+//
+// The default value is the zero Size, resulting in buttons sized to fit their
+// text and image.
+func (tb *ToolBar) ButtonSize() Size {
+	return tb.buttonSize
+}
+
+// SetButtonSize sets the size of the buttons of the ToolBar. The size is
+// applied to the buttons added later as well. It is not possible to go back
+// to automatic sizing.
+func (tb *ToolBar) SetButtonSize(size Size) error {
+	if size.Width <= 0 || size.Height <= 0 {
+		return newError("size must be positive")
+	}
+
+	tb.buttonSize = size
+
+	if err := tb.applyButtonSize(); err != nil {
+		return err
+	}
+
+	tb.SendMessage(TB_AUTOSIZE, 0, 0)
+
+	return tb.updateParentLayout()
+}
+
+func (tb *ToolBar) applyButtonSize() error {
+	if tb.buttonSize == (Size{}) {
+		return nil
+	}
+
+	lParam := uintptr(MAKELONG(uint16(tb.buttonSize.Width), uint16(tb.buttonSize.Height)))
+	if FALSE == tb.SendMessage(TB_SETBUTTONSIZE, 0, lParam) {
+		return newAPIError("SendMessage(TB_SETBUTTONSIZE)")
+	}
+
+	return nil
+}
+
 func (tb *ToolBar) MaxTextRows() int {
 	return tb.maxTextRows
 }
 
 func (tb *ToolBar) SetMaxTextRows(maxTextRows int) error {
 	if 0 == tb.SendMessage(TB_SETMAXTEXTROWS, uintptr(maxTextRows), 0) {
-		return newError("SendMessage(TB_SETMAXTEXTROWS)")
+		return newAPIError("SendMessage(TB_SETMAXTEXTROWS)")
 	}
 
 	tb.maxTextRows = maxTextRows
@@ -179,6 +309,16 @@ func (tb *ToolBar) SetImageList(value *ImageList) {
 func (tb *ToolBar) imageIndex(image *Bitmap) (imageIndex int32, err error) {
 	imageIndex = -1
 	if image != nil {
+		if tb.imageList == nil {
+			// The size of the first image determines the size of all images.
+			imageList, err := NewImageList(image.Size(), 0)
+			if err != nil {
+				return -1, err
+			}
+
+			tb.SetImageList(imageList)
+		}
+
 		// FIXME: Protect against duplicate insertion
 		if imageIndex, err = tb.imageList.AddMasked(image); err != nil {
 			return
@@ -188,6 +328,32 @@ func (tb *ToolBar) imageIndex(image *Bitmap) (imageIndex int32, err error) {
 	return
 }
 
+// wholeDropDown returns if the whole button of action opens its menu, rather
+// than just the arrow.
+func (tb *ToolBar) wholeDropDown(action *Action) bool {
+	return !action.hasTriggeredHandlers()
+}
+
+// showDropDownMenu shows the menu of action below its button and triggers the
+// chosen action.
+func (tb *ToolBar) showDropDownMenu(action *Action) {
+	var r RECT
+	if 0 == tb.SendMessage(tbGetRect, uintptr(action.id), uintptr(unsafe.Pointer(&r))) {
+		return
+	}
+
+	pt := POINT{r.Left, r.Top}
+	if !ClientToScreen(tb.hWnd, &pt) {
+		return
+	}
+
+	anchor := Rectangle{int(pt.X), int(pt.Y), int(r.Right - r.Left), int(r.Bottom - r.Top)}
+
+	if chosen, _ := action.menu.ExecAnchored(anchor, PopupBelow); chosen != nil {
+		chosen.raiseTriggered()
+	}
+}
+
 func (tb *ToolBar) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	switch msg {
 	case WM_NOTIFY:
@@ -199,6 +365,15 @@ func (tb *ToolBar) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintpt
 			if action := actionsById[actionId]; action != nil {
 				action.raiseTriggered()
 			}
+
+		case tbnDropDown:
+			nmtb := (*nmToolBar)(unsafe.Pointer(lParam))
+
+			if action := actionsById[uint16(nmtb.IItem)]; action != nil && action.menu != nil {
+				tb.showDropDownMenu(action)
+			}
+
+			return 0 // TBDDRET_DEFAULT
 		}
 	}
 
@@ -228,20 +403,46 @@ func (tb *ToolBar) initButtonForAction(action *Action, state, style *byte, image
 		*style |= BTNS_GROUP
 	}
 
-	if action.text == "-" {
+	if action.menu != nil {
+		if tb.wholeDropDown(action) {
+			*style |= btnsWholeDropDown
+		} else {
+			*style |= btnsDropDown
+		}
+	}
+
+	actionText := tb.actionText(action)
+
+	if actionText == "-" {
 		*style = BTNS_SEP
 	}
 
-	if *image, err = tb.imageIndex(action.image); err != nil {
+	actionImage := tb.actionImage(action)
+
+	switch tb.buttonStyle {
+	case ToolBarButtonImageOnly:
+		if actionImage != nil && *style != BTNS_SEP {
+			actionText = ""
+		}
+
+	case ToolBarButtonTextOnly:
+		actionImage = nil
+	}
+
+	if *image, err = tb.imageIndex(actionImage); err != nil {
 		return
 	}
 
-	*text = uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(action.Text())))
+	*text = uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(actionText)))
 
 	return
 }
 
 func (tb *ToolBar) onActionChanged(action *Action) error {
+	if !tb.actionVisible(action) {
+		return nil
+	}
+
 	tbbi := TBBUTTONINFO{
 		DwMask: TBIF_IMAGE | TBIF_STATE | TBIF_STYLE | TBIF_TEXT,
 	}
@@ -263,13 +464,19 @@ func (tb *ToolBar) onActionChanged(action *Action) error {
 		uintptr(action.id),
 		uintptr(unsafe.Pointer(&tbbi))) {
 
-		return newError("SendMessage(TB_SETBUTTONINFO) failed")
+		return newAPIError("SendMessage(TB_SETBUTTONINFO)")
 	}
 
 	return nil
 }
 
 func (tb *ToolBar) onActionVisibleChanged(action *Action) error {
+	if tb.actionHidden(action) {
+		// The action is not presented, so there is no item to insert or
+		// remove.
+		return nil
+	}
+
 	if action.Visible() {
 		return tb.onInsertedAction(action)
 	}
@@ -285,10 +492,14 @@ func (tb *ToolBar) onInsertedAction(action *Action) (err error) {
 		}
 	}()
 
-	if !action.Visible() {
+	if !tb.actionVisible(action) {
 		return
 	}
 
+	return tb.insertButton(action)
+}
+
+func (tb *ToolBar) insertButton(action *Action) (err error) {
 	index := tb.actions.indexInObserver(action)
 
 	tbb := TBBUTTON{
@@ -310,25 +521,55 @@ func (tb *ToolBar) onInsertedAction(action *Action) (err error) {
 	tb.SendMessage(TB_BUTTONSTRUCTSIZE, uintptr(unsafe.Sizeof(tbb)), 0)
 
 	if FALSE == tb.SendMessage(TB_INSERTBUTTON, uintptr(index), uintptr(unsafe.Pointer(&tbb))) {
-		return newError("SendMessage(TB_ADDBUTTONS)")
+		return newAPIError("SendMessage(TB_ADDBUTTONS)")
 	}
 
 	if err = tb.applyDefaultButtonWidth(); err != nil {
 		return
 	}
 
+	if err = tb.applyButtonSize(); err != nil {
+		return
+	}
+
 	tb.SendMessage(TB_AUTOSIZE, 0, 0)
 
 	return
 }
 
 func (tb *ToolBar) onRemovingAction(action *Action) error {
-	index := tb.actions.indexInObserver(action)
-
 	action.removeChangedHandler(tb)
 
+	return tb.removeButton(action)
+}
+
+func (tb *ToolBar) removeButton(action *Action) error {
+	index := tb.actions.indexInObserver(action)
+
 	if 0 == tb.SendMessage(TB_DELETEBUTTON, uintptr(index), 0) {
-		return newError("SendMessage(TB_DELETEBUTTON) failed")
+		return newAPIError("SendMessage(TB_DELETEBUTTON)")
+	}
+
+	return nil
+}
+
+// SetActionPresentation sets how action appears in the *ToolBar, overriding
+// its text, image or visibility. Pass nil to remove the override.
+func (tb *ToolBar) SetActionPresentation(action *Action, presentation *ActionPresentation) error {
+	if !tb.actions.Contains(action) {
+		return newError("action not in tool bar")
+	}
+
+	if tb.actionVisible(action) {
+		if err := tb.removeButton(action); err != nil {
+			return err
+		}
+	}
+
+	tb.setActionPresentation(action, presentation)
+
+	if tb.actionVisible(action) {
+		return tb.insertButton(action)
 	}
 
 	return nil
@@ -336,7 +577,7 @@ func (tb *ToolBar) onRemovingAction(action *Action) error {
 
 func (tb *ToolBar) onClearingActions() error {
 	for i := tb.actions.Len() - 1; i >= 0; i-- {
-		if action := tb.actions.At(i); action.Visible() {
+		if action := tb.actions.At(i); tb.actionVisible(action) {
 			if err := tb.onRemovingAction(action); err != nil {
 				return err
 			}
@@ -11,17 +11,18 @@ import (
 
 import . "github.com/lxn/go-winapi"
 
-func init() {
-	var err error
-	if globalToolTip, err = NewToolTip(); err != nil {
-		panic(err)
-	}
-}
+const (
+	ttnShow = 0xFFFFFDF7 // -521
+
+	smCyCursor = 14
+)
 
 type ToolTip struct {
 	WidgetBase
 }
 
+// globalToolTip is created along with the first widget, that may have a tool
+// tip.
 var globalToolTip *ToolTip
 
 func NewToolTip() (*ToolTip, error) {
@@ -94,7 +95,7 @@ func (tt *ToolTip) setTitle(title string, icon uintptr) error {
 	}
 
 	if FALSE == tt.SendMessage(TTM_SETTITLE, icon, uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(title)))) {
-		return newError("TTM_SETTITLE failed")
+		return newAPIError("TTM_SETTITLE")
 	}
 
 	return nil
@@ -110,7 +111,7 @@ func (tt *ToolTip) AddTool(tool Widget) error {
 	ti.UId = uintptr(hwnd)
 
 	if FALSE == tt.SendMessage(TTM_ADDTOOL, 0, uintptr(unsafe.Pointer(&ti))) {
-		return newError("TTM_ADDTOOL failed")
+		return newAPIError("TTM_ADDTOOL")
 	}
 
 	return nil
@@ -172,3 +173,23 @@ func (tt *ToolTip) toolInfo(tool Widget) *TOOLINFO {
 
 	return &ti
 }
+
+// placeAtCursor moves the *ToolTip below the mouse cursor, or above it, if
+// there is no room below on the monitor showing it, using PopupBounds. It is
+// called for TTN_SHOW and returns true, so the control keeps the position.
+func (tt *ToolTip) placeAtCursor() uintptr {
+	var pt POINT
+	var r RECT
+	if !GetCursorPos(&pt) || !GetWindowRect(tt.hWnd, &r) {
+		return 0
+	}
+
+	anchor := Rectangle{int(pt.X), int(pt.Y), 1, int(GetSystemMetrics(smCyCursor))}
+	size := Size{int(r.Right - r.Left), int(r.Bottom - r.Top)}
+
+	b := PopupBounds(anchor, size, PopupBelow)
+
+	SetWindowPos(tt.hWnd, 0, int32(b.X), int32(b.Y), 0, 0, SWP_NOSIZE|SWP_NOZORDER|SWP_NOACTIVATE)
+
+	return 1
+}
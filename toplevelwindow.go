@@ -5,6 +5,7 @@
 package walk
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"syscall"
@@ -28,6 +29,9 @@ var syncFuncs struct {
 var syncMsgId uint32
 var taskbarButtonCreatedMsgId uint32
 
+// uiThreadId is the id of the thread running the message loop.
+var uiThreadId uint32
+
 func init() {
 	syncMsgId = RegisterWindowMessage(syscall.StringToUTF16Ptr("WalkSync"))
 	taskbarButtonCreatedMsgId = RegisterWindowMessage(syscall.StringToUTF16Ptr("TaskbarButtonCreated"))
@@ -39,6 +43,16 @@ func synchronize(f func()) {
 	syncFuncs.funcs = append(syncFuncs.funcs, f)
 }
 
+// postSynchronized enqueues func f like synchronize and wakes up the message
+// loop, so f is called even if no widget is involved.
+func postSynchronized(f func()) {
+	synchronize(f)
+
+	if syncHWnd != 0 {
+		PostMessage(syncHWnd, syncMsgId, 0, 0)
+	}
+}
+
 func runSynchronized() {
 	// Clear the list of callbacks first to avoid deadlock
 	// if a callback itself calls Synchronize()...
@@ -46,22 +60,47 @@ func runSynchronized() {
 	funcs := syncFuncs.funcs
 	syncFuncs.funcs = nil
 	syncFuncs.m.Unlock()
+
+	if len(funcs) == 0 {
+		return
+	}
+
+	beginChangeBatch()
+	defer endChangeBatch()
+
 	for _, f := range funcs {
 		f()
 	}
 }
 
+// SystemCommand identifies a standard item of the system menu of a
+// *TopLevelWindow.
+type SystemCommand uint32
+
+const (
+	SystemCommandRestore  SystemCommand = SC_RESTORE
+	SystemCommandMove     SystemCommand = SC_MOVE
+	SystemCommandSize     SystemCommand = SC_SIZE
+	SystemCommandMinimize SystemCommand = SC_MINIMIZE
+	SystemCommandMaximize SystemCommand = SC_MAXIMIZE
+	SystemCommandClose    SystemCommand = SC_CLOSE
+)
+
 type TopLevelWindow struct {
 	ContainerBase
 	owner                 RootWidget
 	closingPublisher      CloseEventPublisher
 	startingPublisher     EventPublisher
 	titleChangedPublisher EventPublisher
+	menuHintPublisher     StringEventPublisher
 	progressIndicator     *ProgressIndicator
 	icon                  *Icon
+	systemMenu            *Menu
 	prevFocusHWnd         HWND
 	isInRestoreState      bool
 	closeReason           CloseReason
+	ctx                   context.Context
+	cancelCtx             context.CancelFunc
 }
 
 func (tlw *TopLevelWindow) init() {
@@ -73,6 +112,15 @@ func (tlw *TopLevelWindow) init() {
 			return tlw.SetTitle(v.(string))
 		},
 		tlw.titleChangedPublisher.Event()))
+
+	topLevelWindows[tlw] = true
+	installVisibilityHooks()
+
+	updatePowerWindow(tlw, false)
+
+	// Windows belong to the thread that created them, which therefore has to
+	// run the message loop.
+	initUIThread()
 }
 
 func (tlw *TopLevelWindow) LayoutFlags() LayoutFlags {
@@ -108,28 +156,24 @@ func (tlw *TopLevelWindow) SetTitle(value string) error {
 }
 
 func (tlw *TopLevelWindow) Run() int {
+	uiThreadId = GetCurrentThreadId()
+	initUIThread()
+
+	endStartupTrace()
+
 	tlw.startingPublisher.Publish()
 
-	var msg MSG
+	publishRestarted()
 
-	for tlw.hWnd != 0 {
-		switch GetMessage(&msg, 0, 0, 0) {
-		case 0:
-			return int(msg.WParam)
+	publishActivation()
 
-		case -1:
-			return -1
-		}
+	defer appSingleton.saveSettingsOnExit()
 
-		if !IsDialogMessage(tlw.hWnd, &msg) {
-			TranslateMessage(&msg)
-			DispatchMessage(&msg)
-		}
+	defer setWatchdogTarget(setWatchdogTarget(tlw.hWnd))
 
-		runSynchronized()
-	}
+	defer recoverCrash()
 
-	return 0
+	return runMessageLoop(tlw)
 }
 
 func (tlw *TopLevelWindow) Starting() *Event {
@@ -176,6 +220,63 @@ func (tlw *TopLevelWindow) SetIcon(icon *Icon) {
 	tlw.SendMessage(WM_SETICON, 1, hIcon)
 }
 
+// SystemMenu returns the system menu of the *TopLevelWindow, which is shown
+// when clicking the window icon or pressing Alt+Space.
+//
+// Actions added to the returned *Menu are appended to the standard items and
+// raise their Triggered event as usual.
+func (tlw *TopLevelWindow) SystemMenu() (*Menu, error) {
+	if tlw.systemMenu != nil {
+		return tlw.systemMenu, nil
+	}
+
+	hMenu := GetSystemMenu(tlw.hWnd, false)
+	if hMenu == 0 {
+		return nil, lastError("GetSystemMenu")
+	}
+
+	count := GetMenuItemCount(hMenu)
+	if count == -1 {
+		return nil, lastError("GetMenuItemCount")
+	}
+
+	m := &Menu{
+		hMenu:           hMenu,
+		hWnd:            tlw.hWnd,
+		nativeItemCount: int(count),
+		system:          true,
+	}
+	m.actions = newActionList(m)
+
+	menusByHandle[hMenu] = m
+
+	tlw.systemMenu = m
+
+	return m, nil
+}
+
+// RemoveSystemMenuCommand removes a standard item from the system menu of the
+// *TopLevelWindow.
+//
+// Note that removing SystemCommandClose does not prevent the window from being
+// closed by other means, attach to Closing for that.
+func (tlw *TopLevelWindow) RemoveSystemMenuCommand(command SystemCommand) error {
+	m, err := tlw.SystemMenu()
+	if err != nil {
+		return err
+	}
+
+	if !DeleteMenu(m.hMenu, uint32(command), MF_BYCOMMAND) {
+		return lastError("DeleteMenu")
+	}
+
+	m.nativeItemCount--
+
+	DrawMenuBar(tlw.hWnd)
+
+	return nil
+}
+
 func (tlw *TopLevelWindow) Hide() {
 	tlw.widget.SetVisible(false)
 }
@@ -212,6 +313,10 @@ func (tlw *TopLevelWindow) SaveState() error {
 		wp.RcNormalPosition.Left, wp.RcNormalPosition.Top,
 		wp.RcNormalPosition.Right, wp.RcNormalPosition.Bottom)
 
+	if r, ok := snappedBounds(tlw.hWnd, &wp); ok {
+		state += fmt.Sprint(" ", r.Left, r.Top, r.Right, r.Bottom)
+	}
+
 	if err := tlw.putState(state); err != nil {
 		return err
 	}
@@ -237,13 +342,17 @@ func (tlw *TopLevelWindow) RestoreState() error {
 	}
 
 	var wp WINDOWPLACEMENT
+	var snapped RECT
 
-	if _, err := fmt.Sscan(state,
+	// The snapped bounds are only stored for snapped windows.
+	n, err := fmt.Sscan(state,
 		&wp.Flags, &wp.ShowCmd,
 		&wp.PtMinPosition.X, &wp.PtMinPosition.Y,
 		&wp.PtMaxPosition.X, &wp.PtMaxPosition.Y,
 		&wp.RcNormalPosition.Left, &wp.RcNormalPosition.Top,
-		&wp.RcNormalPosition.Right, &wp.RcNormalPosition.Bottom); err != nil {
+		&wp.RcNormalPosition.Right, &wp.RcNormalPosition.Bottom,
+		&snapped.Left, &snapped.Top, &snapped.Right, &snapped.Bottom)
+	if err != nil && n != 10 {
 		return err
 	}
 
@@ -253,6 +362,12 @@ func (tlw *TopLevelWindow) RestoreState() error {
 		return lastError("SetWindowPlacement")
 	}
 
+	if n == 14 {
+		if err := restoreSnappedBounds(tlw.hWnd, snapped); err != nil {
+			return err
+		}
+	}
+
 	if err := tlw.ContainerBase.RestoreState(); err != nil {
 		return err
 	}
@@ -264,6 +379,16 @@ func (tlw *TopLevelWindow) Closing() *CloseEvent {
 	return tlw.closingPublisher.Event()
 }
 
+// MenuHintChanged returns a *StringEvent that is published with the ToolTip
+// of an Action, whenever the user highlights a menu item of the
+// *TopLevelWindow.
+//
+// When the menu is closed, the event is published with an empty string. This
+// can be used to display descriptions of menu items in a status bar.
+func (tlw *TopLevelWindow) MenuHintChanged() *StringEvent {
+	return tlw.menuHintPublisher.Event()
+}
+
 func (tlw *TopLevelWindow) ProgressIndicator() *ProgressIndicator {
 	return tlw.progressIndicator
 }
@@ -286,6 +411,9 @@ func (tlw *TopLevelWindow) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr
 		tlw.closeReason = CloseReasonUnknown
 		var canceled bool
 		tlw.closingPublisher.Publish(&canceled, tlw.closeReason)
+		if !canceled && tlw.closesApplication() {
+			appSingleton.exitingPublisher.Publish(&canceled)
+		}
 		if !canceled {
 			if tlw.owner != nil {
 				tlw.owner.SetEnabled(true)
@@ -317,14 +445,74 @@ func (tlw *TopLevelWindow) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr
 		}
 		return 0
 
+	case WM_MENUSELECT:
+		var hint string
+		if action := actionFromMenuSelect(wParam, lParam); action != nil {
+			hint = action.ToolTip()
+		}
+		tlw.menuHintPublisher.Publish(hint)
+
 	case WM_SETTEXT:
 		tlw.titleChangedPublisher.Publish()
 
+	case WM_WINDOWPOSCHANGED, WM_DISPLAYCHANGE:
+		updateAppVisibility()
+
+	case WM_DESTROY:
+		delete(topLevelWindows, tlw)
+		if len(topLevelWindows) == 0 {
+			uninstallVisibilityHooks()
+		}
+		updateAppVisibility()
+		if tlw.systemMenu != nil {
+			tlw.systemMenu.Dispose()
+			tlw.systemMenu = nil
+		}
+		updatePowerWindow(tlw, true)
+		tlw.cancelContext()
+
+	case WM_POWERBROADCAST:
+		handlePowerBroadcast(tlw, wParam, lParam)
+
+	case WM_SYSCOLORCHANGE, WM_THEMECHANGED, WM_SETTINGCHANGE:
+		handleSystemChange(msg, wParam, lParam)
+
+	case wmDPIChanged:
+		handleDPIChanged(tlw, wParam, lParam)
+
+	case WM_QUERYENDSESSION:
+		if handleQueryEndSession(lParam) {
+			return TRUE
+		}
+		return FALSE
+
+	case WM_ENDSESSION:
+		handleEndSession(wParam)
+
+		// The process may be terminated without a normal exit.
+		if wParam != 0 && appSingleton.autoSavesSettings {
+			appSingleton.saveSettings()
+		}
+
 	case WM_SYSCOMMAND:
-		if wParam == SC_CLOSE {
+		// The low four bits of standard system commands are used by the
+		// system.
+		command := wParam & 0xFFF0
+
+		if command == SC_CLOSE {
 			tlw.closeReason = CloseReasonUser
 		}
 
+		if tlw.systemMenu != nil && command < 0xF000 {
+			// Not a standard system command, so it may be one of ours. Only
+			// items added by walk qualify, not those of the system or
+			// other code sharing the menu.
+			if action, ok := actionsById[uint16(wParam)]; ok && tlw.systemMenu.actions.Contains(action) {
+				action.raiseTriggered()
+				return 0
+			}
+		}
+
 	case taskbarButtonCreatedMsgId:
 		version := GetVersion()
 		major := version & 0xFF
@@ -0,0 +1,212 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const localeNameMaxLength = 85
+
+var (
+	procGetUserDefaultUILanguage = libkernel32.NewProc("GetUserDefaultUILanguage")
+	procLCIDToLocaleName         = libkernel32.NewProc("LCIDToLocaleName")
+)
+
+// TranslationCatalog maps the keys passed to Tr to the texts of a language.
+type TranslationCatalog map[string]string
+
+// CatalogLoader loads the TranslationCatalog of locale, e.g. "de-DE" or
+// "de". It returns an error satisfying os.IsNotExist, if there is none.
+type CatalogLoader func(locale string) (TranslationCatalog, error)
+
+// translationBinding keeps a target up to date with the translation of key.
+type translationBinding struct {
+	key  string
+	args []interface{}
+	set  func(text string) error
+}
+
+var (
+	catalogLoader       CatalogLoader
+	translationLocale   string
+	translationCatalog  TranslationCatalog
+	translationBindings = make(map[interface{}]*translationBinding)
+)
+
+// UserUILocale returns the name of the user interface language of the user,
+// e.g. "en-US".
+func UserUILocale() string {
+	langId, _, _ := procGetUserDefaultUILanguage.Call()
+
+	if procLCIDToLocaleName.Find() == nil {
+		// Since Windows Vista.
+		var buf [localeNameMaxLength]uint16
+
+		if ret, _, _ := procLCIDToLocaleName.Call(
+			langId,
+			uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(len(buf)),
+			0); ret != 0 {
+
+			return syscall.UTF16ToString(buf[:])
+		}
+	}
+
+	return "en-US"
+}
+
+// SetCatalogLoader sets the CatalogLoader, that loads the TranslationCatalog
+// when the language is set using SetLanguage.
+func (app *Application) SetCatalogLoader(loader CatalogLoader) {
+	catalogLoader = loader
+}
+
+// Language returns the locale of the current language, as passed to
+// SetLanguage.
+func (app *Application) Language() string {
+	return translationLocale
+}
+
+// SetLanguage loads the TranslationCatalog of locale, e.g. UserUILocale(),
+// using the CatalogLoader, and retranslates all texts set using Translate.
+//
+// If there is no catalog for a regional locale like "de-AT", the one of the
+// language, "de", is used. Without any catalog, Tr returns the keys.
+func (app *Application) SetLanguage(locale string) error {
+	var catalog TranslationCatalog
+
+	if catalogLoader != nil {
+		for _, l := range localeFallbacks(locale) {
+			c, err := catalogLoader(l)
+			if err == nil {
+				catalog = c
+				break
+			}
+
+			if !os.IsNotExist(err) {
+				return wrapError(err)
+			}
+		}
+	}
+
+	translationLocale = locale
+	translationCatalog = catalog
+
+	for _, b := range translationBindings {
+		b.set(Tr(b.key, b.args...))
+	}
+
+	app.languageChangedPublisher.Publish()
+
+	return nil
+}
+
+// LanguageChanged returns the *Event that is published, after SetLanguage
+// retranslated the texts set using Translate.
+//
+// Handlers update texts, that Translate can't handle, e.g. those of models.
+func (app *Application) LanguageChanged() *Event {
+	return app.languageChangedPublisher.Event()
+}
+
+// Tr returns the translation of key in the current language, or key itself,
+// if there is none.
+//
+// Like the texts of walk itself, key is translated by the TranslationFunction
+// set using SetTranslationFunc, or else looked up in the catalog loaded by
+// SetLanguage. If args are given, the translation is used as format for
+// fmt.Sprintf.
+func Tr(key string, args ...interface{}) string {
+	text := tr(key)
+
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+
+	return text
+}
+
+// translateFromCatalog is the TranslationFunction used, if none is set. It
+// prefers the entry for source qualified by its context, if any.
+func translateFromCatalog(source string, context ...string) string {
+	if len(context) > 0 {
+		if text, ok := translationCatalog[context[0]+gettextContextSeparator+source]; ok {
+			return text
+		}
+	}
+
+	if text, ok := translationCatalog[source]; ok {
+		return text
+	}
+
+	return source
+}
+
+// Translate sets the text of target to Tr(key, args...) and updates it,
+// whenever the language changes.
+//
+// target may be an *Action or anything with a SetText or SetTitle method,
+// like buttons, labels, windows, tab pages and table view columns. Calling
+// Translate again for target replaces the previous key. Widgets are released,
+// when they are disposed of, actions when they are removed from their last
+// ActionList.
+func Translate(target interface{}, key string, args ...interface{}) error {
+	var set func(text string) error
+
+	switch t := target.(type) {
+	case interface {
+		SetText(string) error
+	}:
+		set = t.SetText
+
+	case interface {
+		SetTitle(string) error
+	}:
+		set = t.SetTitle
+
+	default:
+		return newError("target does not support translation")
+	}
+
+	if _, ok := translationBindings[target]; !ok {
+		if w, ok := target.(Widget); ok {
+			w.BaseWidget().Disposing().Attach(func() {
+				delete(translationBindings, target)
+			})
+		}
+	}
+
+	b := &translationBinding{key: key, args: args, set: set}
+	translationBindings[target] = b
+
+	return b.set(Tr(key, args...))
+}
+
+// Untranslate stops updating the text of target, that was set using
+// Translate.
+func Untranslate(target interface{}) {
+	delete(translationBindings, target)
+}
+
+// localeFallbacks returns locale followed by the less specific locales, that
+// may be used instead, e.g. "zh-Hant-TW", "zh-Hant" and "zh".
+func localeFallbacks(locale string) []string {
+	locales := []string{locale}
+
+	for {
+		i := strings.LastIndex(locale, "-")
+		if i < 1 {
+			return locales
+		}
+
+		locale = locale[:i]
+		locales = append(locales, locale)
+	}
+}
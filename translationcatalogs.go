@@ -0,0 +1,243 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	moMagic = 0x950412de
+
+	// gettextContextSeparator separates the context of a message from its id
+	// in gettext catalogs.
+	gettextContextSeparator = "\x04"
+)
+
+// NewJSONCatalogLoader returns a CatalogLoader, that reads the file
+// <dir>\<locale>.json, containing an object mapping keys to texts.
+func NewJSONCatalogLoader(dir string) CatalogLoader {
+	return func(locale string) (TranslationCatalog, error) {
+		b, err := ioutil.ReadFile(filepath.Join(dir, locale+".json"))
+		if err != nil {
+			return nil, err
+		}
+
+		var catalog TranslationCatalog
+		if err := json.Unmarshal(b, &catalog); err != nil {
+			return nil, err
+		}
+
+		return catalog, nil
+	}
+}
+
+// NewPOCatalogLoader returns a CatalogLoader, that reads the gettext file
+// <dir>\<locale>.po, using the msgids as keys.
+//
+// Messages with a context are stored under the key
+// context + "\x04" + msgid. Of plural forms only the first one is used. Fuzzy
+// and untranslated messages are ignored.
+func NewPOCatalogLoader(dir string) CatalogLoader {
+	return func(locale string) (TranslationCatalog, error) {
+		f, err := os.Open(filepath.Join(dir, locale+".po"))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return parsePO(f.Name(), bufio.NewScanner(f))
+	}
+}
+
+// NewMOCatalogLoader returns a CatalogLoader, that reads the compiled gettext
+// file <dir>\<locale>.mo. Keys are built like for NewPOCatalogLoader.
+func NewMOCatalogLoader(dir string) CatalogLoader {
+	return func(locale string) (TranslationCatalog, error) {
+		b, err := ioutil.ReadFile(filepath.Join(dir, locale+".mo"))
+		if err != nil {
+			return nil, err
+		}
+
+		return parseMO(b)
+	}
+}
+
+func parsePO(name string, scanner *bufio.Scanner) (TranslationCatalog, error) {
+	catalog := make(TranslationCatalog)
+
+	var ctx, id, str *string
+	var fuzzy bool
+
+	flush := func() {
+		if id != nil && str != nil && *id != "" && *str != "" && !fuzzy {
+			key := *id
+			if ctx != nil {
+				key = *ctx + gettextContextSeparator + key
+			}
+
+			catalog[key] = *str
+		}
+
+		ctx, id, str, fuzzy = nil, nil, nil, false
+	}
+
+	// cur is the string continuation lines are appended to.
+	var cur *string
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			flush()
+			cur = nil
+			continue
+
+		case strings.HasPrefix(line, "#,"):
+			if str != nil {
+				flush()
+			}
+			fuzzy = strings.Contains(line, "fuzzy")
+			continue
+
+		case strings.HasPrefix(line, "#"):
+			continue
+
+		case strings.HasPrefix(line, `"`):
+			if cur == nil {
+				return nil, newError(name + ":" + strconv.Itoa(lineNo) + ": unexpected string")
+			}
+
+			s, err := strconv.Unquote(line)
+			if err != nil {
+				return nil, newError(name + ":" + strconv.Itoa(lineNo) + ": invalid string")
+			}
+
+			*cur += s
+			continue
+		}
+
+		i := strings.IndexByte(line, ' ')
+		if i < 0 {
+			return nil, newError(name + ":" + strconv.Itoa(lineNo) + ": syntax error")
+		}
+
+		keyword := line[:i]
+
+		s, err := strconv.Unquote(strings.TrimSpace(line[i+1:]))
+		if err != nil {
+			return nil, newError(name + ":" + strconv.Itoa(lineNo) + ": invalid string")
+		}
+
+		switch keyword {
+		case "msgctxt":
+			if str != nil {
+				flush()
+			}
+			ctx = &s
+			cur = ctx
+
+		case "msgid":
+			if str != nil {
+				flush()
+			}
+			id = &s
+			cur = id
+
+		case "msgstr", "msgstr[0]":
+			str = &s
+			cur = str
+
+		default:
+			// msgid_plural and further plural forms.
+			var ignored string
+			cur = &ignored
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	flush()
+
+	return catalog, nil
+}
+
+func parseMO(b []byte) (TranslationCatalog, error) {
+	if len(b) < 20 {
+		return nil, newError("invalid .mo file")
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if binary.BigEndian.Uint32(b) == moMagic {
+		order = binary.BigEndian
+	} else if order.Uint32(b) != moMagic {
+		return nil, newError("invalid .mo file")
+	}
+
+	count := order.Uint32(b[8:])
+	idsOffset := order.Uint32(b[12:])
+	strsOffset := order.Uint32(b[16:])
+
+	// Both tables of 8 bytes per string must fit, before count is trusted.
+	for _, tableOffset := range []uint32{idsOffset, strsOffset} {
+		if uint64(tableOffset)+8*uint64(count) > uint64(len(b)) {
+			return nil, newError("invalid .mo file")
+		}
+	}
+
+	str := func(tableOffset, i int) (string, bool) {
+		entry := tableOffset + 8*i
+		if entry < 0 || entry+8 > len(b) {
+			return "", false
+		}
+
+		length := int(order.Uint32(b[entry:]))
+		offset := int(order.Uint32(b[entry+4:]))
+		if offset < 0 || length < 0 || offset+length > len(b) {
+			return "", false
+		}
+
+		s := b[offset : offset+length]
+
+		// Only the first of the plural forms, separated by NUL, is used.
+		if i := bytes.IndexByte(s, 0); i > -1 {
+			s = s[:i]
+		}
+
+		return string(s), true
+	}
+
+	catalog := make(TranslationCatalog, count)
+
+	for i := 0; i < int(count); i++ {
+		id, ok := str(int(idsOffset), i)
+		if !ok {
+			return nil, newError("invalid .mo file")
+		}
+
+		text, ok := str(int(strsOffset), i)
+		if !ok {
+			return nil, newError("invalid .mo file")
+		}
+
+		// The empty msgid holds the header.
+		if id != "" && text != "" {
+			catalog[id] = text
+		}
+	}
+
+	return catalog, nil
+}
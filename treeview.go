@@ -11,6 +11,13 @@ import (
 
 import . "github.com/lxn/go-winapi"
 
+const (
+	tvhtOnItemIcon      = 0x0002
+	tvhtOnItemLabel     = 0x0004
+	tvhtOnItemStateIcon = 0x0040
+	tvhtOnItem          = tvhtOnItemIcon | tvhtOnItemLabel | tvhtOnItemStateIcon
+)
+
 type treeViewItemInfo struct {
 	handle       HTREEITEM
 	child2Handle map[TreeItem]HTREEITEM
@@ -32,6 +39,9 @@ type TreeView struct {
 	itemCollapsedPublisher        TreeItemEventPublisher
 	itemExpandedPublisher         TreeItemEventPublisher
 	currentItemChangedPublisher   EventPublisher
+	itemActivatedPublisher        EventPublisher
+	itemActivationMode            ItemActivationMode
+	dragAutoScroll                dragAutoScroller
 }
 
 func NewTreeView(parent Container) (*TreeView, error) {
@@ -76,6 +86,21 @@ func (tv *TreeView) Dispose() {
 	tv.disposeImageListAndCaches()
 }
 
+// DragAutoScroll returns how the *TreeView scrolls, while items are dragged
+// near its edges.
+func (tv *TreeView) DragAutoScroll() DragAutoScrollOptions {
+	return tv.dragAutoScroll.options
+}
+
+// SetDragAutoScroll sets how the *TreeView scrolls, while items are dragged
+// near its edges.
+//
+// Scrolling starts, when the tree view reports the begin of a drag operation,
+// and ends when the mouse button is released.
+func (tv *TreeView) SetDragAutoScroll(options DragAutoScrollOptions) {
+	tv.dragAutoScroll.setOptions(options)
+}
+
 func (tv *TreeView) Model() TreeModel {
 	return tv.model
 }
@@ -143,7 +168,7 @@ func (tv *TreeView) SetCurrentItem(item TreeItem) error {
 	}
 
 	if 0 == tv.SendMessage(TVM_SELECTITEM, TVGN_CARET, uintptr(handle)) {
-		return newError("SendMessage(TVM_SELECTITEM) failed")
+		return newAPIError("SendMessage(TVM_SELECTITEM)")
 	}
 
 	tv.currItem = item
@@ -184,7 +209,7 @@ func (tv *TreeView) resetItems() error {
 
 func (tv *TreeView) clearItems() error {
 	if 0 == tv.SendMessage(TVM_DELETEITEM, 0, 0) {
-		return newError("SendMessage(TVM_DELETEITEM) failed")
+		return newAPIError("SendMessage(TVM_DELETEITEM)")
 	}
 
 	tv.item2Info = make(map[TreeItem]*treeViewItemInfo)
@@ -285,7 +310,7 @@ func (tv *TreeView) insertItem(index int, item TreeItem) (HTREEITEM, error) {
 
 	hItem := HTREEITEM(tv.SendMessage(TVM_INSERTITEM, 0, uintptr(unsafe.Pointer(&tvins))))
 	if hItem == 0 {
-		return 0, newError("TVM_INSERTITEM failed")
+		return 0, newAPIError("TVM_INSERTITEM")
 	}
 	tv.item2Info[item] = &treeViewItemInfo{hItem, make(map[TreeItem]HTREEITEM)}
 	tv.handle2Item[hItem] = item
@@ -326,7 +351,7 @@ func (tv *TreeView) updateItem(item TreeItem) error {
 	tv.setTVITEMImageInfo(tvi, item)
 
 	if 0 == tv.SendMessage(TVM_SETITEM, 0, uintptr(unsafe.Pointer(tvi))) {
-		return newError("SendMessage(TVM_SETITEM) failed")
+		return newAPIError("SendMessage(TVM_SETITEM)")
 	}
 
 	return nil
@@ -343,7 +368,7 @@ func (tv *TreeView) removeItem(item TreeItem) error {
 	}
 
 	if 0 == tv.SendMessage(TVM_DELETEITEM, 0, uintptr(info.handle)) {
-		return newError("SendMessage(TVM_DELETEITEM) failed")
+		return newAPIError("SendMessage(TVM_DELETEITEM)")
 	}
 
 	if parentInfo := tv.item2Info[item.Parent()]; parentInfo != nil {
@@ -377,8 +402,70 @@ func (tv *TreeView) CurrentItemChanged() *Event {
 	return tv.currentItemChangedPublisher.Event()
 }
 
+// ItemActivated returns the event that is published after the current item
+// was activated.
+//
+// An item is activated when it is clicked as specified by the
+// ItemActivationMode or the enter key is pressed when the item is current.
+func (tv *TreeView) ItemActivated() *Event {
+	return tv.itemActivatedPublisher.Event()
+}
+
+// ItemActivationMode returns how the user activates items.
+func (tv *TreeView) ItemActivationMode() ItemActivationMode {
+	return tv.itemActivationMode
+}
+
+// SetItemActivationMode sets how the user activates items.
+//
+// A *TreeView always highlights the item under the mouse, so
+// ItemActivationHover behaves like ItemActivationSingleClick.
+func (tv *TreeView) SetItemActivationMode(mode ItemActivationMode) error {
+	if !mode.valid() {
+		return newError("invalid mode")
+	}
+
+	tv.itemActivationMode = mode
+
+	return nil
+}
+
+// activateItemAtCursor makes the item under the mouse current and publishes
+// ItemActivated. Clicks on the expand button or beside the item are ignored.
+func (tv *TreeView) activateItemAtCursor() {
+	var pt POINT
+	if !GetCursorPos(&pt) || !ScreenToClient(tv.hWnd, &pt) {
+		return
+	}
+
+	hti := TVHITTESTINFO{Pt: pt}
+	tv.SendMessage(TVM_HITTEST, 0, uintptr(unsafe.Pointer(&hti)))
+
+	if hti.Flags&tvhtOnItem == 0 {
+		return
+	}
+
+	item, ok := tv.handle2Item[hti.HItem]
+	if !ok {
+		return
+	}
+
+	if err := tv.SetCurrentItem(item); err != nil {
+		return
+	}
+
+	tv.itemActivatedPublisher.Publish()
+}
+
 func (tv *TreeView) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	switch msg {
+	case WM_GETDLGCODE:
+		if wParam == VK_RETURN {
+			// Otherwise the dialog manager takes Return and NM_RETURN is
+			// never sent.
+			return DLGC_WANTALLKEYS
+		}
+
 	case WM_NOTIFY:
 		nmhdr := (*NMHDR)(unsafe.Pointer(lParam))
 
@@ -429,6 +516,30 @@ func (tv *TreeView) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintp
 			tv.currItem = tv.handle2Item[nmtv.ItemNew.HItem]
 
 			tv.currentItemChangedPublisher.Publish()
+
+		case NM_CLICK:
+			if tv.itemActivationMode != ItemActivationDoubleClick {
+				tv.activateItemAtCursor()
+			}
+
+		case NM_DBLCLK:
+			if tv.itemActivationMode == ItemActivationDoubleClick {
+				tv.activateItemAtCursor()
+			}
+
+		case NM_RETURN:
+			if tv.currItem != nil {
+				tv.itemActivatedPublisher.Publish()
+			}
+
+			// Suppress the beep.
+			return 1
+
+		case TVN_BEGINDRAG:
+			tv.dragAutoScroll.start(&tv.WidgetBase, VK_LBUTTON)
+
+		case TVN_BEGINRDRAG:
+			tv.dragAutoScroll.start(&tv.WidgetBase, VK_RBUTTON)
 		}
 	}
 
@@ -0,0 +1,217 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"runtime"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// UserConsentResult is the outcome of RequestUserConsent.
+type UserConsentResult int
+
+const (
+	// UserConsentVerified means the user was verified.
+	UserConsentVerified UserConsentResult = iota
+
+	// UserConsentFailed means the user canceled the prompt or couldn't be
+	// verified.
+	UserConsentFailed
+
+	// UserConsentUnavailable means Windows Hello isn't supported, set up for
+	// the user or allowed by policy.
+	UserConsentUnavailable
+)
+
+const (
+	roInitMultithreaded = 1
+
+	regdbEClassNotReg = 0x80040154
+
+	asyncStatusStarted = 0
+	asyncStatusError   = 3
+)
+
+// Values of the WinRT UserConsentVerificationResult enumeration.
+const (
+	userConsentVerificationVerified             = 0
+	userConsentVerificationDeviceNotPresent     = 1
+	userConsentVerificationNotConfiguredForUser = 2
+	userConsentVerificationDisabledByPolicy     = 3
+)
+
+var (
+	libcombase                 = syscall.NewLazyDLL("combase.dll")
+	procRoInitialize           = libcombase.NewProc("RoInitialize")
+	procRoUninitialize         = libcombase.NewProc("RoUninitialize")
+	procRoGetActivationFactory = libcombase.NewProc("RoGetActivationFactory")
+	procWindowsCreateString    = libcombase.NewProc("WindowsCreateString")
+	procWindowsDeleteString    = libcombase.NewProc("WindowsDeleteString")
+)
+
+var (
+	iidIUserConsentVerifierInterop = GUID{0x39E050C3, 0x4E74, 0x441A, [8]byte{0x8D, 0xC0, 0xB8, 0x11, 0x04, 0xDF, 0x94, 0x9C}}
+	iidIAsyncOperationUserConsent  = GUID{0xFD596FFD, 0x2318, 0x558F, [8]byte{0x9D, 0xBE, 0xD2, 0x1D, 0xF4, 0x37, 0x64, 0xA5}}
+	iidIAsyncInfo                  = GUID{0x00000036, 0x0000, 0x0000, [8]byte{0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+)
+
+// Vtable indexes of the methods used, after those of IUnknown and
+// IInspectable.
+const (
+	vtblRequestVerificationForWindowAsync = 6
+	vtblAsyncInfoStatus                   = 7
+	vtblAsyncInfoErrorCode                = 8
+	vtblAsyncOperationGetResults          = 8
+)
+
+func newHString(s string) (uintptr, error) {
+	u := syscall.StringToUTF16(s)
+
+	var hs uintptr
+	if hr, _, _ := procWindowsCreateString.Call(
+		uintptr(unsafe.Pointer(&u[0])),
+		uintptr(len(u)-1),
+		uintptr(unsafe.Pointer(&hs))); FAILED(HRESULT(int32(hr))) {
+
+		return 0, errorFromHRESULT("WindowsCreateString", HRESULT(int32(hr)))
+	}
+
+	return hs, nil
+}
+
+func deleteHString(hs uintptr) {
+	procWindowsDeleteString.Call(hs)
+}
+
+// RequestUserConsent shows the Windows Hello prompt with message, owned by
+// owner, which may be nil, e.g. before revealing a password or confirming a
+// payment.
+//
+// RequestUserConsent returns immediately. The prompt is shown by another
+// thread and done is called on the UI thread with the result. If err is not
+// nil, result is UserConsentFailed.
+func RequestUserConsent(owner RootWidget, message string, done func(result UserConsentResult, err error)) {
+	var hwnd HWND
+	if owner != nil {
+		hwnd = owner.Handle()
+	}
+
+	go func() {
+		result, err := requestUserConsent(hwnd, message)
+
+		postSynchronized(func() {
+			done(result, err)
+		})
+	}()
+}
+
+func requestUserConsent(hwnd HWND, message string) (UserConsentResult, error) {
+	// Windows before 8 has no WinRT.
+	if procRoInitialize.Find() != nil {
+		return UserConsentUnavailable, nil
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if hr, _, _ := procRoInitialize.Call(roInitMultithreaded); FAILED(HRESULT(int32(hr))) {
+		return UserConsentFailed, errorFromHRESULT("RoInitialize", HRESULT(int32(hr)))
+	}
+	defer procRoUninitialize.Call()
+
+	className, err := newHString("Windows.Security.Credentials.UI.UserConsentVerifier")
+	if err != nil {
+		return UserConsentFailed, err
+	}
+	defer deleteHString(className)
+
+	var interop unsafe.Pointer
+	if hr, _, _ := procRoGetActivationFactory.Call(
+		className,
+		uintptr(unsafe.Pointer(&iidIUserConsentVerifierInterop)),
+		uintptr(unsafe.Pointer(&interop))); FAILED(HRESULT(int32(hr))) {
+
+		if uint32(hr) == regdbEClassNotReg {
+			return UserConsentUnavailable, nil
+		}
+
+		return UserConsentFailed, errorFromHRESULT("RoGetActivationFactory", HRESULT(int32(hr)))
+	}
+	defer comRelease(interop)
+
+	hsMessage, err := newHString(message)
+	if err != nil {
+		return UserConsentFailed, err
+	}
+	defer deleteHString(hsMessage)
+
+	var operation unsafe.Pointer
+	if hr := comCall(
+		interop,
+		vtblRequestVerificationForWindowAsync,
+		uintptr(hwnd),
+		hsMessage,
+		uintptr(unsafe.Pointer(&iidIAsyncOperationUserConsent)),
+		uintptr(unsafe.Pointer(&operation))); FAILED(hr) {
+
+		return UserConsentFailed, errorFromHRESULT("IUserConsentVerifierInterop.RequestVerificationForWindowAsync", hr)
+	}
+	defer comRelease(operation)
+
+	var info unsafe.Pointer
+	if hr := comCall(
+		operation,
+		iUnknownQueryInterface,
+		uintptr(unsafe.Pointer(&iidIAsyncInfo)),
+		uintptr(unsafe.Pointer(&info))); FAILED(hr) {
+
+		return UserConsentFailed, errorFromHRESULT("IAsyncOperation.QueryInterface", hr)
+	}
+	defer comRelease(info)
+
+	// Polling spares us implementing the completion handler interface. The
+	// prompt waits for the user anyway.
+	var status uint32
+	for {
+		if hr := comCall(info, vtblAsyncInfoStatus, uintptr(unsafe.Pointer(&status))); FAILED(hr) {
+			return UserConsentFailed, errorFromHRESULT("IAsyncInfo.Status", hr)
+		}
+
+		if status != asyncStatusStarted {
+			break
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if status == asyncStatusError {
+		var code HRESULT
+		comCall(info, vtblAsyncInfoErrorCode, uintptr(unsafe.Pointer(&code)))
+
+		return UserConsentFailed, errorFromHRESULT("UserConsentVerifier.RequestVerificationAsync", code)
+	}
+
+	var verification int32
+	if hr := comCall(operation, vtblAsyncOperationGetResults, uintptr(unsafe.Pointer(&verification))); FAILED(hr) {
+		// The operation was canceled.
+		return UserConsentFailed, nil
+	}
+
+	switch verification {
+	case userConsentVerificationVerified:
+		return UserConsentVerified, nil
+
+	case userConsentVerificationDeviceNotPresent,
+		userConsentVerificationNotConfiguredForUser,
+		userConsentVerificationDisabledByPolicy:
+		return UserConsentUnavailable, nil
+	}
+
+	return UserConsentFailed, nil
+}
@@ -31,6 +31,14 @@ func mini(a, b int) int {
 	return b
 }
 
+func absi(a int) int {
+	if a < 0 {
+		return -a
+	}
+
+	return a
+}
+
 func boolToInt(value bool) int {
 	if value {
 		return 1
@@ -42,26 +50,39 @@ func boolToInt(value bool) int {
 func ParseFloat(s string) (float64, error) {
 	s = strings.TrimSpace(s)
 
-	t, _ := FormatFloat(1000, 2)
+	decimal, thousand := localeNumberSeparators()
 
-	replaceSep := func(new string, index func(string, func(rune) bool) int) {
-		i := index(t, func(r rune) bool {
-			return r < '0' || r > '9'
-		})
+	if thousand != "" {
+		s = strings.Replace(s, thousand, "", -1)
+	}
+	if decimal != "" {
+		s = strings.Replace(s, decimal, ".", -1)
+	}
 
-		var sep string
-		if i > -1 {
-			sep = string(t[i])
-		}
-		if sep != "" {
-			s = strings.Replace(s, string(sep), new, -1)
+	return strconv.ParseFloat(s, 64)
+}
+
+// localeNumberSeparators returns the decimal and thousand separators of the
+// locale of the user. thousand is empty, if the locale does not group digits.
+func localeNumberSeparators() (decimal, thousand string) {
+	t, _ := FormatFloat(1000, 2)
+
+	var seps []string
+	for _, r := range t {
+		if r < '0' || r > '9' {
+			seps = append(seps, string(r))
 		}
 	}
 
-	replaceSep("", strings.IndexFunc)
-	replaceSep(".", strings.LastIndexFunc)
+	switch len(seps) {
+	case 0:
+		return "", ""
 
-	return strconv.ParseFloat(s, 64)
+	case 1:
+		return seps[0], ""
+	}
+
+	return seps[len(seps)-1], seps[0]
 }
 
 func FormatFloat(f float64, prec int) (string, error) {
@@ -0,0 +1,313 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	dwmwaExtendedFrameBounds = 9
+	dwmwaCloaked             = 14
+
+	gwHwndPrev = 3
+
+	rgnDiff    = 4
+	nullRegion = 1
+
+	eventSystemForeground  = 0x0003
+	eventSystemMinimizeEnd = 0x0017
+	eventObjectShow        = 0x8002
+	eventObjectUncloaked   = 0x8018
+	wineventOutOfContext   = 0x0000
+	objIdWindow            = 0
+
+	wsExTransparent = 0x00000020
+)
+
+var (
+	libdwmapi                 = syscall.NewLazyDLL("dwmapi.dll")
+	procDwmGetWindowAttribute = libdwmapi.NewProc("DwmGetWindowAttribute")
+	procGetWindow             = libuser32.NewProc("GetWindow")
+	procSetWinEventHook       = libuser32.NewProc("SetWinEventHook")
+	procUnhookWinEvent        = libuser32.NewProc("UnhookWinEvent")
+	procCreateRectRgn         = libgdi32.NewProc("CreateRectRgn")
+	procCombineRgn            = libgdi32.NewProc("CombineRgn")
+)
+
+var (
+	topLevelWindows = make(map[*TopLevelWindow]bool)
+	timers          []*Timer
+
+	// visibilityHooks report windows of other applications that are shown,
+	// hidden, moved, activated, minimized or cloaked, because they may cover
+	// or uncover ours.
+	visibilityHooks       []uintptr
+	visibilityHookProcPtr = syscall.NewCallback(visibilityHookProc)
+)
+
+// Timer calls a function periodically on the UI thread, while the application
+// is visible.
+type Timer struct {
+	interval time.Duration
+	f        func()
+	id       uintptr
+}
+
+// StartTimer starts a new *Timer that calls f every interval.
+//
+// While no window of the application can be seen, e.g. because all of them
+// are minimized, hidden, cloaked, covered by other windows or on a
+// disconnected monitor, the *Timer is
+// suspended. This saves battery for work like polling a model, that is only
+// useful while the user can see the result.
+func (app *Application) StartTimer(interval time.Duration, f func()) *Timer {
+	t := &Timer{interval: interval, f: f}
+
+	timers = append(timers, t)
+
+	if !app.hidden {
+		t.resume()
+	}
+
+	return t
+}
+
+// Stop stops the *Timer for good.
+func (t *Timer) Stop() {
+	t.suspend()
+
+	for i, timer := range timers {
+		if timer == t {
+			timers = append(timers[:i], timers[i+1:]...)
+			break
+		}
+	}
+}
+
+func (t *Timer) suspend() {
+	stopTimer(t.id)
+	t.id = 0
+}
+
+func (t *Timer) resume() {
+	if t.id == 0 {
+		t.id = startTimer(t.interval, t.f)
+	}
+}
+
+// Visible returns if any window of the application can be seen by the user.
+//
+// Windows that are minimized, hidden, cloaked, e.g. because they are on
+// another virtual desktop, completely covered by other windows or on a
+// disconnected monitor are not considered visible.
+func (app *Application) Visible() bool {
+	return !app.hidden
+}
+
+// VisibilityChanged returns an *Event that is published when the Visible
+// property of the application changed.
+//
+// Timers started using StartTimer and animations driven by the
+// AnimationClock are suspended while the application is not visible.
+func (app *Application) VisibilityChanged() *Event {
+	return app.visibilityChangedPublisher.Event()
+}
+
+// updateAppVisibility recomputes the Visible property of the application
+// after a top-level window changed.
+func updateAppVisibility() {
+	hidden := true
+	for tlw := range topLevelWindows {
+		if windowCanBeSeen(tlw.hWnd) {
+			hidden = false
+			break
+		}
+	}
+
+	app := appSingleton
+	if hidden == app.hidden {
+		return
+	}
+
+	app.hidden = hidden
+
+	for _, t := range timers {
+		if hidden {
+			t.suspend()
+		} else {
+			t.resume()
+		}
+	}
+
+	if hidden {
+		animationClockSingleton.suspend()
+	} else {
+		animationClockSingleton.resume()
+	}
+
+	app.visibilityChangedPublisher.Publish()
+}
+
+func windowCanBeSeen(hwnd HWND) bool {
+	if hwnd == 0 || !IsWindowVisible(hwnd) || IsIconic(hwnd) {
+		return false
+	}
+
+	if MonitorFromWindow(hwnd, MONITOR_DEFAULTTONULL) == 0 {
+		return false
+	}
+
+	return !windowCloaked(hwnd) && !windowOccluded(hwnd)
+}
+
+func windowCloaked(hwnd HWND) bool {
+	if procDwmGetWindowAttribute.Find() != nil {
+		return false
+	}
+
+	var cloaked uint32
+
+	ret, _, _ := procDwmGetWindowAttribute.Call(
+		uintptr(hwnd),
+		dwmwaCloaked,
+		uintptr(unsafe.Pointer(&cloaked)),
+		unsafe.Sizeof(cloaked))
+
+	return ret == 0 && cloaked != 0
+}
+
+// windowBounds returns the visible bounds of the window in screen
+// coordinates, which exclude the invisible resize borders since Windows 10.
+func windowBounds(hwnd HWND) (RECT, bool) {
+	var rc RECT
+
+	if procDwmGetWindowAttribute.Find() == nil {
+		if ret, _, _ := procDwmGetWindowAttribute.Call(
+			uintptr(hwnd),
+			dwmwaExtendedFrameBounds,
+			uintptr(unsafe.Pointer(&rc)),
+			unsafe.Sizeof(rc)); ret == 0 {
+
+			return rc, true
+		}
+	}
+
+	return rc, GetWindowRect(hwnd, &rc)
+}
+
+func createRectRgn(rc RECT) uintptr {
+	rgn, _, _ := procCreateRectRgn.Call(
+		uintptr(rc.Left),
+		uintptr(rc.Top),
+		uintptr(rc.Right),
+		uintptr(rc.Bottom))
+
+	return rgn
+}
+
+// windowOccluded returns if the window is completely covered by the opaque
+// windows above it in the z-order.
+func windowOccluded(hwnd HWND) bool {
+	rc, ok := windowBounds(hwnd)
+	if !ok {
+		return false
+	}
+
+	rgn := createRectRgn(rc)
+	if rgn == 0 {
+		return false
+	}
+	defer DeleteObject(HGDIOBJ(rgn))
+
+	for h := nextWindowAbove(hwnd); h != 0; h = nextWindowAbove(h) {
+		if !IsWindowVisible(h) || IsIconic(h) || windowCloaked(h) {
+			continue
+		}
+
+		// Layered windows may be translucent or let the mouse through, so
+		// they don't count.
+		if uint32(GetWindowLong(h, GWL_EXSTYLE))&(wsExLayered|wsExTransparent) != 0 {
+			continue
+		}
+
+		other, ok := windowBounds(h)
+		if !ok {
+			continue
+		}
+
+		otherRgn := createRectRgn(other)
+		if otherRgn == 0 {
+			return false
+		}
+
+		ret, _, _ := procCombineRgn.Call(rgn, rgn, otherRgn, rgnDiff)
+		DeleteObject(HGDIOBJ(otherRgn))
+
+		if ret == nullRegion {
+			return true
+		}
+	}
+
+	return false
+}
+
+func nextWindowAbove(hwnd HWND) HWND {
+	ret, _, _ := procGetWindow.Call(uintptr(hwnd), gwHwndPrev)
+
+	return HWND(ret)
+}
+
+// installVisibilityHooks makes sure the Visible property of the application is
+// also recomputed, when windows of other applications change.
+func installVisibilityHooks() {
+	if len(visibilityHooks) > 0 || procSetWinEventHook.Find() != nil {
+		return
+	}
+
+	for _, r := range [][2]uintptr{
+		{eventSystemForeground, eventSystemMinimizeEnd},
+		{eventObjectShow, eventObjectUncloaked},
+	} {
+		if hook, _, _ := procSetWinEventHook.Call(
+			r[0],
+			r[1],
+			0,
+			visibilityHookProcPtr,
+			0,
+			0,
+			wineventOutOfContext); hook != 0 {
+
+			visibilityHooks = append(visibilityHooks, hook)
+		}
+	}
+}
+
+func uninstallVisibilityHooks() {
+	for _, hook := range visibilityHooks {
+		procUnhookWinEvent.Call(hook)
+	}
+
+	visibilityHooks = nil
+}
+
+func visibilityHookProc(hook, event, hwnd, idObject, idChild, idEventThread, eventTime uintptr) uintptr {
+	if int32(idObject) != objIdWindow || idChild != 0 || hwnd == 0 {
+		return 0
+	}
+
+	// Only top-level windows can cover ours.
+	if GetAncestor(HWND(hwnd), GA_ROOT) != HWND(hwnd) {
+		return 0
+	}
+
+	updateAppVisibility()
+
+	return 0
+}
@@ -34,7 +34,7 @@ var translation TranslationFunction
 
 func tr(source string, context ...string) string {
 	if translation == nil {
-		return source
+		return translateFromCatalog(source, context...)
 	}
 
 	return translation(source, context...)
@@ -0,0 +1,252 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+import . "github.com/lxn/go-winapi"
+
+const (
+	defaultWatchdogTimeout     = 5 * time.Second
+	defaultWatchdogOverlayText = "Still working…"
+)
+
+// WatchdogOptions configure the message loop watchdog.
+type WatchdogOptions struct {
+	// Timeout is the time the UI thread may not pump messages, before it is
+	// considered hanging. It defaults to 5 seconds.
+	Timeout time.Duration
+
+	// Log receives the stacks of all goroutines, when a hang is detected.
+	// If nil, the standard logger is used.
+	Log io.Writer
+
+	// ShowOverlay makes the watchdog show a small window with OverlayText,
+	// while the UI thread hangs.
+	ShowOverlay bool
+
+	// OverlayText defaults to "Still working…".
+	OverlayText string
+}
+
+type watchdog struct {
+	options WatchdogOptions
+	stop    chan struct{}
+	pingAck int64
+}
+
+var (
+	watchdogPingMsgId uint32
+
+	watchdogMutex  sync.Mutex
+	activeWatchdog *watchdog
+
+	// watchdogTarget is the window the pings are posted to, stored
+	// atomically, because it is read by the watchdog goroutine.
+	watchdogTarget uintptr
+)
+
+func init() {
+	watchdogPingMsgId = RegisterWindowMessage(syscall.StringToUTF16Ptr("WalkWatchdogPing"))
+}
+
+// StartWatchdog starts a watchdog, that detects when the UI thread doesn't
+// pump messages for longer than the timeout of options, e.g. because of an
+// accidental blocking call.
+//
+// When a hang is detected, the stacks of all goroutines are written to the
+// log, so the culprit can be found. The watchdog is meant for diagnostics and
+// is off by default.
+func (app *Application) StartWatchdog(options WatchdogOptions) {
+	app.StopWatchdog()
+
+	if options.Timeout <= 0 {
+		options.Timeout = defaultWatchdogTimeout
+	}
+	if options.OverlayText == "" {
+		options.OverlayText = defaultWatchdogOverlayText
+	}
+
+	wd := &watchdog{options: options, stop: make(chan struct{})}
+
+	watchdogMutex.Lock()
+	activeWatchdog = wd
+	watchdogMutex.Unlock()
+
+	go wd.run()
+}
+
+// StopWatchdog stops the watchdog started by StartWatchdog, if any.
+func (app *Application) StopWatchdog() {
+	watchdogMutex.Lock()
+	defer watchdogMutex.Unlock()
+
+	if activeWatchdog != nil {
+		close(activeWatchdog.stop)
+		activeWatchdog = nil
+	}
+}
+
+// setWatchdogTarget makes hwnd the window the watchdog pings and returns the
+// previous one.
+func setWatchdogTarget(hwnd HWND) HWND {
+	return HWND(atomic.SwapUintptr(&watchdogTarget, uintptr(hwnd)))
+}
+
+// ackWatchdogPing is called on the UI thread, when a ping arrived.
+func ackWatchdogPing(wParam uintptr) {
+	watchdogMutex.Lock()
+	wd := activeWatchdog
+	watchdogMutex.Unlock()
+
+	if wd != nil {
+		atomic.StoreInt64(&wd.pingAck, int64(wParam))
+	}
+}
+
+func (wd *watchdog) run() {
+	ticker := time.NewTicker(wd.options.Timeout / 4)
+	defer ticker.Stop()
+
+	var seq int64
+	var sentAt time.Time
+	var hanging bool
+	var recovered chan struct{}
+
+	for {
+		select {
+		case <-wd.stop:
+			if recovered != nil {
+				close(recovered)
+			}
+			return
+
+		case now := <-ticker.C:
+			acked := atomic.LoadInt64(&wd.pingAck) == seq
+
+			if acked {
+				if hanging {
+					hanging = false
+
+					wd.logf("walk: UI thread responsive again after %v\n", now.Sub(sentAt))
+
+					if recovered != nil {
+						close(recovered)
+						recovered = nil
+					}
+				}
+
+				target := HWND(atomic.LoadUintptr(&watchdogTarget))
+				if target == 0 {
+					continue
+				}
+
+				seq++
+				sentAt = now
+				PostMessage(target, watchdogPingMsgId, uintptr(seq), 0)
+				continue
+			}
+
+			if !hanging && now.Sub(sentAt) > wd.options.Timeout {
+				hanging = true
+
+				wd.logStacks(now.Sub(sentAt))
+
+				if wd.options.ShowOverlay {
+					recovered = make(chan struct{})
+					go showWatchdogOverlay(wd.options.OverlayText, recovered)
+				}
+			}
+		}
+	}
+}
+
+func (wd *watchdog) logf(format string, args ...interface{}) {
+	if wd.options.Log != nil {
+		fmt.Fprintf(wd.options.Log, format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+func (wd *watchdog) logStacks(blocked time.Duration) {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+
+	wd.logf("walk: UI thread has not pumped messages for %v\n\n%s\n", blocked, buf)
+}
+
+// showWatchdogOverlay shows a small topmost window with text until recovered
+// is closed.
+//
+// The window belongs to a thread of its own, so it stays responsive while the
+// UI thread hangs.
+func showWatchdogOverlay(text string, recovered chan struct{}) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	const width, height = 240, 48
+
+	x := (int(GetSystemMetrics(SM_CXSCREEN)) - width) / 2
+	y := (int(GetSystemMetrics(SM_CYSCREEN)) - height) / 2
+
+	var r RECT
+	if target := HWND(atomic.LoadUintptr(&watchdogTarget)); target != 0 && GetWindowRect(target, &r) {
+		x = int(r.Left+r.Right)/2 - width/2
+		y = int(r.Top+r.Bottom)/2 - height/2
+	}
+
+	hwnd := CreateWindowEx(
+		WS_EX_TOPMOST|WS_EX_TOOLWINDOW,
+		syscall.StringToUTF16Ptr("STATIC"),
+		syscall.StringToUTF16Ptr(text),
+		WS_POPUP|WS_VISIBLE|WS_BORDER|SS_CENTER|SS_CENTERIMAGE,
+		int32(x),
+		int32(y),
+		width,
+		height,
+		0,
+		0,
+		0,
+		nil)
+	if hwnd == 0 {
+		return
+	}
+	defer DestroyWindow(hwnd)
+
+	var msg MSG
+	for {
+		select {
+		case <-recovered:
+			return
+
+		default:
+		}
+
+		for PeekMessage(&msg, 0, 0, 0, PM_REMOVE) {
+			TranslateMessage(&msg)
+			DispatchMessage(&msg)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+}
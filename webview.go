@@ -4,12 +4,6 @@
 
 package walk
 
-import (
-	"fmt"
-	"syscall"
-	"unsafe"
-)
-
 import . "github.com/lxn/go-winapi"
 
 const webViewWindowClass = `\o/ Walk_WebView_Class \o/`
@@ -18,45 +12,47 @@ func init() {
 	MustRegisterWindowClass(webViewWindowClass)
 }
 
+// webViewBackend is the browser engine behind a *WebView. It reports its
+// events by calling the on* methods of the *WebView, so another engine can be
+// added without changing the API of the *WebView.
+type webViewBackend interface {
+	url() (string, error)
+	navigate(url string) error
+
+	// loadHTML fails, if there is no document yet.
+	loadHTML(html string) error
+
+	goBack() error
+	goForward() error
+	stop() error
+	refresh() error
+	invokeScript(name string, args []interface{}) (interface{}, error)
+	resize()
+	dispose()
+}
+
+// WebView shows web pages and HTML, using the WebBrowser control of Internet
+// Explorer.
+//
+// Scripts of the page can be called with InvokeScript and EvaluateScript. The
+// Navigating event allows to cancel navigation, e.g. to handle links within
+// the application.
 type WebView struct {
 	WidgetBase
-	clientSite          webViewIOleClientSite // IMPORTANT: Must remain first member after WidgetBase
-	browserObject       *IOleObject
-	urlChangedPublisher EventPublisher
+	backend                      webViewBackend
+	urlChangedPublisher          EventPublisher
+	navigatingPublisher          WebViewNavigatingEventPublisher
+	navigatedPublisher           StringEventPublisher
+	documentCompletedPublisher   StringEventPublisher
+	canGoBackChangedPublisher    EventPublisher
+	canGoForwardChangedPublisher EventPublisher
+	canGoBack                    bool
+	canGoForward                 bool
+	pendingHTML                  *string
 }
 
 func NewWebView(parent Container) (*WebView, error) {
-	if hr := OleInitialize(); hr != S_OK && hr != S_FALSE {
-		return nil, newError(fmt.Sprint("OleInitialize Error: ", hr))
-	}
-
-	wv := &WebView{
-		clientSite: webViewIOleClientSite{
-			IOleClientSite: IOleClientSite{
-				LpVtbl: webViewIOleClientSiteVtbl,
-			},
-			inPlaceSite: webViewIOleInPlaceSite{
-				IOleInPlaceSite: IOleInPlaceSite{
-					LpVtbl: webViewIOleInPlaceSiteVtbl,
-				},
-				inPlaceFrame: webViewIOleInPlaceFrame{
-					IOleInPlaceFrame: IOleInPlaceFrame{
-						LpVtbl: webViewIOleInPlaceFrameVtbl,
-					},
-				},
-			},
-			docHostUIHandler: webViewIDocHostUIHandler{
-				IDocHostUIHandler: IDocHostUIHandler{
-					LpVtbl: webViewIDocHostUIHandlerVtbl,
-				},
-			},
-			webBrowserEvents2: webViewDWebBrowserEvents2{
-				DWebBrowserEvents2: DWebBrowserEvents2{
-					LpVtbl: webViewDWebBrowserEvents2Vtbl,
-				},
-			},
-		},
-	}
+	wv := new(WebView)
 
 	if err := InitChildWidget(
 		wv,
@@ -67,69 +63,15 @@ func NewWebView(parent Container) (*WebView, error) {
 		return nil, err
 	}
 
-	wv.clientSite.inPlaceSite.inPlaceFrame.webView = wv
-
-	succeeded := false
-
-	defer func() {
-		if !succeeded {
-			wv.Dispose()
-		}
-	}()
-
-	var classFactoryPtr unsafe.Pointer
-	if hr := CoGetClassObject(&CLSID_WebBrowser, CLSCTX_INPROC_HANDLER|CLSCTX_INPROC_SERVER, nil, &IID_IClassFactory, &classFactoryPtr); FAILED(hr) {
-		return nil, errorFromHRESULT("CoGetClassObject", hr)
-	}
-	classFactory := (*IClassFactory)(classFactoryPtr)
-	defer classFactory.Release()
-
-	var browserObjectPtr unsafe.Pointer
-	if hr := classFactory.CreateInstance(nil, &IID_IOleObject, &browserObjectPtr); FAILED(hr) {
-		return nil, errorFromHRESULT("IClassFactory.CreateInstance", hr)
-	}
-	browserObject := (*IOleObject)(browserObjectPtr)
-
-	wv.browserObject = browserObject
-
-	if hr := browserObject.SetClientSite((*IOleClientSite)(unsafe.Pointer(&wv.clientSite))); FAILED(hr) {
-		return nil, errorFromHRESULT("IOleObject.SetClientSite", hr)
-	}
-
-	if hr := browserObject.SetHostNames(syscall.StringToUTF16Ptr("Walk.WebView"), nil); FAILED(hr) {
-		return nil, errorFromHRESULT("IOleObject.SetHostNames", hr)
-	}
-
-	if hr := OleSetContainedObject((*IUnknown)(unsafe.Pointer(browserObject)), true); FAILED(hr) {
-		return nil, errorFromHRESULT("OleSetContainedObject", hr)
-	}
-
-	var rect RECT
-	GetClientRect(wv.hWnd, &rect)
-
-	if hr := browserObject.DoVerb(OLEIVERB_SHOW, nil, (*IOleClientSite)(unsafe.Pointer(&wv.clientSite)), -1, wv.hWnd, &rect); FAILED(hr) {
-		return nil, errorFromHRESULT("IOleObject.DoVerb", hr)
-	}
-
-	var cpcPtr unsafe.Pointer
-	if hr := browserObject.QueryInterface(&IID_IConnectionPointContainer, &cpcPtr); FAILED(hr) {
-		return nil, errorFromHRESULT("IOleObject.QueryInterface(IID_IConnectionPointContainer)", hr)
-	}
-	cpc := (*IConnectionPointContainer)(cpcPtr)
-	defer cpc.Release()
-
-	var cp *IConnectionPoint
-	if hr := cpc.FindConnectionPoint(&DIID_DWebBrowserEvents2, &cp); FAILED(hr) {
-		return nil, errorFromHRESULT("IConnectionPointContainer.FindConnectionPoint(DIID_DWebBrowserEvents2)", hr)
+	backend, err := newWebBrowserBackend(wv)
+	if err != nil {
+		wv.Dispose()
+		return nil, err
 	}
-	defer cp.Release()
 
-	var cookie uint32
-	if hr := cp.Advise(unsafe.Pointer(&wv.clientSite.webBrowserEvents2), &cookie); FAILED(hr) {
-		return nil, errorFromHRESULT("IConnectionPoint.Advise", hr)
-	}
+	wv.backend = backend
 
-	wv.onResize()
+	wv.backend.resize()
 
 	wv.MustRegisterProperty("URL", NewProperty(
 		func() interface{} {
@@ -141,19 +83,14 @@ func NewWebView(parent Container) (*WebView, error) {
 		},
 		wv.urlChangedPublisher.Event()))
 
-	succeeded = true
-
 	return wv, nil
 }
 
 func (wv *WebView) Dispose() {
-	if wv.browserObject != nil {
-		wv.browserObject.Close(OLECLOSE_NOSAVE)
-		wv.browserObject.Release()
+	if wv.backend != nil {
+		wv.backend.dispose()
 
-		wv.browserObject = nil
-
-		OleUninitialize()
+		wv.backend = nil
 	}
 
 	wv.WidgetBase.Dispose()
@@ -168,82 +105,171 @@ func (*WebView) SizeHint() Size {
 }
 
 func (wv *WebView) URL() (url string, err error) {
-	err = wv.withWebBrowser2(func(webBrowser2 *IWebBrowser2) error {
-		var urlBstr *uint16 /*BSTR*/
-		if hr := webBrowser2.Get_LocationURL(&urlBstr); FAILED(hr) {
-			return errorFromHRESULT("IWebBrowser2.Get_LocationURL", hr)
-		}
-		defer SysFreeString(urlBstr)
+	return wv.backend.url()
+}
+
+// SetURL navigates to url, like Navigate.
+func (wv *WebView) SetURL(url string) error {
+	return wv.Navigate(url)
+}
+
+// Navigate navigates to url.
+func (wv *WebView) Navigate(url string) error {
+	wv.pendingHTML = nil
 
-		url = BSTRToString(urlBstr)
+	return wv.backend.navigate(url)
+}
+
+func (wv *WebView) URLChanged() *Event {
+	return wv.urlChangedPublisher.Event()
+}
 
+// SetHTML shows html instead of a page loaded from an URL.
+//
+// Relative links in html are resolved against about:blank, so resources
+// should be referenced by absolute URLs.
+func (wv *WebView) SetHTML(html string) error {
+	if err := wv.backend.loadHTML(html); err == nil {
 		return nil
-	})
+	}
 
-	return
+	// There is no document yet, so load one first and write html into it
+	// when it is complete.
+	if err := wv.Navigate("about:blank"); err != nil {
+		return err
+	}
+
+	wv.pendingHTML = &html
+
+	return nil
 }
 
-func (wv *WebView) SetURL(url string) error {
-	return wv.withWebBrowser2(func(webBrowser2 *IWebBrowser2) error {
-		urlBstr := StringToVariantBSTR(url)
-		flags := IntToVariantI4(0)
-		targetFrameName := StringToVariantBSTR("_self")
+// CanGoBack returns if there is a page to go back to.
+func (wv *WebView) CanGoBack() bool {
+	return wv.canGoBack
+}
 
-		if hr := webBrowser2.Navigate2(urlBstr, flags, targetFrameName, nil, nil); FAILED(hr) {
-			return errorFromHRESULT("IWebBrowser2.Navigate2", hr)
-		}
+// CanGoBackChanged returns the event that is published, when CanGoBack
+// changed.
+func (wv *WebView) CanGoBackChanged() *Event {
+	return wv.canGoBackChangedPublisher.Event()
+}
 
-		return nil
-	})
+// CanGoForward returns if there is a page to go forward to.
+func (wv *WebView) CanGoForward() bool {
+	return wv.canGoForward
 }
 
-func (wv *WebView) URLChanged() *Event {
-	return wv.urlChangedPublisher.Event()
+// CanGoForwardChanged returns the event that is published, when CanGoForward
+// changed.
+func (wv *WebView) CanGoForwardChanged() *Event {
+	return wv.canGoForwardChangedPublisher.Event()
 }
 
-func (wv *WebView) Refresh() error {
-	return wv.withWebBrowser2(func(webBrowser2 *IWebBrowser2) error {
-		if hr := webBrowser2.Refresh(); FAILED(hr) {
-			return errorFromHRESULT("IWebBrowser2.Refresh", hr)
-		}
+// GoBack navigates to the previous page in the history.
+func (wv *WebView) GoBack() error {
+	return wv.backend.goBack()
+}
 
-		return nil
-	})
+// GoForward navigates to the next page in the history.
+func (wv *WebView) GoForward() error {
+	return wv.backend.goForward()
+}
+
+// Stop stops loading the current page.
+func (wv *WebView) Stop() error {
+	return wv.backend.stop()
+}
+
+// InvokeScript calls the global script function name of the current page
+// with args, which may be strings, bools, ints and float64s, and returns its
+// result, if it is one of these types.
+func (wv *WebView) InvokeScript(name string, args ...interface{}) (result interface{}, err error) {
+	return wv.backend.invokeScript(name, args)
+}
+
+// EvaluateScript evaluates the JavaScript code in the current page and
+// returns the result, like InvokeScript.
+func (wv *WebView) EvaluateScript(code string) (interface{}, error) {
+	return wv.InvokeScript("eval", code)
+}
+
+// Navigating returns the event that is published with the URL, before the
+// *WebView navigates there. Navigation is canceled, if a handler sets
+// canceled to true.
+func (wv *WebView) Navigating() *WebViewNavigatingEvent {
+	return wv.navigatingPublisher.Event()
+}
+
+// Navigated returns the event that is published with the URL, after the
+// *WebView navigated there. The page may still be loading.
+func (wv *WebView) Navigated() *StringEvent {
+	return wv.navigatedPublisher.Event()
+}
+
+// DocumentCompleted returns the event that is published with the URL, when
+// the page is completely loaded.
+func (wv *WebView) DocumentCompleted() *StringEvent {
+	return wv.documentCompletedPublisher.Event()
+}
+
+// onNavigating is called by the backend before navigating to url and returns
+// if navigation is canceled.
+func (wv *WebView) onNavigating(url string) (canceled bool) {
+	wv.navigatingPublisher.Publish(url, &canceled)
+
+	return
 }
 
-func (wv *WebView) withWebBrowser2(f func(webBrowser2 *IWebBrowser2) error) error {
-	var webBrowser2Ptr unsafe.Pointer
-	if hr := wv.browserObject.QueryInterface(&IID_IWebBrowser2, &webBrowser2Ptr); FAILED(hr) {
-		return errorFromHRESULT("IOleObject.QueryInterface", hr)
+// onNavigated is called by the backend after navigating to url.
+func (wv *WebView) onNavigated(url string) {
+	wv.urlChangedPublisher.Publish()
+	wv.navigatedPublisher.Publish(url)
+}
+
+// onCanGoBackChanged is called by the backend, when the history changed.
+func (wv *WebView) onCanGoBackChanged(value bool) {
+	if value != wv.canGoBack {
+		wv.canGoBack = value
+		wv.canGoBackChangedPublisher.Publish()
 	}
-	webBrowser2 := (*IWebBrowser2)(webBrowser2Ptr)
-	defer webBrowser2.Release()
+}
 
-	return f(webBrowser2)
+// onCanGoForwardChanged is called by the backend, when the history changed.
+func (wv *WebView) onCanGoForwardChanged(value bool) {
+	if value != wv.canGoForward {
+		wv.canGoForward = value
+		wv.canGoForwardChangedPublisher.Publish()
+	}
 }
 
-func (wv *WebView) onResize() {
-	// FIXME: handle error?
-	wv.withWebBrowser2(func(webBrowser2 *IWebBrowser2) error {
-		bounds := wv.ClientBounds()
+// onDocumentComplete is called by the backend, when the page at url is
+// completely loaded.
+func (wv *WebView) onDocumentComplete(url string) {
+	if html := wv.pendingHTML; html != nil {
+		wv.pendingHTML = nil
+
+		// Loading html completes the document again.
+		if err := wv.backend.loadHTML(*html); err == nil {
+			return
+		}
+	}
 
-		webBrowser2.Put_Left(0)
-		webBrowser2.Put_Top(0)
-		webBrowser2.Put_Width(int32(bounds.Width))
-		webBrowser2.Put_Height(int32(bounds.Height))
+	wv.documentCompletedPublisher.Publish(url)
+}
 
-		return nil
-	})
+func (wv *WebView) Refresh() error {
+	return wv.backend.refresh()
 }
 
 func (wv *WebView) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	switch msg {
 	case WM_SIZE, WM_SIZING:
-		if wv.clientSite.inPlaceSite.inPlaceFrame.webView == nil {
+		if wv.backend == nil {
 			break
 		}
 
-		wv.onResize()
+		wv.backend.resize()
 	}
 
 	return wv.WidgetBase.WndProc(hwnd, msg, wParam, lParam)
@@ -0,0 +1,172 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// Indices of methods in the vtables of the COM interfaces, that the WebView
+// calls beyond those declared by winapi.
+const (
+	iDispatchGetIDsOfNames = 5
+	iDispatchInvoke        = 6
+
+	iWebBrowser2GoBack      = 7
+	iWebBrowser2GoForward   = 8
+	iWebBrowser2Stop        = 14
+	iWebBrowser2GetDocument = 18
+
+	iHTMLDocumentGetScript = 7
+
+	iPersistStreamInitLoad    = 5
+	iPersistStreamInitInitNew = 8
+
+	iConnectionPointUnadvise = 6
+)
+
+const (
+	dispatchMethod = 0x1
+
+	dispidCommandStateChange = 105
+	dispidBeforeNavigate2    = 250
+	dispidDocumentComplete   = 259
+
+	cscNavigateForward = 1
+	cscNavigateBack    = 2
+
+	variantTrue = -1
+
+	vtI4      = 3
+	vtR8      = 5
+	vtBSTR    = 8
+	vtBool    = 11
+	vtVariant = 12
+	vtByRef   = 0x4000
+
+	gmemMoveable = 0x0002
+)
+
+var (
+	iidNull               = IID{}
+	iidIPersistStreamInit = IID{0x7FD52380, 0x4E07, 0x101B, [8]byte{0xAE, 0x2D, 0x08, 0x00, 0x2B, 0x2E, 0xC7, 0x13}}
+
+	libole32                  = syscall.NewLazyDLL("ole32.dll")
+	procCreateStreamOnHGlobal = libole32.NewProc("CreateStreamOnHGlobal")
+	procGlobalAlloc           = libkernel32.NewProc("GlobalAlloc")
+)
+
+// variant has the layout of a VARIANT on both 32 and 64 bit Windows.
+type variant struct {
+	Vt       uint16
+	reserved [3]uint16
+	data     [2]uintptr
+}
+
+func (v *variant) int32() int32 {
+	return *(*int32)(unsafe.Pointer(&v.data[0]))
+}
+
+func (v *variant) float64() float64 {
+	return *(*float64)(unsafe.Pointer(&v.data[0]))
+}
+
+func (v *variant) pointer() unsafe.Pointer {
+	return unsafe.Pointer(v.data[0])
+}
+
+// deref returns the variant v refers to, if v is VT_BYREF|VT_VARIANT.
+func (v *variant) deref() *variant {
+	if v.Vt == vtByRef|vtVariant {
+		return (*variant)(v.pointer())
+	}
+
+	return v
+}
+
+// value returns the Go value of v, if its type is supported, otherwise nil.
+func (v *variant) value() interface{} {
+	v = v.deref()
+
+	switch v.Vt {
+	case vtBSTR:
+		return BSTRToString((*uint16)(v.pointer()))
+
+	case vtI4:
+		return int(v.int32())
+
+	case vtR8:
+		return v.float64()
+
+	case vtBool:
+		return int16(v.int32()) != 0
+	}
+
+	return nil
+}
+
+// newVariant returns a variant holding value, which must be a string, bool,
+// int or float64. The variant must be cleared with variantClear.
+func newVariant(value interface{}) (variant, error) {
+	var v variant
+
+	switch value := value.(type) {
+	case string:
+		v.Vt = vtBSTR
+		v.data[0] = uintptr(unsafe.Pointer(StringToBSTR(value)))
+
+	case bool:
+		v.Vt = vtBool
+		if value {
+			*(*int16)(unsafe.Pointer(&v.data[0])) = variantTrue
+		}
+
+	case int:
+		v.Vt = vtI4
+		*(*int32)(unsafe.Pointer(&v.data[0])) = int32(value)
+
+	case float64:
+		v.Vt = vtR8
+		*(*float64)(unsafe.Pointer(&v.data[0])) = value
+
+	default:
+		return v, newError("unsupported argument type")
+	}
+
+	return v, nil
+}
+
+func variantClear(v *variant) {
+	VariantClear((*VARIANT)(unsafe.Pointer(v)))
+}
+
+// newStreamFromBytes returns an IStream, that reads data.
+func newStreamFromBytes(data []byte) (unsafe.Pointer, error) {
+	hMem, _, _ := procGlobalAlloc.Call(gmemMoveable, uintptr(len(data)))
+	if hMem == 0 {
+		return nil, lastError("GlobalAlloc")
+	}
+
+	if len(data) > 0 {
+		p, _, _ := procGlobalLock.Call(hMem)
+		if p == 0 {
+			return nil, lastError("GlobalLock")
+		}
+
+		copy((*[1 << 30]byte)(unsafe.Pointer(p))[:len(data)], data)
+
+		procGlobalUnlock.Call(hMem)
+	}
+
+	var stream unsafe.Pointer
+	if hr, _, _ := procCreateStreamOnHGlobal.Call(hMem, TRUE, uintptr(unsafe.Pointer(&stream))); FAILED(HRESULT(hr)) {
+		return nil, errorFromHRESULT("CreateStreamOnHGlobal", HRESULT(hr))
+	}
+
+	return stream, nil
+}
@@ -103,17 +103,62 @@ func webView_DWebBrowserEvents2_Invoke(
 	pExcepInfo unsafe.Pointer, // *EXCEPINFO
 	puArgErr *uint32) uintptr {
 
-	var wb WidgetBase
 	var wvcs webViewIOleClientSite
 
-	wv := (*WebView)(unsafe.Pointer(uintptr(unsafe.Pointer(wbe2)) +
+	backend := (*webBrowserBackend)(unsafe.Pointer(uintptr(unsafe.Pointer(wbe2)) +
 		uintptr(unsafe.Sizeof(*wbe2)) -
-		uintptr(unsafe.Sizeof(wvcs)) -
-		uintptr(unsafe.Sizeof(wb))))
+		uintptr(unsafe.Sizeof(wvcs))))
+	wv := backend.wv
+
+	// The arguments are in reverse order.
+	var args []variant
+	if pDispParams.CArgs > 0 {
+		args = (*[1 << 16]variant)(unsafe.Pointer(pDispParams.Rgvarg))[:pDispParams.CArgs]
+	}
+	arg := func(i int) *variant {
+		return &args[len(args)-1-i]
+	}
 
 	switch dispIdMember {
+	case dispidBeforeNavigate2:
+		// pDisp, URL, Flags, TargetFrameName, PostData, Headers, Cancel
+		url, _ := arg(1).value().(string)
+
+		if wv.onNavigating(url) {
+			*(*int16)(arg(6).pointer()) = variantTrue
+		}
+
+		return S_OK
+
 	case DISPID_NAVIGATECOMPLETE2:
-		wv.urlChangedPublisher.Publish()
+		// pDisp, URL
+		url, _ := arg(1).value().(string)
+
+		wv.onNavigated(url)
+
+		return S_OK
+
+	case dispidDocumentComplete:
+		// pDisp, URL
+		url, _ := arg(1).value().(string)
+
+		wv.onDocumentComplete(url)
+
+		return S_OK
+
+	case dispidCommandStateChange:
+		// Command, Enable
+		enabled, _ := arg(1).value().(bool)
+
+		switch arg(0).int32() {
+		case cscNavigateBack:
+			wv.onCanGoBackChanged(enabled)
+
+		case cscNavigateForward:
+			wv.onCanGoForwardChanged(enabled)
+		}
+
+		return S_OK
 	}
 
 	return DISP_E_MEMBERNOTFOUND
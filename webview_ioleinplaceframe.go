@@ -34,7 +34,7 @@ func init() {
 
 type webViewIOleInPlaceFrame struct {
 	IOleInPlaceFrame
-	webView *WebView
+	backend *webBrowserBackend
 }
 
 func webView_IOleInPlaceFrame_QueryInterface(inPlaceFrame *webViewIOleInPlaceFrame, riid REFIID, ppvObj *uintptr) uintptr {
@@ -50,7 +50,7 @@ func webView_IOleInPlaceFrame_Release(inPlaceFrame *webViewIOleInPlaceFrame) uin
 }
 
 func webView_IOleInPlaceFrame_GetWindow(inPlaceFrame *webViewIOleInPlaceFrame, lphwnd *HWND) uintptr {
-	*lphwnd = inPlaceFrame.webView.hWnd
+	*lphwnd = inPlaceFrame.backend.wv.hWnd
 
 	return S_OK
 }
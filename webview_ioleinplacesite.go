@@ -58,7 +58,7 @@ func webView_IOleInPlaceSite_Release(inPlaceSite *webViewIOleInPlaceSite) uintpt
 }
 
 func webView_IOleInPlaceSite_GetWindow(inPlaceSite *webViewIOleInPlaceSite, lphwnd *HWND) uintptr {
-	*lphwnd = inPlaceSite.inPlaceFrame.webView.hWnd
+	*lphwnd = inPlaceSite.inPlaceFrame.backend.wv.hWnd
 
 	return S_OK
 }
@@ -84,7 +84,7 @@ func webView_IOleInPlaceSite_GetWindowContext(inPlaceSite *webViewIOleInPlaceSit
 	*lplpDoc = 0
 
 	lpFrameInfo.FMDIApp = FALSE
-	lpFrameInfo.HwndFrame = inPlaceSite.inPlaceFrame.webView.hWnd
+	lpFrameInfo.HwndFrame = inPlaceSite.inPlaceFrame.backend.wv.hWnd
 	lpFrameInfo.Haccel = 0
 	lpFrameInfo.CAccelEntries = 0
 
@@ -112,7 +112,7 @@ func webView_IOleInPlaceSite_DeactivateAndUndo(inPlaceSite *webViewIOleInPlaceSi
 }
 
 func webView_IOleInPlaceSite_OnPosRectChange(inPlaceSite *webViewIOleInPlaceSite, lprcPosRect *RECT) uintptr {
-	browserObject := inPlaceSite.inPlaceFrame.webView.browserObject
+	browserObject := inPlaceSite.inPlaceFrame.backend.browserObject
 	var inPlaceObjectPtr unsafe.Pointer
 	if hr := browserObject.QueryInterface(&IID_IOleInPlaceObject, &inPlaceObjectPtr); FAILED(hr) {
 		return uintptr(hr)
@@ -0,0 +1,333 @@
+// Copyright 2010 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+import . "github.com/lxn/go-winapi"
+
+// webBrowserBackend is the webViewBackend using the WebBrowser control of
+// Internet Explorer.
+type webBrowserBackend struct {
+	clientSite      webViewIOleClientSite // IMPORTANT: Must remain first member
+	wv              *WebView
+	oleInitialized  bool
+	browserObject   *IOleObject
+	connectionPoint *IConnectionPoint
+	cookie          uint32
+}
+
+func newWebBrowserBackend(wv *WebView) (*webBrowserBackend, error) {
+	wb := &webBrowserBackend{
+		clientSite: webViewIOleClientSite{
+			IOleClientSite: IOleClientSite{
+				LpVtbl: webViewIOleClientSiteVtbl,
+			},
+			inPlaceSite: webViewIOleInPlaceSite{
+				IOleInPlaceSite: IOleInPlaceSite{
+					LpVtbl: webViewIOleInPlaceSiteVtbl,
+				},
+				inPlaceFrame: webViewIOleInPlaceFrame{
+					IOleInPlaceFrame: IOleInPlaceFrame{
+						LpVtbl: webViewIOleInPlaceFrameVtbl,
+					},
+				},
+			},
+			docHostUIHandler: webViewIDocHostUIHandler{
+				IDocHostUIHandler: IDocHostUIHandler{
+					LpVtbl: webViewIDocHostUIHandlerVtbl,
+				},
+			},
+			webBrowserEvents2: webViewDWebBrowserEvents2{
+				DWebBrowserEvents2: DWebBrowserEvents2{
+					LpVtbl: webViewDWebBrowserEvents2Vtbl,
+				},
+			},
+		},
+		wv: wv,
+	}
+	wb.clientSite.inPlaceSite.inPlaceFrame.backend = wb
+
+	succeeded := false
+
+	defer func() {
+		if !succeeded {
+			wb.dispose()
+		}
+	}()
+
+	if hr := OleInitialize(); hr != S_OK && hr != S_FALSE {
+		return nil, newError(fmt.Sprint("OleInitialize Error: ", hr))
+	}
+	wb.oleInitialized = true
+
+	var classFactoryPtr unsafe.Pointer
+	if hr := CoGetClassObject(&CLSID_WebBrowser, CLSCTX_INPROC_HANDLER|CLSCTX_INPROC_SERVER, nil, &IID_IClassFactory, &classFactoryPtr); FAILED(hr) {
+		return nil, errorFromHRESULT("CoGetClassObject", hr)
+	}
+	classFactory := (*IClassFactory)(classFactoryPtr)
+	defer classFactory.Release()
+
+	var browserObjectPtr unsafe.Pointer
+	if hr := classFactory.CreateInstance(nil, &IID_IOleObject, &browserObjectPtr); FAILED(hr) {
+		return nil, errorFromHRESULT("IClassFactory.CreateInstance", hr)
+	}
+	browserObject := (*IOleObject)(browserObjectPtr)
+
+	wb.browserObject = browserObject
+
+	if hr := browserObject.SetClientSite((*IOleClientSite)(unsafe.Pointer(&wb.clientSite))); FAILED(hr) {
+		return nil, errorFromHRESULT("IOleObject.SetClientSite", hr)
+	}
+
+	if hr := browserObject.SetHostNames(syscall.StringToUTF16Ptr("Walk.WebView"), nil); FAILED(hr) {
+		return nil, errorFromHRESULT("IOleObject.SetHostNames", hr)
+	}
+
+	if hr := OleSetContainedObject((*IUnknown)(unsafe.Pointer(browserObject)), true); FAILED(hr) {
+		return nil, errorFromHRESULT("OleSetContainedObject", hr)
+	}
+
+	var rect RECT
+	GetClientRect(wv.hWnd, &rect)
+
+	if hr := browserObject.DoVerb(OLEIVERB_SHOW, nil, (*IOleClientSite)(unsafe.Pointer(&wb.clientSite)), -1, wv.hWnd, &rect); FAILED(hr) {
+		return nil, errorFromHRESULT("IOleObject.DoVerb", hr)
+	}
+
+	var cpcPtr unsafe.Pointer
+	if hr := browserObject.QueryInterface(&IID_IConnectionPointContainer, &cpcPtr); FAILED(hr) {
+		return nil, errorFromHRESULT("IOleObject.QueryInterface(IID_IConnectionPointContainer)", hr)
+	}
+	cpc := (*IConnectionPointContainer)(cpcPtr)
+	defer cpc.Release()
+
+	var cp *IConnectionPoint
+	if hr := cpc.FindConnectionPoint(&DIID_DWebBrowserEvents2, &cp); FAILED(hr) {
+		return nil, errorFromHRESULT("IConnectionPointContainer.FindConnectionPoint(DIID_DWebBrowserEvents2)", hr)
+	}
+
+	if hr := cp.Advise(unsafe.Pointer(&wb.clientSite.webBrowserEvents2), &wb.cookie); FAILED(hr) {
+		cp.Release()
+		return nil, errorFromHRESULT("IConnectionPoint.Advise", hr)
+	}
+
+	// Kept to unadvise the events on dispose.
+	wb.connectionPoint = cp
+
+	succeeded = true
+
+	return wb, nil
+}
+
+func (wb *webBrowserBackend) dispose() {
+	if wb.connectionPoint != nil {
+		comCall(unsafe.Pointer(wb.connectionPoint), iConnectionPointUnadvise, uintptr(wb.cookie))
+		wb.connectionPoint.Release()
+
+		wb.connectionPoint = nil
+	}
+
+	if wb.browserObject != nil {
+		wb.browserObject.Close(OLECLOSE_NOSAVE)
+		wb.browserObject.Release()
+
+		wb.browserObject = nil
+	}
+
+	if wb.oleInitialized {
+		OleUninitialize()
+
+		wb.oleInitialized = false
+	}
+}
+
+func (wb *webBrowserBackend) url() (url string, err error) {
+	err = wb.withWebBrowser2(func(webBrowser2 *IWebBrowser2) error {
+		var urlBstr *uint16 /*BSTR*/
+		if hr := webBrowser2.Get_LocationURL(&urlBstr); FAILED(hr) {
+			return errorFromHRESULT("IWebBrowser2.Get_LocationURL", hr)
+		}
+		defer SysFreeString(urlBstr)
+
+		url = BSTRToString(urlBstr)
+
+		return nil
+	})
+
+	return
+}
+
+func (wb *webBrowserBackend) navigate(url string) error {
+	return wb.withWebBrowser2(func(webBrowser2 *IWebBrowser2) error {
+		urlBstr := StringToVariantBSTR(url)
+		flags := IntToVariantI4(0)
+		targetFrameName := StringToVariantBSTR("_self")
+
+		if hr := webBrowser2.Navigate2(urlBstr, flags, targetFrameName, nil, nil); FAILED(hr) {
+			return errorFromHRESULT("IWebBrowser2.Navigate2", hr)
+		}
+
+		return nil
+	})
+}
+
+func (wb *webBrowserBackend) loadHTML(html string) error {
+	document, err := wb.document()
+	if err != nil {
+		return err
+	}
+	defer comRelease(document)
+
+	var persistStreamInit unsafe.Pointer
+	if hr := comCall(document, iUnknownQueryInterface, uintptr(unsafe.Pointer(&iidIPersistStreamInit)), uintptr(unsafe.Pointer(&persistStreamInit))); FAILED(hr) {
+		return errorFromHRESULT("IDispatch.QueryInterface(IID_IPersistStreamInit)", hr)
+	}
+	defer comRelease(persistStreamInit)
+
+	// The byte order mark tells the control, that the stream is UTF-8.
+	stream, err := newStreamFromBytes(append([]byte{0xEF, 0xBB, 0xBF}, html...))
+	if err != nil {
+		return err
+	}
+	defer comRelease(stream)
+
+	if hr := comCall(persistStreamInit, iPersistStreamInitInitNew); FAILED(hr) {
+		return errorFromHRESULT("IPersistStreamInit.InitNew", hr)
+	}
+
+	if hr := comCall(persistStreamInit, iPersistStreamInitLoad, uintptr(stream)); FAILED(hr) {
+		return errorFromHRESULT("IPersistStreamInit.Load", hr)
+	}
+
+	return nil
+}
+
+// document returns the IDispatch of the current document, which must be
+// released.
+func (wb *webBrowserBackend) document() (document unsafe.Pointer, err error) {
+	err = wb.withWebBrowser2(func(webBrowser2 *IWebBrowser2) error {
+		if hr := comCall(unsafe.Pointer(webBrowser2), iWebBrowser2GetDocument, uintptr(unsafe.Pointer(&document))); FAILED(hr) {
+			return errorFromHRESULT("IWebBrowser2.Get_Document", hr)
+		}
+
+		if document == nil {
+			return newError("no document")
+		}
+
+		return nil
+	})
+
+	return
+}
+
+func (wb *webBrowserBackend) goBack() error {
+	return wb.callWebBrowser2("IWebBrowser2.GoBack", iWebBrowser2GoBack)
+}
+
+func (wb *webBrowserBackend) goForward() error {
+	return wb.callWebBrowser2("IWebBrowser2.GoForward", iWebBrowser2GoForward)
+}
+
+func (wb *webBrowserBackend) stop() error {
+	return wb.callWebBrowser2("IWebBrowser2.Stop", iWebBrowser2Stop)
+}
+
+func (wb *webBrowserBackend) refresh() error {
+	return wb.withWebBrowser2(func(webBrowser2 *IWebBrowser2) error {
+		if hr := webBrowser2.Refresh(); FAILED(hr) {
+			return errorFromHRESULT("IWebBrowser2.Refresh", hr)
+		}
+
+		return nil
+	})
+}
+
+func (wb *webBrowserBackend) callWebBrowser2(name string, index int) error {
+	return wb.withWebBrowser2(func(webBrowser2 *IWebBrowser2) error {
+		if hr := comCall(unsafe.Pointer(webBrowser2), index); FAILED(hr) {
+			return errorFromHRESULT(name, hr)
+		}
+
+		return nil
+	})
+}
+
+func (wb *webBrowserBackend) invokeScript(name string, args []interface{}) (result interface{}, err error) {
+	document, err := wb.document()
+	if err != nil {
+		return nil, err
+	}
+	defer comRelease(document)
+
+	var script unsafe.Pointer
+	if hr := comCall(document, iHTMLDocumentGetScript, uintptr(unsafe.Pointer(&script))); FAILED(hr) {
+		return nil, errorFromHRESULT("IHTMLDocument.Get_Script", hr)
+	}
+	defer comRelease(script)
+
+	namePtr := syscall.StringToUTF16Ptr(name)
+	var dispId int32
+	if hr := comCall(script, iDispatchGetIDsOfNames, uintptr(unsafe.Pointer(&iidNull)), uintptr(unsafe.Pointer(&namePtr)), 1, 0, uintptr(unsafe.Pointer(&dispId))); FAILED(hr) {
+		return nil, errorFromHRESULT("IDispatch.GetIDsOfNames", hr)
+	}
+
+	// The arguments are passed in reverse order.
+	variants := make([]variant, len(args))
+	defer func() {
+		for i := range variants {
+			variantClear(&variants[i])
+		}
+	}()
+	for i, arg := range args {
+		if variants[len(args)-1-i], err = newVariant(arg); err != nil {
+			return nil, err
+		}
+	}
+
+	var params DISPPARAMS
+	if len(variants) > 0 {
+		params.Rgvarg = (*VARIANTARG)(unsafe.Pointer(&variants[0]))
+		params.CArgs = int32(len(variants))
+	}
+
+	var ret variant
+	defer variantClear(&ret)
+
+	if hr := comCall(script, iDispatchInvoke, uintptr(dispId), uintptr(unsafe.Pointer(&iidNull)), 0, dispatchMethod, uintptr(unsafe.Pointer(&params)), uintptr(unsafe.Pointer(&ret)), 0, 0); FAILED(hr) {
+		return nil, errorFromHRESULT("IDispatch.Invoke", hr)
+	}
+
+	return ret.value(), nil
+}
+
+func (wb *webBrowserBackend) resize() {
+	// FIXME: handle error?
+	wb.withWebBrowser2(func(webBrowser2 *IWebBrowser2) error {
+		bounds := wb.wv.ClientBounds()
+
+		webBrowser2.Put_Left(0)
+		webBrowser2.Put_Top(0)
+		webBrowser2.Put_Width(int32(bounds.Width))
+		webBrowser2.Put_Height(int32(bounds.Height))
+
+		return nil
+	})
+}
+
+func (wb *webBrowserBackend) withWebBrowser2(f func(webBrowser2 *IWebBrowser2) error) error {
+	var webBrowser2Ptr unsafe.Pointer
+	if hr := wb.browserObject.QueryInterface(&IID_IWebBrowser2, &webBrowser2Ptr); FAILED(hr) {
+		return errorFromHRESULT("IOleObject.QueryInterface", hr)
+	}
+	webBrowser2 := (*IWebBrowser2)(webBrowser2Ptr)
+	defer webBrowser2.Release()
+
+	return f(webBrowser2)
+}
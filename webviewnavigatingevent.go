@@ -0,0 +1,59 @@
+// Copyright 2013 The Walk Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+type WebViewNavigatingEventHandler func(url string, canceled *bool)
+
+type WebViewNavigatingEvent struct {
+	handlers   []WebViewNavigatingEventHandler
+	priorities []int
+}
+
+func (e *WebViewNavigatingEvent) Attach(handler WebViewNavigatingEventHandler) int {
+	return e.AttachWithPriority(handler, 0)
+}
+
+// AttachWithPriority attaches handler, so that it is called before all
+// handlers with a lower priority.
+//
+// Handlers of equal priority are called in the same order as with Attach. As
+// soon as a handler cancels, the remaining handlers are not called.
+func (e *WebViewNavigatingEvent) AttachWithPriority(handler WebViewNavigatingEventHandler, priority int) int {
+	for i, h := range e.handlers {
+		if h == nil {
+			e.handlers[i] = handler
+			e.priorities[i] = priority
+			return i
+		}
+	}
+
+	e.handlers = append(e.handlers, handler)
+	e.priorities = append(e.priorities, priority)
+	return len(e.handlers) - 1
+}
+
+func (e *WebViewNavigatingEvent) Detach(handle int) {
+	e.handlers[handle] = nil
+}
+
+type WebViewNavigatingEventPublisher struct {
+	event WebViewNavigatingEvent
+}
+
+func (p *WebViewNavigatingEventPublisher) Event() *WebViewNavigatingEvent {
+	return &p.event
+}
+
+func (p *WebViewNavigatingEventPublisher) Publish(url string, canceled *bool) {
+	for _, i := range handlerIndicesByPriority(p.event.priorities) {
+		if handler := p.event.handlers[i]; handler != nil {
+			handler(url, canceled)
+
+			if *canceled {
+				return
+			}
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 	"syscall"
+	"time"
 	"unsafe"
 )
 
@@ -78,6 +79,11 @@ type Widget interface {
 	// By default this is nil.
 	ContextMenu() *Menu
 
+	// ContextMenuRequested returns a *ContextMenuEvent that you can attach to
+	// for customizing or suppressing the context menu of the Widget, right
+	// before it is shown.
+	ContextMenuRequested() *ContextMenuEvent
+
 	// CreateCanvas creates and returns a *Canvas that can be used to draw
 	// inside the ClientBounds of the Widget.
 	//
@@ -172,6 +178,10 @@ type Widget interface {
 	// usually a *MainWindow or *Dialog.
 	RootWidget() RootWidget
 
+	// ScrollIntoView makes the Widget visible to the user, by selecting the
+	// pages of any *TabWidget ancestors that contain it.
+	ScrollIntoView() error
+
 	// SendMessage sends a message to the window and returns the result.
 	SendMessage(msg uint32, wParam, lParam uintptr) uintptr
 
@@ -305,18 +315,23 @@ type WidgetBase struct {
 	hWnd                        HWND
 	origWndProcPtr              uintptr
 	name                        string
+	settingsScope               string
+	excludedFromFormMemory      bool
 	parent                      Container
 	font                        *Font
 	contextMenu                 *Menu
+	contextMenuPublisher        ContextMenuEventPublisher
 	keyDownPublisher            KeyEventPublisher
 	mouseDownPublisher          MouseEventPublisher
 	mouseUpPublisher            MouseEventPublisher
 	mouseMovePublisher          MouseEventPublisher
 	sizeChangedPublisher        EventPublisher
+	disposingPublisher          EventPublisher
 	maxSize                     Size
 	minSize                     Size
 	background                  Brush
 	cursor                      Cursor
+	overlays                    []*Overlay
 	suspended                   bool
 	visible                     bool
 	enabled                     bool
@@ -327,6 +342,9 @@ type WidgetBase struct {
 	visibleChangedPublisher     EventPublisher
 	toolTipTextProperty         Property
 	toolTipTextChangedPublisher EventPublisher
+	designMode                  bool
+	designSelected              bool
+	themeAppName                string
 }
 
 var widgetWndProcPtr uintptr = syscall.NewCallback(widgetWndProc)
@@ -344,6 +362,8 @@ func MustRegisterWindowClass(className string) {
 		panic("window class already registered")
 	}
 
+	defer traceStartup("WindowClasses", time.Now())
+
 	hInst := GetModuleHandle(nil)
 	if hInst == 0 {
 		panic("GetModuleHandle")
@@ -387,6 +407,8 @@ func InitWidget(widget, parent Widget, className string, style, exStyle uint32)
 
 	wb.name2Property = make(map[string]Property)
 
+	initCommonControlsForClass(className)
+
 	var hwndParent HWND
 	if parent != nil {
 		hwndParent = parent.BaseWidget().hWnd
@@ -432,10 +454,26 @@ func InitWidget(widget, parent Widget, className string, style, exStyle uint32)
 
 	setWidgetFont(wb.hWnd, defaultFont)
 
+	if appSingleton.DarkColors() {
+		applyWidgetColorScheme(widget)
+	}
+
 	switch widget.(type) {
 	case *ToolTip:
 	case RootWidget:
 	default:
+		if globalToolTip == nil {
+			start := time.Now()
+
+			tt, err := NewToolTip()
+			if err != nil {
+				return err
+			}
+			globalToolTip = tt
+
+			traceStartup("ToolTip", start)
+		}
+
 		if err := globalToolTip.AddTool(widget); err != nil {
 			return err
 		}
@@ -580,7 +618,7 @@ func (wb *WidgetBase) hasStyleBits(bits uint) bool {
 func (wb *WidgetBase) setAndClearStyleBits(set, clear uint32) error {
 	style := uint32(GetWindowLong(wb.hWnd, GWL_STYLE))
 	if style == 0 {
-		return lastError("GetWindowLong")
+		return lastWidgetError(wb, "GetWindowLong")
 	}
 
 	var newStyle uint32
@@ -589,7 +627,7 @@ func (wb *WidgetBase) setAndClearStyleBits(set, clear uint32) error {
 	if newStyle != style {
 		SetLastError(0)
 		if SetWindowLong(wb.hWnd, GWL_STYLE, int32(newStyle)) == 0 {
-			return lastError("SetWindowLong")
+			return lastWidgetError(wb, "SetWindowLong")
 		}
 	}
 
@@ -627,11 +665,22 @@ func (wb *WidgetBase) SetName(name string) {
 	wb.name = name
 }
 
+// writePath writes the key, under which the state of the *WidgetBase is
+// stored. It consists of the names of the *WidgetBase and its ancestors, up to
+// the nearest settings scope.
 func (wb *WidgetBase) writePath(buf *bytes.Buffer) {
+	if wb.settingsScope != "" {
+		buf.WriteString(wb.settingsScope)
+		return
+	}
+
 	hWndParent := GetAncestor(wb.hWnd, GA_PARENT)
 	if pwi := widgetFromHWND(hWndParent); pwi != nil {
 		pwi.BaseWidget().writePath(buf)
 		buf.WriteByte('/')
+	} else if _, ok := wb.widget.(RootWidget); ok && wb.name == "" && !appSingleton.unscopedWindows {
+		buf.WriteString(defaultSettingsScope(wb.widget))
+		return
 	}
 
 	buf.WriteString(wb.name)
@@ -659,7 +708,9 @@ func (wb *WidgetBase) BaseWidget() *WidgetBase {
 func (wb *WidgetBase) Dispose() {
 	hWnd := wb.hWnd
 	if hWnd != 0 {
-		if _, ok := wb.widget.(*ToolTip); !ok && wb.hWnd != 0 {
+		wb.disposingPublisher.Publish()
+
+		if _, ok := wb.widget.(*ToolTip); !ok && wb.hWnd != 0 && globalToolTip != nil {
 			globalToolTip.RemoveTool(wb.widget)
 		}
 
@@ -676,6 +727,12 @@ func (wb *WidgetBase) Dispose() {
 	}
 }
 
+// Disposing returns an *Event that is published when the *WidgetBase is about
+// to be disposed of.
+func (wb *WidgetBase) Disposing() *Event {
+	return wb.disposingPublisher.Event()
+}
+
 // IsDisposed returns if the *WidgetBase has been disposed of.
 func (wb *WidgetBase) IsDisposed() bool {
 	return wb.hWnd == 0
@@ -699,6 +756,66 @@ func (wb *WidgetBase) SetContextMenu(value *Menu) {
 	wb.contextMenu = value
 }
 
+// ContextMenuRequested returns a *ContextMenuEvent that you can attach to for
+// customizing or suppressing the context menu of the *WidgetBase, right before
+// it is shown.
+func (wb *WidgetBase) ContextMenuRequested() *ContextMenuEvent {
+	return wb.contextMenuPublisher.Event()
+}
+
+// showContextMenu handles WM_CONTEXTMENU for the *WidgetBase and returns if a
+// menu was shown.
+//
+// Messages a widget does not handle are passed on to its parent by
+// DefWindowProc, so the innermost widget with a menu wins.
+func (wb *WidgetBase) showContextMenu(wParam, lParam uintptr) bool {
+	args := &ContextMenuEventArgs{
+		widget: wb.widget,
+		source: widgetFromHWND(HWND(wParam)),
+		menu:   wb.widget.ContextMenu(),
+	}
+
+	x := GET_X_LPARAM(lParam)
+	y := GET_Y_LPARAM(lParam)
+
+	if x == -1 && y == -1 {
+		args.byKeyboard = true
+
+		b := wb.widget.ClientBounds()
+		args.location = Point{b.X + b.Width/2, b.Y + b.Height/2}
+
+		p := POINT{int32(args.location.X), int32(args.location.Y)}
+		if !ClientToScreen(wb.hWnd, &p) {
+			newAPIError("ClientToScreen")
+			return false
+		}
+		x, y = p.X, p.Y
+	} else {
+		p := POINT{x, y}
+		if !ScreenToClient(wb.hWnd, &p) {
+			newAPIError("ScreenToClient")
+			return false
+		}
+		args.location = Point{int(p.X), int(p.Y)}
+	}
+
+	wb.contextMenuPublisher.Publish(args)
+
+	if args.menu == nil || args.menu.IsDisposed() || args.menu.Actions().Len() == 0 {
+		return false
+	}
+
+	TrackPopupMenuEx(
+		args.menu.hMenu,
+		TPM_NOANIMATION|args.menu.popupLayoutFlags(),
+		x,
+		y,
+		rootWidget(wb.widget).BaseWidget().hWnd,
+		nil)
+
+	return true
+}
+
 // Background returns the background Brush of the *WidgetBase.
 //
 // By default this is nil.
@@ -801,11 +918,11 @@ func (wb *WidgetBase) SetSuspended(suspend bool) {
 
 // Invalidate schedules a full repaint of the *WidgetBase.
 func (wb *WidgetBase) Invalidate() error {
-	if !InvalidateRect(wb.hWnd, nil, true) {
-		return newError("InvalidateRect failed")
+	if invalidateBatched(wb) {
+		return nil
 	}
 
-	return nil
+	return wb.invalidateNow()
 }
 
 // Parent returns the Container of the *WidgetBase.
@@ -824,7 +941,7 @@ func (wb *WidgetBase) SetParent(value Container) (err error) {
 
 	style := uint32(GetWindowLong(wb.hWnd, GWL_STYLE))
 	if style == 0 {
-		return lastError("GetWindowLong")
+		return lastWidgetError(wb, "GetWindowLong")
 	}
 
 	if value == nil {
@@ -832,11 +949,11 @@ func (wb *WidgetBase) SetParent(value Container) (err error) {
 		style |= WS_POPUP
 
 		if SetParent(wb.hWnd, 0) == 0 {
-			return lastError("SetParent")
+			return lastWidgetError(wb, "SetParent")
 		}
 		SetLastError(0)
 		if SetWindowLong(wb.hWnd, GWL_STYLE, int32(style)) == 0 {
-			return lastError("SetWindowLong")
+			return lastWidgetError(wb, "SetWindowLong")
 		}
 	} else {
 		style |= WS_CHILD
@@ -844,10 +961,10 @@ func (wb *WidgetBase) SetParent(value Container) (err error) {
 
 		SetLastError(0)
 		if SetWindowLong(wb.hWnd, GWL_STYLE, int32(style)) == 0 {
-			return lastError("SetWindowLong")
+			return lastWidgetError(wb, "SetWindowLong")
 		}
 		if SetParent(wb.hWnd, value.BaseWidget().hWnd) == 0 {
-			return lastError("SetParent")
+			return lastWidgetError(wb, "SetParent")
 		}
 	}
 
@@ -862,7 +979,7 @@ func (wb *WidgetBase) SetParent(value Container) (err error) {
 		int32(b.Height),
 		SWP_FRAMECHANGED) {
 
-		return lastError("SetWindowPos")
+		return lastWidgetError(wb, "SetWindowPos")
 	}
 
 	oldParent := wb.parent
@@ -901,7 +1018,7 @@ func widgetText(hwnd HWND) string {
 
 func setWidgetText(hwnd HWND, text string) error {
 	if TRUE != SendMessage(hwnd, WM_SETTEXT, 0, uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(text)))) {
-		return newError("WM_SETTEXT failed")
+		return newAPIError("WM_SETTEXT")
 	}
 
 	return nil
@@ -938,7 +1055,7 @@ func (wb *WidgetBase) BringToTop() error {
 	}
 
 	if !SetWindowPos(wb.hWnd, HWND_TOP, 0, 0, 0, 0, SWP_NOACTIVATE|SWP_NOMOVE|SWP_NOSIZE) {
-		return lastError("SetWindowPos")
+		return lastWidgetError(wb, "SetWindowPos")
 	}
 
 	return nil
@@ -953,7 +1070,7 @@ func (wb *WidgetBase) Bounds() Rectangle {
 	var r RECT
 
 	if !GetWindowRect(wb.hWnd, &r) {
-		lastError("GetWindowRect")
+		lastWidgetError(wb, "GetWindowRect")
 		return Rectangle{}
 	}
 
@@ -967,7 +1084,7 @@ func (wb *WidgetBase) Bounds() Rectangle {
 	if _, ok := wb.widget.(RootWidget); !ok && wb.parent != nil {
 		p := POINT{int32(b.X), int32(b.Y)}
 		if !ScreenToClient(wb.parent.BaseWidget().hWnd, &p) {
-			newError("ScreenToClient failed")
+			newAPIError("ScreenToClient")
 			return Rectangle{}
 		}
 		b.X = int(p.X)
@@ -991,7 +1108,7 @@ func (wb *WidgetBase) SetBounds(bounds Rectangle) error {
 		int32(bounds.Height),
 		true) {
 
-		return lastError("MoveWindow")
+		return lastWidgetError(wb, "MoveWindow")
 	}
 
 	return nil
@@ -1041,16 +1158,22 @@ func (wb *WidgetBase) dialogBaseUnits() Size {
 	// like e.g. NumberEdit does, so we try to use the right one.
 	widget := widgetFromHWND(wb.hWnd)
 
-	hdc := GetDC(wb.hWnd)
-	defer ReleaseDC(wb.hWnd, hdc)
+	return fontDialogBaseUnits(wb.hWnd, widget.Font())
+}
 
-	hFont := widget.Font().handleForDPI(0)
+// fontDialogBaseUnits returns the dialog base units of font, measured using
+// the device context of hwnd.
+func fontDialogBaseUnits(hwnd HWND, font *Font) Size {
+	hdc := GetDC(hwnd)
+	defer ReleaseDC(hwnd, hdc)
+
+	hFont := font.handleForDPI(0)
 	hFontOld := SelectObject(hdc, HGDIOBJ(hFont))
 	defer SelectObject(hdc, HGDIOBJ(hFontOld))
 
 	var tm TEXTMETRIC
 	if !GetTextMetrics(hdc, &tm) {
-		newError("GetTextMetrics failed")
+		newAPIError("GetTextMetrics")
 	}
 
 	var size SIZE
@@ -1059,7 +1182,7 @@ func (wb *WidgetBase) dialogBaseUnits() Size {
 		dialogBaseUnitsUTF16StringPtr,
 		52,
 		&size) {
-		newError("GetTextExtentPoint32 failed")
+		newAPIError("GetTextExtentPoint32")
 	}
 
 	return Size{int((size.CX/26 + 1) / 2), int(tm.TmHeight)}
@@ -1100,7 +1223,7 @@ func (wb *WidgetBase) SizeHint() Size {
 func (wb *WidgetBase) calculateTextSizeImpl(text string) Size {
 	hdc := GetDC(wb.hWnd)
 	if hdc == 0 {
-		newError("GetDC failed")
+		newAPIError("GetDC")
 		return Size{}
 	}
 	defer ReleaseDC(wb.hWnd, hdc)
@@ -1116,7 +1239,7 @@ func (wb *WidgetBase) calculateTextSizeImpl(text string) Size {
 		str := syscall.StringToUTF16(strings.TrimRight(line, "\r "))
 
 		if !GetTextExtentPoint32(hdc, &str[0], int32(len(str)-1), &s) {
-			newError("GetTextExtentPoint32 failed")
+			newAPIError("GetTextExtentPoint32")
 			return Size{}
 		}
 
@@ -1136,7 +1259,7 @@ func (wb *WidgetBase) updateParentLayout() error {
 		return nil
 	}
 
-	return wb.parent.Layout().Update(false)
+	return updateLayout(wb.parent.Layout(), false)
 }
 
 // Size returns the outer Size of the *WidgetBase, including decorations.
@@ -1247,10 +1370,57 @@ func (wb *WidgetBase) SetClientSize(value Size) error {
 	return wb.SetSize(wb.sizeFromClientSize(value))
 }
 
+// ScrollIntoView makes the *WidgetBase visible to the user, by selecting the
+// pages of any *TabWidget ancestors that contain it.
+//
+// This is useful e.g. to show a widget with invalid input to the user.
+func (wb *WidgetBase) ScrollIntoView() error {
+	for child := wb.widget; child != nil; {
+		parent := hostWidget(child)
+		if parent == nil {
+			break
+		}
+
+		if r, ok := parent.(childRevealer); ok {
+			if err := r.revealChild(child); err != nil {
+				return err
+			}
+		}
+
+		child = parent
+	}
+
+	return nil
+}
+
+// childRevealer is implemented by widgets that may hide some of their
+// children, like *TabWidget.
+type childRevealer interface {
+	revealChild(child Widget) error
+}
+
+// hostWidget returns the Widget that hosts widget, which may not be its
+// Parent, as is the case for a *TabPage.
+func hostWidget(widget Widget) Widget {
+	if tp, ok := widget.(*TabPage); ok {
+		if tp.tabWidget == nil {
+			return nil
+		}
+
+		return tp.tabWidget
+	}
+
+	if parent := widget.Parent(); parent != nil {
+		return parent
+	}
+
+	return nil
+}
+
 // SetFocus sets the keyboard input focus to the *WidgetBase.
 func (wb *WidgetBase) SetFocus() error {
 	if SetFocus(wb.hWnd) == 0 {
-		return lastError("SetFocus")
+		return lastWidgetError(wb, "SetFocus")
 	}
 
 	return nil
@@ -1290,11 +1460,9 @@ func (wb *WidgetBase) CreateCanvas() (*Canvas, error) {
 }
 
 func (wb *WidgetBase) setTheme(appName string) error {
-	if hr := SetWindowTheme(wb.hWnd, syscall.StringToUTF16Ptr(appName), nil); FAILED(hr) {
-		return errorFromHRESULT("SetWindowTheme", hr)
-	}
+	wb.themeAppName = appName
 
-	return nil
+	return wb.applyWindowTheme()
 }
 
 // KeyDown returns a *KeyEvent that you can attach to for handling key down
@@ -1365,10 +1533,22 @@ func (wb *WidgetBase) persistState(restore bool) {
 				persistable.SaveState()
 			}
 		}
+
+		if _, ok := widget.(RootWidget); ok && appSingleton.formMemory {
+			if restore {
+				restoreFormMemory(widget)
+			} else {
+				saveFormMemory(widget)
+			}
+		}
 	}
 }
 
 func (wb *WidgetBase) getState() (string, error) {
+	if layoutStates != nil {
+		return layoutStates[wb.path()], nil
+	}
+
 	settings := appSingleton.settings
 	if settings == nil {
 		return "", newError("App().Settings() must not be nil")
@@ -1379,11 +1559,21 @@ func (wb *WidgetBase) getState() (string, error) {
 }
 
 func (wb *WidgetBase) putState(state string) error {
+	if layoutStates != nil {
+		layoutStates[wb.path()] = state
+		return nil
+	}
+
 	settings := appSingleton.settings
 	if settings == nil {
 		return newError("App().Settings() must not be nil")
 	}
 
+	puttingState = true
+	defer func() {
+		puttingState = false
+	}()
+
 	return settings.Put(wb.path(), state)
 }
 
@@ -1400,7 +1590,7 @@ func widgetFromHWND(hwnd HWND) Widget {
 
 func widgetWndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) (result uintptr) {
 	defer func() {
-		if len(appSingleton.panickingPublisher.event.handlers) > 0 {
+		if len(appSingleton.panickingPublisher.event.handlers) > 0 || appSingleton.crashHandler != nil {
 			var err error
 			if x := recover(); x != nil {
 				if e, ok := x.(error); ok {
@@ -1411,6 +1601,10 @@ func widgetWndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) (result uintpt
 			}
 			if err != nil {
 				appSingleton.panickingPublisher.Publish(err)
+
+				if appSingleton.crashHandler != nil {
+					handleCrash(err)
+				}
 			}
 		}
 	}()
@@ -1419,13 +1613,62 @@ func widgetWndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) (result uintpt
 		return notifyIconWndProc(hwnd, msg, wParam, lParam)
 	}
 
+	if msg == syncMsgId {
+		runSynchronized()
+		return 0
+	}
+
+	if msg == WM_NOTIFY {
+		// Tool tips notify the tools, not themselves.
+		if nmhdr := (*NMHDR)(unsafe.Pointer(lParam)); nmhdr.Code == ttnShow {
+			if tt, ok := widgetFromHWND(nmhdr.HwndFrom).(*ToolTip); ok {
+				return tt.placeAtCursor()
+			}
+		}
+	}
+
+	if msg == watchdogPingMsgId {
+		ackWatchdogPing(wParam)
+		return 0
+	}
+
+	if msg == WM_COPYDATA && hwnd == singleInstanceHWnd {
+		return singleInstanceWndProc(hwnd, msg, wParam, lParam)
+	}
+
+	if ss, ok := splashScreensByHWnd[hwnd]; ok {
+		return splashScreenWndProc(ss, hwnd, msg, wParam, lParam)
+	}
+
 	wi := widgetFromHWND(hwnd)
 	if wi == nil {
 		return DefWindowProc(hwnd, msg, wParam, lParam)
 	}
 
+	if wb := wi.BaseWidget(); wb.designMode {
+		if result, handled := wb.designModeWndProc(msg, wParam, lParam); handled {
+			return result
+		}
+	}
+
+	wb := wi.BaseWidget()
+
+	if len(wb.overlays) > 0 {
+		if result, handled := wb.overlayWndProc(msg, wParam, lParam); handled {
+			return result
+		}
+	}
+
 	result = wi.WndProc(hwnd, msg, wParam, lParam)
 
+	if len(wb.overlays) > 0 && wb.hWnd != 0 {
+		if msg == WM_PAINT {
+			wb.paintOverlays()
+		} else if overlaysNeedPaint(msg) {
+			wb.invalidateOverlays()
+		}
+	}
+
 	return
 }
 
@@ -1436,7 +1679,12 @@ func widgetWndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) (result uintpt
 func (wb *WidgetBase) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	switch msg {
 	case WM_ERASEBKGND:
-		if wb.background == nil {
+		background := wb.background
+		if background == nil && wb.origWndProcPtr == 0 && appSingleton.DarkColors() {
+			// Windows of our own classes are painted like dialogs.
+			background, _, _ = darkBrushes()
+		}
+		if background == nil {
 			break
 		}
 
@@ -1446,7 +1694,7 @@ func (wb *WidgetBase) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uin
 		}
 		defer canvas.Dispose()
 
-		if err := canvas.FillRectangle(wb.background, wb.ClientBounds()); err != nil {
+		if err := canvas.FillRectangle(background, wb.ClientBounds()); err != nil {
 			break
 		}
 
@@ -1480,24 +1728,7 @@ func (wb *WidgetBase) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uin
 		}
 
 	case WM_CONTEXTMENU:
-		sourceWidget := widgetFromHWND(HWND(wParam))
-		if sourceWidget == nil {
-			break
-		}
-
-		x := GET_X_LPARAM(lParam)
-		y := GET_Y_LPARAM(lParam)
-
-		contextMenu := sourceWidget.ContextMenu()
-
-		if contextMenu != nil {
-			TrackPopupMenuEx(
-				contextMenu.hMenu,
-				TPM_NOANIMATION,
-				x,
-				y,
-				rootWidget(sourceWidget).BaseWidget().hWnd,
-				nil)
+		if wb.showContextMenu(wParam, lParam) {
 			return 0
 		}
 
@@ -1511,8 +1742,12 @@ func (wb *WidgetBase) WndProc(hwnd HWND, msg uint32, wParam, lParam uintptr) uin
 		wb.persistState(wParam != 0)
 
 	case WM_DESTROY:
+		if wb.hWnd != 0 {
+			// We are not being destroyed via Dispose.
+			wb.disposingPublisher.Publish()
+		}
 		wb.persistState(false)
-		if _, ok := wb.widget.(*ToolTip); !ok && wb.hWnd != 0 {
+		if _, ok := wb.widget.(*ToolTip); !ok && wb.hWnd != 0 && globalToolTip != nil {
 			globalToolTip.RemoveTool(wb.widget)
 		}
 		wb.hWnd = 0